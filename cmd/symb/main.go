@@ -1,15 +1,16 @@
 package main
 
 import (
+	"cmp"
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
@@ -17,6 +18,9 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/xonecas/symb/internal/config"
 	"github.com/xonecas/symb/internal/delta"
+	"github.com/xonecas/symb/internal/fswatch"
+	"github.com/xonecas/symb/internal/highlight"
+	"github.com/xonecas/symb/internal/logging"
 	"github.com/xonecas/symb/internal/lsp"
 	"github.com/xonecas/symb/internal/mcp"
 	"github.com/xonecas/symb/internal/mcptools"
@@ -28,10 +32,6 @@ import (
 )
 
 func main() {
-	if err := setupFileLogging(); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to setup logging: %v\n", err)
-	}
-
 	// Parse CLI flags.
 	flagSession := flag.String("s", "", "resume a session by ID")
 	flagList := flag.Bool("l", false, "list sessions")
@@ -39,6 +39,20 @@ func main() {
 	flag.StringVar(flagSession, "session", "", "resume a session by ID")
 	flag.BoolVar(flagList, "list", false, "list sessions")
 	flag.BoolVar(flagContinue, "continue", false, "continue most recent session")
+	flagDebug := flag.Bool("debug", false, "log at trace level (overrides log.level)")
+	flagResume := flag.Bool("resume", false, "pick a session to resume")
+	flagOffline := flag.Bool("offline", false, "block network tools and non-local providers")
+	flagDryRun := flag.Bool("dry-run", false, "preview Edit calls and run only read-only Shell commands")
+	flagCheck := flag.Bool("check", false, "check the provider endpoint and credentials, then exit")
+	flagProfile := flag.String("profile", "", "start with a named profile from [profiles]")
+	flagServeMCP := flag.Bool("serve-mcp", false, "serve the built-in tools as an MCP server over stdio")
+	flagCwd := flag.String("cwd", "", "use this directory as the working tree root")
+	flagPrompt := flag.String("prompt", "", "run one turn with this prompt without the TUI, then exit")
+	flagStdin := flag.Bool("stdin", false, "read the --prompt turn's prompt (or the rest of it) from stdin")
+	flagJSON := flag.Bool("json", false, "write the --prompt turn to stdout as newline-delimited JSON events")
+	flagRecord := flag.String("record", "", "record the session's turns to this file for --replay")
+	flagReplay := flag.String("replay", "", "play back a --record file in the UI without a provider, then wait")
+	flagReplaySpeed := flag.Float64("replay-speed", 1, "--replay pace relative to the recording; 0 plays without pauses")
 	flag.Parse()
 
 	configPath := filepath.Join(".", "config.toml")
@@ -50,39 +64,81 @@ func main() {
 	}
 	cfg, err := config.Load(configPath)
 	if err != nil {
-		fmt.Printf("Error loading config: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 		os.Exit(1)
 	}
 
+	for _, w := range cfg.EndpointWarnings() {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+
+	redactor, err := setupFileLogging(cfg.Log, *flagDebug)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to setup logging: %v\n", err)
+	}
+
 	creds, err := config.LoadCredentials()
 	if err != nil {
-		fmt.Printf("Error loading credentials: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error loading credentials: %v\n", err)
 		os.Exit(1)
 	}
+	if redactor != nil {
+		for _, pc := range creds.Providers {
+			redactor.AddSecret(pc.APIKey)
+		}
+	}
 
-	registry := buildRegistry(cfg, creds)
+	if *flagOffline {
+		cfg.Offline = true
+	}
+	if *flagDryRun {
+		cfg.DryRun = true
+	}
 
-	providerName, providerCfg := resolveProvider(cfg, registry)
+	if dir := cmp.Or(*flagCwd, cfg.Cwd); dir != "" {
+		if err := chdirWorkTree(dir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	providerOpts := provider.Options{
-		Temperature: providerCfg.Temperature,
+	if *flagServeMCP {
+		os.Exit(serveMCP(cfg, creds))
 	}
-	prov, err := registry.Create(providerName, providerCfg.Model, providerOpts)
-	if err != nil {
-		fmt.Printf("Error creating provider: %v\n", err)
-		os.Exit(1)
+
+	if *flagReplay != "" {
+		os.Exit(replaySession(cfg, *flagReplay, *flagReplaySpeed))
+	}
+
+	registry := buildRegistry(cfg, creds)
+
+	providerName, providerCfg := resolveProvider(cfg, registry, *flagProfile)
+
+	if *flagCheck {
+		os.Exit(checkProvider(registry, providerName, providerCfg))
 	}
-	defer prov.Close()
 
 	// sharedProvider is the single source of truth for the active provider.
 	// Both the TUI and SubAgentHandler read/write it so model switches are
-	// immediately visible to sub-agents.
+	// immediately visible to sub-agents. It is filled in once the session
+	// is known, since the provider transcript is per session.
 	sharedProvider := &atomic.Pointer[provider.Provider]{}
-	sharedProvider.Store(&prov)
+
+	// exitCode is returned once the deferred shutdown below has run.
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
 
 	svc := setupServices(cfg, creds)
 	defer svc.proxy.Close()
-	defer svc.lspManager.StopAll(context.Background())
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		svc.lspManager.StopAll(ctx)
+	}()
 	if svc.webCache != nil {
 		defer svc.webCache.Close()
 	}
@@ -95,7 +151,7 @@ func main() {
 
 	tools, err := svc.proxy.ListTools(context.Background())
 	if err != nil {
-		fmt.Printf("Warning: Failed to list tools: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: Failed to list tools: %v\n", err)
 		tools = []mcp.Tool{}
 	}
 
@@ -109,54 +165,317 @@ func main() {
 		tools,
 		svc.proxy.Upstream(),
 	)
+	subAgentHandler.SetDryRun(cfg.DryRun)
+	subAgentHandler.SetEditWindow(cfg.Limits.EditWindowLines, cfg.Limits.EditContextLines)
+	subAgentHandler.SetTestHandler(svc.testHandler)
+	subAgentHandler.SetBuildHandler(svc.buildHandler)
 	svc.proxy.RegisterTool(mcptools.NewSubAgentTool(), subAgentHandler.Handle)
 
 	// Re-fetch tools list to include SubAgent
 	tools, err = svc.proxy.ListTools(context.Background())
 	if err != nil {
-		fmt.Printf("Warning: Failed to list tools after SubAgent registration: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: Failed to list tools after SubAgent registration: %v\n", err)
 		tools = []mcp.Tool{}
 	}
 
+	if *flagResume && *flagSession == "" {
+		if svc.webCache == nil {
+			fmt.Fprintln(os.Stderr, "No cache available")
+			os.Exit(1)
+		}
+		id, err := tui.PickSession(svc.webCache, cfg.UI.SyntaxThemeOrDefault(), tui.ColorOptions(cfg.UI.ColorsOrDefault())...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error picking session: %v\n", err)
+			os.Exit(1)
+		}
+		if id == "" {
+			return
+		}
+		*flagSession = id
+	}
+
 	sessionID, resumeHistory := resolveSession(*flagSession, *flagContinue, svc.webCache)
+	restoreScratchpad(svc.scratchpad, sessionID, svc.webCache)
+	restoreWorkDir(sessionID, *flagSession != "" || *flagContinue, *flagCwd != "", svc)
+
+	providerOpts := provider.Options{
+		Temperature:    provider.ConfiguredTemperature(providerCfg.Temperature),
+		StripReasoning: cfg.StripReasoning,
+	}
+	if cfg.Log.Transcripts {
+		transcript, err := openTranscript(sessionID, creds)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to open transcript: %v\n", err)
+		} else {
+			defer transcript.Close()
+			providerOpts.Transcript = transcript
+		}
+	}
+	prov, err := registry.Create(providerName, providerCfg.Model, providerOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating provider: %v\n", err)
+		os.Exit(1)
+	}
+	defer prov.Close()
+	sharedProvider.Store(&prov)
 
 	// Build tree-sitter project symbol index.
 	cwd, err := os.Getwd()
 	if err != nil {
-		fmt.Printf("Warning: failed to get working directory: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: failed to get working directory: %v\n", err)
 		cwd = "."
 	}
 	tsIndex := treesitter.NewIndex(cwd)
-	if err := tsIndex.Build(); err != nil {
-		log.Warn().Err(err).Msg("tree-sitter index build failed")
-	}
+	tsIndex.SetLimits(int64(cfg.Index.MaxFileKBOrDefault())<<10, cfg.Index.Skip)
 
-	// Wire index into Read/Edit handlers for incremental updates.
+	// Wire index into Read/Edit handlers for incremental updates. The index
+	// is built in the background; updates before it finishes are fine.
 	svc.readHandler.SetTSIndex(tsIndex)
 	svc.editHandler.SetTSIndex(tsIndex)
+	svc.replaceHandler.SetTSIndex(tsIndex)
+	svc.outlineHandler.SetTSIndex(tsIndex)
+
+	// Watch the working tree for changes made outside symb. Edits report
+	// their writes so they are not mistaken for external changes.
+	watcher, err := fswatch.New(cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to watch files: %v\n", err)
+	}
+	svc.editHandler.SetWatcher(watcher)
+	svc.replaceHandler.SetWatcher(watcher)
+	subAgentHandler.SetWatcher(watcher)
 
 	// Set session on delta tracker so file deltas are linked.
 	if svc.deltaTracker != nil {
 		svc.deltaTracker.SetSession(sessionID)
 	}
 
-	p := tea.NewProgram(
-		tui.New(prov, sharedProvider, svc.proxy, tools, providerCfg.Model, svc.webCache, sessionID, tsIndex, svc.deltaTracker, svc.fileTracker, providerName, svc.scratchpad, resumeHistory, registry, providerOpts, cfg.UI.SyntaxThemeOrDefault()),
+	provider.SetContextWindows(cfg.ContextWindows)
+	prices := make(map[string]provider.Price, len(cfg.Prices))
+	for model, p := range cfg.Prices {
+		prices[model] = provider.Price{Input: p.Input, Output: p.Output}
+	}
+	provider.SetPrices(prices)
+
+	if *flagPrompt != "" || *flagStdin {
+		prompt, err := readOneShotPrompt(*flagPrompt, *flagStdin, os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exitCode = 1
+			return
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+		defer stop()
+		// The system prompt lists the project's symbols, so the index is
+		// built before the turn rather than alongside it.
+		if err := tsIndex.Build(ctx, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to build the symbol index: %v\n", err)
+		}
+		exitCode = oneShotTurn{
+			prov:         prov,
+			proxy:        svc.proxy,
+			tools:        tools,
+			db:           svc.webCache,
+			sessionID:    sessionID,
+			history:      resumeHistory,
+			modelID:      providerCfg.Model,
+			idx:          tsIndex,
+			dt:           svc.deltaTracker,
+			pad:          svc.scratchpad,
+			confirmTools: cfg.UI.ConfirmTools,
+			turnTimeout:  time.Duration(cfg.Limits.TurnSeconds) * time.Second,
+			tokenLimit:   cfg.Limits.SessionTokens,
+			autoContinue: cfg.Limits.AutoContinue,
+			jsonOut:      *flagJSON,
+			stdout:       os.Stdout,
+			stderr:       os.Stderr,
+		}.run(ctx, prompt)
+		return
+	}
+
+	highlight.SetCacheSize(cfg.UI.HighlightCacheMBOrDefault() << 20)
+
+	opts := append([]tea.ProgramOption{
 		tea.WithFilter(tui.MouseEventFilter),
-	)
-	svc.lspManager.SetCallback(func(absPath string, lines map[int]int) {
-		p.Send(tui.LSPDiagnosticsMsg{FilePath: absPath, Lines: lines})
+		tea.WithoutSignalHandler(),
+	}, tui.ColorOptions(cfg.UI.ColorsOrDefault())...)
+	model := tui.New(prov, sharedProvider, svc.proxy, tools, providerCfg.Model, svc.webCache, sessionID, tsIndex, svc.deltaTracker, svc.fileTracker, providerName, svc.scratchpad, resumeHistory, registry, providerOpts, cfg.ResolvedProfiles(), cfg.Prompts, cfg.UI, cfg.Limits, cfg.Session)
+	if *flagRecord != "" {
+		f, err := os.Create(*flagRecord)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exitCode = 1
+			return
+		}
+		defer f.Close()
+		model = model.WithRecording(f)
+	}
+	p := tea.NewProgram(model, opts...)
+	handleSignals(p)
+	svc.lspManager.SetCallback(func(absPath string, lines map[int]int, messages map[int]string) {
+		p.Send(tui.LSPDiagnosticsMsg{FilePath: absPath, Lines: lines, Messages: messages})
 	})
 
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error running symb: %v\n", err)
+	// Build the symbol index in the background so large repos don't delay
+	// startup. Cancelled on shutdown so quitting mid-index stops the walk.
+	indexCtx, cancelIndex := context.WithCancel(context.Background())
+	defer cancelIndex()
+	go func() {
+		err := tsIndex.Build(indexCtx, func(files int) {
+			p.Send(tui.IndexProgressMsg{Files: files})
+		})
+		p.Send(tui.IndexDoneMsg{Err: err})
+	}()
+
+	// Externally changed files must be read again before an edit, by the
+	// agent and any running sub-agent. Cached results that read them are
+	// dropped, and they are re-indexed and reloaded in the viewer.
+	if watcher != nil {
+		go watcher.Run(indexCtx, func(paths []string) {
+			svc.proxy.InvalidateFiles(paths...)
+			for _, path := range paths {
+				svc.fileTracker.Forget(path)
+				subAgentHandler.Forget(path)
+				tsIndex.UpdateFile(path)
+			}
+			p.Send(tui.FilesChangedMsg{Paths: paths})
+		})
+	}
+
+	final, err := p.Run()
+	// Write any queued messages so the last turn isn't lost, whatever the
+	// quit path. A no-op after ctrl+c, which already flushed.
+	if m, ok := final.(tui.Model); ok && !m.FlushStore(shutdownTimeout) {
+		fmt.Fprintln(os.Stderr, "Warning: timed out saving the last messages")
+	}
+	if err != nil {
+		cancelIndex()
+		fmt.Fprintf(os.Stderr, "Error running symb: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// replaySession plays a --record file back in the TUI. The model gets no
+// provider, store, tools or delta tracker, so playback only drives the
+// display: nothing is sent, saved, or written to files. It returns the
+// process exit code.
+func replaySession(cfg *config.Config, path string, speed float64) int {
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	rec, err := tui.LoadRecording(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", path, err)
+		return 1
+	}
+
+	highlight.SetCacheSize(cfg.UI.HighlightCacheMBOrDefault() << 20)
+	model := tui.New(nil, nil, nil, nil, "replay", nil, "replay", nil, nil, nil, "replay", nil, nil, nil, provider.Options{}, nil, nil, cfg.UI, config.LimitsConfig{}, config.SessionConfig{})
+	opts := append([]tea.ProgramOption{
+		tea.WithFilter(tui.MouseEventFilter),
+		tea.WithoutSignalHandler(),
+	}, tui.ColorOptions(cfg.UI.ColorsOrDefault())...)
+	p := tea.NewProgram(model.WithReplay(rec, speed), opts...)
+	handleSignals(p)
+	if _, err := p.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error running symb: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// checkTimeout bounds the --check request.
+const checkTimeout = 10 * time.Second
+
+// checkProvider runs provider.Check against the configured provider and
+// prints a pass/fail line. It returns the process exit code.
+func checkProvider(registry *provider.Registry, name string, pcfg config.ProviderConfig) int {
+	endpoint := pcfg.Endpoint
+	if endpoint == "" {
+		endpoint = "default endpoint"
+	}
+	prov, err := registry.Create(name, pcfg.Model, provider.Options{})
+	if err != nil {
+		fmt.Printf("FAIL %s (%s): %v\n", name, endpoint, err)
+		return 1
+	}
+	defer prov.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+	n, err := provider.Check(ctx, prov)
+	if err != nil {
+		if status := provider.HTTPStatus(err); status != 0 {
+			fmt.Printf("FAIL %s (%s): HTTP %d: %v\n", name, endpoint, status, err)
+		} else {
+			fmt.Printf("FAIL %s (%s): %v\n", name, endpoint, err)
+		}
+		return 1
+	}
+	fmt.Printf("ok   %s (%s): HTTP 200, %d models available\n", name, endpoint, n)
+	return 0
+}
+
+// serveMCP exposes the tool proxy as an MCP server on stdin/stdout until
+// stdin closes or a signal arrives. Stdout carries the protocol, so
+// anything else that would print there goes to stderr instead. SubAgent is
+// not served since it needs a provider. It returns the process exit code.
+func serveMCP(cfg *config.Config, creds *config.Credentials) int {
+	out := os.Stdout
+	os.Stdout = os.Stderr
+
+	svc := setupServices(cfg, creds)
+	defer svc.proxy.Close()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		svc.lspManager.StopAll(ctx)
+	}()
+	if svc.webCache != nil {
+		defer svc.webCache.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+	if err := svc.proxy.Serve(ctx, os.Stdin, out); err != nil {
+		fmt.Fprintf(os.Stderr, "Error serving MCP: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// shutdownTimeout bounds each shutdown step (store flush, LSP stop).
+const shutdownTimeout = 5 * time.Second
+
+// handleSignals quits the program on SIGINT, SIGTERM or SIGHUP so the normal
+// shutdown path runs: flush the store queue, stop language servers, close
+// the cache. If shutdown still hangs, the process exits after a hard cap.
+// Signals stay caught for the life of the process so a second one can't
+// interrupt cleanup.
+func handleSignals(p *tea.Program) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		s := <-sig
+		log.Info().Str("signal", s.String()).Msg("shutting down")
+		time.AfterFunc(3*shutdownTimeout, func() {
+			log.Warn().Msg("shutdown timed out")
+			os.Exit(1)
+		})
+		p.Quit()
+	}()
+}
+
 func buildRegistry(cfg *config.Config, creds *config.Credentials) *provider.Registry {
 	registry := provider.NewRegistry()
 	for name, providerCfg := range cfg.Providers {
+		if cfg.Offline && !provider.IsLocalEndpoint(providerCfg.Endpoint) {
+			log.Info().Str("provider", name).Msg("Skipping non-local provider in offline mode")
+			continue
+		}
 		apiKey := creds.GetAPIKey(name)
 		if apiKey != "" {
 			log.Info().Str("provider", name).Bool("has_api_key", true).Msg("Registering ZenFactory")
@@ -169,35 +488,59 @@ func buildRegistry(cfg *config.Config, creds *config.Credentials) *provider.Regi
 	return registry
 }
 
-func resolveProvider(cfg *config.Config, registry *provider.Registry) (string, config.ProviderConfig) {
+// resolveProvider picks the startup provider: the named profile (or
+// default_profile), else default_provider, else the first registered one.
+func resolveProvider(cfg *config.Config, registry *provider.Registry, profile string) (string, config.ProviderConfig) {
+	if profile == "" {
+		profile = cfg.DefaultProfile
+	}
 	name := cfg.DefaultProvider
+	prof, hasProfile := cfg.ResolvedProfiles()[profile]
+	if profile != "" {
+		if !hasProfile {
+			fmt.Fprintf(os.Stderr, "Error: Profile %q not found\n", profile)
+			os.Exit(1)
+		}
+		name = prof.Provider
+	}
 	if name == "" {
 		providers := registry.List()
 		if len(providers) == 0 {
-			fmt.Println("Error: No providers configured")
+			fmt.Fprintln(os.Stderr, "Error: No providers configured")
 			os.Exit(1)
 		}
 		name = providers[0]
 	}
 	pcfg, ok := cfg.Providers[name]
 	if !ok {
-		fmt.Printf("Error: Provider %q not found\n", name)
+		fmt.Fprintf(os.Stderr, "Error: Provider %q not found\n", name)
+		os.Exit(1)
+	}
+	if cfg.Offline && !provider.IsLocalEndpoint(pcfg.Endpoint) {
+		fmt.Fprintf(os.Stderr, "Error: Provider %q (%s) is not local and offline mode is on\n", name, pcfg.Endpoint)
 		os.Exit(1)
 	}
+	if hasProfile {
+		pcfg.Model, pcfg.Temperature = prof.Model, prof.Temperature
+	}
 	return name, pcfg
 }
 
 type services struct {
-	proxy        *mcp.Proxy
-	lspManager   *lsp.Manager
-	webCache     *store.Cache
-	readHandler  *mcptools.ReadHandler
-	editHandler  *mcptools.EditHandler
-	shellHandler *mcptools.ShellHandler
-	fileTracker  *mcptools.FileReadTracker
-	deltaTracker *delta.Tracker
-	scratchpad   *mcptools.Scratchpad
-	shell        *shell.Shell
+	proxy          *mcp.Proxy
+	lspManager     *lsp.Manager
+	webCache       *store.Cache
+	readHandler    *mcptools.ReadHandler
+	editHandler    *mcptools.EditHandler
+	replaceHandler *mcptools.BulkReplaceHandler
+	outlineHandler *mcptools.OutlineHandler
+	shellHandler   *mcptools.ShellHandler
+	testHandler    *mcptools.TestHandler
+	buildHandler   *mcptools.BuildHandler
+	fileTracker    *mcptools.FileReadTracker
+	deltaTracker   *delta.Tracker
+	scratchpad     *mcptools.Scratchpad
+	shell          *shell.Shell
 }
 
 func setupServices(cfg *config.Config, creds *config.Credentials) services {
@@ -210,112 +553,214 @@ func setupServices(cfg *config.Config, creds *config.Credentials) services {
 
 	var mcpClient mcp.UpstreamClient
 	if upstream != "" {
-		mcpClient = mcp.NewClient(upstream)
+		client := mcp.NewClient(upstream)
+		client.SetUserAgent(cfg.MCP.UserAgentOrDefault(mcp.Version))
+		mcpClient = client
 	}
 	proxy := mcp.NewProxy(mcpClient)
+	proxy.SetOffline(cfg.Offline)
+	proxy.SetRetries(cfg.MCP.RetriesOrDefault())
+	proxy.SetResultTokens(cfg.ToolResultTokens)
 	if err := proxy.Initialize(context.Background()); err != nil {
-		fmt.Printf("Warning: MCP init failed: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: MCP init failed: %v\n", err)
 	}
 
-	lspManager := lsp.NewManager()
+	lspManager := lsp.NewManager(lspOverrides(cfg.LSP), time.Duration(cfg.LSP.StartTimeoutOrDefault())*time.Second)
+	lspManager.SetMaxSeverity(lsp.ParseSeverity(cfg.LSP.SeverityOrDefault()))
+	lspManager.SetWaitTimeout(time.Duration(cfg.LSP.WaitOrDefault()) * time.Second)
 	fileTracker := mcptools.NewFileReadTracker()
 
 	readHandler := mcptools.NewReadHandler(fileTracker, lspManager)
+	readHandler.SetLimits(cfg.Limits.ReadLines, cfg.Limits.ReadChars)
+	readHandler.SetPolicy(int64(cfg.Limits.ReadMaxKB)<<10, cfg.Limits.ReadRefuseBinary, cfg.Limits.ReadRefuseIgnored)
 	proxy.RegisterTool(mcptools.NewReadTool(), readHandler.Handle)
 
 	proxy.RegisterTool(mcptools.NewGrepTool(), mcptools.MakeGrepHandler())
 
+	outlineHandler := mcptools.NewOutlineHandler()
+	proxy.RegisterTool(mcptools.NewOutlineTool(), outlineHandler.Handle)
+
+	proxy.RegisterTool(mcptools.NewRecentFilesTool(), mcptools.MakeRecentFilesHandler())
+
 	webCache := openWebCache(cfg)
 
-	// Create delta tracker for undo support, sharing the same DB.
+	// Create delta tracker for undo support, sharing the same DB. It also
+	// records the session's file audit, which the tools feed as they run.
 	var dt *delta.Tracker
 	if webCache != nil {
 		dt = delta.New(webCache.DB())
+		dt.SetAudit(webCache.RecordFileAccess)
+		readHandler.SetAudit(dt)
 	}
 
 	editHandler := mcptools.NewEditHandler(fileTracker, lspManager, dt)
+	editHandler.SetDryRun(cfg.DryRun)
+	editHandler.SetWindow(cfg.Limits.EditWindowLines, cfg.Limits.EditContextLines)
 	proxy.RegisterTool(mcptools.NewEditTool(), editHandler.Handle)
 
+	replaceHandler := mcptools.NewBulkReplaceHandler(dt)
+	replaceHandler.SetDryRun(cfg.DryRun)
+	proxy.RegisterTool(mcptools.NewBulkReplaceTool(), replaceHandler.Handle)
+
 	// Shell tool — in-process POSIX interpreter with command blocking.
 	sh := shell.New("", shell.DefaultBlockFuncs())
 	shellHandler := mcptools.NewShellHandler(sh)
+	shellHandler.SetDryRun(cfg.DryRun)
 	proxy.RegisterTool(mcptools.NewShellTool(), shellHandler.Handle)
 
+	testHandler := mcptools.NewTestHandler(sh)
+	testHandler.SetCommand(cfg.Test.Command)
+	testHandler.SetLimits(cfg.Test.TimeoutSeconds, cfg.Test.FailureLines)
+	testHandler.SetDryRun(cfg.DryRun)
+	proxy.RegisterTool(mcptools.NewTestTool(), testHandler.Handle)
+
+	buildHandler := mcptools.NewBuildHandler(sh)
+	buildHandler.SetLanguages(buildLanguages(cfg.Build))
+	buildHandler.SetDryRun(cfg.DryRun)
+	proxy.RegisterTool(mcptools.NewBuildTool(), buildHandler.Handle)
+
 	// TodoWrite tool — agent scratchpad for plan/notes recitation.
 	pad := &mcptools.Scratchpad{}
 	proxy.RegisterTool(mcptools.NewTodoWriteTool(), mcptools.MakeTodoWriteHandler(pad))
 
+	// Cache Read and Grep results. Edit drops the results for its file;
+	// tools that may change any file (Shell, BulkReplace, ...) drop them all.
+	if ttl := cfg.Cache.ToolResultsTTLOrDefault(); ttl > 0 {
+		proxy.EnableCache(time.Duration(ttl)*time.Second, map[string]mcp.CachePolicy{
+			"Read":        {Cache: true, Files: readHandler.Files, Hit: readHandler.MarkRead},
+			"Grep":        {Cache: true},
+			"Edit":        {Files: editHandler.Files},
+			"Outline":     {Files: mcp.NoFiles},
+			"RecentFiles": {Files: mcp.NoFiles},
+			"TodoWrite":   {Files: mcp.NoFiles},
+		})
+	}
+
 	return services{
-		proxy:        proxy,
-		lspManager:   lspManager,
-		webCache:     webCache,
-		readHandler:  readHandler,
-		editHandler:  editHandler,
-		shellHandler: shellHandler,
-		fileTracker:  fileTracker,
-		deltaTracker: dt,
-		scratchpad:   pad,
-		shell:        sh,
+		proxy:          proxy,
+		lspManager:     lspManager,
+		webCache:       webCache,
+		readHandler:    readHandler,
+		editHandler:    editHandler,
+		replaceHandler: replaceHandler,
+		outlineHandler: outlineHandler,
+		shellHandler:   shellHandler,
+		testHandler:    testHandler,
+		buildHandler:   buildHandler,
+		fileTracker:    fileTracker,
+		deltaTracker:   dt,
+		scratchpad:     pad,
+		shell:          sh,
+	}
+}
+
+// buildLanguages converts [build] config into Build tool languages.
+func buildLanguages(cfg map[string]config.BuildConfig) map[string]mcptools.BuildLanguage {
+	langs := make(map[string]mcptools.BuildLanguage, len(cfg))
+	for name, b := range cfg {
+		langs[name] = mcptools.BuildLanguage{Marker: b.Marker, Command: b.Command, ErrorRegex: b.ErrorRegex}
+	}
+	return langs
+}
+
+// lspOverrides converts [lsp.servers] config into manager overrides.
+func lspOverrides(cfg config.LSPConfig) map[string]lsp.ServerOverride {
+	overrides := make(map[string]lsp.ServerOverride, len(cfg.Servers))
+	for name, s := range cfg.Servers {
+		overrides[name] = lsp.ServerOverride{
+			Disabled:    !s.EnabledOrDefault(),
+			Command:     s.Command,
+			Args:        s.Args,
+			FileTypes:   s.FileTypes,
+			RootMarkers: s.RootMarkers,
+			Environment: s.Env,
+			Settings:    s.Settings,
+			InitOptions: s.InitOptions,
+		}
 	}
+	return overrides
 }
 
 func openWebCache(cfg *config.Config) *store.Cache {
 	cacheDir, err := config.EnsureDataDir()
 	if err != nil {
-		fmt.Printf("Warning: cache dir failed: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: cache dir failed: %v\n", err)
 		return nil
 	}
 	cacheTTL := time.Duration(cfg.Cache.CacheTTLOrDefault()) * time.Hour
 	cache, err := store.Open(filepath.Join(cacheDir, "cache.db"), cacheTTL)
 	if err != nil {
-		fmt.Printf("Warning: cache open failed: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: cache open failed: %v\n", err)
 		return nil
 	}
 	return cache
 }
 
-func newSessionID() string {
-	b := make([]byte, 16)
-	if _, err := rand.Read(b); err != nil {
-		log.Warn().Err(err).Msg("failed to read random bytes for session id")
-		return fmt.Sprintf("%x", time.Now().UnixNano())
-	}
-	return hex.EncodeToString(b)
-}
-
-func setupFileLogging() error {
+// setupFileLogging points the global logger at a rotating symb.log behind a
+// redactor. The caller registers credential values on the returned redactor.
+func setupFileLogging(logCfg config.LogConfig, debug bool) (*logging.Redactor, error) {
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 
 	dataDir, err := config.DataDir()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	logDir := filepath.Join(dataDir, "logs")
 	if err := os.MkdirAll(logDir, 0750); err != nil {
-		return err
+		return nil, err
 	}
 
 	logFile := filepath.Join(logDir, "symb.log")
-	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	file, err := logging.OpenRotating(logFile,
+		int64(logCfg.MaxSizeMBOrDefault())<<20,
+		logCfg.MaxBackupsOrDefault(),
+		time.Duration(logCfg.MaxAgeDaysOrDefault())*24*time.Hour,
+	)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	level, err := zerolog.ParseLevel(logCfg.LevelOrDefault())
+	if err != nil {
+		return nil, err
+	}
+	if debug {
+		level = zerolog.TraceLevel
 	}
 
-	log.Logger = log.Output(file)
-	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	redactor := logging.NewRedactor(file)
+	log.Logger = log.Output(redactor)
+	zerolog.SetGlobalLevel(level)
 
-	return nil
+	return redactor, nil
+}
+
+// openTranscript opens (appending) logs/transcripts/<session>.log with every
+// configured credential registered for redaction.
+func openTranscript(sessionID string, creds *config.Credentials) (*logging.Transcript, error) {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return nil, err
+	}
+	t, err := logging.OpenTranscript(filepath.Join(dataDir, "logs", "transcripts"), sessionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, pc := range creds.Providers {
+		t.AddSecret(pc.APIKey)
+	}
+	return t, nil
 }
 
 func listSessions(db *store.Cache) {
 	if db == nil {
-		fmt.Println("No cache available")
+		fmt.Fprintln(os.Stderr, "No cache available")
 		return
 	}
 	sessions, err := db.ListSessions()
 	if err != nil {
-		fmt.Printf("Error listing sessions: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error listing sessions: %v\n", err)
 		return
 	}
 	if len(sessions) == 0 {
@@ -329,7 +774,7 @@ func listSessions(db *store.Cache) {
 		if len(preview) > 50 {
 			preview = preview[:50]
 		}
-		fmt.Printf("%s  %s  %s\n", s.ID, ts, preview)
+		fmt.Printf("%s  %s  %4d msgs  %s\n", s.ID, ts, s.Messages, preview)
 	}
 }
 
@@ -343,7 +788,7 @@ func resolveSession(flagSession string, flagContinue bool, db *store.Cache) (str
 		if db != nil {
 			ok, err := db.SessionExists(flagSession)
 			if err != nil || !ok {
-				fmt.Printf("Session %q not found\n", flagSession)
+				fmt.Fprintf(os.Stderr, "Session %q not found\n", flagSession)
 				os.Exit(1)
 			}
 		}
@@ -352,35 +797,89 @@ func resolveSession(flagSession string, flagContinue bool, db *store.Cache) (str
 
 	case flagContinue:
 		if db == nil {
-			fmt.Println("No cache available")
+			fmt.Fprintln(os.Stderr, "No cache available")
 			os.Exit(1)
 		}
 		id, err := db.LatestSessionID()
 		if err != nil {
-			fmt.Printf("No sessions to continue: %v\n", err)
+			fmt.Fprintf(os.Stderr, "No sessions to continue: %v\n", err)
 			os.Exit(1)
 		}
 		msgs := loadHistory(id, db)
 		return id, msgs
 
 	default:
-		sid := newSessionID()
+		sid := store.NewSessionID()
 		if db != nil {
 			if err := db.CreateSession(sid); err != nil {
-				fmt.Printf("Warning: failed to create session: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Warning: failed to create session: %v\n", err)
 			}
 		}
 		return sid, nil
 	}
 }
 
+// chdirWorkTree makes dir the working tree root. Path checks, file search,
+// the symbol index and the shell all resolve against the process cwd.
+func chdirWorkTree(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("cwd: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("cwd: %s is not a directory", dir)
+	}
+	return os.Chdir(dir)
+}
+
+// restoreWorkDir returns a resumed session to the working tree root it was
+// started in, unless --cwd overrides it, re-anchors the shell and Read's
+// ignore policy there and records the root on the session.
+func restoreWorkDir(sessionID string, resumed, override bool, svc services) {
+	db := svc.webCache
+	if resumed && !override {
+		dir, err := db.LoadWorkDir(sessionID)
+		if err != nil {
+			log.Warn().Err(err).Str("session", sessionID).Msg("failed to load session working directory")
+		} else if dir != "" {
+			if err := chdirWorkTree(dir); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: session working directory: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to get working directory")
+		return
+	}
+	svc.shell.SetRoot(cwd)
+	svc.readHandler.SetRoot(cwd)
+	if err := db.SaveWorkDir(sessionID, cwd); err != nil {
+		log.Warn().Err(err).Str("session", sessionID).Msg("failed to save session working directory")
+	}
+}
+
+// restoreScratchpad reloads the session's saved plan into pad. The TUI
+// persists later TodoWrite updates to whichever session is active.
+func restoreScratchpad(pad *mcptools.Scratchpad, sessionID string, db *store.Cache) {
+	if db == nil {
+		return
+	}
+	content, err := db.LoadScratchpad(sessionID)
+	if err != nil {
+		log.Warn().Err(err).Str("session", sessionID).Msg("failed to load scratchpad")
+	}
+	pad.SetContent(content)
+}
+
 func loadHistory(sessionID string, db *store.Cache) []provider.Message {
 	if db == nil {
 		return nil
 	}
 	stored, err := db.LoadMessages(sessionID)
 	if err != nil {
-		fmt.Printf("Warning: failed to load session history: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: failed to load session history: %v\n", err)
 		return nil
 	}
 	return storedToMessages(stored)
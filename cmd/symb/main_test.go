@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xonecas/symb/internal/mcptools"
+	"github.com/xonecas/symb/internal/shell"
+	"github.com/xonecas/symb/internal/store"
+)
+
+// TestRestoreWorkDir verifies resuming from another directory moves back
+// to the session's tree, and Read's ignore policy follows it there.
+func TestRestoreWorkDir(t *testing.T) {
+	launch, tree := t.TempDir(), t.TempDir()
+	for name, content := range map[string]string{".gitignore": "secret.txt\n", "secret.txt": "s3cret\n"} {
+		if err := os.WriteFile(filepath.Join(tree, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.CreateSession("s"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveWorkDir("s", tree); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir(launch)
+	read := mcptools.NewReadHandler(mcptools.NewFileReadTracker(), nil)
+	read.SetPolicy(0, false, true)
+	svc := services{webCache: db, shell: shell.New("", nil), readHandler: read}
+	restoreWorkDir("s", true, false, svc)
+
+	if wd, _ := os.Getwd(); wd != tree {
+		t.Fatalf("cwd = %s, want the session's tree %s", wd, tree)
+	}
+	if svc.shell.Dir() != tree {
+		t.Errorf("shell dir = %s", svc.shell.Dir())
+	}
+	args, _ := json.Marshal(mcptools.ReadArgs{File: "secret.txt"})
+	res, err := read.Handle(context.Background(), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := res.Content[0].Text; !strings.Contains(text, "gitignored") {
+		t.Errorf("Read of an ignored file in the session's tree = %q", text)
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/symb/internal/delta"
+	"github.com/xonecas/symb/internal/llm"
+	"github.com/xonecas/symb/internal/mcp"
+	"github.com/xonecas/symb/internal/mcptools"
+	"github.com/xonecas/symb/internal/provider"
+	"github.com/xonecas/symb/internal/store"
+	"github.com/xonecas/symb/internal/treesitter"
+)
+
+// readOneShotPrompt returns the --prompt text, followed by everything on
+// stdin when fromStdin is set, e.g. `git diff | symb --stdin --prompt "review"`.
+func readOneShotPrompt(prompt string, fromStdin bool, stdin io.Reader) (string, error) {
+	if fromStdin {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", fmt.Errorf("read stdin: %w", err)
+		}
+		prompt = strings.TrimSpace(strings.Join([]string{prompt, string(data)}, "\n\n"))
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return "", fmt.Errorf("empty prompt")
+	}
+	return prompt, nil
+}
+
+// oneShotTurn runs a single turn without the TUI: the assistant's reply
+// streams to stdout, tool calls and results go to stderr (or, with
+// jsonOut, everything goes to stdout as JSON events), and the turn is
+// saved to the session like any other.
+type oneShotTurn struct {
+	prov         provider.Provider
+	proxy        *mcp.Proxy
+	tools        []mcp.Tool
+	db           *store.Cache
+	sessionID    string
+	history      []provider.Message // the session so far; nil for a new one
+	modelID      string
+	idx          *treesitter.Index
+	dt           *delta.Tracker
+	pad          *mcptools.Scratchpad
+	confirmTools []string // declined: nobody is there to approve them
+	turnTimeout  time.Duration
+	tokenLimit   int // session_tokens; 0 = none
+	autoContinue int // limits.auto_continue
+	jsonOut      bool
+	stdout       io.Writer
+	stderr       io.Writer
+}
+
+// oneShotSink reports a one-shot turn as it runs.
+type oneShotSink interface {
+	delta(evt provider.StreamEvent)
+	message(msg provider.Message)
+	usage(inputTokens, outputTokens int)
+	autoContinue(prompt string)
+	// done reports the turn's token usage, its cost in USD if known, and
+	// the error that ended it, if any.
+	done(sessionID string, inputTokens, outputTokens int, cost *float64, err error)
+}
+
+// run runs the turn with prompt and returns the process exit code.
+func (o oneShotTurn) run(ctx context.Context, prompt string) int {
+	var out oneShotSink = &oneShotOutput{stdout: o.stdout, stderr: o.stderr}
+	if o.jsonOut {
+		out = newOneShotJSON(o.stdout, o.modelID)
+	}
+
+	cp, _, err := o.db.LoadCheckpoint(o.sessionID)
+	if err != nil {
+		log.Warn().Err(err).Str("session", o.sessionID).Msg("failed to load checkpoint")
+	}
+	budget := 0
+	if o.tokenLimit > 0 {
+		used := cp.InputTokens + cp.OutputTokens
+		if used >= o.tokenLimit {
+			out.done(o.sessionID, 0, 0, nil, fmt.Errorf("session token limit reached (%d of %d used)", used, o.tokenLimit))
+			return 1
+		}
+		budget = o.tokenLimit - used
+	}
+
+	history := o.history
+	var pending []provider.Message
+	if i := slices.IndexFunc(history, func(m provider.Message) bool { return m.Role == "system" }); i < 0 {
+		system := provider.Message{Role: "system", Content: llm.BuildSystemPrompt(o.modelID, o.idx), CreatedAt: time.Now()}
+		history = append([]provider.Message{system}, history...)
+		pending = append(pending, system)
+	} else if prompt, ok := llm.RefreshSystemPrompt(history[i].Content, o.modelID, o.idx); ok {
+		history = slices.Clone(history)
+		history[i].Content = prompt
+		if err := o.db.SaveSystemPrompt(o.sessionID, prompt); err != nil {
+			log.Warn().Err(err).Str("session", o.sessionID).Msg("failed to save system prompt")
+		}
+	}
+	user := provider.Message{Role: "user", Content: prompt, CreatedAt: time.Now()}
+	history = append(history, user)
+	pending = append(pending, user)
+	var userID int64
+	for _, msg := range pending {
+		if userID, err = o.db.SaveMessageSync(o.sessionID, store.FromProviderMessage(msg)); err != nil {
+			log.Warn().Err(err).Str("session", o.sessionID).Msg("failed to save message")
+		}
+	}
+	if o.dt != nil && userID > 0 {
+		o.dt.BeginTurn(userID)
+	}
+
+	var turnIn, turnOut int
+	err = llm.ProcessTurn(ctx, llm.ProcessTurnOptions{
+		Provider:       o.prov,
+		Proxy:          o.proxy,
+		Tools:          o.tools,
+		History:        history,
+		Scratchpad:     o.pad,
+		Confirm:        o.confirm,
+		TurnTimeout:    o.turnTimeout,
+		TokenBudget:    budget,
+		AutoContinue:   o.autoContinue,
+		OnDelta:        out.delta,
+		OnAutoContinue: out.autoContinue,
+		OnUsage: func(inputTokens, outputTokens int) {
+			turnIn += inputTokens
+			turnOut += outputTokens
+			out.usage(inputTokens, outputTokens)
+		},
+		OnMessage: func(msg provider.Message) {
+			o.db.SaveMessage(o.sessionID, store.FromProviderMessage(msg))
+			out.message(msg)
+		},
+	})
+
+	cp.Turns = append(cp.Turns, store.TurnCheckpoint{MsgID: userID, InputTokens: cp.InputTokens, OutputTokens: cp.OutputTokens})
+	cp.InputTokens += turnIn
+	cp.OutputTokens += turnOut
+	cp.Scratchpad = o.pad.Content()
+	if err := o.db.SaveCheckpoint(o.sessionID, cp); err != nil {
+		log.Warn().Err(err).Str("session", o.sessionID).Msg("failed to save checkpoint")
+	}
+
+	var cost *float64
+	if usd, ok := provider.Cost(o.modelID, turnIn, turnOut); ok {
+		cost = &usd
+	}
+	out.done(o.sessionID, turnIn, turnOut, cost, err)
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+// confirm declines calls to tools that need approval.
+func (o oneShotTurn) confirm(_ context.Context, call provider.ToolCall) (bool, string) {
+	if slices.Contains(o.confirmTools, call.Name) {
+		return false, call.Name + " needs approval (ui.confirm_tools), which is not possible without the TUI"
+	}
+	return true, ""
+}
+
+// oneShotOutput writes a turn as plain text: the reply on stdout, tool
+// activity on stderr.
+type oneShotOutput struct {
+	stdout, stderr io.Writer
+	streamed       bool // reply text was streamed since the last message
+	midLine        bool // stdout does not end in a newline
+}
+
+func (w *oneShotOutput) delta(evt provider.StreamEvent) {
+	if evt.Type == provider.EventContentDelta {
+		w.content(evt.Content)
+	}
+}
+
+func (w *oneShotOutput) usage(int, int) {}
+
+func (w *oneShotOutput) autoContinue(string) {
+	w.endLine()
+	fmt.Fprintln(w.stderr, "(auto-continuing)")
+}
+
+func (w *oneShotOutput) done(sessionID string, inputTokens, outputTokens int, cost *float64, err error) {
+	w.endLine()
+	spent := fmt.Sprintf("%d in, %d out tokens", inputTokens, outputTokens)
+	if cost != nil {
+		spent += fmt.Sprintf(", $%.4f", *cost)
+	}
+	fmt.Fprintf(w.stderr, "session %s (%s)\n", sessionID, spent)
+	if err != nil {
+		fmt.Fprintf(w.stderr, "Error: %v\n", err)
+	}
+}
+
+func (w *oneShotOutput) content(text string) {
+	if text == "" {
+		return
+	}
+	fmt.Fprint(w.stdout, text)
+	w.streamed = true
+	w.midLine = !strings.HasSuffix(text, "\n")
+}
+
+// endLine finishes a partly written stdout line.
+func (w *oneShotOutput) endLine() {
+	if w.midLine {
+		fmt.Fprintln(w.stdout)
+		w.midLine = false
+	}
+}
+
+// message reports a finished message. Replies that were not streamed, such
+// as a turn stopped by a limit, are written whole.
+func (w *oneShotOutput) message(msg provider.Message) {
+	switch msg.Role {
+	case "assistant":
+		if !w.streamed {
+			w.content(msg.Content)
+		}
+		w.endLine()
+		for _, call := range msg.ToolCalls {
+			fmt.Fprintf(w.stderr, "→ %s %s\n", call.Name, call.Arguments)
+		}
+	case "tool":
+		status := ""
+		if msg.IsError {
+			status = " (error)"
+		}
+		fmt.Fprintf(w.stderr, "← %s%s\n%s\n", msg.FunctionName, status, strings.TrimRight(msg.Content, "\n"))
+	}
+	w.streamed = false
+}
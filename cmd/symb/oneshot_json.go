@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/xonecas/symb/internal/provider"
+)
+
+// oneShotEvent is one line of --json output. Type says which fields are set:
+//
+//	text_delta, reasoning_delta  text
+//	assistant                    text, reasoning (the finished message)
+//	tool_call                    id, name, arguments
+//	tool_result                  id, name, content, is_error
+//	auto_continue                text (the prompt that keeps the turn going)
+//	usage                        usage (one model call)
+//	done                         session_id, usage (the whole turn), error
+//
+// Fields are only ever added, so consumers can ignore ones they don't know.
+type oneShotEvent struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	Reasoning string          `json:"reasoning,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	Content   string          `json:"content,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+	SessionID string          `json:"session_id,omitempty"`
+	Usage     *oneShotUsage   `json:"usage,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// oneShotUsage is token usage; CostUSD is null when the model has no
+// configured price.
+type oneShotUsage struct {
+	InputTokens  int      `json:"input_tokens"`
+	OutputTokens int      `json:"output_tokens"`
+	CostUSD      *float64 `json:"cost_usd"`
+}
+
+// oneShotJSON writes a turn to stdout as newline-delimited oneShotEvents.
+type oneShotJSON struct {
+	enc   *json.Encoder
+	model string // prices each usage event
+}
+
+func newOneShotJSON(w io.Writer, model string) *oneShotJSON {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &oneShotJSON{enc: enc, model: model}
+}
+
+func (j *oneShotJSON) emit(evt oneShotEvent) {
+	_ = j.enc.Encode(evt)
+}
+
+func (j *oneShotJSON) delta(evt provider.StreamEvent) {
+	switch evt.Type {
+	case provider.EventContentDelta:
+		j.emit(oneShotEvent{Type: "text_delta", Text: evt.Content})
+	case provider.EventReasoningDelta:
+		j.emit(oneShotEvent{Type: "reasoning_delta", Text: evt.Content})
+	}
+}
+
+func (j *oneShotJSON) message(msg provider.Message) {
+	switch msg.Role {
+	case "assistant":
+		if msg.Content != "" || msg.Reasoning != "" {
+			j.emit(oneShotEvent{Type: "assistant", Text: msg.Content, Reasoning: msg.Reasoning})
+		}
+		for _, call := range msg.ToolCalls {
+			args := call.Arguments
+			if !json.Valid(args) {
+				args, _ = json.Marshal(string(args)) // keep malformed arguments as a string
+			}
+			j.emit(oneShotEvent{Type: "tool_call", ID: call.ID, Name: call.Name, Arguments: args})
+		}
+	case "tool":
+		j.emit(oneShotEvent{Type: "tool_result", ID: msg.ToolCallID, Name: msg.FunctionName, Content: msg.Content, IsError: msg.IsError})
+	}
+}
+
+func (j *oneShotJSON) usage(inputTokens, outputTokens int) {
+	usage := &oneShotUsage{InputTokens: inputTokens, OutputTokens: outputTokens}
+	if cost, ok := provider.Cost(j.model, inputTokens, outputTokens); ok {
+		usage.CostUSD = &cost
+	}
+	j.emit(oneShotEvent{Type: "usage", Usage: usage})
+}
+
+func (j *oneShotJSON) autoContinue(prompt string) {
+	j.emit(oneShotEvent{Type: "auto_continue", Text: prompt})
+}
+
+func (j *oneShotJSON) done(sessionID string, inputTokens, outputTokens int, cost *float64, err error) {
+	evt := oneShotEvent{
+		Type:      "done",
+		SessionID: sessionID,
+		Usage:     &oneShotUsage{InputTokens: inputTokens, OutputTokens: outputTokens, CostUSD: cost},
+	}
+	if err != nil {
+		evt.Error = err.Error()
+	}
+	j.emit(evt)
+}
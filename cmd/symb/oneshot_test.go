@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xonecas/symb/internal/mcptools"
+	"github.com/xonecas/symb/internal/provider"
+	"github.com/xonecas/symb/internal/store"
+)
+
+// replyProvider answers every request with reply, or fails with err.
+type replyProvider struct {
+	reply string
+	err   error
+}
+
+func (p replyProvider) Name() string { return "reply" }
+
+func (p replyProvider) ChatStream(context.Context, []provider.Message, []provider.Tool) (<-chan provider.StreamEvent, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	ch := make(chan provider.StreamEvent, 3)
+	ch <- provider.StreamEvent{Type: provider.EventContentDelta, Content: p.reply}
+	ch <- provider.StreamEvent{Type: provider.EventUsage, InputTokens: 10, OutputTokens: 2}
+	ch <- provider.StreamEvent{Type: provider.EventDone}
+	close(ch)
+	return ch, nil
+}
+
+func (p replyProvider) ListModels(context.Context) ([]provider.Model, error) { return nil, nil }
+
+func (p replyProvider) Close() error { return nil }
+
+func TestReadOneShotPrompt(t *testing.T) {
+	tests := []struct {
+		prompt    string
+		fromStdin bool
+		stdin     string
+		want      string
+		wantErr   bool
+	}{
+		{"fix it", false, "ignored", "fix it", false},
+		{"review", true, "diff --git a b\n", "review\n\ndiff --git a b", false},
+		{"", true, "from stdin\n", "from stdin", false},
+		{"  ", false, "", "", true},
+		{"", true, "\n", "", true},
+	}
+	for _, tt := range tests {
+		got, err := readOneShotPrompt(tt.prompt, tt.fromStdin, strings.NewReader(tt.stdin))
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("readOneShotPrompt(%q, %v, %q) = %q, %v", tt.prompt, tt.fromStdin, tt.stdin, got, err)
+		}
+	}
+}
+
+// TestOneShotTurn verifies the exit codes of a one-shot turn and that a
+// turn on an existing session, as with --session, appends to it.
+func TestOneShotTurn(t *testing.T) {
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.CreateSession("s"); err != nil {
+		t.Fatal(err)
+	}
+	turn := func(prov provider.Provider, tokenLimit int) (int, string, string) {
+		t.Helper()
+		var stdout, stderr strings.Builder
+		code := oneShotTurn{
+			prov:       prov,
+			db:         db,
+			sessionID:  "s",
+			history:    loadHistory("s", db),
+			modelID:    "test",
+			pad:        &mcptools.Scratchpad{},
+			tokenLimit: tokenLimit,
+			stdout:     &stdout,
+			stderr:     &stderr,
+		}.run(context.Background(), "hi")
+		return code, stdout.String(), stderr.String()
+	}
+	roles := func() string {
+		t.Helper()
+		msgs, err := db.LoadMessages("s")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var roles []string
+		for _, msg := range msgs {
+			roles = append(roles, msg.Role)
+		}
+		return strings.Join(roles, " ")
+	}
+
+	if code, stdout, stderr := turn(replyProvider{reply: "hello"}, 0); code != 0 || stdout != "hello\n" {
+		t.Fatalf("first turn: code %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+	if code, _, _ := turn(replyProvider{reply: "again"}, 0); code != 0 {
+		t.Fatalf("second turn: code %d", code)
+	}
+	if got := roles(); got != "system user assistant user assistant" {
+		t.Errorf("session messages = %s, want the second turn appended", got)
+	}
+
+	if code, _, stderr := turn(replyProvider{err: errors.New("boom")}, 0); code != 1 || !strings.Contains(stderr, "boom") {
+		t.Errorf("provider error: code %d, stderr %q", code, stderr)
+	}
+	if code, _, stderr := turn(replyProvider{reply: "over"}, 20); code != 1 || !strings.Contains(stderr, "token limit") {
+		t.Errorf("token limit: code %d, stderr %q", code, stderr)
+	}
+}
+
+// TestOneShotJSON pins the shape of the --json events scripts rely on.
+func TestOneShotJSON(t *testing.T) {
+	defer provider.SetPrices(nil)
+	provider.SetPrices(map[string]provider.Price{"test": {Input: 1, Output: 2}})
+
+	var out strings.Builder
+	j := newOneShotJSON(&out, "vendor/test-model")
+	j.delta(provider.StreamEvent{Type: provider.EventContentDelta, Content: "<hi>"})
+	j.message(provider.Message{Role: "assistant", Content: "<hi>", ToolCalls: []provider.ToolCall{
+		{ID: "c1", Name: "Read", Arguments: []byte(`{"file":"a.go"}`)},
+		{ID: "c2", Name: "Grep", Arguments: []byte(`{"pattern":`)},
+	}})
+	j.message(provider.Message{Role: "tool", ToolCallID: "c1", FunctionName: "Read", Content: "no such file", IsError: true})
+	j.usage(1000, 500)
+	j.autoContinue("go on")
+	cost := 0.003
+	j.done("s1", 2000, 1000, &cost, nil)
+	j.done("s1", 0, 0, nil, errors.New("session token limit reached"))
+
+	want := []string{
+		`{"type":"text_delta","text":"<hi>"}`,
+		`{"type":"assistant","text":"<hi>"}`,
+		`{"type":"tool_call","id":"c1","name":"Read","arguments":{"file":"a.go"}}`,
+		`{"type":"tool_call","id":"c2","name":"Grep","arguments":"{\"pattern\":"}`,
+		`{"type":"tool_result","id":"c1","name":"Read","content":"no such file","is_error":true}`,
+		`{"type":"usage","usage":{"input_tokens":1000,"output_tokens":500,"cost_usd":0.002}}`,
+		`{"type":"auto_continue","text":"go on"}`,
+		`{"type":"done","session_id":"s1","usage":{"input_tokens":2000,"output_tokens":1000,"cost_usd":0.003}}`,
+		`{"type":"done","session_id":"s1","usage":{"input_tokens":0,"output_tokens":0,"cost_usd":null},"error":"session token limit reached"}`,
+	}
+	got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(got) != len(want) {
+		t.Fatalf("got %d events:\n%s", len(got), out.String())
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d = %s\nwant %s", i, got[i], want[i])
+		}
+	}
+}
@@ -8,9 +8,11 @@ require (
 	charm.land/lipgloss/v2 v2.0.0-beta.3.0.20251106192539-4b304240aab7
 	github.com/BurntSushi/toml v1.6.0
 	github.com/alecthomas/chroma/v2 v2.23.1
+	github.com/charmbracelet/colorprofile v0.4.1
 	github.com/charmbracelet/x/ansi v0.11.6
 	github.com/charmbracelet/x/exp/golden v0.0.0-20260209194814-eeb2896ac759
 	github.com/charmbracelet/x/powernap v0.0.0-20260209132835-6b065b8ba62c
+	github.com/fsnotify/fsnotify v1.10.1
 	github.com/rs/zerolog v1.34.0
 	github.com/sacenox/go-opencode-ai-zen-sdk v0.0.7
 	github.com/smacker/go-tree-sitter v0.0.0-20240827094217-dd81d9e9be82
@@ -20,7 +22,6 @@ require (
 
 require (
 	github.com/aymanbagabas/go-udiff v0.3.1 // indirect
-	github.com/charmbracelet/colorprofile v0.4.1 // indirect
 	github.com/charmbracelet/ultraviolet v0.0.0-20251116181749-377898bcce38 // indirect
 	github.com/charmbracelet/x/term v0.2.2 // indirect
 	github.com/charmbracelet/x/termios v0.1.1 // indirect
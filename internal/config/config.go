@@ -7,6 +7,10 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
 
 	"github.com/BurntSushi/toml"
 )
@@ -15,9 +19,297 @@ import (
 type Config struct {
 	DefaultProvider string                    `toml:"default_provider"`
 	Providers       map[string]ProviderConfig `toml:"providers"`
-	MCP             MCPConfig                 `toml:"mcp"`
-	Cache           CacheConfig               `toml:"cache"`
-	UI              UIConfig                  `toml:"ui"`
+	// DefaultProfile selects a profile at startup; it wins over
+	// DefaultProvider. --profile overrides it.
+	DefaultProfile string                   `toml:"default_profile"`
+	Profiles       map[string]ProfileConfig `toml:"profiles"`
+	MCP            MCPConfig                `toml:"mcp"`
+	Cache          CacheConfig              `toml:"cache"`
+	UI             UIConfig                 `toml:"ui"`
+	Log            LogConfig                `toml:"log"`
+	Limits         LimitsConfig             `toml:"limits"`
+	LSP            LSPConfig                `toml:"lsp"`
+	Index          IndexConfig              `toml:"index"`
+	Test           TestConfig               `toml:"test"`
+	Build          map[string]BuildConfig   `toml:"build"`
+	Session        SessionConfig            `toml:"session"`
+	// Prompts maps names to prompt templates run with "/prompt NAME ARGS".
+	Prompts map[string]string `toml:"prompts"`
+	// ContextWindows sets the context window in tokens per model name or
+	// name prefix, overriding the built-in table (e.g. for an Ollama model
+	// run with a custom num_ctx).
+	ContextWindows map[string]int `toml:"context_windows"`
+	// Prices sets what models cost per model name or name prefix, for the
+	// cost reported at the end of a --prompt run.
+	Prices map[string]PriceConfig `toml:"prices"`
+	// ToolResultTokens caps a tool's result at about this many tokens by
+	// tool name, when the tool returns it. Tools not listed are not capped.
+	ToolResultTokens map[string]int `toml:"tool_result_tokens"`
+	// Offline blocks outbound network use: the MCP upstream (web tools) and
+	// any provider whose endpoint is not localhost.
+	Offline bool `toml:"offline"`
+	// DryRun makes Edit report what it would do and Shell run only
+	// read-only commands, without touching the filesystem.
+	DryRun bool `toml:"dry_run"`
+	// StripReasoning keeps thinking artifacts out of the history replayed
+	// to the provider. Reasoning is still shown and stored.
+	StripReasoning bool `toml:"strip_reasoning"`
+	// Cwd is the working tree root symb runs against, instead of the
+	// directory it was started in. --cwd overrides it.
+	Cwd string `toml:"cwd"`
+}
+
+// LogConfig holds file logging settings for ~/.config/symb/logs/symb.log.
+type LogConfig struct {
+	// Level is the minimum zerolog level: trace, debug, info, warn, or error.
+	// Defaults to "info" if unset.
+	Level string `toml:"level"`
+	// MaxSizeMB rotates the log once it exceeds this size. Defaults to 10.
+	MaxSizeMB int `toml:"max_size_mb"`
+	// MaxBackups is how many rotated logs to keep. Defaults to 3.
+	MaxBackups int `toml:"max_backups"`
+	// MaxAgeDays removes rotated logs older than this. Defaults to 30.
+	MaxAgeDays int `toml:"max_age_days"`
+	// Transcripts writes every raw provider request and response (SSE
+	// included) to logs/transcripts/<session>.log, with credentials
+	// redacted. Verbose and may contain sensitive prompts; off by default.
+	Transcripts bool `toml:"transcripts"`
+}
+
+// LimitsConfig bounds runaway turns and session cost. Zero disables a limit.
+type LimitsConfig struct {
+	// TurnSeconds is the wall-clock limit for one turn, tool calls included.
+	TurnSeconds int `toml:"turn_seconds"`
+	// SessionTokens caps input+output tokens across the session; new turns
+	// are refused once it is reached.
+	SessionTokens int `toml:"session_tokens"`
+	// ReadLines and ReadChars cap a single Read result; larger reads return
+	// a leading window plus a note on how to fetch the rest. Default to 500
+	// lines and 30000 characters if unset.
+	ReadLines int `toml:"read_lines"`
+	ReadChars int `toml:"read_chars"`
+	// ReadMaxKB refuses whole-file Reads of larger files; ranged reads are
+	// still allowed. ReadRefuseBinary refuses files that look binary, and
+	// ReadRefuseIgnored refuses gitignored files. A Read with force=true
+	// bypasses all three. Off by default.
+	ReadMaxKB         int  `toml:"read_max_kb"`
+	ReadRefuseBinary  bool `toml:"read_refuse_binary"`
+	ReadRefuseIgnored bool `toml:"read_refuse_ignored"`
+	// EditWindowLines is the file size in lines above which an Edit result
+	// shows only the changed lines plus EditContextLines on each side,
+	// instead of the whole file. Default to 50 and 20 if unset.
+	EditWindowLines  int `toml:"edit_window_lines"`
+	EditContextLines int `toml:"edit_context_lines"`
+	// AutoContinue is how many times a turn may prompt the model to carry on
+	// when it stops while its TodoWrite plan still has open items ("- [ ]").
+	// Off (0) by default.
+	AutoContinue int `toml:"auto_continue"`
+}
+
+// LSPConfig configures language servers. Built-in servers are used unless
+// overridden or disabled under [lsp.servers.<name>].
+type LSPConfig struct {
+	// StartTimeoutSeconds bounds server startup and initialization.
+	// Defaults to 15 if unset.
+	StartTimeoutSeconds int `toml:"start_timeout_seconds"`
+	// WaitSeconds bounds how long an edit waits for fresh diagnostics.
+	// Defaults to 5 if unset.
+	WaitSeconds int `toml:"wait_seconds"`
+	// Severity is the least severe diagnostic shown in tool results and the
+	// editor: error, warning, info, or hint. Defaults to "warning" if unset.
+	Severity string `toml:"severity"`
+	// Servers adds or overrides servers by name (e.g. "gopls").
+	Servers map[string]LSPServerConfig `toml:"servers"`
+}
+
+// LSPServerConfig describes one language server. Unset fields keep the
+// built-in value when the name matches a built-in server.
+type LSPServerConfig struct {
+	// Enabled set to false stops the server from ever starting.
+	Enabled *bool    `toml:"enabled"`
+	Command string   `toml:"command"`
+	Args    []string `toml:"args"`
+	// FileTypes lists the language IDs the server handles (e.g. "go").
+	FileTypes   []string          `toml:"filetypes"`
+	RootMarkers []string          `toml:"root_markers"`
+	Env         map[string]string `toml:"env"`
+	// Settings and InitOptions are passed to the server unchanged.
+	Settings    map[string]any `toml:"settings"`
+	InitOptions map[string]any `toml:"init_options"`
+}
+
+// StartTimeoutOrDefault returns the server start timeout in seconds or 15 if unset.
+func (l LSPConfig) StartTimeoutOrDefault() int {
+	if l.StartTimeoutSeconds <= 0 {
+		return 15
+	}
+	return l.StartTimeoutSeconds
+}
+
+// WaitOrDefault returns the diagnostics wait in seconds or 5 if unset.
+func (l LSPConfig) WaitOrDefault() int {
+	if l.WaitSeconds <= 0 {
+		return 5
+	}
+	return l.WaitSeconds
+}
+
+// SeverityOrDefault returns the diagnostic severity filter or "warning" if unset.
+func (l LSPConfig) SeverityOrDefault() string {
+	if l.Severity == "" {
+		return "warning"
+	}
+	return l.Severity
+}
+
+// EnabledOrDefault returns whether the server may start (default true).
+func (s LSPServerConfig) EnabledOrDefault() bool {
+	if s.Enabled == nil {
+		return true
+	}
+	return *s.Enabled
+}
+
+// IndexConfig limits what the tree-sitter symbol index parses.
+type IndexConfig struct {
+	// MaxFileKB skips files larger than this. Defaults to 1024 if unset.
+	MaxFileKB int `toml:"max_file_kb"`
+	// Skip lists glob patterns of files to leave out, on top of .gitignore.
+	// Patterns without a slash match file names (e.g. "*.min.js"); others
+	// match paths from the project root (e.g. "testdata/*.go").
+	Skip []string `toml:"skip"`
+}
+
+// TestConfig configures the Test tool.
+type TestConfig struct {
+	// Command runs the project's tests in the shell. Unset guesses it from
+	// the project: go test ./..., cargo test, npm test or pytest.
+	Command string `toml:"command"`
+	// TimeoutSeconds bounds a run. Defaults to 300 if unset.
+	TimeoutSeconds int `toml:"timeout_seconds"`
+	// FailureLines is how many lines of the first failure are shown.
+	// Defaults to 20 if unset.
+	FailureLines int `toml:"failure_lines"`
+}
+
+// BuildConfig configures the Build tool for one language. Unset fields keep
+// the built-in value when the name matches a built-in language (go, rust,
+// typescript).
+type BuildConfig struct {
+	// Marker is a file in the working directory that selects the language
+	// when the tool is not told which to build, e.g. "go.mod".
+	Marker  string `toml:"marker"`
+	Command string `toml:"command"`
+	// ErrorRegex matches one compiler error line, with named groups file
+	// and line, and optionally col, severity and message.
+	ErrorRegex string `toml:"error_regex"`
+}
+
+// PriceConfig is what a model costs in USD per million tokens.
+type PriceConfig struct {
+	Input  float64 `toml:"input"`
+	Output float64 `toml:"output"`
+}
+
+// SessionConfig controls checkpoints of the session state messages don't
+// record (the agent's plan, token totals and turn boundaries), so a crash
+// loses at most the state since the last one.
+type SessionConfig struct {
+	// CheckpointTurns checkpoints after this many finished turns. Defaults
+	// to 5 if unset; -1 disables.
+	CheckpointTurns int `toml:"checkpoint_turns"`
+	// CheckpointIdleSeconds checkpoints once the session has sat idle this
+	// long with unsaved changes. Defaults to 30 if unset; -1 disables.
+	CheckpointIdleSeconds int `toml:"checkpoint_idle_seconds"`
+}
+
+// CheckpointTurnsOrDefault returns the turns between checkpoints: 5 if
+// unset, 0 (disabled) if negative.
+func (s SessionConfig) CheckpointTurnsOrDefault() int {
+	switch {
+	case s.CheckpointTurns < 0:
+		return 0
+	case s.CheckpointTurns == 0:
+		return 5
+	}
+	return s.CheckpointTurns
+}
+
+// CheckpointIdleOrDefault returns the idle time before a checkpoint in
+// seconds: 30 if unset, 0 (disabled) if negative.
+func (s SessionConfig) CheckpointIdleOrDefault() int {
+	switch {
+	case s.CheckpointIdleSeconds < 0:
+		return 0
+	case s.CheckpointIdleSeconds == 0:
+		return 30
+	}
+	return s.CheckpointIdleSeconds
+}
+
+// MaxFileKBOrDefault returns the largest indexed file size in KB or 1024 if unset.
+func (i IndexConfig) MaxFileKBOrDefault() int {
+	if i.MaxFileKB <= 0 {
+		return 1024
+	}
+	return i.MaxFileKB
+}
+
+// clipboardModes lists the accepted values for UIConfig.Clipboard.
+var clipboardModes = []string{"auto", "native", "osc52"}
+
+// colorModes lists the accepted values for UIConfig.Colors.
+var colorModes = []string{"auto", "truecolor", "256", "16", "none"}
+
+// statusSegments lists the accepted names in UIConfig.StatusLeft and
+// UIConfig.StatusRight.
+var statusSegments = []string{
+	"git", "indexing", "plan", "offline", "unsaved", "budget", "tokens", "turn", "context",
+	"timer", "mouse", "highlight", "cursor", "error", "model", "spinner",
+}
+
+// autoScrollModes lists the accepted values for UIConfig.AutoScroll.
+var autoScrollModes = []string{"sticky", "always"}
+
+// submitKeys lists the accepted values for UIConfig.SubmitKey.
+var submitKeys = []string{"enter", "ctrl+enter", "alt+enter"}
+
+// diagSeverities lists the accepted values for LSPConfig.Severity.
+var diagSeverities = []string{"error", "warning", "info", "hint"}
+
+// logLevels lists the accepted values for LogConfig.Level.
+var logLevels = []string{"trace", "debug", "info", "warn", "error"}
+
+// LevelOrDefault returns the configured log level or "info" if unset.
+func (l LogConfig) LevelOrDefault() string {
+	if l.Level == "" {
+		return "info"
+	}
+	return l.Level
+}
+
+// MaxSizeMBOrDefault returns the rotation size in MB or 10 if unset.
+func (l LogConfig) MaxSizeMBOrDefault() int {
+	if l.MaxSizeMB <= 0 {
+		return 10
+	}
+	return l.MaxSizeMB
+}
+
+// MaxBackupsOrDefault returns the number of rotated logs to keep or 3 if unset.
+func (l LogConfig) MaxBackupsOrDefault() int {
+	if l.MaxBackups <= 0 {
+		return 3
+	}
+	return l.MaxBackups
+}
+
+// MaxAgeDaysOrDefault returns the rotated log retention in days or 30 if unset.
+func (l LogConfig) MaxAgeDaysOrDefault() int {
+	if l.MaxAgeDays <= 0 {
+		return 30
+	}
+	return l.MaxAgeDays
 }
 
 // UIConfig holds user-interface settings.
@@ -26,6 +318,240 @@ type UIConfig struct {
 	// UI chrome colors are derived from this theme via highlight.ThemePalette.
 	// Defaults to "vulcan" if unset.
 	SyntaxTheme string `toml:"syntax_theme"`
+
+	// Mouse enables mouse capture (clicks, wheel, drag-selection).
+	// Set to false to leave selection and scrolling to the terminal
+	// (e.g. tmux copy mode). Defaults to true if unset.
+	Mouse *bool `toml:"mouse"`
+
+	// Highlight enables syntax highlighting in the input editor.
+	// Defaults to true if unset; toggle at runtime with ctrl+l.
+	Highlight *bool `toml:"highlight"`
+
+	// HighlightMaxLines falls back to plain rendering when the input
+	// exceeds this many lines. Defaults to 2000 if unset.
+	HighlightMaxLines int `toml:"highlight_max_lines"`
+
+	// HighlightCacheMB bounds the syntax highlight cache in megabytes.
+	// Defaults to 8 if unset.
+	HighlightCacheMB int `toml:"highlight_cache_mb"`
+
+	// ConfirmTools lists tool names (e.g. "Edit", "Shell") whose calls
+	// pause for approval before running. Empty means auto-approve all.
+	ConfirmTools []string `toml:"confirm_tools"`
+
+	// Clipboard selects the copy/paste backend: "auto" uses a native tool
+	// (pbcopy, wl-copy, xclip, xsel, clip.exe) locally and OSC 52 over SSH,
+	// "native" always prefers the tool, "osc52" always uses the terminal.
+	// Defaults to "auto" if unset.
+	Clipboard string `toml:"clipboard"`
+
+	// Colors forces the terminal color depth: "truecolor", "256", "16" or
+	// "none". "auto" detects it from the environment (COLORTERM, TERM).
+	// Colors are downsampled to fit. Defaults to "auto" if unset.
+	Colors string `toml:"colors"`
+
+	// Placeholder is the hint shown in the empty input. Defaults to
+	// "Ask anything... (CTRL+h for keybinds)" if unset.
+	Placeholder string `toml:"placeholder"`
+
+	// InputMaxRows is how tall the input grows as a message gains lines.
+	// It starts at 3 rows and shrinks back when cleared. Defaults to 10 if
+	// unset; 3 or less keeps it fixed.
+	InputMaxRows int `toml:"input_max_rows"`
+
+	// SubmitKey sends the message: "enter", "ctrl+enter" or "alt+enter".
+	// With a modifier, plain enter inserts a newline instead.
+	// Defaults to "enter" if unset, where shift+enter inserts a newline.
+	SubmitKey string `toml:"submit_key"`
+
+	// MaxDisplayTurns bounds how many turns stay rendered in the
+	// conversation pane. Messages always live in the session DB, so this is
+	// purely a display/render-cost bound. Defaults to 5 if unset; -1 keeps
+	// every turn.
+	MaxDisplayTurns int `toml:"max_display_turns"`
+
+	// PageOverlap is how many lines carry over between pages when paging
+	// the input editor or conversation with pgup/pgdown. Defaults to 2 if
+	// unset; -1 pages by a full screen.
+	PageOverlap int `toml:"page_overlap"`
+
+	// ScrollLines is how many lines one mouse wheel event scrolls, in both
+	// the input and the conversation. Fractional values accumulate across
+	// events, which smooths out high-resolution trackpads. Shift+wheel
+	// scrolls a page. Defaults to 3 in the input and 5 in the conversation
+	// if unset.
+	ScrollLines float64 `toml:"scroll_lines"`
+
+	// AutoScroll controls following new output in the conversation:
+	// "sticky" follows only while at the bottom, holding the view still
+	// once the user scrolls up until they scroll back down; "always" jumps
+	// to the bottom on every update. Defaults to "sticky" if unset.
+	AutoScroll string `toml:"auto_scroll"`
+
+	// FrameMS is the render interval in milliseconds: how often streamed
+	// text is redrawn and the spinner advances. Defaults to 16 (~60fps),
+	// or 100 with ReducedMotion.
+	FrameMS int `toml:"frame_ms"`
+
+	// ReducedMotion stops the idle spinner and slows the busy one.
+	ReducedMotion bool `toml:"reduced_motion"`
+
+	// StreamFlushMS is the minimum time between redraws of a streaming
+	// reply, so very fast models render in larger chunks. 0 redraws every
+	// frame.
+	StreamFlushMS int `toml:"stream_flush_ms"`
+
+	// OpenFileContext sends the file last open in the viewer with the next
+	// message, so "fix this" refers to what was on screen. Toggle at
+	// runtime with ctrl+o.
+	OpenFileContext bool `toml:"open_file_context"`
+
+	// OpenFileContextLines bounds how many lines of the open file are sent,
+	// centred on the lines that were visible. Defaults to 200 if unset.
+	OpenFileContextLines int `toml:"open_file_context_lines"`
+
+	// DiagnosticsBlock is the least severe diagnostic that marks a turn's
+	// diagnostics summary as failing: "error" or "warning".
+	// Defaults to "warning" if unset.
+	DiagnosticsBlock string `toml:"diagnostics_block"`
+
+	// StatusLeft and StatusRight choose the status-bar segments on each
+	// side, in order. Unset keeps the default bar; an empty list leaves
+	// that side blank.
+	StatusLeft  []string `toml:"status_left"`
+	StatusRight []string `toml:"status_right"`
+}
+
+// ClipboardOrDefault returns the clipboard backend or "auto" if unset.
+func (u UIConfig) ClipboardOrDefault() string {
+	if u.Clipboard == "" {
+		return "auto"
+	}
+	return u.Clipboard
+}
+
+// ColorsOrDefault returns the color depth mode or "auto" if unset.
+func (u UIConfig) ColorsOrDefault() string {
+	if u.Colors == "" {
+		return "auto"
+	}
+	return u.Colors
+}
+
+// PlaceholderOrDefault returns the input placeholder text.
+func (u UIConfig) PlaceholderOrDefault() string {
+	if u.Placeholder == "" {
+		return "Ask anything... (CTRL+h for keybinds)"
+	}
+	return u.Placeholder
+}
+
+// InputMaxRowsOrDefault returns the input's maximum height, 10 if unset.
+func (u UIConfig) InputMaxRowsOrDefault() int {
+	if u.InputMaxRows == 0 {
+		return 10
+	}
+	return u.InputMaxRows
+}
+
+// SubmitKeyOrDefault returns the submit key or "enter" if unset.
+func (u UIConfig) SubmitKeyOrDefault() string {
+	if u.SubmitKey == "" {
+		return "enter"
+	}
+	return u.SubmitKey
+}
+
+// MaxDisplayTurnsOrDefault returns the display turn cap, 5 if unset, or a
+// negative number for no cap.
+func (u UIConfig) MaxDisplayTurnsOrDefault() int {
+	if u.MaxDisplayTurns == 0 {
+		return 5
+	}
+	return u.MaxDisplayTurns
+}
+
+// PageOverlapOrDefault returns the paging overlap in lines, 2 if unset.
+func (u UIConfig) PageOverlapOrDefault() int {
+	if u.PageOverlap == 0 {
+		return 2
+	}
+	return max(u.PageOverlap, 0)
+}
+
+// InputScrollLinesOrDefault returns the lines one wheel event scrolls the
+// input, 3 if unset.
+func (u UIConfig) InputScrollLinesOrDefault() float64 {
+	if u.ScrollLines <= 0 {
+		return 3
+	}
+	return u.ScrollLines
+}
+
+// ConvScrollLinesOrDefault returns the lines one wheel event scrolls the
+// conversation, 5 if unset.
+func (u UIConfig) ConvScrollLinesOrDefault() float64 {
+	if u.ScrollLines <= 0 {
+		return 5
+	}
+	return u.ScrollLines
+}
+
+// FrameMSOrDefault returns the render interval in milliseconds: frame_ms,
+// or 16 if unset (100 with reduced_motion).
+func (u UIConfig) FrameMSOrDefault() int {
+	switch {
+	case u.FrameMS > 0:
+		return u.FrameMS
+	case u.ReducedMotion:
+		return 100
+	}
+	return 16
+}
+
+// DiagnosticsBlockOrDefault returns the blocking diagnostic severity or
+// "warning" if unset.
+func (u UIConfig) DiagnosticsBlockOrDefault() string {
+	if u.DiagnosticsBlock == "" {
+		return "warning"
+	}
+	return u.DiagnosticsBlock
+}
+
+// AutoScrollOrDefault returns the auto-scroll mode or "sticky" if unset.
+func (u UIConfig) AutoScrollOrDefault() string {
+	if u.AutoScroll == "" {
+		return "sticky"
+	}
+	return u.AutoScroll
+}
+
+// StatusLeftOrDefault returns the left status-bar segments, or the default
+// bar's if unset.
+func (u UIConfig) StatusLeftOrDefault() []string {
+	if u.StatusLeft == nil {
+		return []string{"git", "indexing", "plan", "offline", "unsaved", "budget", "turn", "context", "timer", "mouse", "highlight", "cursor"}
+	}
+	return u.StatusLeft
+}
+
+// StatusRightOrDefault returns the right status-bar segments, or the
+// default bar's if unset.
+func (u UIConfig) StatusRightOrDefault() []string {
+	if u.StatusRight == nil {
+		return []string{"error", "model", "spinner"}
+	}
+	return u.StatusRight
+}
+
+// OpenFileContextLinesOrDefault returns the open-file context line cap or
+// 200 if unset.
+func (u UIConfig) OpenFileContextLinesOrDefault() int {
+	if u.OpenFileContextLines <= 0 {
+		return 200
+	}
+	return u.OpenFileContextLines
 }
 
 // SyntaxThemeOrDefault returns the configured syntax theme or "vulcan" if unset.
@@ -36,9 +562,44 @@ func (u UIConfig) SyntaxThemeOrDefault() string {
 	return u.SyntaxTheme
 }
 
+// MouseOrDefault returns whether mouse capture is enabled (default true).
+func (u UIConfig) MouseOrDefault() bool {
+	if u.Mouse == nil {
+		return true
+	}
+	return *u.Mouse
+}
+
+// HighlightOrDefault returns whether syntax highlighting is enabled (default true).
+func (u UIConfig) HighlightOrDefault() bool {
+	if u.Highlight == nil {
+		return true
+	}
+	return *u.Highlight
+}
+
+// HighlightMaxLinesOrDefault returns the highlight line cap or 2000 if unset.
+func (u UIConfig) HighlightMaxLinesOrDefault() int {
+	if u.HighlightMaxLines <= 0 {
+		return 2000
+	}
+	return u.HighlightMaxLines
+}
+
+// HighlightCacheMBOrDefault returns the highlight cache size in MB or 8 if unset.
+func (u UIConfig) HighlightCacheMBOrDefault() int {
+	if u.HighlightCacheMB <= 0 {
+		return 8
+	}
+	return u.HighlightCacheMB
+}
+
 // CacheConfig holds web cache settings.
 type CacheConfig struct {
 	TTLHours int `toml:"ttl_hours"`
+	// ToolResultsSeconds is how long identical Read/Grep calls reuse a
+	// result. 0 uses the default; negative disables the cache.
+	ToolResultsSeconds int `toml:"tool_results_seconds"`
 }
 
 // CacheTTLOrDefault returns the configured TTL or 24 hours if unset.
@@ -49,6 +610,18 @@ func (c CacheConfig) CacheTTLOrDefault() int {
 	return c.TTLHours
 }
 
+// ToolResultsTTLOrDefault returns the tool result cache TTL in seconds:
+// 30 if unset, 0 (disabled) if negative.
+func (c CacheConfig) ToolResultsTTLOrDefault() int {
+	switch {
+	case c.ToolResultsSeconds < 0:
+		return 0
+	case c.ToolResultsSeconds == 0:
+		return 30
+	}
+	return c.ToolResultsSeconds
+}
+
 // ProviderConfig holds LLM provider settings.
 type ProviderConfig struct {
 	Endpoint    string  `toml:"endpoint"`
@@ -56,9 +629,67 @@ type ProviderConfig struct {
 	Temperature float64 `toml:"temperature"`
 }
 
+// ProfileConfig pins a provider, model and options under a name so they can
+// be switched together with --profile or /profile.
+type ProfileConfig struct {
+	Provider string `toml:"provider"`
+	// Model defaults to the provider's model if unset.
+	Model string `toml:"model"`
+	// Temperature defaults to the provider's temperature if unset.
+	Temperature float64 `toml:"temperature"`
+}
+
+// ResolvedProfiles returns every profile with unset fields filled in from
+// its provider.
+func (c *Config) ResolvedProfiles() map[string]ProfileConfig {
+	profiles := make(map[string]ProfileConfig, len(c.Profiles))
+	for name, p := range c.Profiles {
+		pc := c.Providers[p.Provider]
+		if p.Model == "" {
+			p.Model = pc.Model
+		}
+		if p.Temperature == 0 {
+			p.Temperature = pc.Temperature
+		}
+		profiles[name] = p
+	}
+	return profiles
+}
+
 // MCPConfig holds MCP proxy settings.
 type MCPConfig struct {
 	Upstream string `toml:"upstream"`
+	// UserAgent replaces the default User-Agent sent to the upstream.
+	UserAgent string `toml:"user_agent"`
+	// Contact (a URL or email) is appended to the User-Agent so servers
+	// can reach whoever runs the client.
+	Contact string `toml:"contact"`
+	// Retries is how many times a tool call that failed transiently
+	// (network error, timeout, rate limit) is retried with backoff. Only
+	// web tools and read-only local tools are retried. Defaults to 3 if
+	// unset; negative disables retries.
+	Retries int `toml:"retries"`
+}
+
+// RetriesOrDefault returns the tool retry count: 3 if unset, 0 if negative.
+func (m MCPConfig) RetriesOrDefault() int {
+	if m.Retries == 0 {
+		return 3
+	}
+	return max(m.Retries, 0)
+}
+
+// UserAgentOrDefault returns the upstream User-Agent: user_agent or
+// "symb/<version>", followed by " (+contact)" when a contact is set.
+func (m MCPConfig) UserAgentOrDefault(version string) string {
+	ua := m.UserAgent
+	if ua == "" {
+		ua = "symb/" + version
+	}
+	if m.Contact != "" {
+		ua += " (+" + m.Contact + ")"
+	}
+	return ua
 }
 
 // Load reads configuration from a TOML file and applies environment variable overrides.
@@ -85,6 +716,7 @@ func Load(path string) (*Config, error) {
 
 	// Apply environment variable overrides
 	applyEnvOverrides(cfg)
+	normalizeEndpoints(cfg)
 
 	if err := cfg.Validate(); err != nil {
 		return nil, err
@@ -112,6 +744,146 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	for name, p := range c.Profiles {
+		if _, ok := c.Providers[p.Provider]; !ok {
+			errs = append(errs, fmt.Errorf("[profiles.%s] provider=%q does not exist in providers", name, p.Provider))
+		}
+		if p.Temperature < 0.0 || p.Temperature > 2.0 {
+			errs = append(errs, fmt.Errorf("[profiles.%s] temperature=%v must be between 0.0 and 2.0", name, p.Temperature))
+		}
+	}
+	if c.DefaultProfile != "" {
+		if _, ok := c.Profiles[c.DefaultProfile]; !ok {
+			errs = append(errs, fmt.Errorf("default_profile=%q does not exist in profiles", c.DefaultProfile))
+		}
+	}
+	for name, tmpl := range c.Prompts {
+		if name == "" || strings.ContainsFunc(name, unicode.IsSpace) {
+			errs = append(errs, fmt.Errorf("prompts: name %q must be a single word", name))
+		}
+		if strings.TrimSpace(tmpl) == "" {
+			errs = append(errs, fmt.Errorf("prompts.%s must not be empty", name))
+		}
+	}
+
+	if c.Limits.TurnSeconds < 0 {
+		errs = append(errs, fmt.Errorf("limits.turn_seconds=%d must not be negative", c.Limits.TurnSeconds))
+	}
+	if c.Limits.SessionTokens < 0 {
+		errs = append(errs, fmt.Errorf("limits.session_tokens=%d must not be negative", c.Limits.SessionTokens))
+	}
+
+	for name, b := range c.Build {
+		if b.ErrorRegex == "" {
+			continue
+		}
+		re, err := regexp.Compile(b.ErrorRegex)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("[build.%s] error_regex: %w", name, err))
+		} else if re.SubexpIndex("file") < 0 || re.SubexpIndex("line") < 0 {
+			errs = append(errs, fmt.Errorf("[build.%s] error_regex needs named groups file and line", name))
+		}
+	}
+	if c.Test.TimeoutSeconds < 0 {
+		errs = append(errs, fmt.Errorf("test.timeout_seconds=%d must not be negative", c.Test.TimeoutSeconds))
+	}
+	if c.Test.FailureLines < 0 {
+		errs = append(errs, fmt.Errorf("test.failure_lines=%d must not be negative", c.Test.FailureLines))
+	}
+
+	if c.Limits.ReadLines < 0 {
+		errs = append(errs, fmt.Errorf("limits.read_lines=%d must not be negative", c.Limits.ReadLines))
+	}
+	if c.Limits.ReadChars < 0 {
+		errs = append(errs, fmt.Errorf("limits.read_chars=%d must not be negative", c.Limits.ReadChars))
+	}
+	if c.Limits.ReadMaxKB < 0 {
+		errs = append(errs, fmt.Errorf("limits.read_max_kb=%d must not be negative", c.Limits.ReadMaxKB))
+	}
+	if c.Limits.EditWindowLines < 0 {
+		errs = append(errs, fmt.Errorf("limits.edit_window_lines=%d must not be negative", c.Limits.EditWindowLines))
+	}
+	if c.Limits.EditContextLines < 0 {
+		errs = append(errs, fmt.Errorf("limits.edit_context_lines=%d must not be negative", c.Limits.EditContextLines))
+	}
+	if c.Limits.AutoContinue < 0 {
+		errs = append(errs, fmt.Errorf("limits.auto_continue=%d must not be negative", c.Limits.AutoContinue))
+	}
+
+	for model, window := range c.ContextWindows {
+		if window <= 0 {
+			errs = append(errs, fmt.Errorf("context_windows.%q=%d must be positive", model, window))
+		}
+	}
+
+	for tool, tokens := range c.ToolResultTokens {
+		if tokens < 0 {
+			errs = append(errs, fmt.Errorf("tool_result_tokens.%s=%d must not be negative", tool, tokens))
+		}
+	}
+
+	for model, price := range c.Prices {
+		if price.Input < 0 || price.Output < 0 {
+			errs = append(errs, fmt.Errorf("prices.%q must not be negative", model))
+		}
+	}
+
+	if c.Index.MaxFileKB < 0 {
+		errs = append(errs, fmt.Errorf("index.max_file_kb=%d must not be negative", c.Index.MaxFileKB))
+	}
+	for _, pattern := range c.Index.Skip {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("index.skip: bad pattern %q: %w", pattern, err))
+		}
+	}
+
+	if c.LSP.StartTimeoutSeconds < 0 {
+		errs = append(errs, fmt.Errorf("lsp.start_timeout_seconds=%d must not be negative", c.LSP.StartTimeoutSeconds))
+	}
+
+	if c.LSP.WaitSeconds < 0 {
+		errs = append(errs, fmt.Errorf("lsp.wait_seconds=%d must not be negative", c.LSP.WaitSeconds))
+	}
+	if c.LSP.Severity != "" && !slices.Contains(diagSeverities, c.LSP.Severity) {
+		errs = append(errs, fmt.Errorf("lsp.severity=%q must be one of %v", c.LSP.Severity, diagSeverities))
+	}
+	if cb := c.UI.Clipboard; cb != "" && !slices.Contains(clipboardModes, cb) {
+		errs = append(errs, fmt.Errorf("ui.clipboard=%q must be one of %v", cb, clipboardModes))
+	}
+	if cm := c.UI.Colors; cm != "" && !slices.Contains(colorModes, cm) {
+		errs = append(errs, fmt.Errorf("ui.colors=%q must be one of %v", cm, colorModes))
+	}
+	for _, name := range slices.Concat(c.UI.StatusLeft, c.UI.StatusRight) {
+		if !slices.Contains(statusSegments, name) {
+			errs = append(errs, fmt.Errorf("ui status segment %q must be one of %v", name, statusSegments))
+		}
+	}
+	if as := c.UI.AutoScroll; as != "" && !slices.Contains(autoScrollModes, as) {
+		errs = append(errs, fmt.Errorf("ui.auto_scroll=%q must be one of %v", as, autoScrollModes))
+	}
+	if sk := c.UI.SubmitKey; sk != "" && !slices.Contains(submitKeys, sk) {
+		errs = append(errs, fmt.Errorf("ui.submit_key=%q must be one of %v", sk, submitKeys))
+	}
+	if c.UI.InputMaxRows < 0 {
+		errs = append(errs, fmt.Errorf("ui.input_max_rows=%d must not be negative", c.UI.InputMaxRows))
+	}
+	if c.UI.ScrollLines < 0 {
+		errs = append(errs, fmt.Errorf("ui.scroll_lines=%g must not be negative", c.UI.ScrollLines))
+	}
+	if c.UI.FrameMS < 0 || c.UI.FrameMS > 1000 {
+		errs = append(errs, fmt.Errorf("ui.frame_ms=%d must be between 0 and 1000", c.UI.FrameMS))
+	}
+	if c.UI.StreamFlushMS < 0 {
+		errs = append(errs, fmt.Errorf("ui.stream_flush_ms=%d must not be negative", c.UI.StreamFlushMS))
+	}
+	if b := c.UI.DiagnosticsBlock; b != "" && b != "error" && b != "warning" {
+		errs = append(errs, fmt.Errorf("ui.diagnostics_block=%q must be \"error\" or \"warning\"", b))
+	}
+
+	if c.Log.Level != "" && !slices.Contains(logLevels, c.Log.Level) {
+		errs = append(errs, fmt.Errorf("log.level=%q must be one of %v", c.Log.Level, logLevels))
+	}
+
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
@@ -122,17 +894,17 @@ func (c *Config) Validate() error {
 func validateProviderConfig(name string, cfg ProviderConfig) []error {
 	var errs []error
 	if cfg.Endpoint == "" {
-		errs = append(errs, fmt.Errorf("providers.%s.endpoint is required", name))
+		errs = append(errs, fmt.Errorf("[providers.%s] endpoint is required", name))
 	} else if err := validateEndpoint(cfg.Endpoint); err != nil {
-		errs = append(errs, fmt.Errorf("providers.%s.endpoint=%q is invalid: %v", name, cfg.Endpoint, err))
+		errs = append(errs, fmt.Errorf("[providers.%s] endpoint=%q is invalid: %v", name, cfg.Endpoint, err))
 	}
 
 	if cfg.Model == "" {
-		errs = append(errs, fmt.Errorf("providers.%s.model is required", name))
+		errs = append(errs, fmt.Errorf("[providers.%s] model is required", name))
 	}
 
 	if cfg.Temperature < 0.0 || cfg.Temperature > 2.0 {
-		errs = append(errs, fmt.Errorf("providers.%s.temperature=%v must be between 0.0 and 2.0", name, cfg.Temperature))
+		errs = append(errs, fmt.Errorf("[providers.%s] temperature=%v must be between 0.0 and 2.0", name, cfg.Temperature))
 	}
 
 	return errs
@@ -143,12 +915,49 @@ func validateEndpoint(value string) error {
 	if err != nil {
 		return err
 	}
-	if parsed.Scheme == "" || parsed.Host == "" {
-		return errors.New("missing scheme or host")
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return errors.New("scheme must be http or https (e.g. \"http://localhost:11434\")")
+	}
+	if parsed.Host == "" {
+		return errors.New("missing host")
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return errors.New("must not have a query or fragment")
 	}
 	return nil
 }
 
+// normalizeEndpoints trims surrounding space and trailing slashes from
+// provider endpoints so providers can append API paths directly.
+func normalizeEndpoints(cfg *Config) {
+	for name, p := range cfg.Providers {
+		p.Endpoint = strings.TrimRight(strings.TrimSpace(p.Endpoint), "/")
+		cfg.Providers[name] = p
+	}
+}
+
+// apiPathSuffixes are request paths that providers append themselves; an
+// endpoint ending in one is almost certainly a copied request URL.
+var apiPathSuffixes = []string{"/chat/completions", "/completions", "/models", "/api/chat", "/api/generate", "/api/tags"}
+
+// EndpointWarnings reports provider endpoints that parse but look wrong,
+// such as a full request URL instead of the base URL.
+func (c *Config) EndpointWarnings() []string {
+	var warnings []string
+	for name, p := range c.Providers {
+		for _, suffix := range apiPathSuffixes {
+			if strings.HasSuffix(p.Endpoint, suffix) {
+				warnings = append(warnings, fmt.Sprintf(
+					"[providers.%s] endpoint %q ends in %q; use the base URL, API paths are appended automatically",
+					name, p.Endpoint, suffix))
+				break
+			}
+		}
+	}
+	slices.Sort(warnings)
+	return warnings
+}
+
 // applyEnvOverrides applies environment variable overrides to the configuration.
 func applyEnvOverrides(cfg *Config) {
 	for _, setter := range []struct {
@@ -160,6 +969,11 @@ func applyEnvOverrides(cfg *Config) {
 				cfg.MCP.Upstream = v
 			}
 		}},
+		{"SYMB_OFFLINE", func(v string) {
+			if v == "1" || v == "true" {
+				cfg.Offline = true
+			}
+		}},
 	} {
 		setter.apply(os.Getenv(setter.env))
 	}
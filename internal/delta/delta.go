@@ -16,8 +16,13 @@ type Tracker struct {
 	db        *sql.DB
 	sessionID string
 	turnID    int64 // current turn; 0 = no active turn
+	audit     AuditFunc
 }
 
+// AuditFunc adds a file a tool read or changed to a session's file audit.
+// op is "read", "edit", "create" or "remove".
+type AuditFunc func(sessionID string, turnID int64, path, op string) error
+
 // New creates a Tracker that writes to the given database.
 func New(db *sql.DB) *Tracker {
 	return &Tracker{db: db}
@@ -30,6 +35,13 @@ func (t *Tracker) SetSession(id string) {
 	t.sessionID = id
 }
 
+// SetAudit sets where RecordAccess and Undo record the files they touch.
+func (t *Tracker) SetAudit(audit AuditFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.audit = audit
+}
+
 // BeginTurn sets the current turn ID. All subsequent Record* calls
 // are associated with this turn until the next BeginTurn.
 func (t *Tracker) BeginTurn(turnID int64) {
@@ -90,8 +102,26 @@ func (t *Tracker) RecordCreate(filePath string) {
 	}
 }
 
+// RecordAccess adds a file to the session's audit under the current turn.
+// Tools call it for every file they read or change, sub-agents included.
+func (t *Tracker) RecordAccess(filePath, op string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.recordAccess(t.sessionID, filePath, op)
+}
+
+func (t *Tracker) recordAccess(sessionID, filePath, op string) {
+	if t.audit == nil || sessionID == "" {
+		return
+	}
+	if err := t.audit(sessionID, t.turnID, filePath, op); err != nil {
+		log.Warn().Err(err).Str("file", filePath).Msg("failed to record file access")
+	}
+}
+
 // Undo reverses all file changes for the given turn, in reverse order.
-// Modify ops restore old content; create ops delete the file.
+// Modify ops restore old content; create ops delete the file, which is
+// recorded in the audit as a removal.
 // Returns the list of affected absolute file paths and any error.
 func (t *Tracker) Undo(sessionID string, turnID int64) ([]string, error) {
 	t.mu.Lock()
@@ -125,6 +155,8 @@ func (t *Tracker) Undo(sessionID string, turnID int64) ([]string, error) {
 		case "create":
 			if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
 				log.Warn().Err(err).Str("file", filePath).Msg("undo: failed to remove created file")
+			} else if err == nil {
+				t.recordAccess(sessionID, filePath, "remove")
 			}
 		}
 	}
@@ -25,6 +25,11 @@ type Options struct {
 	MaxResults    int    // Maximum results to return (0 = unlimited)
 	CaseSensitive bool   // Case-sensitive matching
 	RootDir       string // Root directory to search from (defaults to current dir)
+
+	// KeepMatch, if set, vets each matching line of a content search given
+	// the file, the 1-indexed line number and the byte ranges the pattern
+	// matched. Lines it returns false for are left out of the results.
+	KeepMatch func(absPath string, line int, matches [][]int) bool
 }
 
 // Searcher performs file and content searches.
@@ -79,7 +84,7 @@ func (s *Searcher) Search(ctx context.Context, opts Options) ([]Result, error) {
 		if skip := s.shouldSkip(path, d, opts.RootDir); skip != nil {
 			return *skip
 		}
-		matches := s.matchEntry(path, opts.RootDir, regex, opts.ContentSearch)
+		matches := s.matchEntry(path, regex, opts)
 		results = append(results, matches...)
 		if opts.MaxResults > 0 && len(results) >= opts.MaxResults {
 			return filepath.SkipAll
@@ -128,10 +133,10 @@ func (s *Searcher) shouldSkip(path string, d os.DirEntry, rootDir string) *error
 }
 
 // matchEntry checks a single file against the search pattern.
-func (s *Searcher) matchEntry(path, rootDir string, regex *regexp.Regexp, contentSearch bool) []Result {
-	relPath, _ := filepath.Rel(rootDir, path)
-	if contentSearch {
-		matches, err := s.searchFileContent(path, relPath, regex)
+func (s *Searcher) matchEntry(path string, regex *regexp.Regexp, opts Options) []Result {
+	relPath, _ := filepath.Rel(opts.RootDir, path)
+	if opts.ContentSearch {
+		matches, err := s.searchFileContent(path, relPath, regex, opts.KeepMatch)
 		if err != nil {
 			return nil
 		}
@@ -144,7 +149,7 @@ func (s *Searcher) matchEntry(path, rootDir string, regex *regexp.Regexp, conten
 }
 
 // searchFileContent searches a single file for pattern matches.
-func (s *Searcher) searchFileContent(absPath, relPath string, regex *regexp.Regexp) ([]Result, error) {
+func (s *Searcher) searchFileContent(absPath, relPath string, regex *regexp.Regexp, keep func(string, int, [][]int) bool) ([]Result, error) {
 	file, err := os.Open(absPath)
 	if err != nil {
 		return nil, err
@@ -164,7 +169,10 @@ func (s *Searcher) searchFileContent(absPath, relPath string, regex *regexp.Rege
 			return nil, nil
 		}
 
-		if regex.MatchString(line) {
+		if !regex.MatchString(line) {
+			continue
+		}
+		if keep == nil || keep(absPath, lineNum, regex.FindAllStringIndex(line, -1)) {
 			results = append(results, Result{
 				Path:    relPath,
 				Line:    lineNum,
@@ -0,0 +1,170 @@
+// Package fswatch reports files under the working directory that change
+// outside symb, so caches keyed on file content (read tracking, the symbol
+// index, the file viewer) can be refreshed.
+package fswatch
+
+import (
+	"context"
+	"crypto/sha256"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/symb/internal/filesearch"
+)
+
+// debounce is how long events must stop before a batch of changes is
+// reported, so an editor's save or a build step arrives as one batch.
+const debounce = 200 * time.Millisecond
+
+// Watcher watches a directory tree, skipping .git and gitignored paths.
+type Watcher struct {
+	fs      *fsnotify.Watcher
+	root    string
+	matcher *filesearch.GitignoreMatcher
+
+	mu    sync.Mutex
+	wrote map[string][sha256.Size]byte // abs path -> hash of what symb last wrote
+}
+
+// New starts watching root and every directory below it.
+func New(root string) (*Watcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	matcher, err := filesearch.NewGitignoreMatcher(filepath.Join(root, ".gitignore"))
+	if err != nil {
+		matcher, _ = filesearch.NewGitignoreMatcher("")
+	}
+	w := &Watcher{fs: fw, root: root, matcher: matcher, wrote: make(map[string][sha256.Size]byte)}
+	w.addTree(root, nil)
+	return w, nil
+}
+
+// Wrote records that symb itself wrote data to absPath. A change event
+// whose file still holds exactly that content is not reported. Safe to call
+// on a nil Watcher.
+func (w *Watcher) Wrote(absPath string, data []byte) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.wrote[absPath] = sha256.Sum256(data)
+}
+
+// Run delivers batches of changed absolute paths to onChange until ctx is
+// cancelled, then stops watching. Removed files are reported too.
+func (w *Watcher) Run(ctx context.Context, onChange func(paths []string)) {
+	defer w.fs.Close()
+
+	pending := make(map[string]fsnotify.Op)
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case ev, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			if ev.Has(fsnotify.Chmod) && !ev.Has(fsnotify.Write) {
+				continue
+			}
+			if w.ignored(ev.Name) {
+				continue
+			}
+			if ev.Has(fsnotify.Create) {
+				if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+					// Files may land in a new directory before it is watched.
+					w.addTree(ev.Name, func(path string) { pending[path] |= fsnotify.Create })
+					timer.Reset(debounce)
+					continue
+				}
+			}
+			pending[ev.Name] |= ev.Op
+			timer.Reset(debounce)
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			log.Warn().Err(err).Msg("fswatch: watcher error")
+		case <-timer.C:
+			if changed := w.external(pending); len(changed) > 0 {
+				onChange(changed)
+			}
+			pending = make(map[string]fsnotify.Op)
+		}
+	}
+}
+
+// external returns the pending paths that were not symb's own writes,
+// leaving out files created and removed again within the batch (such as
+// the temp file of an atomic write).
+func (w *Watcher) external(pending map[string]fsnotify.Op) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var changed []string
+	for path, op := range pending {
+		if op.Has(fsnotify.Create) {
+			if _, err := os.Lstat(path); err != nil {
+				continue
+			}
+		}
+		if sum, ok := w.wrote[path]; ok {
+			//nolint:gosec // G304: path is inside the watched tree
+			if data, err := os.ReadFile(path); err == nil && sha256.Sum256(data) == sum {
+				continue
+			}
+			delete(w.wrote, path)
+		}
+		changed = append(changed, path)
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// ignored reports whether path is under .git or gitignored.
+func (w *Watcher) ignored(path string) bool {
+	rel, err := filepath.Rel(w.root, path)
+	if err != nil {
+		return true
+	}
+	if rel == ".git" || strings.HasPrefix(rel, ".git"+string(filepath.Separator)) {
+		return true
+	}
+	info, err := os.Lstat(path)
+	return w.matcher.Matches(rel, err == nil && info.IsDir())
+}
+
+// addTree watches dir and the directories below it, passing the files it
+// finds to found if non-nil.
+func (w *Watcher) addTree(dir string, found func(path string)) {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			if found != nil && !w.ignored(path) {
+				found(path)
+			}
+			return nil
+		}
+		if d.Name() == ".git" || (path != w.root && w.ignored(path)) {
+			return filepath.SkipDir
+		}
+		if err := w.fs.Add(path); err != nil {
+			log.Debug().Err(err).Str("dir", path).Msg("fswatch: cannot watch directory")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,77 @@
+package fswatch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestWatcher verifies external changes are reported in one batch, while
+// symb's own writes, gitignored files, and short-lived temp files are not.
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	write(".gitignore", "build/\n")
+	write("build/out.txt", "old")
+	own := write("own.go", "package a\n")
+	ext := write("ext.go", "package a\n")
+
+	w, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	batches := make(chan []string, 4)
+	go w.Run(ctx, func(paths []string) { batches <- paths })
+
+	w.Wrote(own, []byte("package a\n\nfunc F() {}\n"))
+	write("own.go", "package a\n\nfunc F() {}\n")
+	write("build/out.txt", "new")
+	tmp := write(".ext.go.tmp", "x")
+	if err := os.Remove(tmp); err != nil {
+		t.Fatal(err)
+	}
+	write("ext.go", "package b\n")
+	sub := write("sub/new.go", "package sub\n") // in a directory created after New
+
+	want := map[string]bool{ext: true, sub: true}
+	deadline := time.After(5 * time.Second)
+	for len(want) > 0 {
+		select {
+		case paths := <-batches:
+			for _, p := range paths {
+				if !want[p] {
+					t.Errorf("unexpected change reported: %s", p)
+				}
+				delete(want, p)
+			}
+		case <-deadline:
+			t.Fatalf("changes not reported: %v", want)
+		}
+	}
+
+	// A later external change to a file symb wrote is reported.
+	write("own.go", "package c\n")
+	select {
+	case paths := <-batches:
+		if !reflect.DeepEqual(paths, []string{own}) {
+			t.Errorf("reported %v, want %v", paths, []string{own})
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("external change to own.go not reported")
+	}
+}
@@ -0,0 +1,49 @@
+// Package git reads working-tree state from the git command line.
+package git
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// Change is one entry of `git status`: a path relative to the repository
+// root and its two-letter porcelain code (index, then work tree), e.g.
+// "M " for a staged modification or "??" for an untracked path. Untracked
+// directories are reported once, with a trailing slash.
+type Change struct {
+	Code string
+	Path string
+}
+
+// Status returns the changes in the repository containing the current
+// directory, or an error outside a repository or without git.
+func Status() ([]Change, error) {
+	out, err := exec.Command("git", "status", "--porcelain", "-z").Output()
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	fields := strings.Split(string(out), "\x00")
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if len(f) < 4 {
+			continue
+		}
+		c := Change{Code: f[:2], Path: f[3:]}
+		if c.Code[0] == 'R' || c.Code[0] == 'C' {
+			i++ // the next field is the original path
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+// Prefix returns the current directory relative to the repository root,
+// with a trailing slash, or "" at the root or outside a repository.
+func Prefix() string {
+	out, err := exec.Command("git", "rev-parse", "--show-prefix").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
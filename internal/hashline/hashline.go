@@ -35,14 +35,37 @@ func (t TaggedLine) Tag() string {
 	return fmt.Sprintf("%d:%s|%s", t.Num, t.Hash, t.Content)
 }
 
-// TagLines takes file content and returns tagged lines.
-// If startLine > 0, numbering begins at startLine (1-indexed).
+// SplitLines splits content into lines. A final newline terminates the last
+// line rather than starting an empty one, so "a\nb\n" and "a\nb" both have
+// two lines; trailing reports which of the two content was. Empty content
+// is a single empty line, so an empty file can still be anchored.
+func SplitLines(content string) (lines []string, trailing bool) {
+	lines = strings.Split(content, "\n")
+	if len(lines) > 1 && lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1], true
+	}
+	return lines, false
+}
+
+// JoinLines is the inverse of SplitLines: it joins lines and restores the
+// final newline when trailing is set.
+func JoinLines(lines []string, trailing bool) string {
+	s := strings.Join(lines, "\n")
+	if trailing && len(lines) > 0 {
+		s += "\n"
+	}
+	return s
+}
+
+// TagLines takes file content and returns tagged lines, one per line as
+// split by SplitLines. If startLine > 0, numbering begins at startLine
+// (1-indexed).
 func TagLines(content string, startLine int) []TaggedLine {
 	if startLine <= 0 {
 		startLine = 1
 	}
 
-	lines := strings.Split(content, "\n")
+	lines, _ := SplitLines(content)
 	tagged := make([]TaggedLine, len(lines))
 	for i, line := range lines {
 		tagged[i] = TaggedLine{
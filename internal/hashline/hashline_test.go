@@ -248,3 +248,30 @@ func TestValidateRangeRelocate(t *testing.T) {
 		t.Error("expected error for inverted relocated range")
 	}
 }
+
+// TestSplitLines verifies a final newline ends the last line instead of
+// adding an empty one, and that JoinLines restores the content exactly.
+func TestSplitLines(t *testing.T) {
+	for _, tc := range []struct {
+		content  string
+		lines    int
+		trailing bool
+	}{
+		{"a\nb\n", 2, true},
+		{"a\nb", 2, false},
+		{"a\nb\n\n", 3, true}, // a real blank last line survives
+		{"\n", 1, true},
+		{"", 1, false},
+	} {
+		lines, trailing := SplitLines(tc.content)
+		if len(lines) != tc.lines || trailing != tc.trailing {
+			t.Errorf("SplitLines(%q) = %q, %v; want %d lines, trailing %v", tc.content, lines, trailing, tc.lines, tc.trailing)
+		}
+		if got := JoinLines(lines, trailing); got != tc.content {
+			t.Errorf("JoinLines round trip of %q = %q", tc.content, got)
+		}
+		if n := len(TagLines(tc.content, 1)); n != tc.lines {
+			t.Errorf("TagLines(%q) tagged %d lines, want %d", tc.content, n, tc.lines)
+		}
+	}
+}
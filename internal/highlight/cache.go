@@ -0,0 +1,101 @@
+package highlight
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultCacheBytes is the default highlight cache capacity (8 MB).
+const DefaultCacheBytes = 8 << 20
+
+// hlCache memoises Highlight results keyed by "language:theme:bg:text".
+// Render paths call Highlight every frame for mostly unchanged text, so
+// hot entries stay cached while cold ones are evicted least-recently-used.
+var (
+	hlCacheMu sync.Mutex
+	hlCache   = newLRU(DefaultCacheBytes)
+)
+
+// SetCacheSize sets the highlight cache capacity in approximate bytes.
+// Existing entries are evicted as needed; 0 disables caching.
+func SetCacheSize(bytes int) {
+	hlCacheMu.Lock()
+	defer hlCacheMu.Unlock()
+	hlCache.capacity = bytes
+	hlCache.evict()
+}
+
+func cacheGet(key string) (string, bool) {
+	hlCacheMu.Lock()
+	defer hlCacheMu.Unlock()
+	return hlCache.get(key)
+}
+
+func cachePut(key, value string) {
+	hlCacheMu.Lock()
+	defer hlCacheMu.Unlock()
+	hlCache.put(key, value)
+}
+
+// lru is a byte-bounded least-recently-used string cache. Not safe for
+// concurrent use; callers hold hlCacheMu.
+type lru struct {
+	capacity int // max total bytes of keys+values
+	size     int // current total bytes
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key, value string
+}
+
+func newLRU(capacity int) *lru {
+	return &lru{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func entrySize(key, value string) int { return len(key) + len(value) }
+
+func (c *lru) get(key string) (string, bool) {
+	el, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruEntry).value, true
+}
+
+func (c *lru) put(key, value string) {
+	sz := entrySize(key, value)
+	if sz > c.capacity {
+		return // would evict everything else for a single entry
+	}
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*lruEntry)
+		c.size += sz - entrySize(e.key, e.value)
+		e.value = value
+		c.order.MoveToFront(el)
+	} else {
+		c.items[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+		c.size += sz
+	}
+	c.evict()
+}
+
+// evict drops least-recently-used entries until size fits capacity.
+func (c *lru) evict() {
+	for c.size > c.capacity {
+		el := c.order.Back()
+		if el == nil {
+			return
+		}
+		e := el.Value.(*lruEntry)
+		c.order.Remove(el)
+		delete(c.items, e.key)
+		c.size -= entrySize(e.key, e.value)
+	}
+}
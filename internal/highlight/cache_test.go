@@ -0,0 +1,83 @@
+package highlight
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLRU(30) // room for three 10-byte entries
+	c.put("k1", "vvvvvvvv")
+	c.put("k2", "vvvvvvvv")
+	c.put("k3", "vvvvvvvv")
+
+	// Touch k1 so k2 becomes the eviction candidate.
+	if _, ok := c.get("k1"); !ok {
+		t.Fatal("expected k1 hit")
+	}
+	c.put("k4", "vvvvvvvv")
+
+	if _, ok := c.get("k2"); ok {
+		t.Error("expected k2 evicted")
+	}
+	for _, k := range []string{"k1", "k3", "k4"} {
+		if _, ok := c.get(k); !ok {
+			t.Errorf("expected %s cached", k)
+		}
+	}
+	if c.size > c.capacity {
+		t.Errorf("size %d exceeds capacity %d", c.size, c.capacity)
+	}
+}
+
+func TestLRUSkipsOversizedEntries(t *testing.T) {
+	c := newLRU(10)
+	c.put("a", "b")
+	c.put("big", strings.Repeat("x", 20))
+
+	if _, ok := c.get("big"); ok {
+		t.Error("oversized entry should not be cached")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Error("oversized put should not evict existing entries")
+	}
+}
+
+func TestLRUReplaceUpdatesSize(t *testing.T) {
+	c := newLRU(100)
+	c.put("k", "short")
+	c.put("k", "a much longer value")
+	if want := entrySize("k", "a much longer value"); c.size != want {
+		t.Errorf("size = %d, want %d", c.size, want)
+	}
+}
+
+func TestHighlightCacheConcurrent(t *testing.T) {
+	SetCacheSize(4 << 10)
+	defer SetCacheSize(DefaultCacheBytes)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				text := fmt.Sprintf("x := %d", (g+i)%10)
+				want := highlight(text, "go", "vulcan", "#282c34")
+				if got := Highlight(text, "go", "vulcan", "#282c34"); got != want {
+					t.Errorf("cached result mismatch for %q", text)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	hlCacheMu.Lock()
+	defer hlCacheMu.Unlock()
+	if hlCache.size > hlCache.capacity {
+		t.Errorf("size %d exceeds capacity %d", hlCache.size, hlCache.capacity)
+	}
+}
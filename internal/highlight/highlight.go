@@ -14,8 +14,20 @@ import (
 
 // Highlight returns an ANSI-highlighted version of text using the given
 // Chroma language and theme. bgHex ("#rrggbb") is injected after every ANSI
-// reset so the background color is never lost.
+// reset so the background color is never lost. Results are cached (see
+// SetCacheSize).
 func Highlight(text, language, theme, bgHex string) string {
+	key := language + ":" + theme + ":" + bgHex + ":" + text
+	if hl, ok := cacheGet(key); ok {
+		return hl
+	}
+	hl := highlight(text, language, theme, bgHex)
+	cachePut(key, hl)
+	return hl
+}
+
+// highlight is the uncached implementation of Highlight.
+func highlight(text, language, theme, bgHex string) string {
 	lex := lexers.Get(language)
 	if lex == nil {
 		return text
@@ -4,6 +4,7 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -31,6 +32,29 @@ type ToolCallCallback func()
 // UsageCallback is called with accumulated token usage after each LLM call.
 type UsageCallback func(inputTokens, outputTokens int)
 
+// AutoContinueCallback is called with the prompt sent when a turn carries
+// on past the model stopping with open plan items.
+type AutoContinueCallback func(prompt string)
+
+// ConfirmFunc is called before each tool call executes. Returning false
+// skips the call; reason is passed back to the model.
+type ConfirmFunc func(ctx context.Context, call provider.ToolCall) (approved bool, reason string)
+
+type confirmKey struct{}
+
+// WithConfirm returns a context carrying a turn's approval gate to the
+// tools it calls, so a tool that makes tool calls of its own, such as
+// SubAgent, gates them too.
+func WithConfirm(ctx context.Context, confirm ConfirmFunc) context.Context {
+	return context.WithValue(ctx, confirmKey{}, confirm)
+}
+
+// ConfirmFrom returns the approval gate of the turn calling a tool, or nil.
+func ConfirmFrom(ctx context.Context) ConfirmFunc {
+	confirm, _ := ctx.Value(confirmKey{}).(ConfirmFunc)
+	return confirm
+}
+
 // ScratchpadReader provides read access to the agent's working plan.
 type ScratchpadReader interface {
 	Content() string
@@ -38,17 +62,22 @@ type ScratchpadReader interface {
 
 // ProcessTurnOptions holds configuration for processing a turn.
 type ProcessTurnOptions struct {
-	Provider      provider.Provider
-	Proxy         *mcp.Proxy
-	Tools         []mcp.Tool
-	History       []provider.Message
-	OnMessage     MessageCallback
-	OnDelta       DeltaCallback    // Optional: called for each stream event
-	OnToolCall    ToolCallCallback // Optional: called before executing tool calls
-	OnUsage       UsageCallback    // Optional: called with token usage after each LLM call
-	Scratchpad    ScratchpadReader // Optional: agent plan injected at context tail
-	MaxToolRounds int
-	Depth         int // Recursion depth (0=root agent, 1=sub-agent)
+	Provider       provider.Provider
+	Proxy          *mcp.Proxy
+	Tools          []mcp.Tool
+	History        []provider.Message
+	OnMessage      MessageCallback
+	OnDelta        DeltaCallback    // Optional: called for each stream event
+	OnToolCall     ToolCallCallback // Optional: called before executing tool calls
+	OnUsage        UsageCallback    // Optional: called with token usage after each LLM call
+	Scratchpad     ScratchpadReader // Optional: agent plan injected at context tail
+	Confirm        ConfirmFunc      // Optional: user approval gate for tool calls
+	MaxToolRounds  int
+	TurnTimeout    time.Duration        // Optional: wall-clock limit for the whole turn
+	TokenBudget    int                  // Optional: max input+output tokens the turn may use
+	AutoContinue   int                  // Optional: max prompts to carry on when the model stops with open plan items
+	OnAutoContinue AutoContinueCallback // Optional: called with each such prompt
+	Depth          int                  // Recursion depth (0=root agent, 1=sub-agent)
 }
 
 // streamAndCollect runs one LLM call: streams events, collects the response,
@@ -98,6 +127,7 @@ func emitAssistant(opts *ProcessTurnOptions, resp *provider.ChatResponse) {
 		CreatedAt:    time.Now(),
 		InputTokens:  resp.InputTokens,
 		OutputTokens: resp.OutputTokens,
+		Segments:     resp.Segments,
 	}
 	if opts.OnMessage != nil {
 		opts.OnMessage(msg)
@@ -117,9 +147,32 @@ func ProcessTurn(ctx context.Context, opts ProcessTurnOptions) error {
 		return err
 	}
 
+	parent := ctx
+	if opts.TurnTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.TurnTimeout)
+		defer cancel()
+	}
+	used := 0
+	if opts.TokenBudget > 0 {
+		onUsage := opts.OnUsage
+		opts.OnUsage = func(in, out int) {
+			used += in + out
+			if onUsage != nil {
+				onUsage(in, out)
+			}
+		}
+	}
+
 	providerTools := toProviderTools(opts.Tools)
 	var recent []recentCall
+	continued := 0
 	for round := 0; round < opts.MaxToolRounds; round++ {
+		if opts.TokenBudget > 0 && used >= opts.TokenBudget {
+			stopTurn(&opts, fmt.Sprintf("token budget of %d exhausted (%d used) after %d tool rounds", opts.TokenBudget, used, round))
+			return nil
+		}
+
 		// Inject a <system-reminder> into the last tool result to keep
 		// the model focused. Two sources:
 		// 1. Scratchpad (agent-written plan) — preferred when present.
@@ -128,14 +181,22 @@ func ProcessTurn(ctx context.Context, opts ProcessTurnOptions) error {
 
 		resp, err := streamAndCollect(ctx, &opts, providerTools)
 		if err != nil {
+			if turnTimedOut(parent, ctx) {
+				stopTurn(&opts, fmt.Sprintf("%s time limit reached after %d tool rounds", opts.TurnTimeout, round))
+				return nil
+			}
 			return fmt.Errorf("LLM stream failed: %w", err)
 		}
 
 		emitAssistant(&opts, resp)
 
-		// If no tool calls, we're done
+		// If no tool calls, we're done — unless the plan says otherwise
 		if len(resp.ToolCalls) == 0 {
-			return nil
+			if continued >= opts.AutoContinue || !autoContinue(&opts) {
+				return nil
+			}
+			continued++
+			continue
 		}
 
 		// Notify about tool calls if callback provided
@@ -144,10 +205,15 @@ func ProcessTurn(ctx context.Context, opts ProcessTurnOptions) error {
 		}
 
 		// Execute each tool call and update history
-		toolResults := executeToolCalls(ctx, opts.Proxy, resp.ToolCalls, opts.OnMessage)
+		toolResults := executeToolCalls(ctx, opts.Proxy, resp.ToolCalls, opts.Confirm, opts.OnMessage)
 		opts.History = append(opts.History, toolResults...)
 		appendRecentCalls(&opts, resp.ToolCalls, toolResults, &recent)
 
+		if turnTimedOut(parent, ctx) {
+			stopTurn(&opts, fmt.Sprintf("%s time limit reached after %d tool rounds", opts.TurnTimeout, round+1))
+			return nil
+		}
+
 		// Continue loop to let LLM process tool results
 	}
 
@@ -156,6 +222,90 @@ func ProcessTurn(ctx context.Context, opts ProcessTurnOptions) error {
 	return finalizeToolLimit(ctx, &opts)
 }
 
+// turnTimedOut reports whether ctx hit the turn deadline while the caller's
+// context is still live (i.e. the user didn't cancel).
+func turnTimedOut(parent, ctx context.Context) bool {
+	return parent.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// stopTurn ends a turn that hit a budget limit with an assistant message
+// explaining why, so history stays valid for the next turn.
+func stopTurn(opts *ProcessTurnOptions, reason string) {
+	log.Warn().Str("reason", reason).Msg("Turn stopped by budget limit")
+	msg := provider.Message{
+		Role:      "assistant",
+		Content:   "Turn stopped: " + reason + ". Send a new message to continue.",
+		CreatedAt: time.Now(),
+	}
+	if opts.OnMessage != nil {
+		opts.OnMessage(msg)
+	}
+	opts.History = append(opts.History, msg)
+}
+
+// AutoContinuePrompt starts the user message that prompts the model to
+// carry on with its plan, so a resumed session can tell it from one the
+// user sent.
+const AutoContinuePrompt = "You stopped, but your plan still has open items:"
+
+// autoContinue prompts the model to work through the scratchpad's open
+// items, if it has any, and reports whether it did. The prompt is reported
+// via OnAutoContinue and emitted via OnMessage like any other message, so
+// a resumed session's history still alternates between user and assistant.
+func autoContinue(opts *ProcessTurnOptions) bool {
+	if opts.Scratchpad == nil {
+		return false
+	}
+	open := openPlanItems(opts.Scratchpad.Content())
+	if len(open) == 0 {
+		return false
+	}
+	log.Info().Int("open", len(open)).Msg("Auto-continuing turn with open plan items")
+	msg := provider.Message{
+		Role: "user",
+		Content: AutoContinuePrompt + "\n" + strings.Join(open, "\n") +
+			"\nContinue with the next one. If they are done or no longer needed, update the plan with TodoWrite and say so.",
+		CreatedAt: time.Now(),
+	}
+	if opts.OnAutoContinue != nil {
+		opts.OnAutoContinue(msg.Content)
+	}
+	if opts.OnMessage != nil {
+		opts.OnMessage(msg)
+	}
+	opts.History = append(opts.History, msg)
+	return true
+}
+
+// openPlanItems returns the plan's unchecked task-list lines, e.g.
+// "- [ ] write tests" or "2. [ ] update docs".
+func openPlanItems(plan string) []string {
+	var open []string
+	for line := range strings.Lines(plan) {
+		item := strings.TrimSpace(line)
+		marker, rest, ok := strings.Cut(item, " ")
+		if !ok {
+			continue
+		}
+		if marker != "-" && marker != "*" && marker != "+" && !isOrdinal(marker) {
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(rest), "[ ]") {
+			open = append(open, item)
+		}
+	}
+	return open
+}
+
+// isOrdinal reports whether s is an ordered-list marker such as "2." or "2)".
+func isOrdinal(s string) bool {
+	n := len(s) - 1
+	if n < 1 || (s[n] != '.' && s[n] != ')') {
+		return false
+	}
+	return strings.Trim(s[:n], "0123456789") == ""
+}
+
 func normalizeTurnOptions(opts *ProcessTurnOptions) error {
 	// Enforce max depth to prevent infinite recursion
 	if opts.Depth > MaxDepth {
@@ -269,8 +419,10 @@ func collectWithDeltas(ch <-chan provider.StreamEvent, onDelta DeltaCallback) (*
 		switch evt.Type {
 		case provider.EventContentDelta:
 			result.Content += evt.Content
+			result.Segments = addSegment(result.Segments, false, len(evt.Content))
 		case provider.EventReasoningDelta:
 			result.Reasoning += evt.Content
+			result.Segments = addSegment(result.Segments, true, len(evt.Content))
 		case provider.EventToolCallBegin:
 			tca.begin(evt)
 		case provider.EventToolCallDelta:
@@ -295,14 +447,57 @@ func collectWithDeltas(ch <-chan provider.StreamEvent, onDelta DeltaCallback) (*
 	return &result, nil
 }
 
+// addSegment extends the last segment by n bytes, or starts a new one when
+// the stream switches between reasoning and content.
+func addSegment(segs []provider.Segment, reasoning bool, n int) []provider.Segment {
+	if n == 0 {
+		return segs
+	}
+	if last := len(segs) - 1; last >= 0 && segs[last].Reasoning == reasoning {
+		segs[last].Len += n
+		return segs
+	}
+	return append(segs, provider.Segment{Reasoning: reasoning, Len: n})
+}
+
+// pendingCalls converts the calls still queued in a batch for handlers.
+func pendingCalls(calls []provider.ToolCall) []mcp.ToolCall {
+	out := make([]mcp.ToolCall, len(calls))
+	for i, c := range calls {
+		out[i] = mcp.ToolCall{Name: c.Name, Arguments: c.Arguments}
+	}
+	return out
+}
+
 // executeToolCalls executes a list of tool calls and adds results to history.
 // Returns the list of tool result messages that were added.
-func executeToolCalls(ctx context.Context, proxy *mcp.Proxy, toolCalls []provider.ToolCall, onMessage MessageCallback) []provider.Message {
+func executeToolCalls(ctx context.Context, proxy *mcp.Proxy, toolCalls []provider.ToolCall, confirm ConfirmFunc, onMessage MessageCallback) []provider.Message {
 	toolResults := make([]provider.Message, 0, len(toolCalls))
 
-	for _, toolCall := range toolCalls {
+	for i, toolCall := range toolCalls {
+		if confirm != nil {
+			if ok, reason := confirm(ctx, toolCall); !ok {
+				toolMsg := provider.Message{
+					Role:         "tool",
+					Content:      declinedResult(reason),
+					ToolCallID:   toolCall.ID,
+					FunctionName: toolCall.Name,
+					CreatedAt:    time.Now(),
+				}
+				if onMessage != nil {
+					onMessage(toolMsg)
+				}
+				toolResults = append(toolResults, toolMsg)
+				continue
+			}
+		}
+
 		// Execute tool via MCP proxy
-		result, err := proxy.CallTool(ctx, toolCall.Name, toolCall.Arguments)
+		callCtx := mcp.WithPendingCalls(ctx, pendingCalls(toolCalls[i+1:]))
+		if confirm != nil {
+			callCtx = WithConfirm(callCtx, confirm)
+		}
+		result, err := proxy.CallTool(callCtx, toolCall.Name, toolCall.Arguments)
 
 		if err != nil {
 			// Add error result to history
@@ -312,6 +507,7 @@ func executeToolCalls(ctx context.Context, proxy *mcp.Proxy, toolCalls []provide
 				ToolCallID:   toolCall.ID,
 				FunctionName: toolCall.Name,
 				CreatedAt:    time.Now(),
+				IsError:      true,
 			}
 			if onMessage != nil {
 				onMessage(toolMsg)
@@ -331,6 +527,7 @@ func executeToolCalls(ctx context.Context, proxy *mcp.Proxy, toolCalls []provide
 				ToolCallID:   toolCall.ID,
 				FunctionName: toolCall.Name,
 				CreatedAt:    time.Now(),
+				IsError:      true,
 			}
 			if onMessage != nil {
 				onMessage(toolMsg)
@@ -359,6 +556,15 @@ func executeToolCalls(ctx context.Context, proxy *mcp.Proxy, toolCalls []provide
 	return toolResults
 }
 
+// declinedResult is the tool result sent when the user rejects a call.
+func declinedResult(reason string) string {
+	msg := "The user declined this tool call; it was not executed."
+	if reason != "" {
+		msg += " Reason: " + reason
+	}
+	return msg + " Do not retry it unchanged — adjust your approach or ask the user."
+}
+
 // reminderInterval is the number of tool-calling rounds between synthetic
 // goal reminders. After this many rounds the loop injects a system message
 // reciting the user's original request so it stays in the model's recent
@@ -0,0 +1,118 @@
+package llm
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xonecas/symb/internal/provider"
+)
+
+// TestCollectWithDeltasSegments verifies the response records the order
+// reasoning and content streamed in.
+func TestCollectWithDeltasSegments(t *testing.T) {
+	ch := make(chan provider.StreamEvent, 8)
+	for _, evt := range []provider.StreamEvent{
+		{Type: provider.EventReasoningDelta, Content: "think "},
+		{Type: provider.EventReasoningDelta, Content: "one"},
+		{Type: provider.EventContentDelta, Content: "answer"},
+		{Type: provider.EventContentDelta, Content: ""},
+		{Type: provider.EventReasoningDelta, Content: "two"},
+		{Type: provider.EventDone},
+	} {
+		ch <- evt
+	}
+	close(ch)
+
+	resp, err := collectWithDeltas(ch, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []provider.Segment{{Reasoning: true, Len: 9}, {Len: 6}, {Reasoning: true, Len: 3}}
+	if resp.Reasoning != "think onetwo" || resp.Content != "answer" || !reflect.DeepEqual(resp.Segments, want) {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+// textProvider replies to every call with the same text and counts calls.
+type textProvider struct{ calls *int }
+
+func (p textProvider) Name() string { return "text" }
+
+func (p textProvider) ChatStream(context.Context, []provider.Message, []provider.Tool) (<-chan provider.StreamEvent, error) {
+	*p.calls++
+	ch := make(chan provider.StreamEvent, 2)
+	ch <- provider.StreamEvent{Type: provider.EventContentDelta, Content: "done for now"}
+	ch <- provider.StreamEvent{Type: provider.EventDone}
+	close(ch)
+	return ch, nil
+}
+
+func (p textProvider) ListModels(context.Context) ([]provider.Model, error) { return nil, nil }
+
+func (p textProvider) Close() error { return nil }
+
+type staticPlan string
+
+func (p staticPlan) Content() string { return string(p) }
+
+func TestOpenPlanItems(t *testing.T) {
+	plan := `# Plan
+- [x] read the code
+- [ ] write tests
+  * [ ] nested item
++ [ ]no space after the box
+1. [ ] update docs
+2) [X] done
+10. [ ] ship
+a. [ ] not an ordinal
+-[ ] no space after the marker
+[ ] no marker
+- plain item`
+	want := []string{"- [ ] write tests", "* [ ] nested item", "+ [ ]no space after the box", "1. [ ] update docs", "10. [ ] ship"}
+	if got := openPlanItems(plan); !reflect.DeepEqual(got, want) {
+		t.Errorf("openPlanItems = %q, want %q", got, want)
+	}
+}
+
+// TestAutoContinue verifies a turn that stops with open plan items is
+// prompted to carry on, at most AutoContinue times, and that each prompt
+// is emitted as a message so it is saved with the turn.
+func TestAutoContinue(t *testing.T) {
+	tests := []struct {
+		name      string
+		plan      string
+		limit     int
+		wantCalls int
+	}{
+		{"open items", "- [ ] one\n- [x] two", 2, 3},
+		{"disabled", "- [ ] one", 0, 1},
+		{"plan done", "- [x] one", 2, 1},
+		{"no plan", "", 2, 1},
+	}
+	for _, tt := range tests {
+		calls, prompts := 0, 0
+		var roles []string
+		err := ProcessTurn(context.Background(), ProcessTurnOptions{
+			Provider:     textProvider{calls: &calls},
+			History:      []provider.Message{{Role: "user", Content: "go"}},
+			Scratchpad:   staticPlan(tt.plan),
+			AutoContinue: tt.limit,
+			OnAutoContinue: func(prompt string) {
+				prompts++
+				if !strings.HasPrefix(prompt, AutoContinuePrompt+"\n- [ ] one\n") {
+					t.Errorf("%s: prompt = %q", tt.name, prompt)
+				}
+			},
+			OnMessage: func(msg provider.Message) { roles = append(roles, msg.Role) },
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		wantRoles := strings.TrimSuffix(strings.Repeat("assistant user ", tt.wantCalls), " user ")
+		if calls != tt.wantCalls || prompts != tt.wantCalls-1 || strings.Join(roles, " ") != wantRoles {
+			t.Errorf("%s: %d calls, %d prompts, messages %v", tt.name, calls, prompts, roles)
+		}
+	}
+}
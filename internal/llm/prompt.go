@@ -147,6 +147,10 @@ func SubAgentTypePrompt(agentType string) string {
 	}
 }
 
+// indexingNote ends the project outline while the symbol index is still
+// being built.
+const indexingNote = "# (indexing... outline incomplete — use Grep to locate symbols)"
+
 // BuildSystemPrompt constructs the complete system prompt:
 // 1. Base prompt (shared across all models)
 // 2. Model-specific overrides
@@ -164,7 +168,11 @@ func BuildSystemPrompt(modelID string, idx *treesitter.Index) string {
 	}
 
 	if idx != nil {
-		if outline := treesitter.FormatOutline(idx.Snapshot()); outline != "" {
+		outline := treesitter.FormatOutline(idx.Snapshot())
+		if !idx.Ready() {
+			outline = strings.TrimSpace(outline + "\n" + indexingNote)
+		}
+		if outline != "" {
 			parts = append(parts, outline)
 		}
 	}
@@ -172,6 +180,16 @@ func BuildSystemPrompt(modelID string, idx *treesitter.Index) string {
 	return strings.Join(parts, "\n\n---\n\n")
 }
 
+// RefreshSystemPrompt rebuilds a system prompt whose outline was taken
+// before idx was ready. It reports false, leaving prompt as is, when the
+// outline was complete or idx is still building.
+func RefreshSystemPrompt(prompt, modelID string, idx *treesitter.Index) (string, bool) {
+	if idx == nil || !idx.Ready() || !strings.Contains(prompt, indexingNote) {
+		return prompt, false
+	}
+	return BuildSystemPrompt(modelID, idx), true
+}
+
 // readFileIfExists reads a file if it exists, returns empty string otherwise.
 func readFileIfExists(path string) string {
 	data, err := os.ReadFile(path)
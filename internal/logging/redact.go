@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const redacted = "[REDACTED]"
+
+// secretFieldRe matches JSON string fields whose names carry credentials,
+// both at the top level of a log line and inside escaped JSON payloads
+// (e.g. an error body logged as a string).
+var secretFieldRe = regexp.MustCompile(
+	`(?i)(\\?"(?:authorization|x-api-key|api[_-]?key|access[_-]?token|refresh[_-]?token|token|secret|password)\\?"\s*:\s*\\?")` +
+		`((?:[^"\\]|\\[^"])*)`)
+
+// bearerRe matches bearer credentials in header dumps or free text.
+var bearerRe = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`)
+
+// minSecretLen skips registering trivially short values that would
+// otherwise redact unrelated text.
+const minSecretLen = 8
+
+// Redactor is an io.Writer that scrubs credentials from each log line
+// before passing it on. It is installed regardless of level so a debug log
+// can't leak API keys.
+type Redactor struct {
+	w       io.Writer
+	mu      sync.RWMutex
+	secrets []string
+}
+
+// NewRedactor wraps w with credential scrubbing.
+func NewRedactor(w io.Writer) *Redactor {
+	return &Redactor{w: w}
+}
+
+// AddSecret registers a literal value (e.g. an API key) that must never
+// appear in the log, whatever field it ends up in.
+func (r *Redactor) AddSecret(s string) {
+	if len(s) < minSecretLen {
+		return
+	}
+	r.mu.Lock()
+	r.secrets = append(r.secrets, s)
+	r.mu.Unlock()
+}
+
+// Write redacts p and writes it to the underlying writer. It reports len(p)
+// on success since callers only care that their line was consumed.
+func (r *Redactor) Write(p []byte) (int, error) {
+	if _, err := r.w.Write([]byte(r.Redact(string(p)))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Redact returns s with known secret values, secret-named fields, and
+// bearer tokens replaced.
+func (r *Redactor) Redact(s string) string {
+	r.mu.RLock()
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	r.mu.RUnlock()
+	s = secretFieldRe.ReplaceAllString(s, "${1}"+redacted)
+	return bearerRe.ReplaceAllString(s, "${1}"+redacted)
+}
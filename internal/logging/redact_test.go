@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+const testKey = "sk-test-0123456789abcdef"
+
+func TestRedactor_KeyNeverLogged(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRedactor(&buf)
+	r.AddSecret(testKey)
+	logger := zerolog.New(r).Level(zerolog.TraceLevel)
+
+	logger.Debug().Str("request_body", `{"model":"m","note":"`+testKey+`"}`).Msg("request")
+	logger.Debug().Str("authorization", "Bearer "+testKey).Msg("headers")
+	logger.Error().Str("body", `{"error":"bad key `+testKey+`"}`).Msg("api error")
+
+	out := buf.String()
+	if strings.Contains(out, testKey) {
+		t.Fatalf("key leaked into log:\n%s", out)
+	}
+	if got := strings.Count(out, redacted); got < 3 {
+		t.Errorf("expected at least 3 redactions, got %d:\n%s", got, out)
+	}
+}
+
+func TestRedactor_SecretFields(t *testing.T) {
+	r := NewRedactor(&bytes.Buffer{})
+	tests := []struct {
+		in   string
+		leak string
+	}{
+		{`{"Authorization":"Bearer abc.def"}`, "abc.def"},
+		{`{"x-api-key":"k-123"}`, "k-123"},
+		{`{"body":"{\"api_key\":\"k-456\",\"model\":\"m\"}"}`, "k-456"},
+		{`{"msg":"Authorization: Bearer tok789"}`, "tok789"},
+		{`{"password": "hunter2"}`, "hunter2"},
+	}
+	for _, tt := range tests {
+		got := r.Redact(tt.in)
+		if strings.Contains(got, tt.leak) {
+			t.Errorf("Redact(%q) = %q, still contains %q", tt.in, got, tt.leak)
+		}
+	}
+
+	// Unrelated fields are left alone.
+	in := `{"level":"info","model":"m","prompt_tokens":12}`
+	if got := r.Redact(in); got != in {
+		t.Errorf("Redact(%q) = %q, want unchanged", in, got)
+	}
+}
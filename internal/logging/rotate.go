@@ -0,0 +1,134 @@
+// Package logging provides the file sink for symb's zerolog output:
+// size-based rotation and credential redaction.
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotatingFile is an io.Writer that appends to a log file and rotates it
+// when it grows past maxSize. Rotated files are named path.1 (newest) to
+// path.N; backups beyond maxBackups or older than maxAge are removed.
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+	file       *os.File
+	size       int64
+}
+
+// OpenRotating opens (or creates) the log file at path for appending.
+// A zero maxAge keeps backups regardless of age.
+func OpenRotating(path string, maxSize int64, maxBackups int, maxAge time.Duration) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups, maxAge: maxAge}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	if r.size >= maxSize {
+		if err := r.rotate(); err != nil {
+			r.file.Close()
+			return nil, err
+		}
+	}
+	r.prune()
+	return r, nil
+}
+
+// Write appends p, rotating first if it would push the file past maxSize.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the underlying file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+func (r *RotatingFile) open() error {
+	//nolint:gosec // G304: path is the fixed log location under the data dir
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file = f
+	r.size = info.Size()
+	return nil
+}
+
+// rotate shifts path -> path.1 -> path.2 ... and reopens an empty path.
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	_ = os.Remove(r.backupName(r.maxBackups))
+	for i := r.maxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(r.backupName(i), r.backupName(i+1))
+	}
+	if r.maxBackups > 0 {
+		if err := os.Rename(r.path, r.backupName(1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else {
+		_ = os.Remove(r.path)
+	}
+	return r.open()
+}
+
+// prune removes backups older than maxAge.
+func (r *RotatingFile) prune() {
+	if r.maxAge <= 0 {
+		return
+	}
+	matches, _ := filepath.Glob(r.path + ".*")
+	cutoff := time.Now().Add(-r.maxAge)
+	for _, m := range matches {
+		if !isBackupName(r.path, m) {
+			continue
+		}
+		if info, err := os.Stat(m); err == nil && info.ModTime().Before(cutoff) {
+			_ = os.Remove(m)
+		}
+	}
+}
+
+func (r *RotatingFile) backupName(n int) string {
+	return fmt.Sprintf("%s.%d", r.path, n)
+}
+
+// isBackupName reports whether name is path followed by ".<digits>".
+func isBackupName(path, name string) bool {
+	suffix := strings.TrimPrefix(name, path+".")
+	if suffix == name || suffix == "" {
+		return false
+	}
+	for _, c := range suffix {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile_RotatesAndCapsBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "symb.log")
+	r, err := OpenRotating(path, 100, 2, 0)
+	if err != nil {
+		t.Fatalf("OpenRotating: %v", err)
+	}
+	defer r.Close()
+
+	line := strings.Repeat("x", 59) + "\n" // two lines exceed 100 bytes
+	for i := 0; i < 6; i++ {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("expected %s: %v", filepath.Base(name), err)
+		}
+		if info.Size() > 100 {
+			t.Errorf("%s size %d exceeds max", filepath.Base(name), info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("expected at most 2 backups")
+	}
+}
+
+func TestRotatingFile_PrunesOldBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "symb.log")
+	old := path + ".1"
+	if err := os.WriteFile(old, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(old, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenRotating(path, 1<<20, 3, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("OpenRotating: %v", err)
+	}
+	defer r.Close()
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("expected backup older than maxAge to be removed")
+	}
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Transcript is a redacted, per-session transcript file: dir/<session>.log.
+// SetSession moves it onto another session's file (e.g. after a fork)
+// without touching the writers that hold it.
+type Transcript struct {
+	*Redactor
+	dir string
+	out *sessionFile
+}
+
+// sessionFile is the current session's file, swapped under mu.
+type sessionFile struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (f *sessionFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Write(p)
+}
+
+// OpenTranscript creates dir if needed and opens (appending) the transcript
+// for sessionID.
+func OpenTranscript(dir, sessionID string) (*Transcript, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+	out := &sessionFile{}
+	t := &Transcript{Redactor: NewRedactor(out), dir: dir, out: out}
+	if err := t.SetSession(sessionID); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SetSession closes the current file and appends to sessionID's from now
+// on. On error the current file is kept.
+func (t *Transcript) SetSession(sessionID string) error {
+	//nolint:gosec // G304: path is built from the transcript dir and session ID
+	file, err := os.OpenFile(filepath.Join(t.dir, sessionID+".log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	t.out.mu.Lock()
+	old := t.out.file
+	t.out.file = file
+	t.out.mu.Unlock()
+	if old != nil {
+		return old.Close()
+	}
+	return nil
+}
+
+// Close closes the current file.
+func (t *Transcript) Close() error {
+	t.out.mu.Lock()
+	defer t.out.mu.Unlock()
+	return t.out.file.Close()
+}
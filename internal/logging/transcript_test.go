@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTranscript_FollowsSession(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "transcripts")
+	tr, err := OpenTranscript(dir, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.AddSecret("sk-secret-value")
+	fmt.Fprintln(tr, "first key=sk-secret-value")
+	if err := tr.SetSession("b"); err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(tr, "second")
+	if err := tr.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	if got := read("a.log"); got != "first key=[REDACTED]\n" {
+		t.Errorf("a.log = %q", got)
+	}
+	if got := read("b.log"); got != "second\n" {
+		t.Errorf("b.log = %q, want writes after SetSession", got)
+	}
+}
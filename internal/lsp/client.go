@@ -18,8 +18,24 @@ import (
 const (
 	SeverityError   = 1
 	SeverityWarning = 2
+	SeverityInfo    = 3
+	SeverityHint    = 4
 )
 
+// severityNames maps config names to severities.
+var severityNames = map[string]int{
+	"error":   SeverityError,
+	"warning": SeverityWarning,
+	"info":    SeverityInfo,
+	"hint":    SeverityHint,
+}
+
+// ParseSeverity returns the severity for "error", "warning", "info" or
+// "hint", or 0 if the name is unknown.
+func ParseSeverity(name string) int {
+	return severityNames[name]
+}
+
 // Client wraps a powernap LSP client with diagnostics tracking.
 type Client struct {
 	inner    *powernap.Client
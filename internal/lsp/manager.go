@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -32,8 +33,9 @@ var skipAutoStart = map[string]bool{
 }
 
 // DiagCallback is called when diagnostics change for a file.
-// absPath is the filesystem path; lines maps 0-indexed line numbers to max severity.
-type DiagCallback func(absPath string, lines map[int]int)
+// absPath is the filesystem path; lines maps 0-indexed line numbers to max
+// severity and messages maps the same lines to the most severe message.
+type DiagCallback func(absPath string, lines map[int]int, messages map[int]string)
 
 // Manager manages LSP server lifecycles keyed by server name.
 type Manager struct {
@@ -43,21 +45,95 @@ type Manager struct {
 	clients map[string]*Client // serverName -> client
 	broken  map[string]bool    // servers that failed to start
 
+	configured   map[string]bool // servers the user set up explicitly
+	startTimeout time.Duration
+	maxSeverity  int           // least severe diagnostic surfaced (SeverityWarning by default)
+	waitTimeout  time.Duration // how long edits wait for diagnostics
+
 	callback DiagCallback
 }
 
-// NewManager creates a manager with powernap's built-in server defaults.
-func NewManager() *Manager {
+// ServerOverride adds a server or overrides a built-in one by name. Empty
+// fields keep the built-in value.
+type ServerOverride struct {
+	Disabled    bool
+	Command     string
+	Args        []string
+	FileTypes   []string // language IDs, e.g. "go", "typescript"
+	RootMarkers []string
+	Environment map[string]string
+	Settings    map[string]any
+	InitOptions map[string]any
+}
+
+const (
+	// defaultStartTimeout bounds server startup and initialization.
+	defaultStartTimeout = 15 * time.Second
+	// defaultWaitTimeout bounds how long an edit waits for diagnostics.
+	defaultWaitTimeout = 5 * time.Second
+)
+
+// NewManager creates a manager with powernap's built-in server defaults,
+// adjusted by overrides. Servers start lazily on the first file of a
+// matching language. startTimeout <= 0 uses the default.
+func NewManager(overrides map[string]ServerOverride, startTimeout time.Duration) *Manager {
 	// Silence powernap's slog output — it writes to stderr which the TUI owns.
 	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
 
+	if startTimeout <= 0 {
+		startTimeout = defaultStartTimeout
+	}
 	cm := powernapconfig.NewManager()
 	_ = cm.LoadDefaults()
+	configured := make(map[string]bool)
+	for name, o := range overrides {
+		if o.Disabled {
+			cm.RemoveServer(name)
+			continue
+		}
+		cm.AddServer(name, applyOverride(cm, name, o))
+		configured[name] = true
+	}
 	return &Manager{
-		cfgMgr:  cm,
-		clients: make(map[string]*Client),
-		broken:  make(map[string]bool),
+		cfgMgr:       cm,
+		clients:      make(map[string]*Client),
+		broken:       make(map[string]bool),
+		configured:   configured,
+		startTimeout: startTimeout,
+		maxSeverity:  SeverityWarning,
+		waitTimeout:  defaultWaitTimeout,
+	}
+}
+
+// applyOverride merges o into the built-in config for name, if any.
+func applyOverride(cm *powernapconfig.Manager, name string, o ServerOverride) *powernapconfig.ServerConfig {
+	cfg := &powernapconfig.ServerConfig{RootMarkers: []string{".git"}}
+	if base, ok := cm.GetServer(name); ok {
+		c := *base
+		cfg = &c
+	}
+	if o.Command != "" {
+		cfg.Command = o.Command
+	}
+	if o.Args != nil {
+		cfg.Args = o.Args
+	}
+	if o.FileTypes != nil {
+		cfg.FileTypes = o.FileTypes
+	}
+	if o.RootMarkers != nil {
+		cfg.RootMarkers = o.RootMarkers
 	}
+	if o.Environment != nil {
+		cfg.Environment = o.Environment
+	}
+	if o.Settings != nil {
+		cfg.Settings = o.Settings
+	}
+	if o.InitOptions != nil {
+		cfg.InitOptions = o.InitOptions
+	}
+	return cfg
 }
 
 // SetCallback sets the function called when diagnostics change.
@@ -67,6 +143,41 @@ func (m *Manager) SetCallback(cb DiagCallback) {
 	m.callback = cb
 }
 
+// SetMaxSeverity sets the least severe diagnostic to surface, e.g.
+// SeverityError to hide warnings. Out-of-range values are ignored.
+func (m *Manager) SetMaxSeverity(sev int) {
+	if sev < SeverityError || sev > SeverityHint {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxSeverity = sev
+}
+
+// MaxSeverity returns the least severe diagnostic surfaced.
+func (m *Manager) MaxSeverity() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.maxSeverity
+}
+
+// SetWaitTimeout sets how long edits wait for diagnostics. d <= 0 is ignored.
+func (m *Manager) SetWaitTimeout(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.waitTimeout = d
+}
+
+// WaitTimeout returns how long edits wait for diagnostics.
+func (m *Manager) WaitTimeout() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.waitTimeout
+}
+
 // TouchFile ensures the right LSP servers are running for this file and
 // sends didOpen/didChange. Non-blocking — errors are logged, not returned.
 func (m *Manager) TouchFile(ctx context.Context, absPath string) {
@@ -102,9 +213,10 @@ func (m *Manager) NotifyAndWait(ctx context.Context, absPath string, timeout tim
 	// Fire callback once with aggregated diagnostics from all servers.
 	m.mu.Lock()
 	cb := m.callback
+	maxSev := m.maxSeverity
 	m.mu.Unlock()
 	if cb != nil {
-		cb(absPath, diagLineSeverities(all))
+		cb(absPath, diagLineSeverities(all, maxSev), diagLineMessages(all, maxSev))
 	}
 
 	return all
@@ -162,7 +274,7 @@ func (m *Manager) ensureClients(ctx context.Context, absPath string) []*Client {
 			result = append(result, c)
 			continue
 		}
-		if skipAutoStart[cfg.Command] {
+		if skipAutoStart[cfg.Command] && !m.configured[name] {
 			m.broken[name] = true
 			continue
 		}
@@ -218,7 +330,7 @@ func (m *Manager) startClient(ctx context.Context, name string, cfg *powernapcon
 		return nil, err
 	}
 
-	initCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	initCtx, cancel := context.WithTimeout(ctx, m.startTimeout)
 	defer cancel()
 
 	if err := c.initialize(initCtx); err != nil {
@@ -259,15 +371,16 @@ func findRoot(absPath string, markers []string) string {
 }
 
 // diagLineSeverities converts a diagnostic slice to a line->severity map.
-// Only tracks errors (1) and warnings (2). Lower severity number wins.
-func diagLineSeverities(diags []protocol.Diagnostic) map[int]int {
+// Only tracks errors (1) and warnings (2), and nothing less severe than
+// maxSev. Lower severity number wins.
+func diagLineSeverities(diags []protocol.Diagnostic, maxSev int) map[int]int {
 	if len(diags) == 0 {
 		return nil
 	}
 	lines := make(map[int]int)
 	for _, d := range diags {
 		sev := int(d.Severity)
-		if sev != SeverityError && sev != SeverityWarning {
+		if !gutterSeverity(sev, maxSev) {
 			continue
 		}
 		line := int(d.Range.Start.Line) // 0-indexed
@@ -278,50 +391,70 @@ func diagLineSeverities(diags []protocol.Diagnostic) map[int]int {
 	return lines
 }
 
-// FormatDiagnostics formats diagnostics as a text block for LLM tool responses.
-// Returns empty string if no errors or warnings.
-func FormatDiagnostics(displayPath string, diags []protocol.Diagnostic) string {
+// diagLineMessages maps each error/warning line to the message of its most
+// severe diagnostic. Only the first line of multi-line messages is kept.
+func diagLineMessages(diags []protocol.Diagnostic, maxSev int) map[int]string {
 	if len(diags) == 0 {
-		return ""
+		return nil
 	}
-
-	var buf []byte
-	count := 0
+	msgs := make(map[int]string)
+	sevs := make(map[int]int)
 	for _, d := range diags {
 		sev := int(d.Severity)
-		if sev != SeverityError && sev != SeverityWarning {
+		if !gutterSeverity(sev, maxSev) {
 			continue
 		}
-		if count == 0 {
-			buf = append(buf, fmt.Sprintf("\nLSP diagnostics:\n<diagnostics file=%q>\n", displayPath)...)
+		line := int(d.Range.Start.Line)
+		if existing, ok := sevs[line]; ok && sev >= existing {
+			continue
 		}
-		label := "WARNING"
-		if sev == SeverityError {
-			label = "ERROR"
+		sevs[line] = sev
+		msg, _, _ := strings.Cut(d.Message, "\n")
+		msgs[line] = strings.TrimSpace(msg)
+	}
+	return msgs
+}
+
+// gutterSeverity reports whether sev is an error or warning no less severe
+// than maxSev.
+func gutterSeverity(sev, maxSev int) bool {
+	return (sev == SeverityError || sev == SeverityWarning) && sev <= maxSev
+}
+
+// severityLabels are the tool-result prefixes, indexed by severity.
+var severityLabels = [...]string{
+	SeverityError:   "ERROR",
+	SeverityWarning: "WARNING",
+	SeverityInfo:    "INFO",
+	SeverityHint:    "HINT",
+}
+
+// FormatDiagnostics formats diagnostics as a text block for LLM tool responses.
+// Only diagnostics at least as severe as maxSev are included. Returns empty
+// string if none remain.
+func FormatDiagnostics(displayPath string, diags []protocol.Diagnostic, maxSev int) string {
+	var kept []protocol.Diagnostic
+	for _, d := range diags {
+		if sev := int(d.Severity); sev >= SeverityError && sev <= maxSev {
+			kept = append(kept, d)
+		}
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+
+	buf := []byte(fmt.Sprintf("\nLSP diagnostics:\n<diagnostics file=%q>\n", displayPath))
+	for i, d := range kept {
+		if i == 20 {
+			buf = append(buf, fmt.Sprintf("... and %d more\n", len(kept)-i)...)
+			break
 		}
 		buf = append(buf, fmt.Sprintf("%s [%d:%d] %s\n",
-			label,
+			severityLabels[d.Severity],
 			d.Range.Start.Line+1, // display as 1-indexed
 			d.Range.Start.Character+1,
 			d.Message,
 		)...)
-		count++
-		if count >= 20 {
-			remaining := 0
-			for _, dd := range diags {
-				if int(dd.Severity) == SeverityError || int(dd.Severity) == SeverityWarning {
-					remaining++
-				}
-			}
-			remaining -= count
-			if remaining > 0 {
-				buf = append(buf, fmt.Sprintf("... and %d more\n", remaining)...)
-			}
-			break
-		}
-	}
-	if count == 0 {
-		return ""
 	}
 	buf = append(buf, "</diagnostics>"...)
 	return string(buf)
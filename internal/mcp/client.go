@@ -22,6 +22,7 @@ type Client struct {
 	requestID       atomic.Int64
 	sessionID       string // Session ID from server, included in subsequent requests
 	protocolVersion string // Negotiated protocol version
+	userAgent       string
 }
 
 // NewClient creates a new MCP client.
@@ -35,6 +36,11 @@ func NewClient(endpoint string) *Client {
 	}
 }
 
+// SetUserAgent sets the User-Agent header sent with every request.
+func (c *Client) SetUserAgent(ua string) {
+	c.userAgent = ua
+}
+
 // nextID returns the next request ID.
 func (c *Client) nextID() int64 {
 	return c.requestID.Add(1)
@@ -65,6 +71,9 @@ func (c *Client) send(ctx context.Context, req *Request) (*Response, error) {
 
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "application/json, text/event-stream")
+	if c.userAgent != "" {
+		httpReq.Header.Set("User-Agent", c.userAgent)
+	}
 
 	// Include session ID if we have one (required after initialization)
 	if c.sessionID != "" {
@@ -255,8 +264,7 @@ func (c *Client) Initialize(ctx context.Context, clientInfo map[string]interface
 		if err == nil {
 			break
 		}
-		// Log attempt (using fmt to avoid dependency on logger)
-		fmt.Printf("MCP connection attempt %d/%d failed: %v\n", i+1, maxRetries, err)
+		log.Warn().Err(err).Int("attempt", i+1).Int("max", maxRetries).Msg("MCP connection attempt failed")
 	}
 
 	if err != nil {
@@ -303,6 +311,9 @@ func (c *Client) Notify(ctx context.Context, method string, params interface{})
 
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "application/json, text/event-stream")
+	if c.userAgent != "" {
+		httpReq.Header.Set("User-Agent", c.userAgent)
+	}
 
 	// Include session ID if we have one
 	if c.sessionID != "" {
@@ -3,14 +3,20 @@ package mcp
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/rs/zerolog/log"
 )
@@ -24,8 +30,44 @@ type Proxy struct {
 	upstream      UpstreamClient
 	localTools    map[string]Tool
 	localHandlers map[string]ToolHandler
+	offline       bool
+
+	// Result cache for read-only tools; see EnableCache.
+	cacheTTL      time.Duration
+	cachePolicies map[string]CachePolicy
+	cache         map[[32]byte]cachedResult
+
+	// Retries for transient failures; see SetRetries.
+	retries int
+
+	// Per-tool result caps in tokens; see SetResultTokens.
+	resultTokens map[string]int
+}
+
+type cachedResult struct {
+	result  *ToolResult
+	files   []string // files the result depends on; nil means any file
+	expires time.Time
+}
+
+// CachePolicy tells the result cache how a tool relates to files.
+type CachePolicy struct {
+	// Cache stores the tool's successful results. Only read-only tools
+	// may set it.
+	Cache bool
+	// Files returns the absolute paths of the files a call reads (cached
+	// tools) or may change (the others). A nil Files means any file: a
+	// cached result is dropped on any change, and a call drops the whole
+	// cache.
+	Files func(arguments json.RawMessage) []string
+	// Hit replays the side effects of a call served from the cache, such
+	// as marking files read.
+	Hit func(arguments json.RawMessage, result *ToolResult)
 }
 
+// NoFiles is a CachePolicy.Files for tools that never change files.
+func NoFiles(json.RawMessage) []string { return nil }
+
 var (
 	ErrToolRetryExhausted = errors.New("mcp tool call failed after retries")
 )
@@ -70,6 +112,7 @@ func NewProxy(upstream UpstreamClient) *Proxy {
 		upstream:      upstream,
 		localTools:    make(map[string]Tool),
 		localHandlers: make(map[string]ToolHandler),
+		retries:       len(toolRetryDelays),
 	}
 }
 
@@ -82,6 +125,139 @@ func (p *Proxy) RegisterTool(tool Tool, handler ToolHandler) {
 	p.localHandlers[tool.Name] = handler
 }
 
+// EnableCache caches successful results of the tools whose policy sets
+// Cache for ttl, keyed by tool name and arguments. A call to another tool
+// drops the cached results that depend on the files it may change; local
+// tools without a policy may change any file.
+func (p *Proxy) EnableCache(ttl time.Duration, policies map[string]CachePolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cacheTTL = ttl
+	p.cachePolicies = policies
+	p.cache = make(map[[32]byte]cachedResult)
+}
+
+// SetRetries sets how many times a transiently failing call is retried:
+// upstream (web) tools and local tools marked Retryable. n <= 0 disables
+// retries.
+func (p *Proxy) SetRetries(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.retries = max(n, 0)
+}
+
+// SetResultTokens caps the result of each named tool at about that many
+// tokens. A longer result is cut at a line boundary, with a note telling
+// the model how to narrow the call. Tools not named are not capped.
+func (p *Proxy) SetResultTokens(limits map[string]int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.resultTokens = limits
+}
+
+// InvalidateCache drops all cached tool results.
+func (p *Proxy) InvalidateCache() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cache != nil {
+		clear(p.cache)
+	}
+}
+
+// InvalidateFiles drops the cached results that depend on any of the
+// given absolute paths. Call it when files change outside a tool call,
+// e.g. on undo or an external edit.
+func (p *Proxy) InvalidateFiles(paths ...string) {
+	if len(paths) == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, hit := range p.cache {
+		if hit.files == nil || slices.ContainsFunc(hit.files, func(f string) bool { return slices.Contains(paths, f) }) {
+			delete(p.cache, key)
+		}
+	}
+}
+
+func cacheKey(name string, arguments json.RawMessage) [32]byte {
+	h := sha256.New()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write(arguments)
+	var key [32]byte
+	h.Sum(key[:0])
+	return key
+}
+
+// cachedCall serves a cacheable tool from the cache, or runs it and stores
+// a successful result. Other tools invalidate what they may change around
+// the call. Results are copied in and out so callers never share one.
+func (p *Proxy) cachedCall(ctx context.Context, name string, arguments json.RawMessage, call func() (*ToolResult, error)) (*ToolResult, error) {
+	p.mu.RLock()
+	enabled := p.cache != nil
+	policy, known := p.cachePolicies[name]
+	_, local := p.localHandlers[name]
+	p.mu.RUnlock()
+	if !enabled {
+		return call()
+	}
+	if !known && !local {
+		// Upstream tools can't touch local files.
+		policy.Files = NoFiles
+	}
+	if !policy.Cache {
+		if policy.Files == nil {
+			p.InvalidateCache()
+			defer p.InvalidateCache()
+			return call()
+		}
+		files := policy.Files(arguments)
+		p.InvalidateFiles(files...)
+		defer p.InvalidateFiles(files...)
+		return call()
+	}
+
+	key := cacheKey(name, arguments)
+	p.mu.RLock()
+	hit, ok := p.cache[key]
+	p.mu.RUnlock()
+	if ok && time.Now().Before(hit.expires) {
+		log.Debug().Str("tool", name).Msg("tool result served from cache")
+		if policy.Hit != nil {
+			policy.Hit(arguments, hit.result)
+		}
+		return hit.result.clone(), nil
+	}
+
+	result, err := call()
+	if err == nil && result != nil && !result.IsError && ctx.Err() == nil {
+		var files []string
+		if policy.Files != nil {
+			files = policy.Files(arguments)
+		}
+		p.mu.Lock()
+		p.cache[key] = cachedResult{result: result.clone(), files: files, expires: time.Now().Add(p.cacheTTL)}
+		p.mu.Unlock()
+	}
+	return result, err
+}
+
+// SetOffline blocks all upstream traffic. Upstream tools are no longer
+// listed and calls to them return an "offline mode" tool error.
+func (p *Proxy) SetOffline(offline bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.offline = offline
+}
+
+// Offline reports whether upstream traffic is blocked.
+func (p *Proxy) Offline() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.offline
+}
+
 // ListTools returns all available tools (local + upstream).
 func (p *Proxy) ListTools(ctx context.Context) ([]Tool, error) {
 	p.mu.RLock()
@@ -94,7 +270,7 @@ func (p *Proxy) ListTools(ctx context.Context) ([]Tool, error) {
 	}
 
 	// Add upstream tools if available
-	if p.upstream != nil {
+	if p.upstream != nil && !p.offline {
 		upstreamTools, err := p.upstream.ListTools(ctx)
 		if err != nil {
 			log.Warn().
@@ -108,15 +284,47 @@ func (p *Proxy) ListTools(ctx context.Context) ([]Tool, error) {
 	return tools, nil
 }
 
-// CallTool invokes a tool, checking local handlers first then upstream.
+// CallTool invokes a tool, checking local handlers first then upstream, and
+// caps its result per SetResultTokens.
 func (p *Proxy) CallTool(ctx context.Context, name string, arguments json.RawMessage) (*ToolResult, error) {
+	result, err := p.callTool(ctx, name, arguments)
+	p.mu.RLock()
+	limit := p.resultTokens[name]
+	p.mu.RUnlock()
+	if err != nil || result == nil || limit <= 0 {
+		return result, err
+	}
+	return capResult(result, limit), nil
+}
+
+func (p *Proxy) callTool(ctx context.Context, name string, arguments json.RawMessage) (*ToolResult, error) {
 	p.mu.RLock()
 	handler, isLocal := p.localHandlers[name]
+	tool := p.localTools[name]
+	offline := p.offline
 	p.mu.RUnlock()
 
 	// Try local handler first
 	if isLocal {
-		return handler(ctx, arguments)
+		// Check the arguments against the schema so a model that ignores
+		// it learns exactly what to fix, rather than failing cryptically.
+		if problems := validateArguments(tool.InputSchema, arguments); len(problems) > 0 {
+			return schemaError(tool, problems), nil
+		}
+		return p.cachedCall(ctx, name, arguments, func() (*ToolResult, error) {
+			if tool.Retryable {
+				return p.callWithRetry(ctx, name, func() (*ToolResult, error) { return handler(ctx, arguments) })
+			}
+			return handler(ctx, arguments)
+		})
+	}
+
+	if offline {
+		errorMsg := fmt.Sprintf("offline mode: %s needs network access and is disabled", name)
+		return &ToolResult{
+			Content: []ContentBlock{{Type: "text", Text: errorMsg}},
+			IsError: true,
+		}, nil
 	}
 
 	// Fall back to upstream
@@ -128,7 +336,11 @@ func (p *Proxy) CallTool(ctx context.Context, name string, arguments json.RawMes
 			}
 		}
 
-		return p.callUpstreamWithRetry(ctx, name, args)
+		return p.cachedCall(ctx, name, arguments, func() (*ToolResult, error) {
+			return p.callWithRetry(ctx, name, func() (*ToolResult, error) {
+				return p.upstream.CallTool(ctx, name, args)
+			})
+		})
 	}
 
 	errorMsg := fmt.Sprintf("tool not found: %s", name)
@@ -138,11 +350,67 @@ func (p *Proxy) CallTool(ctx context.Context, name string, arguments json.RawMes
 	}, nil
 }
 
-func (p *Proxy) callUpstreamWithRetry(ctx context.Context, name string, args interface{}) (*ToolResult, error) {
+// charsPerToken approximates the characters in a token, to turn a token cap
+// into a length without a tokenizer.
+const charsPerToken = 4
+
+// capResult returns result with its text cut to about maxTokens tokens at a
+// line boundary, plus a note, or result itself if it fits. result is never
+// modified.
+func capResult(result *ToolResult, maxTokens int) *ToolResult {
+	var text strings.Builder
+	var other []ContentBlock
+	for _, b := range result.Content {
+		if b.Type == "text" {
+			text.WriteString(b.Text)
+		} else {
+			other = append(other, b)
+		}
+	}
+	s := text.String()
+	maxChars := maxTokens * charsPerToken
+	total := utf8.RuneCountInString(s)
+	if total <= maxChars {
+		return result
+	}
+
+	var kept strings.Builder
+	n, lines := 0, 0
+	for _, line := range strings.SplitAfter(s, "\n") {
+		size := utf8.RuneCountInString(line)
+		if n+size > maxChars {
+			break
+		}
+		kept.WriteString(line)
+		n += size
+		lines++
+	}
+	if lines == 0 {
+		// The first line alone is too long: cut inside it.
+		kept.WriteString(string([]rune(s)[:maxChars]))
+	}
+	allLines := strings.Count(strings.TrimSuffix(s, "\n"), "\n") + 1
+	note := fmt.Sprintf("\n[Result truncated to about %d tokens: showing %d of %d lines (about %d tokens in all). "+
+		"Narrow the call: a smaller line range for file content, or a more specific pattern or path for searches.]",
+		maxTokens, lines, allLines, total/charsPerToken)
+
+	capped := *result
+	capped.Content = append([]ContentBlock{{Type: "text", Text: strings.TrimRight(kept.String(), "\n") + "\n" + note}}, other...)
+	return &capped
+}
+
+// callWithRetry runs call, retrying with backoff while it fails with a
+// transient error: network trouble, a timeout or a rate limit. Tool errors
+// are results, never retried.
+func (p *Proxy) callWithRetry(ctx context.Context, name string, call func() (*ToolResult, error)) (*ToolResult, error) {
+	p.mu.RLock()
+	retries := p.retries
+	p.mu.RUnlock()
+
 	var lastErr error
-	for attempt := 0; attempt <= len(toolRetryDelays); attempt++ {
+	for attempt := 0; attempt <= retries; attempt++ {
 		if attempt > 0 {
-			delay := toolRetryDelays[attempt-1]
+			delay := toolRetryDelays[min(attempt, len(toolRetryDelays))-1]
 
 			// Check if error is a 429 rate limit
 			is429 := lastErr != nil && (strings.Contains(lastErr.Error(), "429") || strings.Contains(lastErr.Error(), "Rate limited"))
@@ -174,6 +442,7 @@ func (p *Proxy) callUpstreamWithRetry(ctx context.Context, name string, args int
 					Str("tool", name).
 					Int("attempt", attempt).
 					Dur("delay", delay).
+					Err(lastErr).
 					Msg("Retrying MCP tool call after error")
 			}
 
@@ -184,7 +453,7 @@ func (p *Proxy) callUpstreamWithRetry(ctx context.Context, name string, args int
 			}
 		}
 
-		result, err := p.upstream.CallTool(ctx, name, args)
+		result, err := call()
 		if err == nil {
 			// Log successful call at Info level for visibility
 			if attempt > 0 {
@@ -196,32 +465,50 @@ func (p *Proxy) callUpstreamWithRetry(ctx context.Context, name string, args int
 			return result, nil
 		}
 
-		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil || !transientError(err) {
 			return nil, err
 		}
-
 		lastErr = err
 	}
 
 	// Log final failure with more context
 	log.Error().
 		Str("tool", name).
-		Int("total_attempts", len(toolRetryDelays)+1).
+		Int("total_attempts", retries+1).
 		Err(lastErr).
 		Msg("MCP tool call failed after all retries")
 
 	return nil, fmt.Errorf("%w: %v", ErrToolRetryExhausted, lastErr)
 }
 
+// transientStatusRe matches the client's error for an HTTP status worth
+// retrying.
+var transientStatusRe = regexp.MustCompile(`http error (429|502|503|504)\b`)
+
+// transientError reports whether err is a failure a retry may fix: a
+// timeout, a failed or dropped connection, or a rate-limit or gateway
+// HTTP status.
+func transientError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	return transientStatusRe.MatchString(err.Error())
+}
+
 // Initialize initializes the upstream connection if available.
 func (p *Proxy) Initialize(ctx context.Context) error {
-	if p.upstream == nil {
+	if p.upstream == nil || p.Offline() {
 		return nil
 	}
 
 	clientInfo := map[string]interface{}{
 		"name":    "symb",
-		"version": "0.1.0",
+		"version": Version,
 	}
 
 	resp, err := p.upstream.Initialize(ctx, clientInfo)
@@ -241,10 +528,14 @@ func (p *Proxy) HasUpstream() bool {
 	return p.upstream != nil
 }
 
-// Upstream returns the upstream client, or nil if none is configured.
+// Upstream returns the upstream client, or nil if none is configured or
+// the proxy is offline.
 func (p *Proxy) Upstream() UpstreamClient {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
+	if p.offline {
+		return nil
+	}
 	return p.upstream
 }
 
@@ -0,0 +1,168 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// countingTool registers name on p with a handler that counts its calls.
+func countingTool(p *Proxy, name string) *int {
+	calls := new(int)
+	p.RegisterTool(Tool{Name: name, InputSchema: json.RawMessage(`{"type":"object"}`)},
+		func(context.Context, json.RawMessage) (*ToolResult, error) {
+			*calls++
+			return &ToolResult{Content: []ContentBlock{{Type: "text", Text: "result"}}}, nil
+		})
+	return calls
+}
+
+func TestProxyCache(t *testing.T) {
+	p := NewProxy(nil)
+	reads := countingTool(p, "Read")
+	greps := countingTool(p, "Grep")
+	countingTool(p, "Edit")
+	countingTool(p, "Shell")
+	countingTool(p, "Outline")
+	var hits int
+	fileArg := func(args json.RawMessage) []string {
+		var a struct{ File string }
+		_ = json.Unmarshal(args, &a)
+		return []string{a.File}
+	}
+	p.EnableCache(time.Minute, map[string]CachePolicy{
+		"Read":    {Cache: true, Files: fileArg, Hit: func(json.RawMessage, *ToolResult) { hits++ }},
+		"Grep":    {Cache: true},
+		"Edit":    {Files: fileArg},
+		"Outline": {Files: NoFiles},
+	})
+
+	ctx := context.Background()
+	call := func(name, args string) *ToolResult {
+		t.Helper()
+		res, err := p.CallTool(ctx, name, json.RawMessage(args))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	a, b := `{"file":"/a"}`, `{"file":"/b"}`
+
+	first := call("Read", a)
+	first.Content[0].Text = "changed by the caller"
+	if got := call("Read", a); *reads != 1 || hits != 1 || got.Content[0].Text != "result" {
+		t.Fatalf("hit: reads=%d hits=%d text=%q", *reads, hits, got.Content[0].Text)
+	}
+	call("Read", b)
+	call("Grep", `{}`)
+
+	// An Edit of /a drops /a and Grep, which may depend on any file.
+	call("Edit", a)
+	call("Read", a)
+	call("Read", b)
+	call("Grep", `{}`)
+	if *reads != 3 || *greps != 2 {
+		t.Errorf("after Edit: reads=%d greps=%d, want 3 and 2", *reads, *greps)
+	}
+
+	// A read-only tool keeps everything; a tool without a policy clears it.
+	call("Outline", `{}`)
+	call("Read", b)
+	if *reads != 3 {
+		t.Errorf("Outline invalidated the cache: reads=%d", *reads)
+	}
+	call("Shell", `{}`)
+	call("Read", b)
+	if *reads != 4 {
+		t.Errorf("Shell kept the cache: reads=%d", *reads)
+	}
+
+	p.InvalidateFiles("/b")
+	call("Read", b)
+	call("Read", a)
+	if *reads != 6 {
+		t.Errorf("InvalidateFiles: reads=%d, want 6", *reads)
+	}
+}
+
+func TestProxyCacheExpires(t *testing.T) {
+	p := NewProxy(nil)
+	reads := countingTool(p, "Read")
+	p.EnableCache(time.Millisecond, map[string]CachePolicy{"Read": {Cache: true}})
+
+	ctx := context.Background()
+	for range 2 {
+		if _, err := p.CallTool(ctx, "Read", json.RawMessage(`{}`)); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if *reads != 2 {
+		t.Errorf("reads = %d, want an expired result to be re-run", *reads)
+	}
+}
+
+func TestTransientError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, true},
+		{fmt.Errorf("http request: %w", &net.DNSError{IsTimeout: true}), true},
+		{fmt.Errorf("read SSE stream: %w", io.ErrUnexpectedEOF), true},
+		{errors.New("http error 429: slow down (Retry-After: 5)"), true},
+		{errors.New("http error 503: unavailable"), true},
+		{errors.New("http error 404: service unavailable"), false},
+		{errors.New("mcp error -32602: timeout must be a number"), false},
+		{errors.New("file not found"), false},
+	}
+	for _, tt := range tests {
+		if got := transientError(tt.err); got != tt.want {
+			t.Errorf("transientError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+// TestCallWithRetry verifies only Retryable tools are retried, only on a
+// transient error, and never on a tool error whatever its text.
+func TestCallWithRetry(t *testing.T) {
+	defer func(d []time.Duration) { toolRetryDelays = d }(toolRetryDelays)
+	toolRetryDelays = []time.Duration{time.Millisecond}
+
+	refused := &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}
+	tests := []struct {
+		name      string
+		retryable bool
+		fail      error       // returned by the first call
+		result    *ToolResult // returned by the first call when fail is nil
+		wantCalls int
+		wantErr   bool
+	}{
+		{"transient retried", true, refused, nil, 2, false},
+		{"not retryable", false, refused, nil, 1, true},
+		{"permanent error", true, errors.New("bad arguments"), nil, 1, true},
+		{"tool error", true, nil, &ToolResult{Content: []ContentBlock{{Type: "text", Text: "timeout: no such file"}}, IsError: true}, 1, false},
+	}
+	for _, tt := range tests {
+		p := NewProxy(nil)
+		calls := 0
+		p.RegisterTool(Tool{Name: "T", InputSchema: json.RawMessage(`{"type":"object"}`), Retryable: tt.retryable},
+			func(context.Context, json.RawMessage) (*ToolResult, error) {
+				calls++
+				if calls == 1 {
+					return tt.result, tt.fail
+				}
+				return &ToolResult{Content: []ContentBlock{{Type: "text", Text: "ok"}}}, nil
+			})
+		_, err := p.CallTool(context.Background(), "T", json.RawMessage(`{}`))
+		if calls != tt.wantCalls || (err != nil) != tt.wantErr {
+			t.Errorf("%s: calls = %d, err = %v", tt.name, calls, err)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// schema is the subset of JSON Schema that tool input schemas use.
+type schema struct {
+	Type       string             `json:"type"`
+	Properties map[string]*schema `json:"properties"`
+	Required   []string           `json:"required"`
+	Enum       []any              `json:"enum"`
+	Items      *schema            `json:"items"`
+}
+
+// validateArguments checks arguments against a tool's input schema and
+// returns what is wrong with them, one problem per entry: missing required
+// fields, values of the wrong type or outside an enum, and unknown fields
+// that look like a misspelling of a known one. Other unknown fields are
+// ignored, as the handlers ignore them. A schema it cannot parse accepts
+// anything.
+func validateArguments(inputSchema, arguments json.RawMessage) []string {
+	var s schema
+	if len(inputSchema) == 0 || json.Unmarshal(inputSchema, &s) != nil || s.Type != "object" {
+		return nil
+	}
+	if len(bytes.TrimSpace(arguments)) == 0 || string(bytes.TrimSpace(arguments)) == "null" {
+		arguments = json.RawMessage("{}")
+	}
+	var args map[string]any
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return []string{"arguments must be a JSON object"}
+	}
+
+	var problems []string
+	for _, name := range s.Required {
+		if v, ok := args[name]; !ok || v == nil {
+			problems = append(problems, fmt.Sprintf("missing required field %q (%s)", name, s.Properties[name].describe()))
+		}
+	}
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		prop, known := s.Properties[name]
+		if !known {
+			if guess := closestField(name, s.Properties); guess != "" {
+				problems = append(problems, fmt.Sprintf("unknown field %q; did you mean %q?", name, guess))
+			}
+			continue
+		}
+		if args[name] == nil {
+			continue
+		}
+		if problem := prop.check(args[name]); problem != "" {
+			problems = append(problems, fmt.Sprintf("field %q %s", name, problem))
+		}
+	}
+	return problems
+}
+
+// check returns what is wrong with v, or "".
+func (s *schema) check(v any) string {
+	if s == nil {
+		return ""
+	}
+	if !s.typeMatches(v) {
+		return fmt.Sprintf("must be %s, got %s", article(s.Type), jsonType(v))
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, v) {
+		return fmt.Sprintf("must be one of %s, got %s", enumList(s.Enum), compactJSON(v))
+	}
+	if s.Type == "array" && s.Items != nil {
+		for i, item := range v.([]any) {
+			if problem := s.Items.check(item); problem != "" {
+				return fmt.Sprintf("item %d %s", i, problem)
+			}
+		}
+	}
+	return ""
+}
+
+// inEnum reports whether v is one of the enum's values. Only scalars can
+// match: comparing slices or maps would panic.
+func inEnum(enum []any, v any) bool {
+	switch v.(type) {
+	case string, float64, bool:
+		return slices.Contains(enum, v)
+	}
+	return false
+}
+
+func (s *schema) typeMatches(v any) bool {
+	switch s.Type {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	}
+	return true // no type, or one this subset doesn't know
+}
+
+// describe summarizes the schema for a correction, e.g. `string: one of "a", "b"`.
+func (s *schema) describe() string {
+	if s == nil || s.Type == "" {
+		return "any type"
+	}
+	if len(s.Enum) > 0 {
+		return s.Type + ": one of " + enumList(s.Enum)
+	}
+	if s.Type == "array" && s.Items != nil && s.Items.Type != "" {
+		return "array of " + s.Items.Type
+	}
+	return s.Type
+}
+
+// signature lists a tool's fields for a correction, required ones first,
+// e.g. `file (string, required), start (string)`.
+func (s *schema) signature() string {
+	var required, optional []string
+	for name, prop := range s.Properties {
+		if slices.Contains(s.Required, name) {
+			required = append(required, fmt.Sprintf("%s (%s, required)", name, prop.describe()))
+		} else {
+			optional = append(optional, fmt.Sprintf("%s (%s)", name, prop.describe()))
+		}
+	}
+	slices.Sort(required)
+	slices.Sort(optional)
+	return strings.Join(append(required, optional...), ", ")
+}
+
+// schemaError is the result for a call whose arguments don't match the
+// tool's schema: the problems, then the fields the tool takes.
+func schemaError(tool Tool, problems []string) *ToolResult {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Invalid arguments for %s; the call was not run:\n", tool.Name)
+	for _, p := range problems {
+		b.WriteString("- " + p + "\n")
+	}
+	var s schema
+	if json.Unmarshal(tool.InputSchema, &s) == nil && len(s.Properties) > 0 {
+		fmt.Fprintf(&b, "%s takes: %s\n", tool.Name, s.signature())
+	}
+	b.WriteString("Fix the arguments and call it again.")
+	return &ToolResult{Content: []ContentBlock{{Type: "text", Text: b.String()}}, IsError: true}
+}
+
+// closestField returns the known field that name is most likely a
+// misspelling of, or "": one that differs only in case, underscores or
+// dashes, or by a few edits for its length.
+func closestField(name string, properties map[string]*schema) string {
+	norm := func(s string) string {
+		return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(s))
+	}
+	best, bestDist := "", 3
+	for known := range properties {
+		if norm(known) == norm(name) {
+			return known
+		}
+		d := editDistance(norm(name), norm(known))
+		if d > max(1, len(known)/3) {
+			continue // too different to be a typo
+		}
+		if d < bestDist || d == bestDist && known < best {
+			best, bestDist = known, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case string:
+		return "string " + compactJSON(v)
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer " + compactJSON(v)
+		}
+		return "number " + compactJSON(v)
+	case bool:
+		return "boolean " + compactJSON(v)
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "null"
+}
+
+func article(typ string) string {
+	switch typ {
+	case "integer", "array", "object":
+		return "an " + typ
+	}
+	return "a " + typ
+}
+
+func enumList(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = compactJSON(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// compactJSON renders v as JSON, shortened for a one-line message.
+func compactJSON(v any) string {
+	data, _ := json.Marshal(v)
+	s := string(data)
+	if len(s) > 40 {
+		s = s[:37] + "..."
+	}
+	return s
+}
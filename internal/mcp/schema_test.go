@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestValidateArguments(t *testing.T) {
+	schema := json.RawMessage(`{"type":"object","required":["file"],"properties":{
+		"file":{"type":"string"},
+		"start":{"type":"integer"},
+		"mode":{"type":"string","enum":["a","b"]},
+		"tags":{"type":"array","items":{"type":"string"}}}}`)
+	tests := []struct {
+		name   string
+		schema json.RawMessage
+		args   string
+		want   []string
+	}{
+		{"valid", schema, `{"file":"a.go","start":3,"mode":"b","tags":["x"]}`, nil},
+		{"no arguments", schema, ``, []string{`missing required field "file" (string)`}},
+		{"null arguments", schema, `null`, []string{`missing required field "file" (string)`}},
+		{"null required field", schema, `{"file":null}`, []string{`missing required field "file" (string)`}},
+		{"not an object", schema, `[1]`, []string{"arguments must be a JSON object"}},
+		{"not an integer", schema, `{"file":"a.go","start":1.5}`, []string{`field "start" must be an integer, got number 1.5`}},
+		{"outside enum", schema, `{"file":"a.go","mode":"c"}`, []string{`field "mode" must be one of "a", "b", got "c"`}},
+		{"bad item", schema, `{"file":"a.go","tags":["x",1]}`, []string{`field "tags" item 1 must be a string, got integer 1`}},
+		{"misspelled field", schema, `{"File":"a.go","strt":1}`, []string{
+			`missing required field "file" (string)`,
+			`unknown field "File"; did you mean "file"?`,
+			`unknown field "strt"; did you mean "start"?`,
+		}},
+		{"unrelated field ignored", schema, `{"file":"a.go","verbose":true}`, nil},
+		{"unparsable schema", json.RawMessage(`{`), `{"x":1}`, nil},
+		{"not an object schema", json.RawMessage(`{"type":"string"}`), `1`, nil},
+	}
+	for _, tt := range tests {
+		if got := validateArguments(tt.schema, json.RawMessage(tt.args)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: validateArguments(%s) = %q, want %q", tt.name, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestClosestField(t *testing.T) {
+	properties := map[string]*schema{"file_path": nil, "start_line": nil, "mode": nil, "pattern": nil, "cat": nil, "car": nil}
+	tests := []struct{ name, want string }{
+		{"FilePath", "file_path"},
+		{"file-path", "file_path"},
+		{"start_lin", "start_line"},
+		{"mod", "mode"},
+		{"patern", "pattern"},
+		{"cax", "car"}, // a tie goes to the first name in order
+		{"xyz", ""},
+		{"mdoe", ""}, // two edits is too many for a short name
+	}
+	for _, tt := range tests {
+		if got := closestField(tt.name, properties); got != tt.want {
+			t.Errorf("closestField(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTypeMatches(t *testing.T) {
+	tests := []struct {
+		typ  string
+		v    any
+		want bool
+	}{
+		{"string", "a", true},
+		{"string", 1.0, false},
+		{"integer", 2.0, true},
+		{"integer", 2.5, false},
+		{"number", 2.5, true},
+		{"number", "2.5", false},
+		{"boolean", true, true},
+		{"boolean", "true", false},
+		{"array", []any{}, true},
+		{"array", map[string]any{}, false},
+		{"object", map[string]any{}, true},
+		{"object", []any{}, false},
+		{"", nil, true},
+		{"null", 1.0, true},
+	}
+	for _, tt := range tests {
+		if got := (&schema{Type: tt.typ}).typeMatches(tt.v); got != tt.want {
+			t.Errorf("%q.typeMatches(%#v) = %v, want %v", tt.typ, tt.v, got, tt.want)
+		}
+	}
+}
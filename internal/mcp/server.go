@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/rs/zerolog/log"
+)
+
+// supportedProtocolVersions lists the MCP versions Serve speaks, newest
+// first. A client asking for another version is offered the newest.
+var supportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// negotiateProtocolVersion answers a client's initialize: the requested
+// version when supported, the newest supported one otherwise.
+func negotiateProtocolVersion(requested string) string {
+	if slices.Contains(supportedProtocolVersions, requested) {
+		return requested
+	}
+	return supportedProtocolVersions[0]
+}
+
+// Serve runs the proxy as an MCP server over the stdio transport: one
+// JSON-RPC message per line on r, responses written to w. Requests are
+// handled in order. It returns nil when r reaches EOF or ctx is done;
+// reads happen in a goroutine so a blocked read doesn't hold up shutdown.
+func (p *Proxy) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		in := bufio.NewReader(r)
+		for {
+			line, err := in.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("read request: %w", err)
+		case line := <-lines:
+			if resp := p.handleRequest(ctx, line); resp != nil {
+				if err := enc.Encode(resp); err != nil {
+					return fmt.Errorf("write response: %w", err)
+				}
+			}
+		}
+	}
+}
+
+// handleRequest dispatches one message. Notifications (no ID) get no
+// response, so it returns nil for them.
+func (p *Proxy) handleRequest(ctx context.Context, line []byte) *Response {
+	var req Request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return NewErrorResponse(nil, ErrorCodeParseError, "parse error: "+err.Error())
+	}
+	if req.ID == nil {
+		log.Debug().Str("method", req.Method).Msg("MCP notification")
+		return nil
+	}
+
+	var result interface{}
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+		result = map[string]interface{}{
+			"protocolVersion": negotiateProtocolVersion(params.ProtocolVersion),
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]interface{}{"name": "symb", "version": Version},
+		}
+	case "ping":
+		result = map[string]interface{}{}
+	case "tools/list":
+		tools, err := p.ListTools(ctx)
+		if err != nil {
+			return NewErrorResponse(req.ID, ErrorCodeInternalError, err.Error())
+		}
+		result = ListToolsResult{Tools: tools}
+	case "tools/call":
+		var params CallToolParams
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.Name == "" {
+			return NewErrorResponse(req.ID, ErrorCodeInvalidParams, "tools/call needs a tool name")
+		}
+		res, err := p.CallTool(ctx, params.Name, params.Arguments)
+		if err != nil {
+			return NewErrorResponse(req.ID, ErrorCodeInternalError, err.Error())
+		}
+		result = res
+	default:
+		return NewErrorResponse(req.ID, ErrorCodeMethodNotFound, "method not found: "+req.Method)
+	}
+
+	resp, err := NewResponse(req.ID, result)
+	if err != nil {
+		return NewErrorResponse(req.ID, ErrorCodeInternalError, err.Error())
+	}
+	return resp
+}
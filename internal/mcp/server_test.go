@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newEchoProxy() *Proxy {
+	p := NewProxy(nil)
+	p.RegisterTool(Tool{
+		Name:        "Echo",
+		Description: "echoes text",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"text":{"type":"string"}},"required":["text"]}`),
+	}, func(_ context.Context, args json.RawMessage) (*ToolResult, error) {
+		var a struct{ Text string }
+		if err := json.Unmarshal(args, &a); err != nil {
+			return nil, err
+		}
+		return &ToolResult{Content: []ContentBlock{{Type: "text", Text: a.Text}}}, nil
+	})
+	return p
+}
+
+// TestServeRoundTrip drives initialize, tools/list and tools/call through
+// Serve and checks each response.
+func TestServeRoundTrip(t *testing.T) {
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"Echo","arguments":{"text":"hi"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"nope"}`,
+	}, "\n") + "\n"
+	var out strings.Builder
+	if err := newEchoProxy().Serve(context.Background(), strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(out.String()))
+	next := func(result any) *Error {
+		t.Helper()
+		var resp Response
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if resp.Error == nil && result != nil {
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return resp.Error
+	}
+
+	var init struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		ServerInfo      struct{ Name string }
+	}
+	if err := next(&init); err != nil || init.ProtocolVersion != "2025-03-26" || init.ServerInfo.Name != "symb" {
+		t.Errorf("initialize = %+v, %v", init, err)
+	}
+	var list ListToolsResult
+	if err := next(&list); err != nil || len(list.Tools) != 1 || list.Tools[0].Name != "Echo" {
+		t.Errorf("tools/list = %+v, %v", list, err)
+	}
+	var call ToolResult
+	if err := next(&call); err != nil || call.IsError || len(call.Content) != 1 || call.Content[0].Text != "hi" {
+		t.Errorf("tools/call = %+v, %v", call, err)
+	}
+	if err := next(nil); err == nil || err.Code != ErrorCodeMethodNotFound {
+		t.Errorf("unknown method error = %+v", err)
+	}
+	if dec.More() {
+		t.Error("notification got a response")
+	}
+}
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	for requested, want := range map[string]string{
+		"2024-11-05": "2024-11-05",
+		"2025-06-18": "2025-06-18",
+		"1999-01-01": supportedProtocolVersions[0],
+		"":           supportedProtocolVersions[0],
+	} {
+		if got := negotiateProtocolVersion(requested); got != want {
+			t.Errorf("negotiateProtocolVersion(%q) = %q, want %q", requested, got, want)
+		}
+	}
+}
+
+// TestServeStopsOnCancel verifies Serve returns once ctx is done even
+// while a read is blocked.
+func TestServeStopsOnCancel(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	var out strings.Builder
+	done := make(chan error, 1)
+	go func() { done <- newEchoProxy().Serve(ctx, r, &out) }()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve = %v, want nil on cancel", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Serve still blocked after cancel")
+	}
+}
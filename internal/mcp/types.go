@@ -4,8 +4,12 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"slices"
 )
 
+// Version is the symb version reported to MCP peers and in User-Agent.
+const Version = "0.1.0"
+
 // Request represents an MCP request.
 type Request struct {
 	JSONRPC string          `json:"jsonrpc"`
@@ -33,6 +37,10 @@ type Tool struct {
 	Name        string          `json:"name"`
 	Description string          `json:"description"`
 	InputSchema json.RawMessage `json:"inputSchema"`
+	// Retryable lets the proxy retry a call that failed transiently. Only
+	// set it on read-only tools: a call that may have changed something
+	// must never run twice. Upstream tools are always retried.
+	Retryable bool `json:"-"`
 }
 
 // ToolCall represents a tool invocation.
@@ -41,12 +49,32 @@ type ToolCall struct {
 	Arguments json.RawMessage `json:"arguments"`
 }
 
+type pendingCallsKey struct{}
+
+// WithPendingCalls returns a context telling a tool handler which calls are
+// queued after it in the same batch, so it can defer work a later call redoes.
+func WithPendingCalls(ctx context.Context, calls []ToolCall) context.Context {
+	return context.WithValue(ctx, pendingCallsKey{}, calls)
+}
+
+// PendingCalls returns the calls queued after the current one, if any.
+func PendingCalls(ctx context.Context) []ToolCall {
+	calls, _ := ctx.Value(pendingCallsKey{}).([]ToolCall)
+	return calls
+}
+
 // ToolResult represents the result of a tool call.
 type ToolResult struct {
 	Content []ContentBlock `json:"content"`
 	IsError bool           `json:"isError,omitempty"`
 }
 
+// clone returns a copy of r whose content can be changed without
+// affecting r.
+func (r *ToolResult) clone() *ToolResult {
+	return &ToolResult{Content: slices.Clone(r.Content), IsError: r.IsError}
+}
+
 // ContentBlock represents a content block in tool results.
 type ContentBlock struct {
 	Type string `json:"type"`
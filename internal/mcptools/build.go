@@ -0,0 +1,305 @@
+package mcptools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xonecas/symb/internal/mcp"
+	"github.com/xonecas/symb/internal/shell"
+)
+
+const (
+	defaultBuildTimeout = 300 // seconds
+	buildTailLines      = 30  // raw output lines shown
+	maxBuildDiagnostics = 50
+)
+
+// BuildLanguage is how the Build tool builds one language: the command to
+// run, the marker file that selects it, and a regex matching one compiler
+// error with named groups file, line, and optionally col, severity and
+// message.
+type BuildLanguage struct {
+	Marker     string
+	Command    string
+	ErrorRegex string
+}
+
+// builtinBuildLanguages are tried in this order when no language is given.
+var builtinBuildLanguages = []string{"go", "rust", "typescript"}
+
+var defaultBuildLanguages = map[string]BuildLanguage{
+	"go": {
+		Marker:     "go.mod",
+		Command:    "go build ./... && go vet ./...",
+		ErrorRegex: `^(?P<file>[^\s:]+\.go):(?P<line>\d+):(?:(?P<col>\d+):)? (?P<message>.+)$`,
+	},
+	"rust": {
+		Marker:     "Cargo.toml",
+		Command:    "cargo build --message-format short",
+		ErrorRegex: `^(?P<file>[^\s:]+\.rs):(?P<line>\d+):(?P<col>\d+): (?P<severity>error|warning)(?:\[\w+\])?: (?P<message>.+)$`,
+	},
+	"typescript": {
+		Marker:     "tsconfig.json",
+		Command:    "npx tsc --noEmit --pretty false",
+		ErrorRegex: `^(?P<file>[^\s(]+)\((?P<line>\d+),(?P<col>\d+)\): (?P<severity>error|warning) (?P<message>.+)$`,
+	},
+}
+
+// BuildArgs are the arguments to the Build tool.
+type BuildArgs struct {
+	Language string `json:"language,omitempty"`
+	Args     string `json:"args,omitempty"`    // appended to the build command
+	Timeout  int    `json:"timeout,omitempty"` // seconds
+}
+
+// NewBuildTool creates the Build tool definition.
+func NewBuildTool() mcp.Tool {
+	return mcp.Tool{
+		Name: "Build",
+		Description: `Build (compile and vet) the project and get compiler errors as file:line:col: message entries, with the exit status and the tail of the raw output.
+The command is configured per language (built in: go, rust, typescript), picked from the project's marker file unless language is given.
+Use it after edits for a fast check that doesn't depend on an LSP server.`,
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"language": {"type": "string", "description": "Language whose build command to run, e.g. go. Default: detected from the project"},
+				"args":     {"type": "string", "description": "Extra arguments appended to the build command"},
+				"timeout":  {"type": "integer", "description": "Timeout in seconds (default 300)"}
+			}
+		}`),
+	}
+}
+
+// BuildHandler handles Build tool calls.
+type BuildHandler struct {
+	sh        *shell.Shell
+	languages map[string]BuildLanguage
+	order     []string // detection order: configured languages, then built-ins
+	dryRun    bool
+}
+
+// NewBuildHandler creates a handler for the Build tool with the built-in
+// languages.
+func NewBuildHandler(sh *shell.Shell) *BuildHandler {
+	h := &BuildHandler{sh: sh}
+	h.SetLanguages(nil)
+	return h
+}
+
+// SetLanguages adds or overrides languages by name. Empty fields keep the
+// built-in value.
+func (h *BuildHandler) SetLanguages(overrides map[string]BuildLanguage) {
+	h.languages = make(map[string]BuildLanguage, len(defaultBuildLanguages)+len(overrides))
+	for name, lang := range defaultBuildLanguages {
+		h.languages[name] = lang
+	}
+	var custom []string
+	for name, o := range overrides {
+		lang := h.languages[name]
+		if o.Marker != "" {
+			lang.Marker = o.Marker
+		}
+		if o.Command != "" {
+			lang.Command = o.Command
+		}
+		if o.ErrorRegex != "" {
+			lang.ErrorRegex = o.ErrorRegex
+		}
+		h.languages[name] = lang
+		if !slices.Contains(builtinBuildLanguages, name) {
+			custom = append(custom, name)
+		}
+	}
+	slices.Sort(custom)
+	h.order = append(custom, builtinBuildLanguages...)
+}
+
+// SetDryRun makes Handle report the command instead of running it.
+func (h *BuildHandler) SetDryRun(on bool) { h.dryRun = on }
+
+// Handle implements the mcp.ToolHandler interface.
+func (h *BuildHandler) Handle(ctx context.Context, arguments json.RawMessage) (*mcp.ToolResult, error) {
+	var args BuildArgs
+	if len(arguments) > 0 {
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return toolError("Invalid arguments: %v", err), nil
+		}
+	}
+	name := args.Language
+	if name == "" {
+		name = h.detect()
+		if name == "" {
+			return toolError("No build marker found (%s); pass language or configure [build.<language>] in config.toml", h.markers()), nil
+		}
+	}
+	lang, ok := h.languages[name]
+	if !ok || lang.Command == "" {
+		return toolError("No build command for language %q; configure [build.%s] in config.toml", name, name), nil
+	}
+	errRe, err := regexp.Compile(lang.ErrorRegex)
+	if err != nil {
+		return toolError("Invalid error_regex for %s: %v", name, err), nil
+	}
+	command := lang.Command
+	if args.Args != "" {
+		command += " " + args.Args
+	}
+	if h.dryRun {
+		return toolText(fmt.Sprintf("(dry run) Would run:\n$ %s\nNothing was executed; no output is available.\n", command)), nil
+	}
+
+	timeout := defaultBuildTimeout
+	if args.Timeout > 0 {
+		timeout = min(args.Timeout, maxTimeoutSec)
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	var out bytes.Buffer
+	execErr := h.sh.ExecStream(ctx, command, &out, &out)
+	exitCode := shell.ExitCode(execErr)
+	timedOut := ctx.Err() != nil
+	text := formatBuildResult(command, out.String(), errRe, exitCode, timedOut)
+	if exitCode != 0 || timedOut {
+		return &mcp.ToolResult{
+			Content: []mcp.ContentBlock{{Type: "text", Text: text}},
+			IsError: true,
+		}, nil
+	}
+	return toolText(text), nil
+}
+
+// detect returns the first language whose marker file is in the working
+// directory, or "".
+func (h *BuildHandler) detect() string {
+	dir := h.sh.Dir()
+	for _, name := range h.order {
+		if marker := h.languages[name].Marker; marker != "" {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// markers lists the marker files detect looks for.
+func (h *BuildHandler) markers() string {
+	var markers []string
+	for _, name := range h.order {
+		if marker := h.languages[name].Marker; marker != "" {
+			markers = append(markers, marker)
+		}
+	}
+	return strings.Join(markers, ", ")
+}
+
+// buildDiagnostic is one compiler error parsed from build output.
+type buildDiagnostic struct {
+	severity string // ERROR or WARNING, as in LSP diagnostics
+	file     string
+	line     string
+	col      string
+	message  string
+}
+
+// parseBuildOutput extracts the compiler errors errRe matches, in order
+// and without duplicates.
+func parseBuildOutput(lines []string, errRe *regexp.Regexp) []buildDiagnostic {
+	var diags []buildDiagnostic
+	seen := make(map[buildDiagnostic]bool)
+	for _, line := range lines {
+		m := errRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		group := func(name string) string {
+			if i := errRe.SubexpIndex(name); i > 0 {
+				return m[i]
+			}
+			return ""
+		}
+		d := buildDiagnostic{
+			severity: "ERROR",
+			file:     filepath.ToSlash(filepath.Clean(group("file"))),
+			line:     group("line"),
+			col:      group("col"),
+			message:  strings.TrimSpace(group("message")),
+		}
+		if d.file == "." || d.line == "" {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(group("severity")), "warn") {
+			d.severity = "WARNING"
+		}
+		if !seen[d] {
+			seen[d] = true
+			diags = append(diags, d)
+		}
+	}
+	return diags
+}
+
+// formatBuildResult renders the exit status, the error count, the tail of
+// the raw output, and the parsed errors as a trailing "Build diagnostics:"
+// block (the TUI shows it like LSP diagnostics).
+func formatBuildResult(command, output string, errRe *regexp.Regexp, exitCode int, timedOut bool) string {
+	output = strings.TrimRight(output, "\n")
+	var lines []string
+	if output != "" {
+		lines = strings.Split(output, "\n")
+	}
+	diags := parseBuildOutput(lines, errRe)
+
+	var b strings.Builder
+	switch {
+	case timedOut:
+		fmt.Fprintf(&b, "TIMED OUT: %s\n", command)
+	case exitCode != 0:
+		fmt.Fprintf(&b, "FAIL (exit %d): %s\n", exitCode, command)
+	default:
+		fmt.Fprintf(&b, "OK: %s\n", command)
+	}
+	errors, warnings := 0, 0
+	for _, d := range diags {
+		if d.severity == "WARNING" {
+			warnings++
+		} else {
+			errors++
+		}
+	}
+	fmt.Fprintf(&b, "%d error(s), %d warning(s)\n", errors, warnings)
+
+	if len(lines) > 0 {
+		start := max(0, len(lines)-buildTailLines)
+		tail := strings.Join(lines[start:], "\n")
+		if len([]rune(tail)) > maxOutputChars {
+			tail = truncateMiddle(tail, maxOutputChars)
+		}
+		fmt.Fprintf(&b, "\nOutput (last %d of %d lines):\n%s\n", len(lines)-start, len(lines), tail)
+	}
+
+	if len(diags) > 0 {
+		b.WriteString("\nBuild diagnostics:\n")
+		for i, d := range diags {
+			if i == maxBuildDiagnostics {
+				fmt.Fprintf(&b, "... and %d more\n", len(diags)-i)
+				break
+			}
+			loc := d.file + ":" + d.line
+			if d.col != "" {
+				loc += ":" + d.col
+			}
+			fmt.Fprintf(&b, "%s %s: %s\n", d.severity, loc, d.message)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,93 @@
+package mcptools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/xonecas/symb/internal/shell"
+)
+
+func TestParseBuildOutput(t *testing.T) {
+	tests := []struct {
+		lang   string
+		output string
+		want   []string
+	}{
+		{
+			lang: "go",
+			output: "# example.com/a [example.com/a.test]\n./a/a.go:3:2: undefined: x\n" +
+				"a/b.go:10: missing return\n./a/a.go:3:2: undefined: x\n",
+			want: []string{"ERROR a/a.go:3:2: undefined: x", "ERROR a/b.go:10:: missing return"},
+		},
+		{
+			lang: "rust",
+			output: "   Compiling demo v0.1.0\n" +
+				"src/main.rs:2:5: error[E0425]: cannot find value `x` in this scope\n" +
+				"src/lib.rs:7:9: warning: unused variable: `y`\n",
+			want: []string{"ERROR src/main.rs:2:5: cannot find value `x` in this scope", "WARNING src/lib.rs:7:9: unused variable: `y`"},
+		},
+		{
+			lang:   "typescript",
+			output: "src/a.ts(3,5): error TS2304: Cannot find name 'x'.\n",
+			want:   []string{"ERROR src/a.ts:3:5: TS2304: Cannot find name 'x'."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			re := regexp.MustCompile(defaultBuildLanguages[tt.lang].ErrorRegex)
+			var got []string
+			for _, d := range parseBuildOutput(strings.Split(tt.output, "\n"), re) {
+				got = append(got, d.severity+" "+d.file+":"+d.line+":"+d.col+": "+d.message)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("diagnostics = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBuildTool verifies the language is picked by its marker file and a
+// failed build reports its status, output and diagnostics block.
+func TestBuildTool(t *testing.T) {
+	// The marker is looked up in the shell's directory, not the process's.
+	dir := t.TempDir()
+	h := NewBuildHandler(shell.New(dir, nil))
+	h.SetLanguages(map[string]BuildLanguage{
+		"fake": {
+			Marker:     "fake.mod",
+			Command:    `printf '%s\n' 'compiling' 'x.fk:4:2: bad token'; exit 2`,
+			ErrorRegex: `^(?P<file>\S+\.fk):(?P<line>\d+):(?P<col>\d+): (?P<message>.+)$`,
+		},
+	})
+
+	result, err := h.Handle(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "No build marker found") {
+		t.Errorf("no marker: %+v", result)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "fake.mod"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	result, err = h.Handle(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "FAIL (exit 2): printf"
+	text := result.Content[0].Text
+	if !result.IsError || !strings.HasPrefix(text, want) {
+		t.Errorf("result = %q, want an error starting %q", text, want)
+	}
+	for _, want := range []string{"1 error(s), 0 warning(s)", "Output (last 2 of 2 lines):\ncompiling\n", "\nBuild diagnostics:\nERROR x.fk:4:2: bad token"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("result missing %q:\n%s", want, text)
+		}
+	}
+}
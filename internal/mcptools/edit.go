@@ -4,12 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
-	"time"
+	"syscall"
 
 	"github.com/xonecas/symb/internal/delta"
+	"github.com/xonecas/symb/internal/fswatch"
 	"github.com/xonecas/symb/internal/hashline"
 	"github.com/xonecas/symb/internal/lsp"
 	"github.com/xonecas/symb/internal/mcp"
@@ -17,10 +21,11 @@ import (
 )
 
 const (
-	// windowThreshold is the minimum line count to trigger windowed output.
-	windowThreshold = 50
-	// windowContext is the number of lines shown above/below the edit region.
-	windowContext = 20
+	// defaultWindowThreshold is the line count above which Edit shows a
+	// window around the change instead of the whole file.
+	defaultWindowThreshold = 50
+	// defaultWindowContext is the number of lines shown above/below the edit region.
+	defaultWindowContext = 20
 )
 
 // editRegion describes which lines (1-indexed, inclusive) were affected in the new file.
@@ -37,6 +42,9 @@ type EditArgs struct {
 	End       string `json:"end,omitempty"`     // "line:hash" anchor
 	After     string `json:"after,omitempty"`   // "line:hash" anchor (insert)
 	Content   string `json:"content,omitempty"` // text content
+	// AllowConflictMarkers lets content contain merge-conflict markers,
+	// which are otherwise refused.
+	AllowConflictMarkers bool `json:"allow_conflict_markers,omitempty"`
 }
 
 // NewEditTool creates the Edit tool definition.
@@ -63,7 +71,8 @@ Operations:
 				"start":     {"type": "string", "description": "Start anchor as 'line:hash' (replace, delete)"},
 				"end":       {"type": "string", "description": "End anchor as 'line:hash' (replace, delete)"},
 				"after":     {"type": "string", "description": "Insert-after anchor as 'line:hash' (insert)"},
-				"content":   {"type": "string", "description": "Text content (replace, insert, create)"}
+				"content":   {"type": "string", "description": "Text content (replace, insert, create)"},
+				"allow_conflict_markers": {"type": "boolean", "description": "Set only when the content must contain merge-conflict markers (<<<<<<< / >>>>>>>) on purpose. Default: false"}
 			},
 			"required": ["file", "operation"]
 		}`),
@@ -77,11 +86,34 @@ type EditHandler struct {
 	tsIndex      *treesitter.Index
 	deltaTracker *delta.Tracker
 	rootDir      string
+	dryRun       bool
+	watcher      *fswatch.Watcher
+
+	windowThreshold int
+	windowContext   int
 }
 
 // NewEditHandler creates a handler for the Edit tool.
 func NewEditHandler(tracker *FileReadTracker, lspManager *lsp.Manager, dt *delta.Tracker) *EditHandler {
-	return &EditHandler{tracker: tracker, lspManager: lspManager, deltaTracker: dt}
+	return &EditHandler{
+		tracker:         tracker,
+		lspManager:      lspManager,
+		deltaTracker:    dt,
+		windowThreshold: defaultWindowThreshold,
+		windowContext:   defaultWindowContext,
+	}
+}
+
+// SetWindow sets the file size in lines above which an Edit result shows
+// only a window around the change, and how many lines of context that
+// window has on each side. Values <= 0 keep the defaults.
+func (h *EditHandler) SetWindow(threshold, contextLines int) {
+	if threshold > 0 {
+		h.windowThreshold = threshold
+	}
+	if contextLines > 0 {
+		h.windowContext = contextLines
+	}
 }
 
 // SetTSIndex sets the tree-sitter index for incremental updates on edit.
@@ -90,6 +122,35 @@ func (h *EditHandler) SetTSIndex(idx *treesitter.Index) { h.tsIndex = idx }
 // SetRootDir overrides the base directory for path validation.
 func (h *EditHandler) SetRootDir(root string) { h.rootDir = root }
 
+// SetDryRun makes Handle report the change it would make as a diff, without
+// writing the file or recording a delta.
+func (h *EditHandler) SetDryRun(on bool) { h.dryRun = on }
+
+// SetWatcher tells w about every write, so its own edits are not reported
+// as external changes.
+func (h *EditHandler) SetWatcher(w *fswatch.Watcher) { h.watcher = w }
+
+// Files returns the absolute path an Edit call may change, for the proxy's
+// result cache.
+func (h *EditHandler) Files(arguments json.RawMessage) []string {
+	var args EditArgs
+	if json.Unmarshal(arguments, &args) != nil || args.File == "" {
+		return nil
+	}
+	absPath, err := h.resolve(args.File)
+	if err != nil {
+		return []string{}
+	}
+	return []string{absPath}
+}
+
+func (h *EditHandler) resolve(file string) (string, error) {
+	if h.rootDir != "" {
+		return validatePathWithRoot(file, h.rootDir)
+	}
+	return validatePath(file)
+}
+
 // Handle implements the mcp.ToolHandler interface.
 func (h *EditHandler) Handle(ctx context.Context, arguments json.RawMessage) (*mcp.ToolResult, error) {
 	var args EditArgs
@@ -103,17 +164,17 @@ func (h *EditHandler) Handle(ctx context.Context, arguments json.RawMessage) (*m
 		return toolError("operation is required (replace, insert, delete, or create)"), nil
 	}
 
-	var absPath string
-	var err error
-	if h.rootDir != "" {
-		absPath, err = validatePathWithRoot(args.File, h.rootDir)
-	} else {
-		absPath, err = validatePath(args.File)
-	}
+	absPath, err := h.resolve(args.File)
 	if err != nil {
 		return toolError("%v", err), nil
 	}
 
+	if lines := conflictMarkerLines(args.Content); len(lines) > 0 && !args.AllowConflictMarkers {
+		return toolError("content contains merge-conflict markers (content lines %s); %s was not changed. "+
+			"Resolve the conflict in the content, or set allow_conflict_markers if the markers are intended.",
+			joinInts(lines), args.File), nil
+	}
+
 	if args.Operation == "create" {
 		return h.handleCreate(ctx, absPath, args.File, args.Content)
 	}
@@ -125,47 +186,91 @@ func (h *EditHandler) Handle(ctx context.Context, arguments json.RawMessage) (*m
 	return h.applyEdit(ctx, absPath, args)
 }
 
+// conflictMarkerRe matches the opening and closing lines of a merge
+// conflict. A bare "=======" is also a Markdown heading underline, so it
+// alone does not count.
+var conflictMarkerRe = regexp.MustCompile(`^(<{7}|>{7})( |$)`)
+
+// conflictMarkerLines returns the 1-indexed content lines that look like
+// merge-conflict markers.
+func conflictMarkerLines(content string) []int {
+	var lines []int
+	for i, line := range strings.Split(content, "\n") {
+		if conflictMarkerRe.MatchString(line) {
+			lines = append(lines, i+1)
+		}
+	}
+	return lines
+}
+
+// joinInts renders ints as a comma-separated list.
+func joinInts(ns []int) string {
+	parts := make([]string, len(ns))
+	for i, n := range ns {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ", ")
+}
+
 // applyEdit reads the file, applies the edit operation, writes it back, and returns fresh hashes.
 func (h *EditHandler) applyEdit(ctx context.Context, absPath string, args EditArgs) (*mcp.ToolResult, error) {
 	content, err := os.ReadFile(absPath)
 	if err != nil {
 		return toolError("Failed to read file: %v", err), nil
 	}
-	lines := strings.Split(string(content), "\n")
+	lines, trailing := hashline.SplitLines(string(content))
 
-	var result string
+	var newLines []string
 	var region editRegion
 	switch args.Operation {
 	case "replace":
-		result, region, err = applyReplace(lines, args)
+		newLines, region, err = applyReplace(lines, args)
 	case "insert":
-		result, region, err = applyInsert(lines, args)
+		newLines, region, err = applyInsert(lines, args)
 	case "delete":
-		result, region, err = applyDelete(lines, args)
+		newLines, region, err = applyDelete(lines, args)
 	default:
 		return toolError("unknown operation %q: use replace, insert, delete, or create", args.Operation), nil
 	}
 	if err != nil {
 		return toolError("%v", err), nil
 	}
+	// The file keeps its final newline, or lack of one, whatever the edit.
+	result := hashline.JoinLines(newLines, trailing)
+
+	// A cancelled turn stops here with the file untouched. Past this point
+	// the write is committed and the delta recorded, so the result is
+	// reported even if the turn is cancelled while diagnostics run.
+	if ctx.Err() != nil {
+		return toolError("Edit cancelled; %s was not changed", args.File), nil
+	}
+
+	if h.dryRun {
+		return toolText(fmt.Sprintf("(dry run) Would edit %s:\n\n%s\nThe file was not changed; its hashes are unchanged.",
+			args.File, lineDiff(lines, newLines))), nil
+	}
 
 	if h.deltaTracker != nil {
 		h.deltaTracker.RecordModify(absPath, content)
 	}
 
-	if err := os.WriteFile(absPath, []byte(result), 0600); err != nil {
+	h.watcher.Wrote(absPath, []byte(result))
+	if err := writeFileAtomic(absPath, []byte(result)); err != nil {
 		return toolError("Failed to write file: %v", err), nil
 	}
 
+	if h.deltaTracker != nil {
+		h.deltaTracker.RecordAccess(absPath, "edit")
+	}
+
 	tagged := hashline.TagLines(result, 1)
-	text := formatEditResponse(args.File, tagged, region)
+	_, removed, added := changedBlock(lines, newLines)
+	change := fmt.Sprintf("+%d/-%d", len(added), len(removed))
+	text := formatEditResponse(args.File, tagged, region, change, h.windowThreshold, h.windowContext)
 
-	if h.lspManager != nil {
-		diags := h.lspManager.NotifyAndWait(ctx, absPath, 5*time.Second)
-		text += lsp.FormatDiagnostics(args.File, diags)
-	}
+	text += h.diagnostics(ctx, absPath, args.File)
 	if h.tsIndex != nil {
-		h.tsIndex.UpdateFile(absPath)
+		h.tsIndex.EditFile(absPath, content, []byte(result))
 	}
 
 	return &mcp.ToolResult{
@@ -179,6 +284,15 @@ func (h *EditHandler) handleCreate(ctx context.Context, absPath, displayPath, co
 		return toolError("File already exists: %s (use replace/insert/delete to modify)", displayPath), nil
 	}
 
+	if ctx.Err() != nil {
+		return toolError("Edit cancelled; %s was not created", displayPath), nil
+	}
+
+	if h.dryRun {
+		return toolText(fmt.Sprintf("(dry run) Would create %s:\n\n%s\nThe file was not created.",
+			displayPath, lineDiff(nil, createdLines(content)))), nil
+	}
+
 	// Create parent directories
 	dir := filepath.Dir(absPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -189,9 +303,13 @@ func (h *EditHandler) handleCreate(ctx context.Context, absPath, displayPath, co
 		h.deltaTracker.RecordCreate(absPath)
 	}
 
-	if err := os.WriteFile(absPath, []byte(content), 0600); err != nil {
+	h.watcher.Wrote(absPath, []byte(content))
+	if err := writeFileAtomic(absPath, []byte(content)); err != nil {
 		return toolError("Failed to create file: %v", err), nil
 	}
+	if h.deltaTracker != nil {
+		h.deltaTracker.RecordAccess(absPath, "create")
+	}
 
 	tagged := hashline.TagLines(content, 1)
 	taggedOutput := hashline.FormatTagged(tagged)
@@ -199,10 +317,7 @@ func (h *EditHandler) handleCreate(ctx context.Context, absPath, displayPath, co
 	text := fmt.Sprintf("Created %s (%d lines):\n\n%s", displayPath, len(tagged), taggedOutput)
 
 	// Closed-loop LSP diagnostics for newly created file.
-	if h.lspManager != nil {
-		diags := h.lspManager.NotifyAndWait(ctx, absPath, 5*time.Second)
-		text += lsp.FormatDiagnostics(displayPath, diags)
-	}
+	text += h.diagnostics(ctx, absPath, displayPath)
 	if h.tsIndex != nil {
 		h.tsIndex.UpdateFile(absPath)
 	}
@@ -212,39 +327,146 @@ func (h *EditHandler) handleCreate(ctx context.Context, absPath, displayPath, co
 	}, nil
 }
 
-// formatEditResponse builds the response text, using windowed output for large files.
-func formatEditResponse(displayPath string, tagged []hashline.TaggedLine, region editRegion) string {
+// changedBlock returns the 0-indexed line where before and after first
+// differ, and the lines removed and added from there up to their common
+// tail.
+func changedBlock(before, after []string) (prefix int, removed, added []string) {
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix && before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+	return prefix, before[prefix : len(before)-suffix], after[prefix : len(after)-suffix]
+}
+
+// lineDiff renders the changed block between before and after as a hunk: a
+// header with the old line range, then "-" and "+" lines.
+func lineDiff(before, after []string) string {
+	prefix, removed, added := changedBlock(before, after)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", prefix+1, len(removed), prefix+1, len(added))
+	for _, l := range removed {
+		b.WriteString("-" + l + "\n")
+	}
+	for _, l := range added {
+		b.WriteString("+" + l + "\n")
+	}
+	return b.String()
+}
+
+// writeFileAtomic replaces path with data via a temp file in the same
+// directory, so an interrupted write never leaves a half-written file.
+// An existing file keeps its mode; new files get 0600. Symlinks are
+// followed so the link survives, and a file with several hard links is
+// written in place since a rename would split it from its other names.
+func writeFileAtomic(path string, data []byte) error {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	}
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode() & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+		if st, ok := info.Sys().(*syscall.Stat_t); ok && st.Nlink > 1 {
+			return os.WriteFile(path, data, mode)
+		}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// diagnostics notifies the LSP servers of the change and returns formatted
+// diagnostics. When a later call in the same batch edits the same file, the
+// servers are only notified: the last edit waits and reports for all of them.
+func (h *EditHandler) diagnostics(ctx context.Context, absPath, displayPath string) string {
+	if h.lspManager == nil {
+		return ""
+	}
+	if editsPending(ctx, displayPath) {
+		h.lspManager.TouchFile(ctx, absPath)
+		return "\n(LSP diagnostics deferred to the next edit of this file)"
+	}
+	diags := h.lspManager.NotifyAndWait(ctx, absPath, h.lspManager.WaitTimeout())
+	return lsp.FormatDiagnostics(displayPath, diags, h.lspManager.MaxSeverity())
+}
+
+// editsPending reports whether a queued Edit call targets file.
+func editsPending(ctx context.Context, file string) bool {
+	for _, c := range mcp.PendingCalls(ctx) {
+		if c.Name != "Edit" {
+			continue
+		}
+		var args struct {
+			File string `json:"file"`
+		}
+		if json.Unmarshal(c.Arguments, &args) == nil && args.File == file {
+			return true
+		}
+	}
+	return false
+}
+
+// formatEditResponse builds the response text, showing only the edit region
+// and contextLines lines around it for files over threshold lines. change
+// is the edit's "+added/-removed" line count for the header.
+func formatEditResponse(displayPath string, tagged []hashline.TaggedLine, region editRegion, change string, threshold, contextLines int) string {
 	total := len(tagged)
-	if total <= windowThreshold {
-		return fmt.Sprintf("Edited %s (%d lines):\n\n%s", displayPath, total, hashline.FormatTagged(tagged))
+	if total <= threshold {
+		return fmt.Sprintf("Edited %s (%d lines, %s):\n\n%s", displayPath, total, change, hashline.FormatTagged(tagged))
 	}
 
 	// Clamp window bounds.
-	winStart := region.start - windowContext
+	winStart := region.start - contextLines
 	if winStart < 1 {
 		winStart = 1
 	}
-	winEnd := region.end + windowContext
+	winEnd := region.end + contextLines
 	if winEnd > total {
 		winEnd = total
 	}
 
 	window := tagged[winStart-1 : winEnd] // tagged is 0-indexed, line nums are 1-indexed
-	return fmt.Sprintf("Edited %s (%d lines, showing %d–%d):\n\n%s",
-		displayPath, total, winStart, winEnd, hashline.FormatTagged(window))
+	return fmt.Sprintf("Edited %s (%d lines, %s, showing %d–%d):\n\n%s",
+		displayPath, total, change, winStart, winEnd, hashline.FormatTagged(window))
+}
+
+// createdLines returns the lines of a new file's content.
+func createdLines(content string) []string {
+	lines, _ := hashline.SplitLines(content)
+	return lines
 }
 
-func applyReplace(lines []string, args EditArgs) (string, editRegion, error) {
+// The apply functions take the file's lines as split by hashline.SplitLines
+// and return the edited lines, leaving the final newline to the caller.
+
+func applyReplace(lines []string, args EditArgs) ([]string, editRegion, error) {
 	start, err := hashline.ParseAnchor(args.Start)
 	if err != nil {
-		return "", editRegion{}, fmt.Errorf("replace start: %w", err)
+		return nil, editRegion{}, fmt.Errorf("replace start: %w", err)
 	}
 	end, err := hashline.ParseAnchor(args.End)
 	if err != nil {
-		return "", editRegion{}, fmt.Errorf("replace end: %w", err)
+		return nil, editRegion{}, fmt.Errorf("replace end: %w", err)
 	}
 	if err := hashline.ValidateRange(lines, &start, &end); err != nil {
-		return "", editRegion{}, fmt.Errorf("replace: %w", err)
+		return nil, editRegion{}, fmt.Errorf("replace: %w", err)
 	}
 
 	inserted := strings.Split(args.Content, "\n")
@@ -257,16 +479,16 @@ func applyReplace(lines []string, args EditArgs) (string, editRegion, error) {
 		start: start.Num,
 		end:   start.Num + len(inserted) - 1,
 	}
-	return strings.Join(newLines, "\n"), region, nil
+	return newLines, region, nil
 }
 
-func applyInsert(lines []string, args EditArgs) (string, editRegion, error) {
+func applyInsert(lines []string, args EditArgs) ([]string, editRegion, error) {
 	after, err := hashline.ParseAnchor(args.After)
 	if err != nil {
-		return "", editRegion{}, fmt.Errorf("insert after: %w", err)
+		return nil, editRegion{}, fmt.Errorf("insert after: %w", err)
 	}
 	if err := after.Validate(lines); err != nil {
-		return "", editRegion{}, fmt.Errorf("insert: after anchor: %w", err)
+		return nil, editRegion{}, fmt.Errorf("insert: after anchor: %w", err)
 	}
 
 	inserted := strings.Split(args.Content, "\n")
@@ -279,20 +501,20 @@ func applyInsert(lines []string, args EditArgs) (string, editRegion, error) {
 		start: after.Num + 1,
 		end:   after.Num + len(inserted),
 	}
-	return strings.Join(newLines, "\n"), region, nil
+	return newLines, region, nil
 }
 
-func applyDelete(lines []string, args EditArgs) (string, editRegion, error) {
+func applyDelete(lines []string, args EditArgs) ([]string, editRegion, error) {
 	start, err := hashline.ParseAnchor(args.Start)
 	if err != nil {
-		return "", editRegion{}, fmt.Errorf("delete start: %w", err)
+		return nil, editRegion{}, fmt.Errorf("delete start: %w", err)
 	}
 	end, err := hashline.ParseAnchor(args.End)
 	if err != nil {
-		return "", editRegion{}, fmt.Errorf("delete end: %w", err)
+		return nil, editRegion{}, fmt.Errorf("delete end: %w", err)
 	}
 	if err := hashline.ValidateRange(lines, &start, &end); err != nil {
-		return "", editRegion{}, fmt.Errorf("delete: %w", err)
+		return nil, editRegion{}, fmt.Errorf("delete: %w", err)
 	}
 
 	newLines := make([]string, 0, len(lines))
@@ -308,5 +530,5 @@ func applyDelete(lines []string, args EditArgs) (string, editRegion, error) {
 		start: regionLine,
 		end:   regionLine,
 	}
-	return strings.Join(newLines, "\n"), region, nil
+	return newLines, region, nil
 }
@@ -7,9 +7,12 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/xonecas/symb/internal/delta"
 	"github.com/xonecas/symb/internal/hashline"
 	"github.com/xonecas/symb/internal/mcp"
+	"github.com/xonecas/symb/internal/store"
 )
 
 const threeLineContent = "aaa\nbbb\nccc\n"
@@ -328,6 +331,39 @@ func TestEditWindowedResponse(t *testing.T) {
 	}
 }
 
+// TestEditCustomWindow verifies SetWindow changes when the result is
+// windowed and how much context it shows, clamped to the file bounds.
+func TestEditCustomWindow(t *testing.T) {
+	var sb strings.Builder
+	for i := 1; i <= 30; i++ {
+		sb.WriteString("line\n")
+	}
+	content := sb.String()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "big.txt")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	handler := newTrackedHandler(t, dir)
+	handler.tracker.MarkRead(path)
+	handler.SetWindow(10, 3)
+
+	h15 := hashFor(content, 15)
+	text := callEdit(t, handler, `{"file": "big.txt", "operation": "replace", "start": "15:`+h15+`", "end": "15:`+h15+`", "content": "MID"}`).Content[0].Text
+	if !strings.HasPrefix(text, "Edited big.txt (30 lines, +1/-1, showing 12–18):") {
+		t.Errorf("header = %q", strings.SplitN(text, "\n", 2)[0])
+	}
+	if strings.Contains(text, "\n11:") || strings.Contains(text, "\n19:") {
+		t.Errorf("window has lines beyond the context:\n%s", text)
+	}
+
+	h2 := hashFor(content, 2) // line 2 is unchanged by the first edit
+	text = callEdit(t, handler, `{"file": "big.txt", "operation": "replace", "start": "2:`+h2+`", "end": "2:`+h2+`", "content": "TOP"}`).Content[0].Text
+	if !strings.HasPrefix(text, "Edited big.txt (30 lines, +1/-1, showing 1–5):") {
+		t.Errorf("header = %q", strings.SplitN(text, "\n", 2)[0])
+	}
+}
+
 func TestEditSmallFileFullResponse(t *testing.T) {
 	dir, path := setupTestFile(t)
 	handler := newTrackedHandler(t, dir)
@@ -386,3 +422,375 @@ func TestEditBadAnchorFormat(t *testing.T) {
 		t.Fatal("should fail with bad anchor")
 	}
 }
+
+func TestEditsPending(t *testing.T) {
+	ctx := mcp.WithPendingCalls(context.Background(), []mcp.ToolCall{
+		{Name: "Read", Arguments: json.RawMessage(`{"file":"a.go"}`)},
+		{Name: "Edit", Arguments: json.RawMessage(`{"file":"b.go","operation":"delete"}`)},
+	})
+	if editsPending(ctx, "a.go") {
+		t.Error("a Read of the same file should not defer diagnostics")
+	}
+	if !editsPending(ctx, "b.go") {
+		t.Error("a queued Edit of the same file should defer diagnostics")
+	}
+	if editsPending(context.Background(), "b.go") {
+		t.Error("no pending calls should never defer")
+	}
+}
+
+// TestEditCancelled verifies that a cancelled turn leaves the file and its
+// undo delta consistent: either untouched with nothing recorded, or fully
+// applied and restorable.
+func TestEditCancelled(t *testing.T) {
+	dir, path := setupTestFile(t)
+	db, err := store.Open(filepath.Join(t.TempDir(), "cache.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	dt := delta.New(db.DB())
+	dt.SetSession("s")
+	dt.BeginTurn(1)
+
+	handler := NewEditHandler(NewFileReadTracker(), nil, dt)
+	handler.SetRootDir(dir)
+	handler.tracker.MarkRead(path)
+	args := json.RawMessage(`{"file": "test.txt", "operation": "replace", "start": "2:` + hashFor(threeLineContent, 2) +
+		`", "end": "2:` + hashFor(threeLineContent, 2) + `", "content": "xxx"}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := handler.Handle(ctx, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "cancelled") {
+		t.Fatalf("cancelled edit result = %+v", result)
+	}
+	if got, _ := os.ReadFile(path); string(got) != threeLineContent {
+		t.Errorf("cancelled edit changed the file: %q", got)
+	}
+	if affected, _ := dt.Undo("s", 1); len(affected) != 0 {
+		t.Errorf("cancelled edit recorded deltas for %v", affected)
+	}
+	if _, err := handler.Handle(ctx, json.RawMessage(`{"file": "new.txt", "operation": "create", "content": "x"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.txt")); !os.IsNotExist(err) {
+		t.Errorf("cancelled create wrote the file: %v", err)
+	}
+
+	if result := callEdit(t, handler, string(args)); result.IsError {
+		t.Fatalf("edit failed: %s", result.Content[0].Text)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "aaa\nxxx\nccc\n" {
+		t.Errorf("edit content = %q", got)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0644 {
+		t.Errorf("edit changed mode to %v", info.Mode().Perm())
+	}
+	affected, err := dt.Undo("s", 1)
+	if err != nil || len(affected) != 1 || affected[0] != path {
+		t.Fatalf("undo affected %v, err %v", affected, err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != threeLineContent {
+		t.Errorf("undo left %q", got)
+	}
+}
+
+// TestFileAccessAudit verifies Read, Edit and BulkReplace record the files
+// they touch as they run, failures aside, and undo records the removal of
+// a created file.
+func TestFileAccessAudit(t *testing.T) {
+	dir, path := setupTestFile(t)
+	t.Chdir(dir)
+	db, err := store.Open(filepath.Join(t.TempDir(), "cache.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	dt := delta.New(db.DB())
+	dt.SetAudit(db.RecordFileAccess)
+	dt.SetSession("s")
+	dt.BeginTurn(1)
+
+	tracker := NewFileReadTracker()
+	read := NewReadHandler(tracker, nil)
+	read.SetAudit(dt)
+	edit := NewEditHandler(tracker, nil, dt)
+	edit.SetRootDir(dir)
+	replace := NewBulkReplaceHandler(dt)
+	for _, call := range []struct {
+		handle func(context.Context, json.RawMessage) (*mcp.ToolResult, error)
+		args   string
+	}{
+		{read.Handle, `{"file": "test.txt"}`},
+		{read.Handle, `{"file": "missing.txt"}`},
+		{edit.Handle, `{"file": "new.txt", "operation": "create", "content": "x"}`},
+		{replace.Handle, `{"find": "aaa", "replace": "zzz", "apply": true}`},
+	} {
+		if _, err := call.handle(context.Background(), json.RawMessage(call.args)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := dt.Undo("s", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	created := filepath.Join(dir, "new.txt")
+	want := []store.FileAccess{{Path: path, Op: "read"}, {Path: created, Op: "create"}, {Path: path, Op: "edit"}, {Path: created, Op: "remove"}}
+	got, err := db.LoadFileAccesses("s")
+	if err != nil || len(got) != len(want) {
+		t.Fatalf("audit = %+v, %v", got, err)
+	}
+	for i, w := range want {
+		if got[i].Path != w.Path || got[i].Op != w.Op || got[i].TurnID != 1 {
+			t.Errorf("audit[%d] = %+v, want %s %s", i, got[i], w.Op, w.Path)
+		}
+	}
+}
+
+func TestEditConflictMarkers(t *testing.T) {
+	dir, path := setupTestFile(t)
+	handler := newTrackedHandler(t, dir)
+	handler.tracker.MarkRead(path)
+
+	h2 := hashFor(threeLineContent, 2)
+	conflict := `"content": "<<<<<<< HEAD\nbbb\n=======\nBBB\n>>>>>>> feature"`
+
+	result := callEdit(t, handler, `{"file": "test.txt", "operation": "replace", "start": "2:`+h2+`", "end": "2:`+h2+`", `+conflict+`}`)
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "content lines 1, 5") {
+		t.Fatalf("expected conflict marker refusal, got: %s", result.Content[0].Text)
+	}
+	if got, _ := os.ReadFile(path); string(got) != threeLineContent {
+		t.Errorf("file changed despite refusal: %q", got)
+	}
+
+	result = callEdit(t, handler, `{"file": "new.txt", "operation": "create", `+conflict+`}`)
+	if !result.IsError {
+		t.Error("create with conflict markers was not refused")
+	}
+
+	// A Markdown heading underline is not a conflict marker.
+	result = callEdit(t, handler, `{"file": "test.txt", "operation": "replace", "start": "2:`+h2+`", "end": "2:`+h2+`", "content": "Title\n======="}`)
+	if result.IsError {
+		t.Fatalf("setext heading refused: %s", result.Content[0].Text)
+	}
+
+	h4 := hashFor("aaa\nTitle\n=======\nccc\n", 4)
+	result = callEdit(t, handler, `{"file": "test.txt", "operation": "replace", "start": "4:`+h4+`", "end": "4:`+h4+`", "allow_conflict_markers": true, `+conflict+`}`)
+	if result.IsError {
+		t.Fatalf("override refused: %s", result.Content[0].Text)
+	}
+	if got, _ := os.ReadFile(path); !strings.Contains(string(got), ">>>>>>> feature") {
+		t.Errorf("override did not write the markers: %q", got)
+	}
+}
+
+func TestEditDryRun(t *testing.T) {
+	dir, path := setupTestFile(t)
+	db, err := store.Open(filepath.Join(t.TempDir(), "cache.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	dt := delta.New(db.DB())
+	dt.SetSession("s")
+	dt.BeginTurn(1)
+	handler := NewEditHandler(NewFileReadTracker(), nil, dt)
+	handler.SetRootDir(dir)
+	handler.SetDryRun(true)
+	handler.tracker.MarkRead(path)
+
+	h2 := hashFor(threeLineContent, 2)
+	result := callEdit(t, handler, `{"file": "test.txt", "operation": "replace", "start": "2:`+h2+`", "end": "2:`+h2+`", "content": "xxx\nyyy"}`)
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.Content[0].Text)
+	}
+	text := result.Content[0].Text
+	if !strings.HasPrefix(text, "(dry run)") || !strings.Contains(text, "@@ -2,1 +2,2 @@\n-bbb\n+xxx\n+yyy\n") {
+		t.Errorf("unexpected dry-run result:\n%s", text)
+	}
+	if got, _ := os.ReadFile(path); string(got) != threeLineContent {
+		t.Errorf("dry run changed the file: %q", got)
+	}
+
+	result = callEdit(t, handler, `{"file": "new.txt", "operation": "create", "content": "hello"}`)
+	if result.IsError || !strings.Contains(result.Content[0].Text, "+hello\n") {
+		t.Errorf("unexpected dry-run create result: %s", result.Content[0].Text)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.txt")); !os.IsNotExist(err) {
+		t.Errorf("dry run created the file: %v", err)
+	}
+	if affected, _ := dt.Undo("s", 1); len(affected) != 0 {
+		t.Errorf("dry run recorded deltas for %v", affected)
+	}
+}
+
+// TestEditTrailingNewline runs a Read→Edit cycle on files with and without
+// a final newline: Read reports no phantom blank line, the last line can be
+// edited, and the file keeps its ending.
+func TestEditTrailingNewline(t *testing.T) {
+	for _, tc := range []struct {
+		name, src, want string
+	}{
+		{"newline", "aaa\nbbb\nccc\n", "aaa\nbbb\nCCC\nddd\n"},
+		{"no newline", "aaa\nbbb\nccc", "aaa\nbbb\nCCC\nddd"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Chdir(dir)
+			path := filepath.Join(dir, "test.txt")
+			if err := os.WriteFile(path, []byte(tc.src), 0644); err != nil {
+				t.Fatal(err)
+			}
+			tracker := NewFileReadTracker()
+			read, err := NewReadHandler(tracker, nil).Handle(context.Background(), json.RawMessage(`{"file":"test.txt"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := read.Content[0].Text
+			if !strings.HasPrefix(out, "Read test.txt (3 lines):") || strings.Contains(out, "4:") {
+				t.Fatalf("Read output:\n%s", out)
+			}
+
+			edit := NewEditHandler(tracker, nil, nil)
+			edit.SetRootDir(dir)
+			h3 := hashFor(tc.src, 3)
+			result := callEdit(t, edit, `{"file":"test.txt","operation":"replace","start":"3:`+h3+`","end":"3:`+h3+`","content":"CCC\nddd"}`)
+			if result.IsError {
+				t.Fatalf("edit failed: %s", result.Content[0].Text)
+			}
+			if !strings.HasPrefix(result.Content[0].Text, "Edited test.txt (4 lines, +2/-1):") {
+				t.Errorf("edit response:\n%s", result.Content[0].Text)
+			}
+			got, _ := os.ReadFile(path)
+			if string(got) != tc.want {
+				t.Errorf("file = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestEditSchemaCorrection verifies the proxy checks Edit calls against the
+// declared schema before running them, listing what to fix.
+func TestEditSchemaCorrection(t *testing.T) {
+	dir, path := setupTestFile(t)
+	handler := newTrackedHandler(t, dir)
+	handler.tracker.MarkRead(path)
+	proxy := mcp.NewProxy(nil)
+	proxy.RegisterTool(NewEditTool(), handler.Handle)
+
+	call := func(args string) *mcp.ToolResult {
+		t.Helper()
+		result, err := proxy.CallTool(context.Background(), "Edit", json.RawMessage(args))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	result := call(`{"File": "test.txt", "operation": "modify", "contents": "x", "allow_conflict_markers": "yes"}`)
+	text := result.Content[0].Text
+	if !result.IsError {
+		t.Fatalf("invalid call ran: %s", text)
+	}
+	for _, want := range []string{
+		"Invalid arguments for Edit; the call was not run:",
+		`- missing required field "file" (string)`,
+		`- unknown field "File"; did you mean "file"?`,
+		`- field "operation" must be one of "replace", "insert", "delete", "create", got "modify"`,
+		`- field "allow_conflict_markers" must be a boolean, got string "yes"`,
+		`- unknown field "contents"; did you mean "content"?`,
+		"Edit takes: file (string, required), operation (string: one of",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("correction missing %q:\n%s", want, text)
+		}
+	}
+	if data, _ := os.ReadFile(path); string(data) != threeLineContent {
+		t.Errorf("file changed: %q", data)
+	}
+
+	h1 := hashFor(threeLineContent, 1)
+	result = call(`{"file": "test.txt", "operation": "replace", "start": "1:` + h1 + `", "end": "1:` + h1 + `", "content": "XXX", "reason": "extra fields are ignored"}`)
+	if result.IsError {
+		t.Errorf("valid call rejected: %s", result.Content[0].Text)
+	}
+}
+
+// TestWriteFileAtomicLinks verifies a write goes through symlinks and hard
+// links instead of replacing them, and keeps the file's mode.
+func TestWriteFileAtomicLinks(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.sh")
+	if err := os.WriteFile(target, []byte("old\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link.sh")
+	if err := os.Symlink("target.sh", link); err != nil {
+		t.Fatal(err)
+	}
+	hard := filepath.Join(dir, "hard.sh")
+	if err := os.Link(target, hard); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(link, []byte("via symlink\n")); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("symlink replaced: %v", err)
+	}
+	if err := writeFileAtomic(hard, []byte("via hard link\n")); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(target)
+	if string(got) != "via hard link\n" {
+		t.Errorf("target = %q, want the hard link's write", got)
+	}
+	if info, _ := os.Stat(target); info.Mode().Perm() != 0755 {
+		t.Errorf("mode = %v, want 0755", info.Mode().Perm())
+	}
+
+	// A single-link file is still replaced by rename with its mode kept.
+	if err := os.Remove(hard); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileAtomic(link, []byte("renamed\n")); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = os.ReadFile(target)
+	if info, _ := os.Stat(target); string(got) != "renamed\n" || info.Mode().Perm() != 0755 {
+		t.Errorf("target = %q, mode %v", got, info.Mode().Perm())
+	}
+}
+
+// TestEditChangeCount verifies the result header counts the lines the edit
+// actually changed in the file.
+func TestEditChangeCount(t *testing.T) {
+	h2 := hashFor(threeLineContent, 2)
+	tests := []struct {
+		name, args, want string
+	}{
+		{"replace", `"operation":"replace","start":"2:` + h2 + `","end":"2:` + h2 + `","content":"X\nY"`, "+2/-1"},
+		// A final newline in the content adds a blank line to the file.
+		{"content ending in a newline", `"operation":"replace","start":"2:` + h2 + `","end":"2:` + h2 + `","content":"X\nY\n"`, "+3/-1"},
+		// Empty content leaves an empty line in place of the range.
+		{"empty content", `"operation":"replace","start":"2:` + h2 + `","end":"2:` + h2 + `","content":""`, "+1/-1"},
+		{"unchanged", `"operation":"replace","start":"2:` + h2 + `","end":"2:` + h2 + `","content":"bbb"`, "+0/-0"},
+		{"insert", `"operation":"insert","after":"2:` + h2 + `","content":"X"`, "+1/-0"},
+		{"delete", `"operation":"delete","start":"2:` + h2 + `","end":"2:` + h2 + `"`, "+0/-1"},
+	}
+	for _, tt := range tests {
+		dir, path := setupTestFile(t)
+		handler := newTrackedHandler(t, dir)
+		handler.tracker.MarkRead(path)
+		result := callEdit(t, handler, `{"file":"`+path+`",`+tt.args+`}`)
+		header, _, _ := strings.Cut(result.Content[0].Text, "\n")
+		if result.IsError || !strings.Contains(header, ", "+tt.want+")") {
+			t.Errorf("%s: header = %q, want %s", tt.name, header, tt.want)
+		}
+	}
+}
@@ -29,6 +29,14 @@ func (t *FileReadTracker) WasRead(absPath string) bool {
 	return ok
 }
 
+// Forget drops the read record for a file, so it must be read again before
+// it can be edited (used when the file changes outside symb).
+func (t *FileReadTracker) Forget(absPath string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.read, absPath)
+}
+
 // Reset clears all read records (used on undo).
 func (t *FileReadTracker) Reset() {
 	t.mu.Lock()
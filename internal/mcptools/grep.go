@@ -6,10 +6,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/xonecas/symb/internal/filesearch"
 	"github.com/xonecas/symb/internal/mcp"
+	"github.com/xonecas/symb/internal/treesitter"
 )
 
 // GrepArgs represents arguments for the grep tool.
@@ -18,6 +20,7 @@ type GrepArgs struct {
 	ContentSearch bool   `json:"content_search,omitempty"` // Search file contents (default: false, searches filenames)
 	MaxResults    int    `json:"max_results,omitempty"`    // Max results to return (default: 100)
 	CaseSensitive bool   `json:"case_sensitive,omitempty"` // Case-sensitive matching (default: false)
+	CodeOnly      bool   `json:"code_only,omitempty"`      // Skip matches in comments and strings (default: false)
 }
 
 // NewGrepTool creates the grep tool definition.
@@ -31,10 +34,12 @@ func NewGrepTool() mcp.Tool {
 				"pattern":        {"type": "string", "description": "Pattern to search for (regex). For filenames: matches against basename or path. For content: matches line contents."},
 				"content_search": {"type": "boolean", "description": "If true, search file contents (grep); if false, search filenames (find). Default: false"},
 				"max_results":    {"type": "integer", "description": "Maximum number of results to return. Default: 100"},
-				"case_sensitive": {"type": "boolean", "description": "Enable case-sensitive matching. Default: false (case-insensitive)"}
+				"case_sensitive": {"type": "boolean", "description": "Enable case-sensitive matching. Default: false (case-insensitive)"},
+				"code_only":      {"type": "boolean", "description": "With content_search, skip matches inside comments and string literals, e.g. to find real uses of an identifier. Applies to languages with a tree-sitter grammar (Go); other files are searched as usual. Default: false"}
 			},
 			"required": ["pattern"]
 		}`),
+		Retryable: true,
 	}
 }
 
@@ -80,13 +85,18 @@ func MakeGrepHandler() mcp.ToolHandler {
 		}
 
 		// Execute search
-		results, err := searcher.Search(ctx, filesearch.Options{
+		opts := filesearch.Options{
 			Pattern:       args.Pattern,
 			ContentSearch: args.ContentSearch,
 			MaxResults:    args.MaxResults,
 			CaseSensitive: args.CaseSensitive,
 			RootDir:       cwd,
-		})
+		}
+		var filtered int
+		if args.CodeOnly && args.ContentSearch {
+			opts.KeepMatch = codeOnly(&filtered)
+		}
+		results, err := searcher.Search(ctx, opts)
 
 		if err != nil {
 			return &mcp.ToolResult{
@@ -116,6 +126,9 @@ func MakeGrepHandler() mcp.ToolHandler {
 				output.WriteString(fmt.Sprintf("\n(Limited to %d results. Use max_results parameter to see more)", args.MaxResults))
 			}
 		}
+		if filtered > 0 {
+			output.WriteString(fmt.Sprintf("\n(%d match(es) in comments or strings filtered out by code_only)", filtered))
+		}
 
 		return &mcp.ToolResult{
 			Content: []mcp.ContentBlock{{Type: "text", Text: output.String()}},
@@ -123,3 +136,31 @@ func MakeGrepHandler() mcp.ToolHandler {
 		}, nil
 	}
 }
+
+// codeOnly returns a filesearch.Options.KeepMatch hook that drops lines
+// whose matches all fall inside comments or string literals, counting them
+// in filtered. Files without a tree-sitter grammar keep every match.
+func codeOnly(filtered *int) func(string, int, [][]int) bool {
+	var path string
+	var spans []treesitter.Span
+	var ok bool
+	return func(absPath string, line int, matches [][]int) bool {
+		if absPath != path {
+			path, spans, ok = absPath, nil, false
+			//nolint:gosec // G304: path comes from the search walk of the working directory
+			if src, err := os.ReadFile(absPath); err == nil {
+				spans, ok = treesitter.NonCode(absPath, src)
+			}
+		}
+		if !ok {
+			return true
+		}
+		for _, m := range matches {
+			if !slices.ContainsFunc(spans, func(s treesitter.Span) bool { return s.Contains(line-1, m[0]) }) {
+				return true
+			}
+		}
+		*filtered++
+		return false
+	}
+}
@@ -0,0 +1,99 @@
+package mcptools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xonecas/symb/internal/mcp"
+)
+
+// TestGrepCodeOnly verifies code_only drops matches inside comments and
+// string literals, reports how many were dropped, and leaves files without
+// a grammar alone.
+func TestGrepCodeOnly(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	src := "package p\n\n" +
+		"// Frob frobs things.\n" +
+		"func Frob() string {\n" +
+		"\treturn \"Frob\" + `\n" +
+		"Frob in a raw string`\n" +
+		"}\n\n" +
+		"var x = Frob() /* Frob */\n"
+	for name, data := range map[string]string{"p.go": src, "notes.txt": "// Frob\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	grep := func(args string) string {
+		t.Helper()
+		result, err := MakeGrepHandler()(context.Background(), json.RawMessage(args))
+		if err != nil || result.IsError {
+			t.Fatalf("%s: %v %+v", args, err, result)
+		}
+		return result.Content[0].Text
+	}
+
+	if got := grep(`{"pattern":"Frob","content_search":true,"case_sensitive":true}`); !strings.Contains(got, "Found 6 match(es)") {
+		t.Errorf("plain grep:\n%s", got)
+	}
+	got := grep(`{"pattern":"Frob","content_search":true,"case_sensitive":true,"code_only":true}`)
+	for _, want := range []string{"Found 3 match(es)", "p.go:4:func Frob()", "p.go:9:var x = Frob()", "notes.txt:1:// Frob", "(3 match(es) in comments or strings filtered out by code_only)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("code_only result missing %q:\n%s", want, got)
+		}
+	}
+}
+
+// TestGrepResultTokens verifies a Grep with thousands of matches is cut to
+// its tool_result_tokens cap at a line boundary, with a note on narrowing
+// the search, and that uncapped tools pass through whole.
+func TestGrepResultTokens(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	var src strings.Builder
+	for i := range 5000 {
+		fmt.Fprintf(&src, "needle %d\n", i)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "big.txt"), []byte(src.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	proxy := mcp.NewProxy(nil)
+	proxy.RegisterTool(NewGrepTool(), MakeGrepHandler())
+	grep := func() string {
+		t.Helper()
+		result, err := proxy.CallTool(context.Background(), "Grep", json.RawMessage(`{"pattern":"needle","content_search":true,"max_results":5000}`))
+		if err != nil || result.IsError {
+			t.Fatalf("%v %+v", err, result)
+		}
+		return result.Content[0].Text
+	}
+
+	full := grep()
+	if !strings.Contains(full, "Found 5000 match(es)") || strings.Contains(full, "truncated") {
+		t.Fatalf("uncapped result: %.200s", full)
+	}
+
+	proxy.SetResultTokens(map[string]int{"Grep": 500})
+	capped := grep()
+	body, note, ok := strings.Cut(capped, "\n[Result truncated to about 500 tokens: showing ")
+	if !ok {
+		t.Fatalf("missing truncation note:\n%s", capped[len(capped)-300:])
+	}
+	if len(body) > 500*4 {
+		t.Errorf("kept %d characters, over the 2000 the cap allows", len(body))
+	}
+	if !strings.HasSuffix(body, "\n") || !strings.HasPrefix(body, "Found 5000 match(es)") {
+		t.Errorf("not cut at a line boundary: ...%q", body[len(body)-40:])
+	}
+	if !strings.Contains(note, "of 5004 lines") || !strings.Contains(note, "more specific pattern or path") {
+		t.Errorf("note = %q", note)
+	}
+}
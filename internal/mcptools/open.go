@@ -1,44 +1,55 @@
 package mcptools
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/xonecas/symb/internal/delta"
+	"github.com/xonecas/symb/internal/filesearch"
 	"github.com/xonecas/symb/internal/hashline"
+	"github.com/xonecas/symb/internal/highlight"
 	"github.com/xonecas/symb/internal/lsp"
 	"github.com/xonecas/symb/internal/mcp"
 	"github.com/xonecas/symb/internal/treesitter"
 )
 
 const (
-	maxReadLines = 500   // Max lines returned by Read before truncation.
-	maxReadChars = 30000 // Max characters returned by Read before truncation.
+	defaultReadLines = 500   // Max lines returned by Read before windowing.
+	defaultReadChars = 30000 // Max characters returned by Read before windowing.
+	maxReadFiles     = 20    // Max files in one multi-file Read.
 )
 
 // ReadArgs represents arguments for the Read tool.
 type ReadArgs struct {
-	File  string `json:"file"`
-	Start int    `json:"start,omitempty"` // Optional: start line (1-indexed)
-	End   int    `json:"end,omitempty"`   // Optional: end line (1-indexed)
+	File  string   `json:"file"`
+	Files []string `json:"files,omitempty"` // Optional: several whole files instead of file
+	Start int      `json:"start,omitempty"` // Optional: start line (1-indexed)
+	End   int      `json:"end,omitempty"`   // Optional: end line (1-indexed)
+	Force bool     `json:"force,omitempty"` // Read a file the policy would refuse
 }
 
 // NewReadTool creates the Read tool definition.
 func NewReadTool() mcp.Tool {
 	return mcp.Tool{
 		Name:        "Read",
-		Description: `Reads a file and returns hashline-tagged content. Each line is returned as "linenum:hash|content". You MUST Read a file before editing it with Edit. Use start/end for line ranges. Output is capped at 500 lines / 20k characters — always use start/end on large files.`,
+		Description: `Reads a file and returns hashline-tagged content. Each line is returned as "linenum:hash|content". You MUST Read a file before editing it with Edit. Use start/end for line ranges. Pass files instead of file to read several related files in one call; each is delimited by "==> path <==" and windowed, and the whole result shares one size cap. Large results are cut to a window with a note giving the file size and the start line to continue from — use start/end on large files.`,
 		InputSchema: json.RawMessage(`{
 			"type": "object",
 			"properties": {
 				"file":  {"type": "string", "description": "Path to the file to read"},
+				"files": {"type": "array", "items": {"type": "string"}, "description": "Optional: paths of several files to read whole, instead of file (up to 20)"},
 				"start": {"type": "integer", "description": "Optional: starting line number (1-indexed, inclusive)"},
-				"end":   {"type": "integer", "description": "Optional: ending line number (1-indexed, inclusive)"}
-			},
-			"required": ["file"]
+				"end":   {"type": "integer", "description": "Optional: ending line number (1-indexed, inclusive)"},
+				"force": {"type": "boolean", "description": "Read a gitignored, oversized, or binary file that would otherwise be refused"}
+			}
 		}`),
+		Retryable: true,
 	}
 }
 
@@ -47,13 +58,59 @@ type ReadHandler struct {
 	tracker    *FileReadTracker
 	lspManager *lsp.Manager
 	tsIndex    *treesitter.Index
+	audit      *delta.Tracker
+	maxLines   int
+	maxChars   int
+
+	// Refusal policy; see SetPolicy.
+	maxSize       int64
+	refuseBinary  bool
+	refuseIgnores bool
+	ignoreRoot    string // the directory whose .gitignore ignored holds
+	ignored       *filesearch.GitignoreMatcher
 }
 
 // NewReadHandler creates a handler for the Read tool.
 func NewReadHandler(tracker *FileReadTracker, lspManager *lsp.Manager) *ReadHandler {
-	return &ReadHandler{tracker: tracker, lspManager: lspManager}
+	return &ReadHandler{tracker: tracker, lspManager: lspManager, maxLines: defaultReadLines, maxChars: defaultReadChars}
+}
+
+// SetLimits sets the line and character caps on a Read result. Values <= 0
+// keep the defaults.
+func (h *ReadHandler) SetLimits(lines, chars int) {
+	if lines > 0 {
+		h.maxLines = lines
+	}
+	if chars > 0 {
+		h.maxChars = chars
+	}
 }
 
+// SetPolicy makes Read refuse, unless the call sets force, whole-file reads
+// of files over maxSize bytes (0 allows any size), files that look binary,
+// and files matched by the working directory's .gitignore.
+func (h *ReadHandler) SetPolicy(maxSize int64, refuseBinary, refuseIgnored bool) {
+	h.maxSize = maxSize
+	h.refuseBinary = refuseBinary
+	h.refuseIgnores = refuseIgnored
+	if wd, err := os.Getwd(); err == nil {
+		h.SetRoot(wd)
+	}
+}
+
+// SetRoot points the policy at the .gitignore of dir, the working tree
+// root, e.g. once a resumed session has moved back to its own.
+func (h *ReadHandler) SetRoot(dir string) {
+	h.ignoreRoot = dir
+	h.ignored = nil
+	if h.refuseIgnores {
+		h.ignored, _ = filesearch.NewGitignoreMatcher(filepath.Join(dir, ".gitignore"))
+	}
+}
+
+// SetAudit records the files Read reads in dt's session audit.
+func (h *ReadHandler) SetAudit(dt *delta.Tracker) { h.audit = dt }
+
 // SetTSIndex sets the tree-sitter index for incremental updates on read.
 func (h *ReadHandler) SetTSIndex(idx *treesitter.Index) { h.tsIndex = idx }
 
@@ -63,21 +120,124 @@ func (h *ReadHandler) Handle(_ context.Context, arguments json.RawMessage) (*mcp
 	if err := json.Unmarshal(arguments, &args); err != nil {
 		return toolError("Invalid arguments: %v", err), nil
 	}
+	if len(args.Files) > 0 {
+		return h.readMany(args), nil
+	}
 	if args.File == "" {
 		return toolError("File path cannot be empty"), nil
 	}
+	result, _ := h.read(args, h.maxChars)
+	return result, nil
+}
+
+// Files returns the absolute paths a Read call reads, for the proxy's
+// result cache.
+func (h *ReadHandler) Files(arguments json.RawMessage) []string {
+	var args ReadArgs
+	if json.Unmarshal(arguments, &args) != nil {
+		return nil
+	}
+	var files []string
+	for _, file := range append([]string{args.File}, args.Files...) {
+		if absPath, err := validatePath(file); file != "" && err == nil {
+			files = append(files, absPath)
+		}
+	}
+	return files
+}
+
+// MarkRead marks the files of a Read served from the proxy's cache as
+// read, as the call itself did: all but those result lists as failed or
+// skipped.
+func (h *ReadHandler) MarkRead(arguments json.RawMessage, result *mcp.ToolResult) {
+	var args ReadArgs
+	if json.Unmarshal(arguments, &args) != nil {
+		return
+	}
+	for _, file := range append([]string{args.File}, args.Files...) {
+		absPath, err := validatePath(file)
+		if file == "" || err != nil || strings.Contains(result.Content[0].Text, "\n- "+file+": ") {
+			continue
+		}
+		h.markRead(absPath)
+	}
+}
+
+// markRead marks a file read for Edit and records it in the audit.
+func (h *ReadHandler) markRead(absPath string) {
+	h.tracker.MarkRead(absPath)
+	if h.audit != nil {
+		h.audit.RecordAccess(absPath, "read")
+	}
+}
+
+// readMany reads several whole files into one result: a header listing
+// each file and its line count, then each file's own Read output under a
+// "==> path <==" delimiter. The files share one character budget; files
+// past it are listed as skipped and not marked read. The result is an error
+// only when no file could be read.
+func (h *ReadHandler) readMany(args ReadArgs) *mcp.ToolResult {
+	if args.File != "" || args.Start > 0 || args.End > 0 {
+		return toolError("files cannot be combined with file, start or end; Read a range of one file separately")
+	}
+	if len(args.Files) > maxReadFiles {
+		return toolError("Too many files (%d); Read at most %d per call", len(args.Files), maxReadFiles)
+	}
+
+	budget := h.maxChars
+	read := 0
+	summary := make([]string, 0, len(args.Files))
+	var body strings.Builder
+	for _, file := range args.Files {
+		if budget <= 0 {
+			summary = append(summary, fmt.Sprintf("- %s: skipped, result size cap reached; Read it separately", file))
+			continue
+		}
+		result, lines := h.read(ReadArgs{File: file, Force: args.Force}, budget)
+		text := result.Content[0].Text
+		if result.IsError {
+			summary = append(summary, fmt.Sprintf("- %s: error", file))
+		} else {
+			summary = append(summary, fmt.Sprintf("- %s (%d lines)", file, lines))
+			budget -= utf8.RuneCountInString(text)
+			read++
+		}
+		fmt.Fprintf(&body, "\n\n==> %s <==\n%s", file, text)
+	}
 
+	header := fmt.Sprintf("Read %d files:\n%s", len(args.Files), strings.Join(summary, "\n"))
+	return &mcp.ToolResult{
+		Content: []mcp.ContentBlock{{Type: "text", Text: header + body.String()}},
+		IsError: read == 0,
+	}
+}
+
+// read reads one file, capping the tagged output at maxChars characters.
+// It also returns the number of lines in the result.
+func (h *ReadHandler) read(args ReadArgs, maxChars int) (*mcp.ToolResult, int) {
 	absPath, err := validatePath(args.File)
 	if err != nil {
-		return toolError("%v", err), nil
+		return toolError("%v", err), 0
+	}
+
+	if !args.Force {
+		if msg := h.refuseIgnored(absPath, args.File); msg != "" {
+			return toolError("%s", msg), 0
+		}
 	}
 
 	content, err := os.ReadFile(absPath)
 	if err != nil {
-		return toolError("Failed to read file: %v", err), nil
+		return toolError("Failed to read file: %v", err), 0
 	}
 
-	h.tracker.MarkRead(absPath)
+	if !args.Force {
+		if msg := h.refuseContent(args, content); msg != "" {
+			return toolError("%s", msg), 0
+		}
+	}
+
+	h.markRead(absPath)
 	if h.lspManager != nil {
 		go h.lspManager.TouchFile(context.Background(), absPath)
 	}
@@ -85,30 +245,26 @@ func (h *ReadHandler) Handle(_ context.Context, arguments json.RawMessage) (*mcp
 		go h.tsIndex.UpdateFile(absPath)
 	}
 
-	lines := strings.Split(string(content), "\n")
+	lines, _ := hashline.SplitLines(string(content))
 	selectedContent, startLine, err := extractRange(lines, string(content), args.Start, args.End)
 	if err != nil {
-		return toolError("%v", err), nil
+		return toolError("%v", err), 0
 	}
 
 	tagged := hashline.TagLines(selectedContent, startLine)
 
-	// Cap output to avoid blowing up context. When the file is too large,
-	// return only the first maxReadLines and tell the LLM to use line ranges.
-	truncatedRead := false
+	// Cap output to avoid blowing up context: keep whole lines up to the
+	// line and character limits and tell the LLM where to continue.
 	totalLines := len(tagged)
-	if len(tagged) > maxReadLines {
-		tagged = tagged[:maxReadLines]
-		truncatedRead = true
-	}
+	tagged = h.window(tagged, maxChars)
+	truncatedRead := len(tagged) < totalLines
 
 	taggedOutput := hashline.FormatTagged(tagged)
 
-	// Secondary cap on raw character count (handles very long lines).
+	// A single line longer than the character limit is cut mid-line.
 	// Use []rune to avoid splitting multi-byte UTF-8 sequences.
-	if runes := []rune(taggedOutput); len(runes) > maxReadChars {
-		taggedOutput = string(runes[:maxReadChars]) + "\n[Truncated — output exceeded character limit]"
-		truncatedRead = true
+	if runes := []rune(taggedOutput); len(runes) > maxChars {
+		taggedOutput = string(runes[:maxChars]) + "\n[Truncated — line exceeded character limit]"
 	}
 
 	rangeInfo := ""
@@ -120,14 +276,98 @@ func (h *ReadHandler) Handle(_ context.Context, arguments json.RawMessage) (*mcp
 		rangeInfo = fmt.Sprintf(" (lines %d-%d)", startLine, end)
 	}
 
-	header := fmt.Sprintf("Read %s%s (%d lines):\n\n%s", args.File, rangeInfo, len(tagged), taggedOutput)
+	header := fmt.Sprintf("Read %s%s (%d lines):\n", args.File, rangeInfo, len(tagged))
+	if ctx := h.readContext(absPath, startLine, startLine+len(tagged)-1); ctx != "" {
+		header += ctx + "\n"
+	}
+	header += "\n" + taggedOutput
 	if truncatedRead {
-		header += fmt.Sprintf("\n\n[Showing %d of %d lines. Use start/end parameters to read specific sections.]", len(tagged), totalLines)
+		last := startLine + len(tagged) - 1
+		header += fmt.Sprintf("\n\n[Showing lines %d-%d of %d; the file has %d lines (%s). Read with start=%d to continue, or start/end for a specific section.]",
+			startLine, last, startLine+totalLines-1, len(lines), formatSize(len(content)), last+1)
 	}
 
 	return &mcp.ToolResult{
 		Content: []mcp.ContentBlock{{Type: "text", Text: header}},
-	}, nil
+	}, len(tagged)
+}
+
+// refuseIgnored returns guidance when file is gitignored and the policy
+// refuses those, or "" to go ahead.
+func (h *ReadHandler) refuseIgnored(absPath, file string) string {
+	if h.ignored == nil {
+		return ""
+	}
+	rel, err := filepath.Rel(h.ignoreRoot, absPath)
+	if err != nil || !h.ignored.Matches(filepath.ToSlash(rel), false) {
+		return ""
+	}
+	return fmt.Sprintf("Not reading %s: it is gitignored, so it is likely generated or a build artifact. Read the source it is built from instead, or call Read again with force=true if you really need it.", file)
+}
+
+// refuseContent returns guidance when content is binary or a whole-file
+// read is over the size cap, or "" to go ahead.
+func (h *ReadHandler) refuseContent(args ReadArgs, content []byte) string {
+	if h.refuseBinary && looksBinary(content) {
+		return fmt.Sprintf("Not reading %s: it looks like a binary file (%s), which is not useful as text. Inspect it with Shell (e.g. `file` or `xxd | head`), or call Read again with force=true.", args.File, formatSize(len(content)))
+	}
+	if h.maxSize > 0 && int64(len(content)) > h.maxSize && args.Start <= 0 && args.End <= 0 {
+		return fmt.Sprintf("Not reading all of %s: it is %s, over the %s limit for a whole-file Read. Use Grep or Outline to find the relevant part and Read it with start/end, or call Read again with force=true.", args.File, formatSize(len(content)), formatSize(int(h.maxSize)))
+	}
+	return ""
+}
+
+// looksBinary reports whether data has a NUL byte in its first 8000 bytes,
+// the same heuristic git uses.
+func looksBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0
+}
+
+// window returns the leading lines of tagged that fit the line cap and
+// maxChars, always keeping at least one line.
+func (h *ReadHandler) window(tagged []hashline.TaggedLine, maxChars int) []hashline.TaggedLine {
+	if len(tagged) > h.maxLines {
+		tagged = tagged[:h.maxLines]
+	}
+	chars := 0
+	for i, t := range tagged {
+		chars += utf8.RuneCountInString(t.Tag()) + 1
+		if chars > maxChars && i > 0 {
+			return tagged[:i]
+		}
+	}
+	return tagged
+}
+
+// formatSize renders a byte count as B, KB or MB.
+func formatSize(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// readContext returns one orientation line for a Read result: the file's
+// language and, when the symbol index has it, the symbol enclosing the
+// returned lines (e.g. "Language: go · in method (*T).Handle").
+func (h *ReadHandler) readContext(absPath string, start, end int) string {
+	var parts []string
+	if lang := highlight.DetectLanguage(absPath); lang != "text" {
+		parts = append(parts, "Language: "+lang)
+	}
+	if h.tsIndex != nil {
+		if sym, ok := h.tsIndex.Enclosing(absPath, start, end); ok {
+			name := sym.Name
+			if sym.Receiver != "" {
+				name = "(" + sym.Receiver + ")." + name
+			}
+			parts = append(parts, "in "+sym.Kind.String()+" "+name)
+		}
+	}
+	return strings.Join(parts, " · ")
 }
 
 // extractRange returns the selected content and start line number for a line range.
@@ -0,0 +1,210 @@
+package mcptools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xonecas/symb/internal/treesitter"
+)
+
+// TestReadContextHeader verifies Read adds one language/symbol line after
+// the header without disturbing the header the TUI parses.
+func TestReadContextHeader(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	path := filepath.Join(dir, "main.go")
+	src := "package main\n\nfunc Foo() {\n\tprintln(1)\n}\n"
+	if err := os.WriteFile(path, []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+	idx := treesitter.NewIndex(dir)
+	idx.UpdateFile(path)
+	h := NewReadHandler(NewFileReadTracker(), nil)
+	h.SetTSIndex(idx)
+
+	result, err := h.Handle(context.Background(), json.RawMessage(`{"file":"main.go","start":4,"end":4}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(result.Content[0].Text, "\n")
+	if lines[0] != "Read main.go (lines 4-4) (1 lines):" {
+		t.Errorf("header = %q", lines[0])
+	}
+	if lines[1] != "Language: go · in func Foo" {
+		t.Errorf("context line = %q", lines[1])
+	}
+	if lines[2] != "" || !strings.HasPrefix(lines[3], "4:") {
+		t.Errorf("content not after a blank line: %q", lines[2:])
+	}
+}
+
+// TestReadWindow verifies an over-cap Read returns whole leading lines and
+// a note with the file size and where to continue.
+func TestReadWindow(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	var src strings.Builder
+	for range 20 {
+		src.WriteString("0123456789\n")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "big.txt"), []byte(src.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+	h := NewReadHandler(NewFileReadTracker(), nil)
+
+	read := func() string {
+		result, err := h.Handle(context.Background(), json.RawMessage(`{"file":"big.txt"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Content[0].Text
+	}
+
+	if out := read(); strings.Contains(out, "[Showing") {
+		t.Errorf("read under the default cap was windowed:\n%s", out)
+	}
+
+	h.SetLimits(5, 0)
+	out := read()
+	if !strings.HasPrefix(out, "Read big.txt (5 lines):") {
+		t.Errorf("header = %q", strings.SplitN(out, "\n", 2)[0])
+	}
+	if !strings.Contains(out, "[Showing lines 1-5 of 20; the file has 20 lines (220 B). Read with start=6") {
+		t.Errorf("missing continuation note:\n%s", out)
+	}
+
+	// Character cap cuts at a line boundary.
+	h.SetLimits(100, 50)
+	out = read()
+	if !strings.Contains(out, "[Showing lines 1-3 of 20;") {
+		t.Errorf("char cap did not window by whole lines:\n%s", out)
+	}
+}
+
+// TestReadPolicy verifies the refusal policy turns away gitignored,
+// binary, and oversized whole-file reads with guidance, and that ranged
+// reads and force=true get through.
+func TestReadPolicy(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	files := map[string]string{
+		".gitignore":  "dist/\n",
+		"dist/app.js": "bundle",
+		"logo.png":    "\x89PNG\x00\x00",
+		"big.txt":     strings.Repeat("0123456789\n", 200),
+		"small.go":    "package p\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := NewReadHandler(NewFileReadTracker(), nil)
+	h.SetPolicy(1024, true, true)
+
+	for _, tc := range []struct {
+		args    string
+		refused string // substring of the guidance, or "" if allowed
+	}{
+		{`{"file":"dist/app.js"}`, "gitignored"},
+		{`{"file":"logo.png"}`, "binary"},
+		{`{"file":"big.txt"}`, "start/end"},
+		{`{"file":"big.txt","start":1,"end":5}`, ""},
+		{`{"file":"small.go"}`, ""},
+		{`{"file":"dist/app.js","force":true}`, ""},
+		{`{"file":"logo.png","force":true}`, ""},
+	} {
+		result, err := h.Handle(context.Background(), json.RawMessage(tc.args))
+		if err != nil {
+			t.Fatal(err)
+		}
+		text := result.Content[0].Text
+		if tc.refused == "" {
+			if result.IsError {
+				t.Errorf("%s refused: %s", tc.args, text)
+			}
+			continue
+		}
+		if !result.IsError || !strings.Contains(text, tc.refused) || !strings.Contains(text, "force=true") {
+			t.Errorf("%s: got %q, want guidance mentioning %q", tc.args, text, tc.refused)
+		}
+	}
+}
+
+// TestReadMany verifies a multi-file Read returns a header with line counts
+// and each file under a delimiter, marks the files read, and stops at the
+// shared result cap.
+func TestReadMany(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	for name, data := range map[string]string{
+		"a.go":   "package a\n\nfunc A() {}\n",
+		"b.go":   "package b\n",
+		"big.go": strings.Repeat("// filler line\n", 100),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tracker := NewFileReadTracker()
+	h := NewReadHandler(tracker, nil)
+	h.SetLimits(0, 400)
+
+	result, err := h.Handle(context.Background(), json.RawMessage(`{"files":["a.go","missing.go","big.go","b.go"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("multi-file Read failed: %s", result.Content[0].Text)
+	}
+	text := result.Content[0].Text
+	for _, want := range []string{
+		"Read 4 files:\n- a.go (3 lines)\n- missing.go: error\n- big.go (",
+		"- b.go: skipped",
+		"==> a.go <==\nRead a.go (3 lines):",
+		"==> missing.go <==\nFailed to read file",
+		"==> big.go <==\nRead big.go (",
+		"Read with start=",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("result missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "==> b.go <==") {
+		t.Errorf("b.go returned past the result cap:\n%s", text)
+	}
+	for name, want := range map[string]bool{"a.go": true, "big.go": true, "b.go": false} {
+		abs, _ := filepath.Abs(name)
+		if got := tracker.WasRead(abs); got != want {
+			t.Errorf("%s read = %v, want %v", name, got, want)
+		}
+	}
+
+	// A result served from the proxy's cache marks the same files.
+	tracker.Reset()
+	h.MarkRead(json.RawMessage(`{"files":["a.go","missing.go","big.go","b.go"]}`), result)
+	for name, want := range map[string]bool{"a.go": true, "missing.go": false, "big.go": true, "b.go": false} {
+		abs, _ := filepath.Abs(name)
+		if got := tracker.WasRead(abs); got != want {
+			t.Errorf("cached: %s read = %v, want %v", name, got, want)
+		}
+	}
+
+	result, _ = h.Handle(context.Background(), json.RawMessage(`{"files":["a.go"],"start":2}`))
+	if !result.IsError {
+		t.Errorf("files with start accepted: %s", result.Content[0].Text)
+	}
+
+	result, _ = h.Handle(context.Background(), json.RawMessage(`{"files":["missing.go","gone.go"]}`))
+	if !result.IsError || !strings.HasPrefix(result.Content[0].Text, "Read 2 files:\n- missing.go: error\n- gone.go: error") {
+		t.Errorf("all files failed but result = %+v", result)
+	}
+}
@@ -0,0 +1,82 @@
+package mcptools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/xonecas/symb/internal/mcp"
+	"github.com/xonecas/symb/internal/treesitter"
+)
+
+// OutlineArgs represents arguments for the Outline tool.
+type OutlineArgs struct {
+	File string `json:"file"`
+}
+
+// NewOutlineTool creates the Outline tool definition.
+func NewOutlineTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "Outline",
+		Description: `Returns the symbol outline of a source file: top-level declarations with their line ranges, methods nested under their types. Much cheaper than Read — use it to find what to Read in a large file, then Read just those lines.`,
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"file": {"type": "string", "description": "Path to the file to outline"}
+			},
+			"required": ["file"]
+		}`),
+		Retryable: true,
+	}
+}
+
+// OutlineHandler handles Outline tool calls.
+type OutlineHandler struct {
+	tsIndex *treesitter.Index
+}
+
+// NewOutlineHandler creates a handler for the Outline tool.
+func NewOutlineHandler() *OutlineHandler {
+	return &OutlineHandler{}
+}
+
+// SetTSIndex sets the tree-sitter index symbols are served from. Files the
+// index does not know yet are parsed on demand.
+func (h *OutlineHandler) SetTSIndex(idx *treesitter.Index) { h.tsIndex = idx }
+
+// Handle implements the mcp.ToolHandler interface.
+func (h *OutlineHandler) Handle(_ context.Context, arguments json.RawMessage) (*mcp.ToolResult, error) {
+	var args OutlineArgs
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return toolError("Invalid arguments: %v", err), nil
+	}
+	if args.File == "" {
+		return toolError("File path cannot be empty"), nil
+	}
+
+	absPath, err := validatePath(args.File)
+	if err != nil {
+		return toolError("%v", err), nil
+	}
+	if !treesitter.Supported(absPath) {
+		return toolError("No outline support for %s files; use Read", filepath.Ext(absPath)), nil
+	}
+
+	var syms []treesitter.Symbol
+	if h.tsIndex != nil {
+		syms = h.tsIndex.FileSymbols(absPath)
+	}
+	if syms == nil {
+		syms, err = treesitter.ParseFile(absPath)
+		if err != nil {
+			return toolError("Failed to parse file: %v", err), nil
+		}
+	}
+
+	outline := treesitter.FormatFileOutline(syms)
+	if outline == "" {
+		return toolText(fmt.Sprintf("Outline %s: no symbols", args.File)), nil
+	}
+	return toolText(fmt.Sprintf("Outline %s:\n\n%s", args.File, outline)), nil
+}
@@ -0,0 +1,48 @@
+package mcptools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xonecas/symb/internal/treesitter"
+)
+
+// TestOutline verifies Outline serves nested symbols with line ranges, from
+// the index or by parsing, and refuses paths outside the working directory.
+func TestOutline(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	src := "package main\n\ntype T struct{}\n\nfunc (t *T) M() {\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+	want := "Outline main.go:\n\n3 type T struct_type\n  5-6 func (t *T) M()\n"
+
+	h := NewOutlineHandler()
+	call := func(args string) (string, bool) {
+		result, err := h.Handle(context.Background(), json.RawMessage(args))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Content[0].Text, result.IsError
+	}
+
+	if got, isErr := call(`{"file":"main.go"}`); isErr || got != want {
+		t.Errorf("parsed outline = %q (error %v), want %q", got, isErr, want)
+	}
+	idx := treesitter.NewIndex(dir)
+	idx.UpdateFile(filepath.Join(dir, "main.go"))
+	h.SetTSIndex(idx)
+	if got, isErr := call(`{"file":"main.go"}`); isErr || got != want {
+		t.Errorf("indexed outline = %q (error %v), want %q", got, isErr, want)
+	}
+
+	for _, args := range []string{`{"file":"../main.go"}`, `{"file":"notes.txt"}`, `{}`} {
+		if got, isErr := call(args); !isErr {
+			t.Errorf("%s: expected an error, got %q", args, got)
+		}
+	}
+}
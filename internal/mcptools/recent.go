@@ -0,0 +1,213 @@
+package mcptools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xonecas/symb/internal/filesearch"
+	"github.com/xonecas/symb/internal/git"
+	"github.com/xonecas/symb/internal/mcp"
+)
+
+const (
+	defaultRecentFiles = 20  // Files RecentFiles lists by default.
+	maxRecentFiles     = 200 // Most files RecentFiles lists.
+)
+
+// RecentFilesArgs represents arguments for the RecentFiles tool.
+type RecentFilesArgs struct {
+	Limit   int  `json:"limit,omitempty"`   // Max files to list (default 20)
+	Changed bool `json:"changed,omitempty"` // Only files with uncommitted git changes
+}
+
+// NewRecentFilesTool creates the RecentFiles tool definition.
+func NewRecentFilesTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "RecentFiles",
+		Description: `Lists the most recently modified files in the working directory, newest first, with their modification time and git status (modified, staged, added, untracked, ...). Respects .gitignore. Use it to find what the user was just working on, e.g. for "continue my work" tasks; set changed=true to list only the uncommitted git working set.`,
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"limit":   {"type": "integer", "description": "Maximum number of files to list. Default: 20"},
+				"changed": {"type": "boolean", "description": "If true, list only files with uncommitted changes (staged, unstaged, untracked or deleted). Default: false"}
+			}
+		}`),
+	}
+}
+
+// recentFile is one row of a RecentFiles result.
+type recentFile struct {
+	path   string // relative to the working directory
+	mtime  time.Time
+	status string // git status label, "" when unchanged
+}
+
+// MakeRecentFilesHandler creates a handler for the RecentFiles tool.
+func MakeRecentFilesHandler() mcp.ToolHandler {
+	return handleRecentFiles
+}
+
+func handleRecentFiles(ctx context.Context, arguments json.RawMessage) (*mcp.ToolResult, error) {
+	var args RecentFilesArgs
+	if len(arguments) > 0 {
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return toolError("Invalid arguments: %v", err), nil
+		}
+	}
+	if args.Limit <= 0 {
+		args.Limit = defaultRecentFiles
+	}
+	args.Limit = min(args.Limit, maxRecentFiles)
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return toolError("Failed to get working directory: %v", err), nil
+	}
+	status, inRepo := workingSet()
+	if args.Changed && !inRepo {
+		return toolError("Not in a git repository; call RecentFiles without changed=true"), nil
+	}
+
+	files, err := recentFiles(ctx, cwd, status, args.Changed)
+	if err != nil {
+		return toolError("Failed to list files: %v", err), nil
+	}
+	if len(files) == 0 {
+		if args.Changed {
+			return toolText("No uncommitted changes"), nil
+		}
+		return toolText("No files found"), nil
+	}
+
+	total := len(files)
+	files = files[:min(len(files), args.Limit)]
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d most recently modified files (of %d), newest first:\n\n", len(files), total)
+	for _, f := range files {
+		when := "-" // deleted: no mtime
+		if !f.mtime.IsZero() {
+			when = f.mtime.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(&b, "%-19s  %-9s  %s\n", when, f.status, f.path)
+	}
+	return toolText(strings.TrimRight(b.String(), "\n")), nil
+}
+
+// recentFiles walks root, skipping .git and gitignored paths, and returns
+// its files newest first with their git status. With changedOnly it keeps
+// just the files in status, including deleted ones.
+func recentFiles(ctx context.Context, root string, status map[string]string, changedOnly bool) ([]recentFile, error) {
+	matcher, err := filesearch.NewGitignoreMatcher(filepath.Join(root, ".gitignore"))
+	if err != nil {
+		matcher, _ = filesearch.NewGitignoreMatcher("")
+	}
+
+	var files []recentFile
+	seen := make(map[string]bool)
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if d.Name() == ".git" || matcher.Matches(rel, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if matcher.Matches(rel, false) {
+			return nil
+		}
+		label := statusOf(status, rel)
+		if changedOnly && label == "" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		seen[rel] = true
+		files = append(files, recentFile{path: rel, mtime: info.ModTime(), status: label})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Deleted files are part of the working set but not on disk.
+	for rel, label := range status {
+		if label == "deleted" && !seen[rel] {
+			files = append(files, recentFile{path: rel, status: label})
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if !files[i].mtime.Equal(files[j].mtime) {
+			return files[i].mtime.After(files[j].mtime)
+		}
+		return files[i].path < files[j].path
+	})
+	return files, nil
+}
+
+// workingSet returns the git status label of each changed path, relative
+// to the working directory, and whether the working directory is in a git
+// repository at all.
+func workingSet() (map[string]string, bool) {
+	changes, err := git.Status()
+	if err != nil {
+		return nil, false
+	}
+	prefix := git.Prefix()
+	status := make(map[string]string, len(changes))
+	for _, c := range changes {
+		if rel, ok := strings.CutPrefix(c.Path, prefix); ok {
+			status[rel] = statusLabel(c.Code)
+		}
+	}
+	return status, true
+}
+
+// statusOf returns the status label of rel, which may be inside an
+// untracked directory listed only by its own path.
+func statusOf(status map[string]string, rel string) string {
+	if label, ok := status[rel]; ok {
+		return label
+	}
+	for dir := filepath.Dir(rel); dir != "."; dir = filepath.Dir(dir) {
+		if label := status[dir+"/"]; label != "" {
+			return label
+		}
+	}
+	return ""
+}
+
+// statusLabel turns a porcelain status code into a word.
+func statusLabel(code string) string {
+	switch {
+	case code == "??":
+		return "untracked"
+	case strings.Contains(code, "D"):
+		return "deleted"
+	case strings.Contains(code, "A"):
+		return "added"
+	case strings.Contains(code, "R"):
+		return "renamed"
+	case code[1] == ' ':
+		return "staged"
+	}
+	return "modified"
+}
@@ -0,0 +1,92 @@
+package mcptools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRecentFiles verifies files are listed newest first with their git
+// status, gitignored files are left out, and changed=true keeps only the
+// working set, deleted files included.
+func TestRecentFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	base := time.Now().Add(-time.Hour)
+	write := func(name, data string, age time.Duration) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		mtime := base.Add(-age)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(".gitignore", "", 10*time.Minute)
+	write("old.go", "package a\n", 9*time.Minute)
+	write("edited.go", "package a\n", 8*time.Minute)
+	write("gone.go", "package a\n", 8*time.Minute)
+	run("init", "-q")
+	run("add", ".")
+	run("commit", "-q", "-m", "init")
+
+	write("edited.go", "package b\n", 0)
+	write(".gitignore", "*.log\n", 5*time.Minute)
+	write("new/fresh.go", "package fresh\n", time.Minute)
+	write("build.log", "ignored", -time.Minute) // newest, but gitignored
+	if err := os.Remove(filepath.Join(dir, "gone.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	list := func(args string) []string {
+		t.Helper()
+		result, err := MakeRecentFilesHandler()(context.Background(), json.RawMessage(args))
+		if err != nil || result.IsError {
+			t.Fatalf("%s: %v %+v", args, err, result)
+		}
+		lines := strings.Split(result.Content[0].Text, "\n")[2:]
+		for i, line := range lines {
+			fields := strings.Fields(line)
+			if fields[0] == "-" { // no mtime
+				fields = fields[1:]
+			} else {
+				fields = fields[2:]
+			}
+			lines[i] = strings.Join(fields, " ")
+		}
+		return lines
+	}
+
+	got := list(`{}`)
+	want := []string{"modified edited.go", "untracked new/fresh.go", "modified .gitignore", "old.go", "deleted gone.go"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("recent files = %q, want %q", got, want)
+	}
+
+	got = list(`{"changed":true,"limit":2}`)
+	want = []string{"modified edited.go", "untracked new/fresh.go"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("changed files = %q, want %q", got, want)
+	}
+}
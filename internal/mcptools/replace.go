@@ -0,0 +1,315 @@
+package mcptools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/xonecas/symb/internal/delta"
+	"github.com/xonecas/symb/internal/filesearch"
+	"github.com/xonecas/symb/internal/fswatch"
+	"github.com/xonecas/symb/internal/mcp"
+	"github.com/xonecas/symb/internal/treesitter"
+)
+
+const (
+	maxReplaceFiles    = 200      // files one call may change
+	maxReplaceFileSize = 10 << 20 // larger files are skipped
+	replaceSampleLines = 3        // changed lines previewed per file
+)
+
+// BulkReplaceArgs are the arguments to the BulkReplace tool.
+type BulkReplaceArgs struct {
+	Find    string `json:"find"`
+	Replace string `json:"replace"`
+	Regex   bool   `json:"regex,omitempty"`
+	Word    bool   `json:"word,omitempty"`
+	Glob    string `json:"glob,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Apply   bool   `json:"apply,omitempty"`
+}
+
+// NewBulkReplaceTool creates the BulkReplace tool definition.
+func NewBulkReplaceTool() mcp.Tool {
+	return mcp.Tool{
+		Name: "BulkReplace",
+		Description: `Find and replace text across many files at once, e.g. to rename an identifier project-wide.
+By default this only previews: the match count per file and a few changed lines. Check the preview, then call again with the same arguments and apply=true to write every file.
+Gitignored files, binary files and .git are skipped. Each changed file can be undone like an Edit. Re-Read a file before editing it afterwards: its hashes change.
+Prefer Edit for changes to a single place.`,
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"find":    {"type": "string", "description": "Text to find (a Go regular expression when regex is true)"},
+				"replace": {"type": "string", "description": "Replacement text. With regex, $1 or ${name} insert groups"},
+				"regex":   {"type": "boolean", "description": "Treat find as a regular expression. Default: false (literal)"},
+				"word":    {"type": "boolean", "description": "Match whole words only. Default: false"},
+				"glob":    {"type": "string", "description": "Only files matching this pattern, e.g. *.go, or internal/*/*.go with a slash to match the relative path"},
+				"path":    {"type": "string", "description": "Directory to search (default: the working directory)"},
+				"apply":   {"type": "boolean", "description": "Write the changes. Default: false (preview only)"}
+			},
+			"required": ["find", "replace"]
+		}`),
+	}
+}
+
+// BulkReplaceHandler handles BulkReplace tool calls.
+type BulkReplaceHandler struct {
+	deltaTracker *delta.Tracker
+	tsIndex      *treesitter.Index
+	watcher      *fswatch.Watcher
+	dryRun       bool
+}
+
+// NewBulkReplaceHandler creates a handler for the BulkReplace tool.
+func NewBulkReplaceHandler(dt *delta.Tracker) *BulkReplaceHandler {
+	return &BulkReplaceHandler{deltaTracker: dt}
+}
+
+// SetTSIndex sets the tree-sitter index for incremental updates on write.
+func (h *BulkReplaceHandler) SetTSIndex(idx *treesitter.Index) { h.tsIndex = idx }
+
+// SetWatcher tells w about every write, so its own changes are not
+// reported as external ones.
+func (h *BulkReplaceHandler) SetWatcher(w *fswatch.Watcher) { h.watcher = w }
+
+// SetDryRun makes Handle preview even when asked to apply.
+func (h *BulkReplaceHandler) SetDryRun(on bool) { h.dryRun = on }
+
+// replaceFile is one file with matches and its content after replacement.
+type replaceFile struct {
+	rel      string
+	abs      string
+	old, new []byte
+	count    int
+	samples  []string // "line: old -> new" previews
+	lines    int      // matching lines, of which samples shows the first few
+}
+
+// Handle implements the mcp.ToolHandler interface.
+func (h *BulkReplaceHandler) Handle(ctx context.Context, arguments json.RawMessage) (*mcp.ToolResult, error) {
+	var args BulkReplaceArgs
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return toolError("Invalid arguments: %v", err), nil
+	}
+	if args.Find == "" {
+		return toolError("find is required"), nil
+	}
+	re, err := replacePattern(args)
+	if err != nil {
+		return toolError("Invalid pattern: %v", err), nil
+	}
+	if args.Glob != "" {
+		if _, err := filepath.Match(args.Glob, ""); err != nil {
+			return toolError("Invalid glob %q: %v", args.Glob, err), nil
+		}
+	}
+	dir := args.Path
+	if dir == "" {
+		dir = "."
+	}
+	root, err := validatePath(dir)
+	if err != nil {
+		return toolError("%v", err), nil
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return toolError("Not a directory: %s", dir), nil
+	}
+
+	files, err := findReplacements(ctx, root, re, args)
+	if err != nil {
+		return toolError("Failed to search files: %v", err), nil
+	}
+	if len(files) == 0 {
+		return toolText("No matches; no files changed."), nil
+	}
+	if len(files) > maxReplaceFiles {
+		return toolError("%d files match, more than the %d one call may change; narrow glob or path", len(files), maxReplaceFiles), nil
+	}
+	if !args.Apply || h.dryRun {
+		return toolText(formatReplacePreview(files, h.dryRun && args.Apply)), nil
+	}
+	if ctx.Err() != nil {
+		return toolError("BulkReplace cancelled; no files were changed"), nil
+	}
+	if err := h.write(files); err != nil {
+		return toolError("%v", err), nil
+	}
+
+	total := 0
+	var b strings.Builder
+	for _, f := range files {
+		total += f.count
+		fmt.Fprintf(&b, "- %s: %d\n", f.rel, f.count)
+	}
+	return toolText(fmt.Sprintf("Replaced %d occurrence(s) in %d file(s):\n%s\nHashes of these files changed; re-Read before editing them.",
+		total, len(files), b.String())), nil
+}
+
+// write replaces every file, recording a delta and an audit entry for each. If a write fails
+// the files already written are restored, so the change applies to all
+// files or none.
+func (h *BulkReplaceHandler) write(files []replaceFile) error {
+	for i, f := range files {
+		h.watcher.Wrote(f.abs, f.new)
+		if err := writeFileAtomic(f.abs, f.new); err != nil {
+			for _, done := range files[:i] {
+				h.watcher.Wrote(done.abs, done.old)
+				_ = writeFileAtomic(done.abs, done.old)
+			}
+			return fmt.Errorf("failed to write %s: %v; no files were changed", f.rel, err)
+		}
+	}
+	for _, f := range files {
+		if h.deltaTracker != nil {
+			h.deltaTracker.RecordModify(f.abs, f.old)
+			h.deltaTracker.RecordAccess(f.abs, "edit")
+		}
+		if h.tsIndex != nil {
+			h.tsIndex.EditFile(f.abs, f.old, f.new)
+		}
+	}
+	return nil
+}
+
+// replacePattern compiles find as a regex, or quotes it for a literal
+// match, optionally bounded to whole words.
+func replacePattern(args BulkReplaceArgs) (*regexp.Regexp, error) {
+	pattern := args.Find
+	if !args.Regex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if args.Word {
+		pattern = `\b(?:` + pattern + `)\b`
+	}
+	return regexp.Compile(pattern)
+}
+
+// findReplacements walks root, skipping .git, gitignored, binary and
+// oversized files, and returns the files re matches with their new content.
+func findReplacements(ctx context.Context, root string, re *regexp.Regexp, args BulkReplaceArgs) ([]replaceFile, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	matcher, err := filesearch.NewGitignoreMatcher(filepath.Join(wd, ".gitignore"))
+	if err != nil {
+		matcher, _ = filesearch.NewGitignoreMatcher("")
+	}
+
+	var files []replaceFile
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(wd, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if d.Name() == ".git" || (rel != "." && matcher.Matches(rel, true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || matcher.Matches(rel, false) || !globMatches(args.Glob, rel) {
+			return nil
+		}
+		if info, err := d.Info(); err != nil || info.Size() > maxReplaceFileSize {
+			return nil
+		}
+		old, err := os.ReadFile(path)
+		if err != nil || bytes.IndexByte(old, 0) >= 0 {
+			return nil
+		}
+		if f, ok := replaceIn(old, re, args); ok {
+			f.rel, f.abs = rel, path
+			files = append(files, f)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// globMatches reports whether rel matches glob: its base name, or the
+// whole relative path when glob has a slash. An empty glob matches all.
+func globMatches(glob, rel string) bool {
+	if glob == "" {
+		return true
+	}
+	name := filepath.Base(rel)
+	if strings.Contains(glob, "/") {
+		name = rel
+	}
+	ok, _ := filepath.Match(glob, name)
+	return ok
+}
+
+// replaceIn applies the replacement to content, returning the new content,
+// the match count and a few changed lines.
+func replaceIn(content []byte, re *regexp.Regexp, args BulkReplaceArgs) (replaceFile, bool) {
+	count := len(re.FindAllIndex(content, -1))
+	if count == 0 {
+		return replaceFile{}, false
+	}
+	replace := func(s string) string {
+		if args.Regex {
+			return re.ReplaceAllString(s, args.Replace)
+		}
+		return re.ReplaceAllLiteralString(s, args.Replace)
+	}
+	f := replaceFile{old: content, new: []byte(replace(string(content))), count: count}
+	if bytes.Equal(f.old, f.new) {
+		return replaceFile{}, false
+	}
+	for i, line := range strings.Split(string(content), "\n") {
+		if !re.MatchString(line) {
+			continue
+		}
+		f.lines++
+		if len(f.samples) < replaceSampleLines {
+			f.samples = append(f.samples, fmt.Sprintf("%d: %s\n   -> %s",
+				i+1, strings.TrimSpace(line), strings.TrimSpace(replace(line))))
+		}
+	}
+	return f, true
+}
+
+// formatReplacePreview lists each file's match count and sample changes.
+func formatReplacePreview(files []replaceFile, dryRun bool) string {
+	total := 0
+	for _, f := range files {
+		total += f.count
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Preview: %d occurrence(s) in %d file(s)\n", total, len(files))
+	for _, f := range files {
+		fmt.Fprintf(&b, "\n%s: %d\n", f.rel, f.count)
+		for _, s := range f.samples {
+			b.WriteString("  " + s + "\n")
+		}
+		if more := f.lines - len(f.samples); more > 0 {
+			fmt.Fprintf(&b, "  ... and %d more line(s)\n", more)
+		}
+	}
+	if dryRun {
+		b.WriteString("\n(dry run) No files were changed.")
+	} else {
+		b.WriteString("\nNo files were changed. Call again with apply=true to write these changes.")
+	}
+	text := b.String()
+	if len([]rune(text)) > maxOutputChars {
+		text = truncateMiddle(text, maxOutputChars)
+	}
+	return text
+}
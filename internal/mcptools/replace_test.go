@@ -0,0 +1,83 @@
+package mcptools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBulkReplace verifies a call without apply only previews, apply writes
+// every matching file, and gitignored, binary and non-glob files are left
+// alone.
+func TestBulkReplace(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	files := map[string]string{
+		".gitignore":   "gen/\n",
+		"a.go":         "func oldName() {}\nvar x = oldName()\n",
+		"sub/b.go":     "// oldNameX is not a match with word\nvar y = oldName()\n",
+		"notes.md":     "oldName\n",
+		"gen/c.go":     "oldName\n",
+		"blob.go":      "oldName\x00",
+		"untouched.go": "package a\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := NewBulkReplaceHandler(nil)
+	call := func(args string) string {
+		t.Helper()
+		result, err := h.Handle(context.Background(), json.RawMessage(args))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.IsError {
+			t.Fatalf("error: %s", result.Content[0].Text)
+		}
+		return result.Content[0].Text
+	}
+
+	preview := call(`{"find": "oldName", "replace": "newName", "word": true, "glob": "*.go"}`)
+	for _, want := range []string{"Preview: 3 occurrence(s) in 2 file(s)", "a.go: 2\n", "sub/b.go: 1\n", "2: var x = oldName()\n   -> var x = newName()", "apply=true"} {
+		if !strings.Contains(preview, want) {
+			t.Errorf("preview missing %q:\n%s", want, preview)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.go")); string(data) != files["a.go"] {
+		t.Errorf("preview changed a.go: %q", data)
+	}
+
+	applied := call(`{"find": "oldName", "replace": "newName", "word": true, "glob": "*.go", "apply": true}`)
+	if !strings.HasPrefix(applied, "Replaced 3 occurrence(s) in 2 file(s):\n") {
+		t.Errorf("apply result = %q", applied)
+	}
+	want := map[string]string{
+		"a.go":     "func newName() {}\nvar x = newName()\n",
+		"sub/b.go": "// oldNameX is not a match with word\nvar y = newName()\n",
+		"notes.md": files["notes.md"],
+		"gen/c.go": files["gen/c.go"],
+		"blob.go":  files["blob.go"],
+	}
+	for name, content := range want {
+		if data, _ := os.ReadFile(filepath.Join(dir, name)); string(data) != content {
+			t.Errorf("%s = %q, want %q", name, data, content)
+		}
+	}
+
+	regex := call(`{"find": "(new)Name", "replace": "${1}er", "regex": true, "path": "sub", "apply": true}`)
+	if !strings.Contains(regex, "- sub/b.go: 1\n") {
+		t.Errorf("regex result = %q", regex)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "sub/b.go")); !strings.Contains(string(data), "var y = newer()") {
+		t.Errorf("sub/b.go = %q", data)
+	}
+}
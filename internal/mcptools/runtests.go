@@ -0,0 +1,318 @@
+package mcptools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xonecas/symb/internal/mcp"
+	"github.com/xonecas/symb/internal/shell"
+)
+
+const (
+	defaultTestTimeout      = 300 // seconds
+	defaultTestFailureLines = 20
+)
+
+// testCommands maps a project marker file to its usual test command, in
+// order of preference.
+var testCommands = []struct{ marker, command string }{
+	{"go.mod", "go test ./..."},
+	{"Cargo.toml", "cargo test"},
+	{"package.json", "npm test"},
+	{"pyproject.toml", "pytest"},
+	{"pytest.ini", "pytest"},
+}
+
+var (
+	goTestRe    = regexp.MustCompile(`^\s*--- (PASS|FAIL|SKIP): (\S+)`)
+	goPackageRe = regexp.MustCompile(`^(ok|FAIL)\s*\t(\S+)(.*)`)
+	cargoFailRe = regexp.MustCompile(`^test (\S+) \.\.\. FAILED`)
+	pytestRe    = regexp.MustCompile(`^FAILED (\S+)`)
+	testCountRe = regexp.MustCompile(`(\d+) (passed|failed|skipped|ignored)`)
+)
+
+// TestArgs are the arguments to the Test tool.
+type TestArgs struct {
+	Args    string `json:"args,omitempty"`    // appended to the test command
+	Timeout int    `json:"timeout,omitempty"` // seconds
+}
+
+// NewTestTool creates the Test tool definition.
+func NewTestTool() mcp.Tool {
+	return mcp.Tool{
+		Name: "Test",
+		Description: `Run the project's tests and get a concise summary: pass/fail counts, the failing test names, and the first lines of the first failure.
+The test command is configured per project (or guessed: go test ./..., cargo test, npm test, pytest). Use args to narrow the run, e.g. "-run TestParse ./internal/parser" for Go.
+Prefer this over Shell for the run-tests-and-fix loop.`,
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"args":    {"type": "string", "description": "Extra arguments appended to the test command, e.g. a test filter or package"},
+				"timeout": {"type": "integer", "description": "Timeout in seconds (default 300)"}
+			}
+		}`),
+	}
+}
+
+// TestHandler handles Test tool calls.
+type TestHandler struct {
+	sh           *shell.Shell
+	command      string
+	timeout      int
+	failureLines int
+	dryRun       bool
+}
+
+// NewTestHandler creates a handler for the Test tool.
+func NewTestHandler(sh *shell.Shell) *TestHandler {
+	return &TestHandler{sh: sh, timeout: defaultTestTimeout, failureLines: defaultTestFailureLines}
+}
+
+// SetCommand sets the test command. Empty guesses it from the project.
+func (h *TestHandler) SetCommand(command string) { h.command = command }
+
+// SetLimits sets the default timeout in seconds and how many lines of the
+// first failure are shown. Zero keeps the default.
+func (h *TestHandler) SetLimits(timeout, failureLines int) {
+	if timeout > 0 {
+		h.timeout = timeout
+	}
+	if failureLines > 0 {
+		h.failureLines = failureLines
+	}
+}
+
+// SetDryRun makes Handle report the command instead of running it.
+func (h *TestHandler) SetDryRun(on bool) { h.dryRun = on }
+
+// Handle implements the mcp.ToolHandler interface.
+func (h *TestHandler) Handle(ctx context.Context, arguments json.RawMessage) (*mcp.ToolResult, error) {
+	var args TestArgs
+	if len(arguments) > 0 {
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return toolError("Invalid arguments: %v", err), nil
+		}
+	}
+	command := h.command
+	if command == "" {
+		command = guessTestCommand(h.sh.Dir())
+	}
+	if command == "" {
+		return toolError("No test command configured and none could be guessed; set [test] command in config.toml or use Shell"), nil
+	}
+	if args.Args != "" {
+		command += " " + args.Args
+	}
+	if h.dryRun {
+		return toolText(fmt.Sprintf("(dry run) Would run:\n$ %s\nNothing was executed; no output is available.\n", command)), nil
+	}
+
+	timeout := h.timeout
+	if args.Timeout > 0 {
+		timeout = min(args.Timeout, maxTimeoutSec)
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	var out bytes.Buffer
+	execErr := h.sh.ExecStream(ctx, command, &out, &out)
+	exitCode := shell.ExitCode(execErr)
+	summary := formatTestSummary(command, out.String(), exitCode, ctx.Err() != nil, h.failureLines)
+	if exitCode != 0 || ctx.Err() != nil {
+		return &mcp.ToolResult{
+			Content: []mcp.ContentBlock{{Type: "text", Text: summary}},
+			IsError: true,
+		}, nil
+	}
+	return toolText(summary), nil
+}
+
+// guessTestCommand returns the test command for the project in dir, the
+// shell's working directory, or "" when no marker file is found.
+func guessTestCommand(dir string) string {
+	for _, c := range testCommands {
+		if _, err := os.Stat(filepath.Join(dir, c.marker)); err == nil {
+			return c.command
+		}
+	}
+	return ""
+}
+
+// testResult is what parseTestOutput extracts from a test run.
+type testResult struct {
+	passed, failed, skipped int
+	passSeen                bool // passes were reported; go test lists them only with -v
+	pkgOK, pkgFailed        int
+	failures                []string // failing tests (and packages that failed to build)
+	firstFailure            int      // line index of the first failure, -1 if none
+}
+
+// parseTestOutput reads go test output, falling back to the "N passed, M
+// failed" summaries of cargo, pytest and jest.
+func parseTestOutput(lines []string) testResult {
+	r := testResult{firstFailure: -1}
+	fail := func(i int, name string) {
+		r.failures = append(r.failures, name)
+		if r.firstFailure < 0 {
+			r.firstFailure = i
+		}
+	}
+	for i, line := range lines {
+		if m := goTestRe.FindStringSubmatch(line); m != nil {
+			if m[1] == "FAIL" {
+				// With -v the test's output precedes its result line.
+				fail(lastIndex(lines[:i], "=== RUN   "+m[2], i), m[2])
+			}
+			if strings.Contains(m[2], "/") {
+				continue // subtests are counted through their parent
+			}
+			switch m[1] {
+			case "PASS":
+				r.passed++
+				r.passSeen = true
+			case "FAIL":
+				r.failed++
+			case "SKIP":
+				r.skipped++
+			}
+			continue
+		}
+		if m := goPackageRe.FindStringSubmatch(line); m != nil {
+			if m[1] == "ok" {
+				r.pkgOK++
+				continue
+			}
+			r.pkgFailed++
+			if strings.Contains(m[3], "[build failed]") || strings.Contains(m[3], "[setup failed]") {
+				// The compiler errors follow a "# package" header.
+				fail(lastIndex(lines[:i], "# "+m[2], i), m[2]+" (build failed)")
+			}
+			continue
+		}
+		if m := cargoFailRe.FindStringSubmatch(line); m != nil {
+			fail(i, m[1])
+		} else if m := pytestRe.FindStringSubmatch(line); m != nil {
+			fail(i, m[1])
+		}
+	}
+	if r.passed+r.failed+r.skipped+r.pkgOK+r.pkgFailed == 0 {
+		r.passed, r.failed, r.skipped, r.passSeen = summaryCounts(lines)
+	}
+	return r
+}
+
+// lastIndex returns the index of the last line in lines that is prefix or
+// starts with prefix and a space, or def if there is none.
+func lastIndex(lines []string, prefix string, def int) int {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if rest, ok := strings.CutPrefix(lines[i], prefix); ok && (rest == "" || rest[0] == ' ') {
+			return i
+		}
+	}
+	return def
+}
+
+// summaryCounts reads the counts of a cargo, pytest or jest summary line.
+// cargo prints one "test result:" line per target, so those are summed;
+// otherwise the last line with counts wins. sawPassed reports whether the
+// summary counted passes at all.
+func summaryCounts(lines []string) (passed, failed, skipped int, sawPassed bool) {
+	count := func(line string) (p, f, s int) {
+		for _, m := range testCountRe.FindAllStringSubmatch(line, -1) {
+			n, _ := strconv.Atoi(m[1])
+			switch m[2] {
+			case "passed":
+				p += n
+				sawPassed = true
+			case "failed":
+				f += n
+			default:
+				s += n
+			}
+		}
+		return p, f, s
+	}
+	cargo := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "test result:") {
+			p, f, s := count(line)
+			passed, failed, skipped = passed+p, failed+f, skipped+s
+			cargo = true
+		}
+	}
+	if cargo {
+		return passed, failed, skipped, sawPassed
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		if testCountRe.MatchString(lines[i]) {
+			passed, failed, skipped = count(lines[i])
+			return passed, failed, skipped, sawPassed
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// formatTestSummary renders the result of a test run: status, counts,
+// failing tests and the first failureLines lines of the first failure. Runs
+// that fail without a recognizable failure show the tail of the output.
+func formatTestSummary(command, output string, exitCode int, timedOut bool, failureLines int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	r := parseTestOutput(lines)
+
+	var b strings.Builder
+	status := "PASS"
+	switch {
+	case timedOut:
+		status = "TIMED OUT"
+	case exitCode != 0:
+		status = fmt.Sprintf("FAIL (exit %d)", exitCode)
+	}
+	fmt.Fprintf(&b, "%s: %s\n", status, command)
+
+	var counts []string
+	switch {
+	case r.passSeen:
+		counts = append(counts, fmt.Sprintf("tests: %d passed, %d failed, %d skipped", r.passed, r.failed, r.skipped))
+	case r.failed+r.skipped > 0:
+		counts = append(counts, fmt.Sprintf("tests: %d failed, %d skipped", r.failed, r.skipped))
+	}
+	if r.pkgOK+r.pkgFailed > 0 {
+		counts = append(counts, fmt.Sprintf("packages: %d ok, %d failed", r.pkgOK, r.pkgFailed))
+	}
+	if len(counts) > 0 {
+		b.WriteString(strings.Join(counts, "; ") + "\n")
+	}
+	if status == "PASS" {
+		return b.String()
+	}
+
+	if len(r.failures) > 0 {
+		b.WriteString("\nFailing:\n")
+		for _, name := range r.failures {
+			b.WriteString("- " + name + "\n")
+		}
+	}
+	excerpt := "First failure"
+	start := r.firstFailure
+	if start < 0 {
+		excerpt = "Output tail"
+		start = max(0, len(lines)-failureLines)
+	}
+	end := min(len(lines), start+failureLines)
+	fmt.Fprintf(&b, "\n%s (lines %d-%d of %d):\n", excerpt, start+1, end, len(lines))
+	b.WriteString(strings.Join(lines[start:end], "\n") + "\n")
+
+	text := b.String()
+	if len([]rune(text)) > maxOutputChars {
+		text = truncateMiddle(text, maxOutputChars)
+	}
+	return text
+}
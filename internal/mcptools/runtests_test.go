@@ -0,0 +1,132 @@
+package mcptools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xonecas/symb/internal/shell"
+)
+
+func TestParseTestOutput(t *testing.T) {
+	tests := []struct {
+		name         string
+		output       string
+		passed       int
+		failed       int
+		pkgFailed    int
+		failures     []string
+		firstFailure int
+	}{
+		{
+			name: "go",
+			output: "ok  \texample.com/a\t0.01s\n" +
+				"--- FAIL: TestParse (0.00s)\n" +
+				"    --- FAIL: TestParse/empty (0.00s)\n" +
+				"        parse_test.go:12: got 1, want 0\n" +
+				"FAIL\n" +
+				"FAIL\texample.com/b\t0.02s\n",
+			failed: 1, pkgFailed: 1,
+			failures:     []string{"TestParse", "TestParse/empty"},
+			firstFailure: 1,
+		},
+		{
+			name: "go verbose",
+			output: "=== RUN   TestOK\n--- PASS: TestOK (0.00s)\n" +
+				"=== RUN   TestBad\n    bad_test.go:3: boom\n--- FAIL: TestBad (0.00s)\n" +
+				"FAIL\texample.com/a\t0.01s\n",
+			passed: 1, failed: 1, pkgFailed: 1,
+			failures:     []string{"TestBad"},
+			firstFailure: 2,
+		},
+		{
+			name: "go build failure",
+			output: "# example.com/a [example.com/a.test]\n./a.go:3:2: undefined: x\n" +
+				"FAIL\texample.com/a [build failed]\n",
+			pkgFailed:    1,
+			failures:     []string{"example.com/a (build failed)"},
+			firstFailure: 0,
+		},
+		{
+			name: "pytest",
+			output: "FAILED tests/test_x.py::test_y - assert 1 == 2\n" +
+				"==== 1 failed, 3 passed in 0.12s ====\n",
+			passed: 3, failed: 1,
+			failures:     []string{"tests/test_x.py::test_y"},
+			firstFailure: 0,
+		},
+		{
+			name: "cargo",
+			output: "test a::ok ... ok\ntest a::bad ... FAILED\n" +
+				"test result: FAILED. 1 passed; 1 failed; 0 ignored\n" +
+				"test result: ok. 2 passed; 0 failed; 0 ignored\n",
+			passed: 3, failed: 1,
+			failures:     []string{"a::bad"},
+			firstFailure: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := parseTestOutput(strings.Split(strings.TrimRight(tt.output, "\n"), "\n"))
+			if r.passed != tt.passed || r.failed != tt.failed || r.pkgFailed != tt.pkgFailed {
+				t.Errorf("counts = %d passed, %d failed, %d packages failed", r.passed, r.failed, r.pkgFailed)
+			}
+			if strings.Join(r.failures, "|") != strings.Join(tt.failures, "|") {
+				t.Errorf("failures = %q, want %q", r.failures, tt.failures)
+			}
+			if r.firstFailure != tt.firstFailure {
+				t.Errorf("first failure at line %d, want %d", r.firstFailure, tt.firstFailure)
+			}
+		})
+	}
+}
+
+// TestTestTool verifies a failing run is reported as an error with the
+// failing test and the start of its output, and a passing one as counts.
+func TestGuessTestCommand(t *testing.T) {
+	dir := t.TempDir()
+	if got := guessTestCommand(dir); got != "" {
+		t.Errorf("no marker: got %q", got)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Cargo.toml"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := guessTestCommand(dir); got != "cargo test" {
+		t.Errorf("got %q, want cargo test from the given directory", got)
+	}
+}
+
+func TestTestTool(t *testing.T) {
+	h := NewTestHandler(shell.New(t.TempDir(), nil))
+	h.SetLimits(0, 2)
+
+	h.SetCommand(`printf '%s\n' '--- FAIL: TestX (0.00s)' '    x_test.go:3: boom' 'FAIL' 'FAIL	pkg	0.01s'; exit 1`)
+	result, err := h.Handle(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].Text
+	if !result.IsError {
+		t.Error("failing run not reported as an error")
+	}
+	for _, want := range []string{"FAIL (exit 1)", "tests: 1 failed, 0 skipped;", "- TestX\n", "First failure (lines 1-2 of 4):\n--- FAIL: TestX (0.00s)\n    x_test.go:3: boom\n"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("summary missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "passed") {
+		t.Errorf("passes counted without PASS lines:\n%s", text)
+	}
+
+	h.SetCommand(`printf '%s\n' 'ok  	pkg	0.01s'`)
+	result, err = h.Handle(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError || !strings.Contains(result.Content[0].Text, "packages: 1 ok, 0 failed") {
+		t.Errorf("passing run = %+v", result)
+	}
+}
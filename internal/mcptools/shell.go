@@ -45,6 +45,7 @@ type ShellHandler struct {
 	// OnOutput is called with incremental output chunks for real-time streaming.
 	// May be nil.
 	OnOutput func(chunk string)
+	dryRun   bool
 }
 
 // NewShellHandler creates a handler for the Shell tool.
@@ -52,6 +53,9 @@ func NewShellHandler(sh *shell.Shell) *ShellHandler {
 	return &ShellHandler{sh: sh}
 }
 
+// SetDryRun makes Handle run only read-only commands, skipping the rest.
+func (h *ShellHandler) SetDryRun(on bool) { h.dryRun = on }
+
 // Handle implements the mcp.ToolHandler interface.
 func (h *ShellHandler) Handle(ctx context.Context, arguments json.RawMessage) (*mcp.ToolResult, error) {
 	var args ShellArgs
@@ -61,7 +65,6 @@ func (h *ShellHandler) Handle(ctx context.Context, arguments json.RawMessage) (*
 	if args.Command == "" {
 		return toolError("command is required"), nil
 	}
-
 	timeout := 60
 	if args.Timeout > 0 {
 		timeout = args.Timeout
@@ -76,12 +79,17 @@ func (h *ShellHandler) Handle(ctx context.Context, arguments json.RawMessage) (*
 	// Execute command with streaming output.
 	var stdout, stderr bytes.Buffer
 
+	// A dry run only runs read-only commands and notes the others on stderr.
+	exec := h.sh.ExecStream
+	if h.dryRun {
+		exec = h.sh.ExecDryRun
+	}
 	var execErr error
 	if h.OnOutput != nil {
 		sw := &streamWriter{buf: &stdout, onChunk: h.OnOutput}
-		execErr = h.sh.ExecStream(ctx, args.Command, sw, &stderr)
+		execErr = exec(ctx, args.Command, sw, &stderr)
 	} else {
-		execErr = h.sh.ExecStream(ctx, args.Command, &stdout, &stderr)
+		execErr = exec(ctx, args.Command, &stdout, &stderr)
 	}
 
 	// Format result.
@@ -96,6 +104,9 @@ func (h *ShellHandler) Handle(ctx context.Context, arguments json.RawMessage) (*
 	if len([]rune(output)) > maxOutputChars {
 		output = truncateMiddle(output, maxOutputChars)
 	}
+	if h.dryRun {
+		output = "(dry run) Commands that change files were not run.\n" + output
+	}
 
 	if exitCode != 0 {
 		return &mcp.ToolResult{
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"sync/atomic"
 
 	"github.com/xonecas/symb/internal/delta"
+	"github.com/xonecas/symb/internal/fswatch"
+	"github.com/xonecas/symb/internal/llm"
 	"github.com/xonecas/symb/internal/lsp"
 	"github.com/xonecas/symb/internal/mcp"
 	"github.com/xonecas/symb/internal/provider"
@@ -30,7 +33,7 @@ func NewSubAgentTool() mcp.Tool {
 			"type": "object",
 			"properties": {
 				"prompt":         {"type": "string", "description": "Task description for the sub-agent. Be specific about what needs to be accomplished and the expected output format."},
-				"type":           {"type": "string", "enum": ["explore", "editor", "reviewer", "web"], "description": "Subagent type controls available tools and prompt. explore=read-only codebase search (Read, Grep, Shell); editor=surgical code changes (Read, Edit, Grep, Shell); reviewer=code review, read-only; web=documentation/API research (WebSearch, WebFetch). Omit for general tasks with all tools."},
+				"type":           {"type": "string", "enum": ["explore", "editor", "reviewer", "web"], "description": "Subagent type controls available tools and prompt. explore=read-only codebase search (Read, Grep, Outline, RecentFiles, Shell); editor=surgical code changes (Read, Edit, Grep, Outline, Shell, Test, Build); reviewer=code review, read-only; web=documentation/API research (WebSearch, WebFetch). Omit for general tasks with all tools."},
 				"max_iterations": {"type": "integer", "description": "Maximum tool rounds for the sub-agent (default: 5)"}
 			},
 			"required": ["prompt"]
@@ -46,6 +49,17 @@ type SubAgentHandler struct {
 	sh           *shell.Shell
 	allTools     []mcp.Tool
 	upstream     mcp.UpstreamClient
+	dryRun       bool
+	watcher      *fswatch.Watcher
+	test         *TestHandler
+	build        *BuildHandler
+
+	editWindowThreshold int
+	editWindowContext   int
+
+	// Read trackers of the running sub-agents; see Forget.
+	mu       sync.Mutex
+	trackers map[*FileReadTracker]struct{}
 }
 
 // NewSubAgentHandler creates a handler for the SubAgent tool.
@@ -75,6 +89,51 @@ func NewSubAgentHandler(
 	}
 }
 
+// SetDryRun makes the sub-agents' Edit and Shell calls previews only.
+func (h *SubAgentHandler) SetDryRun(on bool) { h.dryRun = on }
+
+// SetTestHandler shares the parent's Test tool with the sub-agents.
+func (h *SubAgentHandler) SetTestHandler(t *TestHandler) { h.test = t }
+
+// SetBuildHandler shares the parent's Build tool with the sub-agents.
+func (h *SubAgentHandler) SetBuildHandler(b *BuildHandler) { h.build = b }
+
+// SetEditWindow passes the Edit result window size to the sub-agents' Edit
+// handlers; see EditHandler.SetWindow.
+func (h *SubAgentHandler) SetEditWindow(threshold, contextLines int) {
+	h.editWindowThreshold, h.editWindowContext = threshold, contextLines
+}
+
+// SetWatcher passes w to the sub-agents' Edit handlers.
+func (h *SubAgentHandler) SetWatcher(w *fswatch.Watcher) { h.watcher = w }
+
+// Forget drops the read record for a file in every running sub-agent, so
+// it must be read again before it can be edited (used when the file
+// changes outside symb).
+func (h *SubAgentHandler) Forget(absPath string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for t := range h.trackers {
+		t.Forget(absPath)
+	}
+}
+
+// track registers a sub-agent's read tracker with Forget until the
+// returned func is called.
+func (h *SubAgentHandler) track(t *FileReadTracker) (untrack func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.trackers == nil {
+		h.trackers = make(map[*FileReadTracker]struct{})
+	}
+	h.trackers[t] = struct{}{}
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.trackers, t)
+	}
+}
+
 // Handle implements the mcp.ToolHandler interface.
 func (h *SubAgentHandler) Handle(ctx context.Context, arguments json.RawMessage) (*mcp.ToolResult, error) {
 	if err := ctx.Err(); err != nil {
@@ -91,11 +150,22 @@ func (h *SubAgentHandler) Handle(ctx context.Context, arguments json.RawMessage)
 
 	// Create isolated FileReadTracker for sub-agent
 	subTracker := NewFileReadTracker()
+	defer h.track(subTracker)()
 
 	// Create fresh handlers with isolated tracker
 	subReadHandler := NewReadHandler(subTracker, h.lspManager)
+	if h.deltaTracker != nil {
+		subReadHandler.SetAudit(h.deltaTracker)
+	}
 	subEditHandler := NewEditHandler(subTracker, h.lspManager, h.deltaTracker)
 	subShellHandler := NewShellHandler(h.sh)
+	subEditHandler.SetDryRun(h.dryRun)
+	subEditHandler.SetWatcher(h.watcher)
+	subEditHandler.SetWindow(h.editWindowThreshold, h.editWindowContext)
+	subShellHandler.SetDryRun(h.dryRun)
+	subReplaceHandler := NewBulkReplaceHandler(h.deltaTracker)
+	subReplaceHandler.SetDryRun(h.dryRun)
+	subReplaceHandler.SetWatcher(h.watcher)
 
 	// Create proxy with sub-agent tools (filtered - no nested SubAgent).
 	// Pass upstream so tools like web_search_exa can be dispatched.
@@ -109,10 +179,24 @@ func (h *SubAgentHandler) Handle(ctx context.Context, arguments json.RawMessage)
 			subProxy.RegisterTool(tool, subReadHandler.Handle)
 		case "Edit":
 			subProxy.RegisterTool(tool, subEditHandler.Handle)
+		case "BulkReplace":
+			subProxy.RegisterTool(tool, subReplaceHandler.Handle)
 		case "Shell":
 			subProxy.RegisterTool(tool, subShellHandler.Handle)
 		case "Grep":
 			subProxy.RegisterTool(tool, MakeGrepHandler())
+		case "Outline":
+			subProxy.RegisterTool(tool, NewOutlineHandler().Handle)
+		case "RecentFiles":
+			subProxy.RegisterTool(tool, MakeRecentFilesHandler())
+		case "Test":
+			if h.test != nil {
+				subProxy.RegisterTool(tool, h.test.Handle)
+			}
+		case "Build":
+			if h.build != nil {
+				subProxy.RegisterTool(tool, h.build.Handle)
+			}
 		case "TodoWrite":
 			// Sub-agents get their own scratchpad
 			subPad := &Scratchpad{}
@@ -120,6 +204,15 @@ func (h *SubAgentHandler) Handle(ctx context.Context, arguments json.RawMessage)
 		}
 	}
 
+	// The sub-agent's calls need the same approval as the caller's. The
+	// caller's context is used to ask, since the sub-agent's outlives it.
+	var confirm llm.ConfirmFunc
+	if parent := llm.ConfirmFrom(ctx); parent != nil {
+		confirm = func(_ context.Context, call provider.ToolCall) (bool, string) {
+			return parent(ctx, call)
+		}
+	}
+
 	subCtx, subCancel := context.WithCancel(context.Background())
 	defer subCancel()
 	result, err := subagent.Run(subCtx, subagent.Options{
@@ -129,6 +222,7 @@ func (h *SubAgentHandler) Handle(ctx context.Context, arguments json.RawMessage)
 		Prompt:        args.Prompt,
 		Type:          args.Type,
 		MaxIterations: args.MaxIterations,
+		Confirm:       confirm,
 	})
 	if err != nil {
 		return toolError("%v", err), nil
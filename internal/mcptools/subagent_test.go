@@ -0,0 +1,92 @@
+package mcptools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/xonecas/symb/internal/llm"
+	"github.com/xonecas/symb/internal/mcp"
+	"github.com/xonecas/symb/internal/provider"
+	"github.com/xonecas/symb/internal/shell"
+)
+
+// TestSubAgentForget verifies an outside change is forgotten by the read
+// trackers of running sub-agents only.
+func TestSubAgentForget(t *testing.T) {
+	h := &SubAgentHandler{}
+	running, done := NewFileReadTracker(), NewFileReadTracker()
+	running.MarkRead("/a")
+	done.MarkRead("/a")
+	defer h.track(running)()
+	h.track(done)()
+
+	h.Forget("/a")
+	if running.WasRead("/a") {
+		t.Error("running sub-agent still has /a read")
+	}
+	if !done.WasRead("/a") {
+		t.Error("finished sub-agent's tracker was still tracked")
+	}
+}
+
+// scriptedProvider replies with each of its responses in turn: a tool
+// call when it has a name, text otherwise.
+type scriptedProvider struct {
+	replies []provider.StreamEvent
+	calls   int
+}
+
+func (p *scriptedProvider) Name() string { return "scripted" }
+
+func (p *scriptedProvider) ChatStream(context.Context, []provider.Message, []provider.Tool) (<-chan provider.StreamEvent, error) {
+	reply := p.replies[min(p.calls, len(p.replies)-1)]
+	p.calls++
+	ch := make(chan provider.StreamEvent, 3)
+	if reply.ToolCallName != "" {
+		ch <- provider.StreamEvent{Type: provider.EventToolCallBegin, ToolCallID: "c1", ToolCallName: reply.ToolCallName}
+		ch <- provider.StreamEvent{Type: provider.EventToolCallDelta, ToolCallArgs: reply.ToolCallArgs}
+	} else {
+		ch <- provider.StreamEvent{Type: provider.EventContentDelta, Content: reply.Content}
+	}
+	ch <- provider.StreamEvent{Type: provider.EventDone}
+	close(ch)
+	return ch, nil
+}
+
+func (p *scriptedProvider) ListModels(context.Context) ([]provider.Model, error) { return nil, nil }
+
+func (p *scriptedProvider) Close() error { return nil }
+
+// TestSubAgentConfirm verifies a sub-agent's tool calls go through the
+// approval gate of the turn that called it.
+func TestSubAgentConfirm(t *testing.T) {
+	dir := t.TempDir()
+	var prov provider.Provider = &scriptedProvider{replies: []provider.StreamEvent{
+		{ToolCallName: "Shell", ToolCallArgs: `{"command":"touch made","description":"make a file"}`},
+		{Content: "done"},
+	}}
+	shared := &atomic.Pointer[provider.Provider]{}
+	shared.Store(&prov)
+	h := NewSubAgentHandler(shared, nil, nil, shell.New(dir, nil), []mcp.Tool{NewShellTool()}, nil)
+
+	var asked []string
+	confirm := func(_ context.Context, call provider.ToolCall) (bool, string) {
+		asked = append(asked, call.Name)
+		return false, "not approved"
+	}
+	args, _ := json.Marshal(SubAgentArgs{Prompt: "make a file", Type: "editor"})
+	res, err := h.Handle(llm.WithConfirm(context.Background(), confirm), args)
+	if err != nil || res.IsError {
+		t.Fatalf("Handle = %+v, %v", res, err)
+	}
+	if len(asked) != 1 || asked[0] != "Shell" {
+		t.Errorf("asked to approve %v, want the sub-agent's Shell call", asked)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "made")); !os.IsNotExist(err) {
+		t.Errorf("declined Shell call ran: %v", err)
+	}
+}
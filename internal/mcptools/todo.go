@@ -23,6 +23,13 @@ func (s *Scratchpad) Content() string {
 	return s.content
 }
 
+// SetContent replaces the scratchpad text.
+func (s *Scratchpad) SetContent(content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.content = content
+}
+
 // TodoWriteArgs represents arguments for the TodoWrite tool.
 type TodoWriteArgs struct {
 	Content string `json:"content"`
@@ -60,9 +67,7 @@ func MakeTodoWriteHandler(pad *Scratchpad) mcp.ToolHandler {
 			}, nil
 		}
 
-		pad.mu.Lock()
-		pad.content = args.Content
-		pad.mu.Unlock()
+		pad.SetContent(args.Content)
 
 		return &mcp.ToolResult{
 			Content: []mcp.ContentBlock{{Type: "text", Text: "Plan updated."}},
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	zen "github.com/sacenox/go-opencode-ai-zen-sdk"
+)
+
+// AuthError is a 401 or 403 from a provider: its API key is missing, wrong,
+// or lacks access. Error says which credentials key to fix; Err keeps the
+// raw response.
+type AuthError struct {
+	Provider   string
+	StatusCode int
+	HasKey     bool // whether an API key was sent
+	Err        error
+}
+
+func (e *AuthError) Error() string {
+	key := fmt.Sprintf("providers.%s.api_key", e.Provider)
+	if !e.HasKey {
+		return fmt.Sprintf("%s requires an API key but %s is not set; add it to ~/.config/symb/credentials.json: "+
+			`{"providers": {%q: {"api_key": "..."}}}`, e.Provider, key, e.Provider)
+	}
+	reason := "is invalid"
+	if e.StatusCode == 403 {
+		reason = "lacks access to this model or endpoint"
+	}
+	return fmt.Sprintf("%s rejected %s: the key %s; update it in ~/.config/symb/credentials.json", e.Provider, key, reason)
+}
+
+func (e *AuthError) Unwrap() error { return e.Err }
+
+// authError replaces a 401 or 403 err with an AuthError, logging the raw
+// response body the friendly message leaves out. Other errors pass through.
+func authError(name string, hasKey bool, err error) error {
+	status := HTTPStatus(err)
+	if status != 401 && status != 403 {
+		return err
+	}
+	evt := log.Error().Err(err).Str("provider", name).Int("status", status).Bool("has_api_key", hasKey)
+	var apiErr *zen.APIError
+	if errors.As(err, &apiErr) {
+		evt = evt.Str("body", string(apiErr.Body))
+	}
+	evt.Msg("provider rejected credentials")
+	return &AuthError{Provider: name, StatusCode: status, HasKey: hasKey, Err: err}
+}
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	zen "github.com/sacenox/go-opencode-ai-zen-sdk"
+)
+
+// StatusError is a non-2xx HTTP response from a provider endpoint.
+type StatusError struct {
+	Op         string // what was requested, e.g. "list models"
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s status %d: %s", e.Op, e.StatusCode, e.Body)
+}
+
+// HTTPStatus returns the HTTP status code carried by err, or 0 when err
+// didn't come from an HTTP response (e.g. connection refused).
+func HTTPStatus(err error) int {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.StatusCode
+	}
+	var apiErr *zen.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// Check verifies that p's endpoint is reachable and its credentials are
+// accepted. It lists models rather than chatting: that is fast, costs no
+// tokens, and needs no streaming. It returns how many models were listed.
+func Check(ctx context.Context, p Provider) (int, error) {
+	models, err := p.ListModels(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return len(models), nil
+}
@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultContextWindow is assumed for models the table does not know. It is
+// deliberately small so utilization errs on the side of running high.
+const DefaultContextWindow = 32_000
+
+// contextWindows maps model name prefixes to context window sizes in
+// tokens. The longest matching prefix wins.
+var contextWindows = map[string]int{
+	"claude":      200_000,
+	"gpt-5":       400_000,
+	"gpt-4.1":     1_047_576,
+	"gpt-4o":      128_000,
+	"gpt-oss":     131_072,
+	"o3":          200_000,
+	"o4-mini":     200_000,
+	"gemini":      1_048_576,
+	"qwen3-coder": 262_144,
+	"qwen3":       40_960,
+	"qwen2.5":     32_768,
+	"kimi-k2":     262_144,
+	"glm-5":       200_000,
+	"glm-4.6":     200_000,
+	"glm-4.5":     128_000,
+	"deepseek":    128_000,
+	"grok-code":   256_000,
+	"grok-4":      256_000,
+	"llama3.1":    128_000,
+	"llama3.2":    128_000,
+	"llama3":      8_192,
+	"mistral":     32_768,
+	"codestral":   256_000,
+	"devstral":    128_000,
+}
+
+var (
+	windowsMu       sync.Mutex
+	windowOverrides map[string]int
+	warnedModels    = make(map[string]bool)
+)
+
+// SetContextWindows sets context window sizes that take precedence over the
+// built-in table, keyed by model name or name prefix.
+func SetContextWindows(overrides map[string]int) {
+	windowsMu.Lock()
+	defer windowsMu.Unlock()
+	windowOverrides = make(map[string]int, len(overrides))
+	for k, v := range overrides {
+		windowOverrides[strings.ToLower(k)] = v
+	}
+}
+
+// ContextWindow returns the context window of model in tokens. Any
+// "vendor/" prefix is ignored. Unknown models get DefaultContextWindow, with
+// a warning logged once per model.
+func ContextWindow(model string) int {
+	name := strings.ToLower(model)
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+
+	windowsMu.Lock()
+	defer windowsMu.Unlock()
+	if n, ok := longestPrefix(windowOverrides, name); ok {
+		return n
+	}
+	if n, ok := longestPrefix(contextWindows, name); ok {
+		return n
+	}
+	if !warnedModels[name] {
+		warnedModels[name] = true
+		log.Warn().Str("model", model).Int("window", DefaultContextWindow).
+			Msg("unknown context window; set it under [context_windows]")
+	}
+	return DefaultContextWindow
+}
+
+// longestPrefix returns the value of the longest key of table that prefixes name.
+func longestPrefix[V any](table map[string]V, name string) (V, bool) {
+	best, v := -1, *new(V)
+	for prefix, value := range table {
+		if strings.HasPrefix(name, prefix) && len(prefix) > best {
+			best, v = len(prefix), value
+		}
+	}
+	return v, best >= 0
+}
@@ -0,0 +1,22 @@
+package provider
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// IsLocalEndpoint reports whether endpoint points at this machine
+// (localhost or a loopback address). Offline mode only allows these.
+func IsLocalEndpoint(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -15,5 +15,7 @@ func NewOllamaFactory(name string, endpoint string) *OllamaFactory {
 func (f *OllamaFactory) Name() string { return f.name }
 
 func (f *OllamaFactory) Create(model string, opts Options) Provider {
-	return NewOllamaWithTemp(f.name, f.endpoint, model, opts.Temperature)
+	p := NewOllamaWithTemp(f.name, f.endpoint, model, opts.Temperature)
+	p.httpClient = transcriptClient(opts.Transcript)
+	return p
 }
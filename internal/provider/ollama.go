@@ -22,14 +22,15 @@ type OllamaProvider struct {
 	baseURL     string
 	httpClient  *http.Client
 	model       string
-	temperature float64
+	temperature *float64 // nil leaves it to the server
 }
 
 func NewOllama(endpoint, model string) *OllamaProvider {
-	return NewOllamaWithTemp("ollama", endpoint, model, 0.7)
+	temp := 0.7
+	return NewOllamaWithTemp("ollama", endpoint, model, &temp)
 }
 
-func NewOllamaWithTemp(name string, endpoint, model string, temperature float64) *OllamaProvider {
+func NewOllamaWithTemp(name string, endpoint, model string, temperature *float64) *OllamaProvider {
 	baseURL := strings.TrimRight(endpoint, "/") + "/v1"
 
 	return &OllamaProvider{
@@ -50,10 +51,13 @@ func (p *OllamaProvider) ChatStream(ctx context.Context, messages []Message, too
 		Model:         p.model,
 		Messages:      mergeConsecutiveSystemMessages(toOllamaMessages(messages)),
 		Tools:         toOllamaTools(tools),
-		Temperature:   float32(p.temperature),
 		Stream:        true,
 		StreamOptions: &chatStreamOptions{IncludeUsage: true},
 	}
+	if p.temperature != nil {
+		temp := float32(*p.temperature)
+		req.Temperature = &temp
+	}
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
@@ -67,7 +71,7 @@ func (p *OllamaProvider) ChatStream(ctx context.Context, messages []Message, too
 		model:    p.model,
 	})
 	if err != nil {
-		return nil, err
+		return nil, authError(p.name, false, err)
 	}
 
 	ch := make(chan StreamEvent)
@@ -81,7 +85,7 @@ func (p *OllamaProvider) ChatStream(ctx context.Context, messages []Message, too
 }
 
 func (p *OllamaProvider) ListModels(ctx context.Context) ([]Model, error) {
-	baseURL := strings.TrimRight(p.baseURL, "/v1")
+	baseURL := strings.TrimSuffix(p.baseURL, "/v1")
 	url := baseURL + "/api/tags"
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -98,7 +102,7 @@ func (p *OllamaProvider) ListModels(ctx context.Context) ([]Model, error) {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("list models status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, authError(p.name, false, &StatusError{Op: "list models", StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))})
 	}
 
 	var listResp ollamaListResponse
@@ -152,7 +156,7 @@ type ollamaChatRequest struct {
 	Model         string             `json:"model"`
 	Messages      []ollamaReqMessage `json:"messages"`
 	Tools         []ollamaReqTool    `json:"tools,omitempty"`
-	Temperature   float32            `json:"temperature,omitempty"`
+	Temperature   *float32           `json:"temperature,omitempty"`
 	Stream        bool               `json:"stream"`
 	StreamOptions *chatStreamOptions `json:"stream_options,omitempty"`
 }
@@ -405,7 +409,7 @@ func sseAttempt(ctx context.Context, cfg httpRequestConfig, attempt int) (io.Rea
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		payload, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("stream request status %d: %s", resp.StatusCode, strings.TrimSpace(string(payload))), nil
+		return nil, &StatusError{Op: "stream request", StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(payload))}, nil
 	}
 
 	return resp.Body, nil, nil
@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestOllamaTemperature verifies an explicit zero temperature is sent and
+// a nil one is left out.
+func TestOllamaTemperature(t *testing.T) {
+	var got []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Error(err)
+		}
+		got = append(got, body)
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	zero := 0.0
+	for _, temp := range []*float64{&zero, nil} {
+		p := NewOllamaWithTemp("local", srv.URL, "m", temp)
+		events, err := p.ChatStream(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for range events {
+		}
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("requests = %d", len(got))
+	}
+	if v, ok := got[0]["temperature"]; !ok || v != 0.0 {
+		t.Errorf("explicit 0: temperature = %v, %v", v, ok)
+	}
+	if v, ok := got[1]["temperature"]; ok {
+		t.Errorf("nil: temperature = %v, want it left out", v)
+	}
+}
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"strings"
+	"sync"
+)
+
+// Price is what a model costs in USD per million tokens.
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+var (
+	pricesMu sync.Mutex
+	prices   map[string]Price
+)
+
+// SetPrices sets model prices keyed by model name or name prefix. There is
+// no built-in table: prices change too often to ship.
+func SetPrices(table map[string]Price) {
+	pricesMu.Lock()
+	defer pricesMu.Unlock()
+	prices = make(map[string]Price, len(table))
+	for k, v := range table {
+		prices[strings.ToLower(k)] = v
+	}
+}
+
+// Cost returns what inputTokens and outputTokens cost on model in USD, or
+// false if model has no price. Any "vendor/" prefix is ignored.
+func Cost(model string, inputTokens, outputTokens int) (float64, bool) {
+	name := strings.ToLower(model)
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+
+	pricesMu.Lock()
+	defer pricesMu.Unlock()
+	p, ok := longestPrefix(prices, name)
+	if !ok {
+		return 0, false
+	}
+	return (float64(inputTokens)*p.Input + float64(outputTokens)*p.Output) / 1e6, true
+}
@@ -0,0 +1,30 @@
+package provider
+
+import "testing"
+
+func TestCost(t *testing.T) {
+	defer SetPrices(nil)
+	SetPrices(map[string]Price{
+		"Claude-Sonnet":   {Input: 3, Output: 15},
+		"claude-sonnet-4": {Input: 2, Output: 10},
+		"gpt-4o":          {Input: 1, Output: 4},
+	})
+	tests := []struct {
+		model  string
+		want   float64
+		wantOK bool
+	}{
+		{"gpt-4o", 1 + 4, true},
+		{"openai/GPT-4o-mini", 1 + 4, true},           // vendor and case ignored, prefix match
+		{"claude-sonnet-4-5", 2 + 10, true},           // longest prefix wins
+		{"anthropic/claude-sonnet-3.7", 3 + 15, true}, // shorter prefix when the longer misses
+		{"llama3", 0, false},
+		{"4o", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := Cost(tt.model, 1e6, 1e6)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Cost(%q) = %v, %v, want %v, %v", tt.model, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -24,6 +25,17 @@ type Message struct {
 	CreatedAt    time.Time  // Message timestamp
 	InputTokens  int        // Token usage for this LLM call (assistant messages only)
 	OutputTokens int        // Token usage for this LLM call (assistant messages only)
+	IsError      bool       // For tool result messages: the call failed
+	Segments     []Segment  // For assistant messages: the order Reasoning and Content streamed in (nil if unknown)
+}
+
+// Segment is a run of reasoning or content a model streamed without
+// switching to the other. A message's segments split its Reasoning and
+// Content in arrival order, so models that think, answer and think again
+// are shown as they streamed.
+type Segment struct {
+	Reasoning bool `json:"reasoning,omitempty"`
+	Len       int  `json:"len"` // bytes of Reasoning or Content
 }
 
 // Tool represents a tool/function definition for the LLM.
@@ -48,6 +60,7 @@ type ChatResponse struct {
 	Reasoning    string     // Model reasoning content (optional)
 	InputTokens  int        // Input/prompt token count (0 if unavailable)
 	OutputTokens int        // Output/completion token count (0 if unavailable)
+	Segments     []Segment  // Streaming order of Reasoning and Content (nil if unknown)
 }
 
 // StreamEventType identifies the kind of streaming event.
@@ -153,7 +166,32 @@ func (r *Registry) Create(name, model string, opts Options) (Provider, error) {
 
 // Options holds provider generation settings.
 type Options struct {
-	Temperature float64
+	// Temperature is sent with every request; nil leaves it to the
+	// provider, so an explicit 0 is kept.
+	Temperature *float64
+	// Transcript receives every raw request and response when non-nil.
+	Transcript io.Writer
+	// StripReasoning leaves thinking artifacts (Gemini thought signatures on
+	// tool calls of earlier turns) out of the history sent back to the
+	// provider; the turn in progress keeps them, as Gemini requires.
+	// Reasoning text is shown and stored but never replayed either way.
+	StripReasoning bool
+}
+
+// ConfiguredTemperature turns a config temperature, where 0 means unset,
+// into an Options.Temperature.
+func ConfiguredTemperature(t float64) *float64 {
+	if t == 0 {
+		return nil
+	}
+	return &t
+}
+
+// SessionTranscript is a Transcript kept per session. Forking a session
+// moves it onto the new session's transcript.
+type SessionTranscript interface {
+	io.Writer
+	SetSession(sessionID string) error
 }
 
 // List returns all registered provider names.
@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// transcriptTransport copies every HTTP exchange into a transcript: the
+// request line and body, the response status, then the raw response body
+// (SSE events included) as the caller reads it. Headers are never written
+// since they carry credentials. Lines are tagged "#N" per exchange so
+// concurrent streams (sub-agents) stay readable.
+type transcriptTransport struct {
+	base http.RoundTripper
+	w    *transcriptWriter
+}
+
+type transcriptWriter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	next atomic.Int64
+}
+
+// transcriptClient returns an HTTP client that records exchanges to w, or a
+// plain client when w is nil.
+func transcriptClient(w io.Writer) *http.Client {
+	if w == nil {
+		return &http.Client{}
+	}
+	return &http.Client{Transport: &transcriptTransport{
+		base: http.DefaultTransport,
+		w:    &transcriptWriter{w: w},
+	}}
+}
+
+func (tw *transcriptWriter) line(id int64, dir string, text []byte) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	fmt.Fprintf(tw.w, "#%d %s %s\n", id, dir, bytes.TrimRight(text, "\r"))
+}
+
+func (t *transcriptTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := t.w.next.Add(1)
+	t.w.line(id, "=", fmt.Appendf(nil, "%s %s %s", time.Now().Format(time.RFC3339), req.Method, req.URL))
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			t.w.line(id, ">", data)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.w.line(id, "!", []byte(err.Error()))
+		return nil, err
+	}
+	t.w.line(id, "<", []byte(resp.Status))
+	resp.Body = &transcriptBody{ReadCloser: resp.Body, w: t.w, id: id}
+	return resp, nil
+}
+
+// transcriptBody writes the response body to the transcript line by line.
+type transcriptBody struct {
+	io.ReadCloser
+	w       *transcriptWriter
+	id      int64
+	partial []byte
+}
+
+func (b *transcriptBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.partial = append(b.partial, p[:n]...)
+	for {
+		i := bytes.IndexByte(b.partial, '\n')
+		if i < 0 {
+			break
+		}
+		if i > 0 {
+			b.w.line(b.id, "<", b.partial[:i])
+		}
+		b.partial = b.partial[i+1:]
+	}
+	return n, err
+}
+
+func (b *transcriptBody) Close() error {
+	if len(b.partial) > 0 {
+		b.w.line(b.id, "<", b.partial)
+		b.partial = nil
+	}
+	return b.ReadCloser.Close()
+}
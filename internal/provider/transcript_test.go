@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTranscriptClient_RecordsExchange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			t.Error("request headers not passed through")
+		}
+		io.WriteString(w, "data: one\r\n\ndata: two")
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`{"q":1}`))
+	req.Header.Set("Authorization", "Bearer sk-never-written")
+	resp, err := transcriptClient(&buf).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != "data: one\r\n\ndata: two" {
+		t.Errorf("body = %q, want it unchanged", body)
+	}
+	resp.Body.Close()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	want := []string{"#1 > {\"q\":1}", "#1 < 200 OK", "#1 < data: one", "#1 < data: two"}
+	if len(lines) != 5 || !strings.HasPrefix(lines[0], "#1 = ") || !strings.HasSuffix(lines[0], "POST "+srv.URL) {
+		t.Fatalf("transcript = %q", lines)
+	}
+	for i, w := range want {
+		if lines[i+1] != w {
+			t.Errorf("line %d = %q, want %q", i+1, lines[i+1], w)
+		}
+	}
+	if strings.Contains(buf.String(), "sk-never-written") {
+		t.Error("headers written to transcript")
+	}
+}
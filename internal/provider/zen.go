@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"strings"
 
 	"github.com/rs/zerolog/log"
@@ -14,14 +15,20 @@ type ZenProvider struct {
 	name        string
 	client      *zen.Client
 	model       string
-	temperature float64
+	temperature *float64 // nil leaves it to the provider
+	// stripReasoning drops thought signatures from the tool calls of
+	// earlier turns.
+	stripReasoning bool
 }
 
-func NewZen(name, apiKey, baseURL, model string, temperature float64) (*ZenProvider, error) {
+func NewZen(name, apiKey, baseURL, model string, temperature *float64, transcript io.Writer) (*ZenProvider, error) {
 	cfg := zen.Config{
 		APIKey:  apiKey,
 		BaseURL: baseURL,
 	}
+	if transcript != nil {
+		cfg.HTTPClient = transcriptClient(transcript)
+	}
 	client, err := zen.NewClient(cfg)
 	if err != nil {
 		return nil, err
@@ -43,16 +50,13 @@ func (p *ZenProvider) ChatStream(ctx context.Context, messages []Message, tools
 	system, rest := splitSystem(messages)
 
 	req := zen.NormalizedRequest{
-		Model:      p.model,
-		System:     system,
-		Messages:   toZenMessages(rest),
-		Tools:      toZenTools(tools),
-		Reasoning:  &zen.NormalizedReasoning{Effort: "low"},
-		ToolChoice: &zen.NormalizedToolChoice{Type: zen.ToolChoiceAuto},
-	}
-
-	if p.temperature > 0 {
-		req.Temperature = &p.temperature
+		Model:       p.model,
+		System:      system,
+		Messages:    toZenMessages(rest, p.stripReasoning),
+		Tools:       toZenTools(tools),
+		Reasoning:   &zen.NormalizedReasoning{Effort: "low"},
+		ToolChoice:  &zen.NormalizedToolChoice{Type: zen.ToolChoiceAuto},
+		Temperature: p.temperature,
 	}
 
 	deltas, errs, err := p.client.Stream(ctx, req)
@@ -65,7 +69,7 @@ func (p *ZenProvider) ChatStream(ctx context.Context, messages []Message, tools
 				Str("model", p.model).
 				Msg("zen: Stream failed")
 		}
-		return nil, err
+		return nil, authError(p.name, true, err)
 	}
 
 	ch := make(chan StreamEvent)
@@ -125,7 +129,7 @@ func (p *ZenProvider) ChatStream(ctx context.Context, messages []Message, tools
 					Str("body", string(apiErr.Body)).
 					Msg("zen: stream API error")
 			}
-			trySend(ctx, ch, StreamEvent{Type: EventError, Err: err})
+			trySend(ctx, ch, StreamEvent{Type: EventError, Err: authError(p.name, true, err)})
 		}
 	}()
 
@@ -136,7 +140,7 @@ func (p *ZenProvider) ListModels(ctx context.Context) ([]Model, error) {
 	resp, err := p.client.ListModels(ctx)
 	if err != nil {
 		log.Error().Err(err).Str("provider", p.name).Msg("ListModels failed")
-		return nil, err
+		return nil, authError(p.name, true, err)
 	}
 
 	models := make([]Model, len(resp.Data))
@@ -167,7 +171,17 @@ func splitSystem(messages []Message) (system string, rest []Message) {
 	return strings.Join(parts, "\n\n"), rest
 }
 
-func toZenMessages(messages []Message) []zen.NormalizedMessage {
+// toZenMessages converts history for the SDK. With stripReasoning, tool
+// calls from earlier turns are replayed without their thought signatures.
+// The current turn, from the last user message on, keeps them: Gemini
+// rejects a function call of the turn in progress that lacks its signature.
+func toZenMessages(messages []Message, stripReasoning bool) []zen.NormalizedMessage {
+	turnStart := 0
+	for i, m := range messages {
+		if m.Role == "user" {
+			turnStart = i
+		}
+	}
 	result := make([]zen.NormalizedMessage, len(messages))
 	for i, m := range messages {
 		nm := zen.NormalizedMessage{
@@ -185,6 +199,9 @@ func toZenMessages(messages []Message) []zen.NormalizedMessage {
 					Arguments:        tc.Arguments,
 					ThoughtSignature: tc.ThoughtSignature,
 				}
+				if stripReasoning && i < turnStart {
+					nm.ToolCalls[j].ThoughtSignature = ""
+				}
 			}
 		}
 		result[i] = nm
@@ -268,9 +285,10 @@ func (f *ZenFactory) Create(model string, opts Options) Provider {
 		Str("base_url", baseURL).
 		Msg("ZenFactory.Create")
 
-	p, err := NewZen(f.name, f.apiKey, baseURL, model, opts.Temperature)
+	p, err := NewZen(f.name, f.apiKey, baseURL, model, opts.Temperature, opts.Transcript)
 	if err != nil {
 		panic("zen: failed to create provider: " + err.Error())
 	}
+	p.stripReasoning = opts.StripReasoning
 	return p
 }
@@ -0,0 +1,36 @@
+package provider
+
+import "testing"
+
+func TestToZenMessages_StripReasoning(t *testing.T) {
+	call := func(sig string) []ToolCall { return []ToolCall{{ID: "1", Name: "Read", ThoughtSignature: sig}} }
+	history := []Message{
+		{Role: "user", Content: "first"},
+		{Role: "assistant", ToolCalls: call("old")},
+		{Role: "tool", ToolCallID: "1", Content: "ok"},
+		{Role: "user", Content: "second"},
+		{Role: "assistant", ToolCalls: call("current")},
+		{Role: "tool", ToolCallID: "1", Content: "ok"},
+	}
+	for _, tt := range []struct {
+		strip        bool
+		old, current string
+	}{
+		{false, "old", "current"},
+		{true, "", "current"},
+	} {
+		got := toZenMessages(history, tt.strip)
+		if len(got) != len(history) {
+			t.Fatalf("strip=%v: %d messages, want %d", tt.strip, len(got), len(history))
+		}
+		if sig := got[1].ToolCalls[0].ThoughtSignature; sig != tt.old {
+			t.Errorf("strip=%v: earlier turn signature = %q, want %q", tt.strip, sig, tt.old)
+		}
+		if sig := got[4].ToolCalls[0].ThoughtSignature; sig != tt.current {
+			t.Errorf("strip=%v: current turn signature = %q, want %q", tt.strip, sig, tt.current)
+		}
+		if got[2].ToolCallID != "1" || got[3].Content != "second" {
+			t.Errorf("strip=%v: messages changed: %+v", tt.strip, got)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package shell
+
+import (
+	"slices"
+	"strings"
+)
+
+// readOnlyCommands only read files and print, whatever their arguments,
+// apart from the flags listed in writeFlags.
+var readOnlyCommands = []string{
+	"basename", "cat", "cmp", "column", "comm", "cut", "date", "df", "diff",
+	"dirname", "du", "echo", "egrep", "false", "fgrep", "file", "find",
+	"fold", "grep", "head", "jq", "ls", "nl", "od", "paste", "printf", "pwd",
+	"readlink", "realpath", "rg", "sed", "seq", "sort", "stat", "tail", "test",
+	"tr", "tree", "true", "uname", "wc", "which", "whoami", "[",
+}
+
+// writeFlags are the flags that make a read-only command write files or
+// run another program. A single letter matches anywhere in a short-flag
+// cluster, e.g. "i" in "sed -ni"; a "--" flag also matches an abbreviation
+// of it or one with "=value"; others, such as find's, match whole.
+var writeFlags = map[string][]string{
+	"date": {"s", "--set"},
+	"file": {"C", "--compile"},
+	"find": {"-delete", "-exec", "-execdir", "-ok", "-okdir", "-fprint", "-fprint0", "-fprintf", "-fls"},
+	"git":  {"O", "--open-files-in-pager", "--output"},
+	"rg":   {"--pre"},
+	"sed":  {"i", "--in-place"},
+	"sort": {"o", "--output", "--compress-program"},
+	"tree": {"o"},
+}
+
+// readOnlySubcommands are the subcommands that only read, for commands
+// whose other subcommands change things.
+var readOnlySubcommands = map[string][]string{
+	"git": {"blame", "cat-file", "describe", "diff", "grep", "log", "ls-files", "ls-tree", "rev-parse", "shortlog", "show", "status"},
+	"go":  {"doc", "env", "list", "version", "vet"},
+}
+
+// ReadOnly reports whether a command only reads: a known read-only command
+// without a flag that writes, or a read-only subcommand such as "git log".
+// Unknown commands are assumed to change something.
+func ReadOnly(args []string) bool {
+	if len(args) == 0 {
+		return true
+	}
+	if subs, ok := readOnlySubcommands[args[0]]; ok {
+		if len(args) < 2 || !slices.Contains(subs, args[1]) {
+			return false
+		}
+		// "go env -w" writes the go env file.
+		if args[0] == "go" && args[1] == "env" && slices.Contains(args, "-w") {
+			return false
+		}
+		return !hasWriteFlag(args[0], args[2:])
+	}
+	if !slices.Contains(readOnlyCommands, args[0]) || hasWriteFlag(args[0], args[1:]) {
+		return false
+	}
+	return args[0] != "sed" || !sedWrites(args[1:])
+}
+
+// hasWriteFlag reports whether args hold one of cmd's writeFlags.
+func hasWriteFlag(cmd string, args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		name, _, _ := strings.Cut(arg, "=")
+		for _, flag := range writeFlags[cmd] {
+			switch {
+			case len(flag) == 1:
+				if len(arg) > 1 && arg[0] == '-' && arg[1] != '-' && strings.Contains(arg[1:], flag) {
+					return true
+				}
+			case strings.HasPrefix(flag, "--"):
+				if len(name) > 2 && strings.HasPrefix(flag, name) {
+					return true
+				}
+			case arg == flag:
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sedWrites reports whether sed's scripts can write a file or run a
+// command: a w, W or e command, or an s command with the w or e flag. A
+// script read from a file (-f) can't be checked, so it counts as writing.
+func sedWrites(args []string) bool {
+	var scripts []string
+	expression := false // a script came from -e, so none is positional
+	positional := -1
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			if !expression && positional < 0 && i+1 < len(args) {
+				positional = i + 1
+			}
+			i = len(args)
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg, "=")
+			switch {
+			case len(name) > 2 && strings.HasPrefix("--file", name):
+				return true
+			case len(name) > 2 && strings.HasPrefix("--expression", name):
+				expression = true
+				if !hasValue && i+1 < len(args) {
+					i++
+					value = args[i]
+				}
+				scripts = append(scripts, value)
+			}
+		case len(arg) > 1 && arg[0] == '-':
+			// In a cluster such as "-ne", e, f and l take the rest of the
+			// cluster or the next argument as their value.
+			if j := strings.IndexAny(arg, "efl"); j > 0 {
+				value := arg[j+1:]
+				if value == "" && i+1 < len(args) {
+					i++
+					value = args[i]
+				}
+				switch arg[j] {
+				case 'e':
+					expression = true
+					scripts = append(scripts, value)
+				case 'f':
+					return true
+				}
+			}
+		default:
+			if positional < 0 {
+				positional = i
+			}
+		}
+	}
+	if !expression && positional >= 0 {
+		scripts = append(scripts, args[positional])
+	}
+	return slices.ContainsFunc(scripts, sedScriptWrites)
+}
+
+// sedScriptWrites reports whether one sed script has a w, W or e command
+// or an s command with the w or e flag. Regular expressions, replacements,
+// text and labels are skipped, so a "w" in them doesn't count.
+func sedScriptWrites(script string) bool {
+	for i := 0; i < len(script); i++ {
+		switch c := script[i]; c {
+		case '/':
+			i = skipDelimited(script, i+1, '/')
+		case '\\':
+			if i+1 < len(script) {
+				i = skipDelimited(script, i+2, script[i+1])
+			}
+		case 's', 'y':
+			if i+1 >= len(script) {
+				return false
+			}
+			delim := script[i+1]
+			i = skipDelimited(script, i+2, delim)
+			i = skipDelimited(script, i+1, delim)
+			for i+1 < len(script) && !strings.ContainsRune(";\n}", rune(script[i+1])) {
+				i++
+				if c == 's' && (script[i] == 'w' || script[i] == 'e') {
+					return true
+				}
+			}
+		case 'w', 'W', 'e':
+			return true
+		case 'a', 'i', 'c', 'r', 'R', '#':
+			// Text, a file name or a comment: the rest of the line.
+			i = skipDelimited(script, i+1, '\n')
+		case 'b', 't', 'T', ':':
+			// A label ends at the line or a ";".
+			for i+1 < len(script) && script[i+1] != '\n' && script[i+1] != ';' {
+				i++
+			}
+		}
+	}
+	return false
+}
+
+// skipDelimited returns the index of the first unescaped delim in s at or
+// after i, or len(s) if there is none.
+func skipDelimited(s string, i int, delim byte) int {
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case delim:
+			return i
+		}
+	}
+	return len(s)
+}
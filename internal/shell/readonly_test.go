@@ -0,0 +1,97 @@
+package shell
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadOnly(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"ls", "-la"}, true},
+		{[]string{"grep", "-rn", "TODO", "."}, true},
+		{[]string{"sed", "-n", "1,5p", "f.go"}, true},
+		{[]string{"sed", "-i", "s/a/b/", "f.go"}, false},
+		{[]string{"sed", "-i.bak", "s/a/b/", "f.go"}, false},
+		{[]string{"sort", "-o", "out", "in"}, false},
+		{[]string{"sort", "--output=out", "in"}, false},
+		{[]string{"sed", "-ni", "s/a/b/", "f.go"}, false},
+		{[]string{"sed", "-Ei", "s/a+/b/", "f.go"}, false},
+		{[]string{"sed", "--in-pl", "s/a/b/", "f.go"}, false},
+		{[]string{"sed", "-n", "w out", "f.go"}, false},
+		{[]string{"sed", "-n", "1,5W out", "f.go"}, false},
+		{[]string{"sed", "-n", "/x/w out", "f.go"}, false},
+		{[]string{"sed", "s/a/b/gw out", "f.go"}, false},
+		{[]string{"sed", "s/a/date/e", "f.go"}, false},
+		{[]string{"sed", "-e", "p", "-e", "$w out", "f.go"}, false},
+		{[]string{"sed", "--expression=1w out", "f.go"}, false},
+		{[]string{"sed", "-f", "script.sed", "f.go"}, false},
+		{[]string{"sed", "-n", "/warning/p", "f.go"}, true},
+		{[]string{"sed", "s/who/what/g;s|w|x|", "f.go"}, true},
+		{[]string{"sed", "-ne", "/w/p", "f.go"}, true},
+		{[]string{"sed", "1i\\write this", "f.go"}, true},
+		{[]string{"sort", "-uo", "out", "in"}, false},
+		{[]string{"sort", "--out=out", "in"}, false},
+		{[]string{"sort", "-rn", "in"}, true},
+		{[]string{"tree", "-o", "out"}, false},
+		{[]string{"tree", "-L", "2"}, true},
+		{[]string{"date", "-s", "2020-01-01"}, false},
+		{[]string{"date", "+%s"}, true},
+		{[]string{"rg", "--pre=sh", "x"}, false},
+		{[]string{"find", ".", "-name", "*.go"}, true},
+		{[]string{"find", ".", "-delete"}, false},
+		{[]string{"git", "log", "--oneline"}, true},
+		{[]string{"git", "diff", "-w"}, true},
+		{[]string{"git", "diff", "--output=x.patch"}, false},
+		{[]string{"git", "log", "--output", "log.txt"}, false},
+		{[]string{"git", "grep", "-O", "TODO"}, false},
+		{[]string{"git", "commit", "-m", "x"}, false},
+		{[]string{"git"}, false},
+		{[]string{"go", "vet", "./..."}, true},
+		{[]string{"go", "env", "-w", "GOFLAGS=-mod=mod"}, false},
+		{[]string{"go", "build"}, false},
+		{[]string{"rm", "-rf", "x"}, false},
+		{[]string{"make"}, false},
+		{nil, true},
+	}
+	for _, tt := range tests {
+		if got := ReadOnly(tt.args); got != tt.want {
+			t.Errorf("ReadOnly(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestExecDryRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sh := New(dir, DefaultBlockFuncs())
+
+	var stdout, stderr strings.Builder
+	err := sh.ExecDryRun(context.Background(), "cat a.txt; rm a.txt; echo hi > b.txt; echo done", &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "hello\ndone\n" {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "not run: rm a.txt") || !strings.Contains(stderr.String(), "not written: ") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.txt")); err != nil {
+		t.Errorf("dry run removed a.txt: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b.txt")); !os.IsNotExist(err) {
+		t.Errorf("dry run wrote b.txt: %v", err)
+	}
+
+	if err := sh.ExecDryRun(context.Background(), "sudo ls", &stdout, &stderr); err == nil {
+		t.Error("blocked command ran in a dry run")
+	}
+}
@@ -44,7 +44,7 @@ func (s *Shell) Exec(ctx context.Context, command string) (string, string, error
 	defer s.mu.Unlock()
 
 	var stdout, stderr bytes.Buffer
-	err := s.execCommon(ctx, command, &stdout, &stderr)
+	err := s.execCommon(ctx, command, &stdout, &stderr, false)
 	return stdout.String(), stderr.String(), err
 }
 
@@ -53,7 +53,25 @@ func (s *Shell) ExecStream(ctx context.Context, command string, stdout, stderr i
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	return s.execCommon(ctx, command, stdout, stderr)
+	return s.execCommon(ctx, command, stdout, stderr, false)
+}
+
+// ExecDryRun runs a command, streaming output to the provided writers, but
+// only runs the commands ReadOnly accepts. Other commands and redirections
+// that write a file are skipped with a note on stderr.
+func (s *Shell) ExecDryRun(ctx context.Context, command string, stdout, stderr io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.execCommon(ctx, command, stdout, stderr, true)
+}
+
+// SetRoot re-anchors the shell at dir, resetting its working directory.
+func (s *Shell) SetRoot(dir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.root = dir
+	s.cwd = dir
 }
 
 // Dir returns the current working directory.
@@ -63,7 +81,7 @@ func (s *Shell) Dir() string {
 	return s.cwd
 }
 
-func (s *Shell) execCommon(ctx context.Context, command string, stdout, stderr io.Writer) (err error) {
+func (s *Shell) execCommon(ctx context.Context, command string, stdout, stderr io.Writer, dryRun bool) (err error) {
 	var runner *interp.Runner
 	defer func() {
 		if r := recover(); r != nil {
@@ -79,7 +97,7 @@ func (s *Shell) execCommon(ctx context.Context, command string, stdout, stderr i
 		return fmt.Errorf("could not parse command: %w", err)
 	}
 
-	runner, err = s.newInterp(stdout, stderr)
+	runner, err = s.newInterp(stdout, stderr, dryRun)
 	if err != nil {
 		return fmt.Errorf("could not create interpreter: %w", err)
 	}
@@ -87,17 +105,23 @@ func (s *Shell) execCommon(ctx context.Context, command string, stdout, stderr i
 	return runner.Run(ctx, parsed)
 }
 
-func (s *Shell) newInterp(stdout, stderr io.Writer) (*interp.Runner, error) {
-	return interp.New(
+func (s *Shell) newInterp(stdout, stderr io.Writer, dryRun bool) (*interp.Runner, error) {
+	opts := []interp.RunnerOption{
 		interp.StdIO(nil, stdout, stderr),
 		interp.Interactive(false),
 		interp.Env(expand.ListEnviron(s.env...)),
 		interp.Dir(s.cwd),
-		interp.ExecHandlers(s.blockHandler()),
-	)
+		interp.ExecHandlers(s.blockHandler(dryRun)),
+	}
+	if dryRun {
+		opts = append(opts, interp.OpenHandler(dryRunOpenHandler))
+	}
+	return interp.New(opts...)
 }
 
-func (s *Shell) blockHandler() func(next interp.ExecHandlerFunc) interp.ExecHandlerFunc {
+// blockHandler refuses blocked commands and, in a dry run, skips the ones
+// ReadOnly does not accept.
+func (s *Shell) blockHandler(dryRun bool) func(next interp.ExecHandlerFunc) interp.ExecHandlerFunc {
 	return func(next interp.ExecHandlerFunc) interp.ExecHandlerFunc {
 		return func(ctx context.Context, args []string) error {
 			if len(args) == 0 {
@@ -108,11 +132,32 @@ func (s *Shell) blockHandler() func(next interp.ExecHandlerFunc) interp.ExecHand
 					return fmt.Errorf("command blocked: %q", args[0])
 				}
 			}
+			if dryRun && !ReadOnly(args) {
+				fmt.Fprintf(interp.HandlerCtx(ctx).Stderr, "(dry run) not run: %s\n", strings.Join(args, " "))
+				return nil
+			}
 			return next(ctx, args)
 		}
 	}
 }
 
+// dryRunOpenHandler opens files for reading as usual, but turns a
+// redirection that would write a file into a note on stderr.
+func dryRunOpenHandler(ctx context.Context, path string, flag int, perm os.FileMode) (io.ReadWriteCloser, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 && path != os.DevNull {
+		fmt.Fprintf(interp.HandlerCtx(ctx).Stderr, "(dry run) not written: %s\n", path)
+		return discard{}, nil
+	}
+	return interp.DefaultOpenHandler()(ctx, path, flag, perm)
+}
+
+// discard swallows the output of a redirection skipped in a dry run.
+type discard struct{}
+
+func (discard) Read([]byte) (int, error)    { return 0, io.EOF }
+func (discard) Write(p []byte) (int, error) { return len(p), nil }
+func (discard) Close() error                { return nil }
+
 // updateFromRunner persists cwd and exported env vars after execution.
 // If the runner's cwd escaped the project root, it is clamped back and a
 // warning is written to stderr so the LLM knows.
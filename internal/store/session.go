@@ -1,8 +1,13 @@
 package store
 
 import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math"
+	"slices"
 	"strings"
 	"time"
 
@@ -34,6 +39,18 @@ type SessionMessage struct {
 	CreatedAt    time.Time
 	InputTokens  int
 	OutputTokens int
+	IsError      bool            // tool result from a failed call
+	Segments     json.RawMessage // JSON array of provider.Segment; empty if unknown
+}
+
+// NewSessionID returns a random 32-character hex session ID.
+func NewSessionID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Warn().Err(err).Msg("failed to read random bytes for session id")
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
 }
 
 // CreateSession inserts a new session and returns its ID.
@@ -64,18 +81,26 @@ func (c *Cache) SaveMessage(sessionID string, msg SessionMessage) {
 
 // SaveMessages persists a batch of messages atomically.
 func (c *Cache) SaveMessages(sessionID string, msgs []SessionMessage) error {
+	_, err := c.SaveMessagesSync(sessionID, msgs)
+	return err
+}
+
+// SaveMessagesSync persists a batch of messages atomically and returns the
+// DB row ID of the last one.
+func (c *Cache) SaveMessagesSync(sessionID string, msgs []SessionMessage) (int64, error) {
 	if c == nil || len(msgs) == 0 {
-		return nil
+		return 0, nil
 	}
 
 	var err error
 	for attempt := 0; attempt <= SQLiteBusyMaxRetries; attempt++ {
-		err = c.saveMessagesOnce(sessionID, msgs)
+		var id int64
+		id, err = c.saveMessagesOnce(sessionID, msgs)
 		if err == nil {
-			return nil
+			return id, nil
 		}
 		if !IsSQLiteBusy(err) || attempt == SQLiteBusyMaxRetries {
-			return err
+			return 0, err
 		}
 		backoff := time.Duration((attempt+1)*SQLiteBusyBackoffStepMs) * time.Millisecond
 		if backoff > SQLiteBusyMaxBackoff {
@@ -83,7 +108,7 @@ func (c *Cache) SaveMessages(sessionID string, msgs []SessionMessage) error {
 		}
 		time.Sleep(backoff)
 	}
-	return err
+	return 0, err
 }
 
 // SaveMessageSync persists a message synchronously and returns its DB row ID.
@@ -112,30 +137,35 @@ func (c *Cache) SaveMessageSync(sessionID string, msg SessionMessage) (int64, er
 	return 0, err
 }
 
-func (c *Cache) saveMessagesOnce(sessionID string, msgs []SessionMessage) error {
+func (c *Cache) saveMessagesOnce(sessionID string, msgs []SessionMessage) (int64, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	tx, err := c.db.Begin()
 	if err != nil {
-		return err
+		return 0, err
 	}
 
+	var id int64
 	for _, msg := range msgs {
 		tc := msg.ToolCalls
 		if tc == nil {
 			tc = json.RawMessage("[]")
 		}
-		if _, err := tx.Exec(
-			`INSERT INTO messages (session_id, role, content, reasoning, tool_calls, tool_call_id, created, input_tokens, output_tokens)
-			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		res, err := tx.Exec(
+			`INSERT INTO messages (session_id, role, content, reasoning, tool_calls, tool_call_id, created, input_tokens, output_tokens, is_error, segments)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 			sessionID, msg.Role, msg.Content, msg.Reasoning, string(tc), msg.ToolCallID, msg.CreatedAt.Unix(),
-			msg.InputTokens, msg.OutputTokens,
-		); err != nil {
+			msg.InputTokens, msg.OutputTokens, msg.IsError, string(msg.Segments),
+		)
+		if err == nil {
+			id, err = res.LastInsertId()
+		}
+		if err != nil {
 			if rbErr := tx.Rollback(); rbErr != nil {
 				log.Warn().Err(rbErr).Msg("failed to rollback message save")
 			}
-			return err
+			return 0, err
 		}
 	}
 
@@ -143,16 +173,16 @@ func (c *Cache) saveMessagesOnce(sessionID string, msgs []SessionMessage) error
 		if rbErr := tx.Rollback(); rbErr != nil {
 			log.Warn().Err(rbErr).Msg("failed to rollback message save")
 		}
-		return err
+		return 0, err
 	}
 
 	if err := tx.Commit(); err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
 			log.Warn().Err(rbErr).Msg("failed to rollback message save")
 		}
-		return err
+		return 0, err
 	}
-	return nil
+	return id, nil
 }
 
 func (c *Cache) saveMessageSyncOnce(sessionID string, msg SessionMessage) (int64, error) {
@@ -170,10 +200,10 @@ func (c *Cache) saveMessageSyncOnce(sessionID string, msg SessionMessage) (int64
 	}
 
 	res, err := tx.Exec(
-		`INSERT INTO messages (session_id, role, content, reasoning, tool_calls, tool_call_id, created, input_tokens, output_tokens)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO messages (session_id, role, content, reasoning, tool_calls, tool_call_id, created, input_tokens, output_tokens, is_error, segments)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		sessionID, msg.Role, msg.Content, msg.Reasoning, string(tc), msg.ToolCallID, msg.CreatedAt.Unix(),
-		msg.InputTokens, msg.OutputTokens,
+		msg.InputTokens, msg.OutputTokens, msg.IsError, string(msg.Segments),
 	)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
@@ -222,6 +252,21 @@ func (c *Cache) DeleteMessagesFrom(sessionID string, minID int64) error {
 	return err
 }
 
+// SaveSystemPrompt replaces the content of a session's system message.
+func (c *Cache) SaveSystemPrompt(sessionID, content string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err := c.db.Exec(
+		"UPDATE messages SET content = ? WHERE session_id = ? AND role = 'system'",
+		content, sessionID,
+	)
+	return err
+}
+
 // LoadLastMessage returns the most recent message for a session, or nil if none.
 func (c *Cache) LoadLastMessage(sessionID string) (*SessionMessage, error) {
 	if c == nil {
@@ -231,16 +276,17 @@ func (c *Cache) LoadLastMessage(sessionID string) (*SessionMessage, error) {
 	defer c.mu.Unlock()
 
 	var m SessionMessage
-	var tc string
+	var tc, segs string
 	var created int64
 	err := c.db.QueryRow(
-		`SELECT role, content, reasoning, tool_calls, tool_call_id, created, input_tokens, output_tokens
+		`SELECT role, content, reasoning, tool_calls, tool_call_id, created, input_tokens, output_tokens, is_error, segments
 		 FROM messages WHERE session_id = ? ORDER BY id DESC LIMIT 1`, sessionID,
-	).Scan(&m.Role, &m.Content, &m.Reasoning, &tc, &m.ToolCallID, &created, &m.InputTokens, &m.OutputTokens)
+	).Scan(&m.Role, &m.Content, &m.Reasoning, &tc, &m.ToolCallID, &created, &m.InputTokens, &m.OutputTokens, &m.IsError, &segs)
 	if err != nil {
 		return nil, err
 	}
 	m.ToolCalls = json.RawMessage(tc)
+	m.Segments = json.RawMessage(segs)
 	m.CreatedAt = time.Unix(created, 0)
 	return &m, nil
 }
@@ -254,23 +300,63 @@ func (c *Cache) LoadMessages(sessionID string) ([]SessionMessage, error) {
 	defer c.mu.Unlock()
 
 	rows, err := c.db.Query(
-		`SELECT role, content, reasoning, tool_calls, tool_call_id, created, input_tokens, output_tokens
+		`SELECT role, content, reasoning, tool_calls, tool_call_id, created, input_tokens, output_tokens, is_error, segments
 		 FROM messages WHERE session_id = ? ORDER BY id`, sessionID,
 	)
 	if err != nil {
 		return nil, err
 	}
+	return scanMessages(rows)
+}
+
+// LoadTurnsBefore returns up to turns whole turns that precede the message
+// beforeID, ordered by ID, along with the ID of the first returned message
+// (the user message starting the oldest turn). It returns no messages once
+// the start of the session is reached.
+func (c *Cache) LoadTurnsBefore(sessionID string, beforeID int64, turns int) ([]SessionMessage, int64, error) {
+	if c == nil {
+		return nil, 0, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var firstID sql.NullInt64
+	err := c.db.QueryRow(
+		`SELECT MIN(id) FROM (SELECT id FROM messages
+		 WHERE session_id = ? AND role = 'user' AND id < ? ORDER BY id DESC LIMIT ?)`,
+		sessionID, beforeID, turns,
+	).Scan(&firstID)
+	if err != nil || !firstID.Valid {
+		return nil, 0, err
+	}
+
+	rows, err := c.db.Query(
+		`SELECT role, content, reasoning, tool_calls, tool_call_id, created, input_tokens, output_tokens, is_error, segments
+		 FROM messages WHERE session_id = ? AND id >= ? AND id < ? ORDER BY id`,
+		sessionID, firstID.Int64, beforeID,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	msgs, err := scanMessages(rows)
+	return msgs, firstID.Int64, err
+}
+
+// scanMessages reads message rows selected in LoadMessages column order and
+// closes rows.
+func scanMessages(rows *sql.Rows) ([]SessionMessage, error) {
 	defer rows.Close()
 
 	var msgs []SessionMessage
 	for rows.Next() {
 		var m SessionMessage
-		var tc string
+		var tc, segs string
 		var created int64
-		if err := rows.Scan(&m.Role, &m.Content, &m.Reasoning, &tc, &m.ToolCallID, &created, &m.InputTokens, &m.OutputTokens); err != nil {
+		if err := rows.Scan(&m.Role, &m.Content, &m.Reasoning, &tc, &m.ToolCallID, &created, &m.InputTokens, &m.OutputTokens, &m.IsError, &segs); err != nil {
 			continue
 		}
 		m.ToolCalls = json.RawMessage(tc)
+		m.Segments = json.RawMessage(segs)
 		m.CreatedAt = time.Unix(created, 0)
 		msgs = append(msgs, m)
 	}
@@ -280,8 +366,10 @@ func (c *Cache) LoadMessages(sessionID string) ([]SessionMessage, error) {
 // SessionSummary holds info for listing sessions.
 type SessionSummary struct {
 	ID        string
+	Title     string
 	Timestamp time.Time
 	Preview   string // first 50 chars of last user message
+	Messages  int    // total stored messages
 }
 
 // ListSessions returns sessions ordered by most recent user message.
@@ -293,7 +381,8 @@ func (c *Cache) ListSessions() ([]SessionSummary, error) {
 	defer c.mu.Unlock()
 
 	rows, err := c.db.Query(`
-		SELECT s.id, m.created, m.content
+		SELECT s.id, s.title, m.created, m.content,
+		  (SELECT COUNT(*) FROM messages m3 WHERE m3.session_id = s.id)
 		FROM sessions s
 		JOIN messages m ON m.session_id = s.id
 		WHERE m.role = 'user'
@@ -311,7 +400,7 @@ func (c *Cache) ListSessions() ([]SessionSummary, error) {
 	for rows.Next() {
 		var s SessionSummary
 		var ts int64
-		if err := rows.Scan(&s.ID, &ts, &s.Preview); err != nil {
+		if err := rows.Scan(&s.ID, &s.Title, &ts, &s.Preview, &s.Messages); err != nil {
 			continue
 		}
 		s.Timestamp = time.Unix(ts, 0)
@@ -354,6 +443,7 @@ func ToProviderMessages(msgs []SessionMessage) []provider.Message {
 			Reasoning:  m.Reasoning,
 			ToolCallID: m.ToolCallID,
 			CreatedAt:  m.CreatedAt,
+			IsError:    m.IsError,
 		}
 		if len(m.ToolCalls) > 0 {
 			var tcs []provider.ToolCall
@@ -361,11 +451,267 @@ func ToProviderMessages(msgs []SessionMessage) []provider.Message {
 				pm.ToolCalls = tcs
 			}
 		}
+		if len(m.Segments) > 0 {
+			var segs []provider.Segment
+			if err := json.Unmarshal(m.Segments, &segs); err == nil {
+				pm.Segments = segs
+			}
+		}
 		out = append(out, pm)
 	}
 	return out
 }
 
+// FromProviderMessage converts a provider message to a stored message.
+func FromProviderMessage(msg provider.Message) SessionMessage {
+	var tc json.RawMessage
+	if len(msg.ToolCalls) > 0 {
+		encoded, err := json.Marshal(msg.ToolCalls)
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to marshal tool calls")
+		} else {
+			tc = encoded
+		}
+	}
+	var segs json.RawMessage
+	if len(msg.Segments) > 0 {
+		if encoded, err := json.Marshal(msg.Segments); err == nil {
+			segs = encoded
+		}
+	}
+	return SessionMessage{
+		Role:         msg.Role,
+		Content:      msg.Content,
+		Reasoning:    msg.Reasoning,
+		ToolCalls:    tc,
+		ToolCallID:   msg.ToolCallID,
+		CreatedAt:    msg.CreatedAt,
+		InputTokens:  msg.InputTokens,
+		OutputTokens: msg.OutputTokens,
+		IsError:      msg.IsError,
+		Segments:     segs,
+	}
+}
+
+// SaveScratchpad stores the agent's plan on the session row.
+func (c *Cache) SaveScratchpad(sessionID, content string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err := c.db.Exec(
+		"UPDATE sessions SET scratchpad = ?, updated = ? WHERE id = ?",
+		content, time.Now().Unix(), sessionID,
+	)
+	return err
+}
+
+// LoadScratchpad returns the agent's saved plan for a session, or "" if none.
+func (c *Cache) LoadScratchpad(sessionID string) (string, error) {
+	if c == nil {
+		return "", nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var content string
+	err := c.db.QueryRow("SELECT scratchpad FROM sessions WHERE id = ?", sessionID).Scan(&content)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return content, err
+}
+
+// SaveDraft stores the unsent input on the session row. It does not bump
+// the session's updated time.
+func (c *Cache) SaveDraft(sessionID, text string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err := c.db.Exec("UPDATE sessions SET draft = ? WHERE id = ?", text, sessionID)
+	return err
+}
+
+// LoadDraft returns the unsent input saved for a session, or "" if none.
+func (c *Cache) LoadDraft(sessionID string) (string, error) {
+	if c == nil {
+		return "", nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var text string
+	err := c.db.QueryRow("SELECT draft FROM sessions WHERE id = ?", sessionID).Scan(&text)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return text, err
+}
+
+// SaveWorkDir stores the working tree root the session runs against.
+func (c *Cache) SaveWorkDir(sessionID, dir string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err := c.db.Exec("UPDATE sessions SET work_dir = ? WHERE id = ?", dir, sessionID)
+	return err
+}
+
+// LoadWorkDir returns the working tree root saved for a session, or "" if
+// none (sessions from before it was recorded).
+func (c *Cache) LoadWorkDir(sessionID string) (string, error) {
+	if c == nil {
+		return "", nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var dir string
+	err := c.db.QueryRow("SELECT work_dir FROM sessions WHERE id = ?", sessionID).Scan(&dir)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return dir, err
+}
+
+// Checkpoint is the session state derived during a run that messages alone
+// don't record: the agent's plan, token totals and turn boundaries.
+type Checkpoint struct {
+	Scratchpad   string           `json:"-"` // stored in its own column
+	InputTokens  int              `json:"input_tokens"`
+	OutputTokens int              `json:"output_tokens"`
+	Turns        []TurnCheckpoint `json:"turns,omitempty"`
+}
+
+// TurnCheckpoint is where a turn starts: its user message and the token
+// totals before it.
+type TurnCheckpoint struct {
+	MsgID        int64 `json:"msg_id"`
+	InputTokens  int   `json:"input_tokens"`
+	OutputTokens int   `json:"output_tokens"`
+}
+
+// SaveCheckpoint stores cp on the session row. It does not bump the
+// session's updated time.
+func (c *Cache) SaveCheckpoint(sessionID string, cp Checkpoint) error {
+	if c == nil {
+		return nil
+	}
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err = c.db.Exec("UPDATE sessions SET scratchpad = ?, checkpoint = ? WHERE id = ?",
+		cp.Scratchpad, string(data), sessionID)
+	return err
+}
+
+// LoadCheckpoint returns the last checkpoint saved for a session. ok is
+// false if there is none.
+func (c *Cache) LoadCheckpoint(sessionID string) (cp Checkpoint, ok bool, err error) {
+	if c == nil {
+		return Checkpoint{}, false, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var data string
+	err = c.db.QueryRow("SELECT scratchpad, checkpoint FROM sessions WHERE id = ?", sessionID).Scan(&cp.Scratchpad, &data)
+	if err == sql.ErrNoRows || (err == nil && data == "") {
+		return Checkpoint{}, false, nil
+	}
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+	if err := json.Unmarshal([]byte(data), &cp); err != nil {
+		return Checkpoint{}, false, fmt.Errorf("decode checkpoint: %w", err)
+	}
+	return cp, true, nil
+}
+
+// ForkSession copies srcID's messages with id <= upToMsgID (all of them if
+// upToMsgID <= 0), along with its title, scratchpad and working directory, into a new session
+// and returns the new ID. File deltas are not copied, so undo in the fork
+// only reaches back to the fork point.
+func (c *Cache) ForkSession(srcID string, upToMsgID int64) (string, error) {
+	if c == nil {
+		return "", fmt.Errorf("no cache")
+	}
+	if upToMsgID <= 0 {
+		upToMsgID = math.MaxInt64
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	newID := NewSessionID()
+	now := time.Now().Unix()
+	res, err := tx.Exec(`
+		INSERT INTO sessions (id, title, created, updated, scratchpad, work_dir)
+		SELECT ?, title, ?, ?, scratchpad, work_dir FROM sessions WHERE id = ?`,
+		newID, now, now, srcID)
+	if err != nil {
+		return "", err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return "", fmt.Errorf("session %q not found", srcID)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO messages (session_id, role, content, reasoning, tool_calls, tool_call_id, created, input_tokens, output_tokens, is_error, segments)
+		SELECT ?, role, content, reasoning, tool_calls, tool_call_id, created, input_tokens, output_tokens, is_error, segments
+		FROM messages WHERE session_id = ? AND id <= ? ORDER BY id`,
+		newID, srcID, upToMsgID); err != nil {
+		return "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return newID, nil
+}
+
+// UserMessageIDs returns the row IDs of a session's user messages in order,
+// i.e. the start of each turn.
+func (c *Cache) UserMessageIDs(sessionID string) ([]int64, error) {
+	if c == nil {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rows, err := c.db.Query(
+		"SELECT id FROM messages WHERE session_id = ? AND role = 'user' ORDER BY id", sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 // SessionExists returns true if a session with the given ID exists.
 func (c *Cache) SessionExists(id string) (bool, error) {
 	if c == nil {
@@ -381,3 +727,98 @@ func (c *Cache) SessionExists(id string) (bool, error) {
 	}
 	return count > 0, nil
 }
+
+// AddInputHistory records text the user submitted in a session's input.
+func (c *Cache) AddInputHistory(sessionID, text string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err := c.db.Exec(
+		"INSERT INTO input_history (session_id, text, created) VALUES (?, ?, ?)",
+		sessionID, text, time.Now().Unix(),
+	)
+	return err
+}
+
+// LoadInputHistory returns up to limit of a session's most recent input
+// submissions, oldest first.
+func (c *Cache) LoadInputHistory(sessionID string, limit int) ([]string, error) {
+	if c == nil {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rows, err := c.db.Query(
+		"SELECT text FROM input_history WHERE session_id = ? ORDER BY id DESC LIMIT ?", sessionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []string
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			return nil, err
+		}
+		history = append(history, text)
+	}
+	slices.Reverse(history)
+	return history, rows.Err()
+}
+
+// FileAccess is one entry in a session's file audit: a file a tool read or
+// changed during a turn.
+type FileAccess struct {
+	Path    string
+	Op      string // "read", "edit", "create" or "remove"
+	TurnID  int64  // messages.id of the turn's user message; 0 if unknown
+	Created time.Time
+}
+
+// RecordFileAccess adds an entry to a session's file audit.
+func (c *Cache) RecordFileAccess(sessionID string, turnID int64, path, op string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err := c.db.Exec(
+		"INSERT INTO file_access (session_id, turn_id, file_path, op, created) VALUES (?, ?, ?, ?, ?)",
+		sessionID, turnID, path, op, time.Now().Unix(),
+	)
+	return err
+}
+
+// LoadFileAccesses returns a session's file audit, oldest first.
+func (c *Cache) LoadFileAccesses(sessionID string) ([]FileAccess, error) {
+	if c == nil {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rows, err := c.db.Query(
+		"SELECT file_path, op, turn_id, created FROM file_access WHERE session_id = ? ORDER BY id", sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accesses []FileAccess
+	for rows.Next() {
+		var a FileAccess
+		var created int64
+		if err := rows.Scan(&a.Path, &a.Op, &a.TurnID, &created); err != nil {
+			return nil, err
+		}
+		a.Created = time.Unix(created, 0)
+		accesses = append(accesses, a)
+	}
+	return accesses, rows.Err()
+}
@@ -59,6 +59,26 @@ CREATE TABLE IF NOT EXISTS file_deltas (
 );
 
 CREATE INDEX IF NOT EXISTS idx_deltas_turn ON file_deltas(session_id, turn_id);
+
+CREATE TABLE IF NOT EXISTS input_history (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id  TEXT NOT NULL,
+	text        TEXT NOT NULL,
+	created     INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_input_history_session ON input_history(session_id, id);
+
+CREATE TABLE IF NOT EXISTS file_access (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id  TEXT NOT NULL,
+	turn_id     INTEGER NOT NULL,
+	file_path   TEXT NOT NULL,
+	op          TEXT NOT NULL,
+	created     INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_access_session ON file_access(session_id, id);
 `
 
 // Cache is a SQLite-backed cache for web results and session storage.
@@ -114,6 +134,54 @@ func Open(dbPath string, ttl time.Duration) (*Cache, error) {
 		}
 	}
 
+	// Migrate: add scratchpad column to sessions table.
+	if !hasColumn(db, "sessions", "scratchpad") {
+		if _, err := db.Exec("ALTER TABLE sessions ADD COLUMN scratchpad TEXT NOT NULL DEFAULT ''"); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("add sessions.scratchpad: %w", err)
+		}
+	}
+
+	// Migrate: add is_error column to messages table.
+	if !hasColumn(db, "messages", "is_error") {
+		if _, err := db.Exec("ALTER TABLE messages ADD COLUMN is_error INTEGER NOT NULL DEFAULT 0"); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("add messages.is_error: %w", err)
+		}
+	}
+
+	// Migrate: add draft column to sessions table.
+	if !hasColumn(db, "sessions", "draft") {
+		if _, err := db.Exec("ALTER TABLE sessions ADD COLUMN draft TEXT NOT NULL DEFAULT ''"); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("add sessions.draft: %w", err)
+		}
+	}
+
+	// Migrate: add work_dir column to sessions table.
+	if !hasColumn(db, "sessions", "work_dir") {
+		if _, err := db.Exec("ALTER TABLE sessions ADD COLUMN work_dir TEXT NOT NULL DEFAULT ''"); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("add sessions.work_dir: %w", err)
+		}
+	}
+
+	// Migrate: add checkpoint column to sessions table.
+	if !hasColumn(db, "sessions", "checkpoint") {
+		if _, err := db.Exec("ALTER TABLE sessions ADD COLUMN checkpoint TEXT NOT NULL DEFAULT ''"); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("add sessions.checkpoint: %w", err)
+		}
+	}
+
+	// Migrate: add segments column to messages table.
+	if !hasColumn(db, "messages", "segments") {
+		if _, err := db.Exec("ALTER TABLE messages ADD COLUMN segments TEXT NOT NULL DEFAULT ''"); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("add messages.segments: %w", err)
+		}
+	}
+
 	c := &Cache{
 		db:  db,
 		ttl: ttl,
@@ -2,8 +2,11 @@ package store
 
 import (
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
+
+	"github.com/xonecas/symb/internal/provider"
 )
 
 func openTestCache(t *testing.T, ttl time.Duration) *Cache {
@@ -229,3 +232,418 @@ func sliceEqual(a, b []string) bool {
 	}
 	return true
 }
+
+func TestScratchpad_SaveLoad(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	c := openAt(t, dbPath)
+	if err := c.CreateSession("s1"); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	// Empty for a fresh session.
+	if got, err := c.LoadScratchpad("s1"); err != nil || got != "" {
+		t.Fatalf("fresh session: got %q, %v", got, err)
+	}
+
+	plan := "1. read code\n2. fix bug"
+	if err := c.SaveScratchpad("s1", plan); err != nil {
+		t.Fatalf("SaveScratchpad: %v", err)
+	}
+	c.Close()
+
+	// Survives reopening the database.
+	c = openAt(t, dbPath)
+	defer c.Close()
+	got, err := c.LoadScratchpad("s1")
+	if err != nil {
+		t.Fatalf("LoadScratchpad: %v", err)
+	}
+	if got != plan {
+		t.Errorf("got %q, want %q", got, plan)
+	}
+
+	// Unknown session is a miss, not an error.
+	if got, err := c.LoadScratchpad("missing"); err != nil || got != "" {
+		t.Errorf("missing session: got %q, %v", got, err)
+	}
+}
+
+func TestDraft_SaveLoad(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	c := openAt(t, dbPath)
+	if err := c.CreateSession("s1"); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if err := c.SaveDraft("s1", "half a\nprompt"); err != nil {
+		t.Fatalf("SaveDraft: %v", err)
+	}
+	c.Close()
+
+	c = openAt(t, dbPath)
+	defer c.Close()
+	if got, err := c.LoadDraft("s1"); err != nil || got != "half a\nprompt" {
+		t.Errorf("LoadDraft = %q, %v", got, err)
+	}
+	if err := c.SaveDraft("s1", ""); err != nil {
+		t.Fatalf("SaveDraft: %v", err)
+	}
+	if got, _ := c.LoadDraft("s1"); got != "" {
+		t.Errorf("cleared draft = %q", got)
+	}
+	if got, err := c.LoadDraft("missing"); err != nil || got != "" {
+		t.Errorf("missing session: got %q, %v", got, err)
+	}
+}
+
+func TestCheckpoint_SaveLoad(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	c := openAt(t, dbPath)
+	if err := c.CreateSession("s1"); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if _, ok, err := c.LoadCheckpoint("s1"); ok || err != nil {
+		t.Errorf("before save: ok=%v, %v", ok, err)
+	}
+	want := Checkpoint{
+		Scratchpad:   "- [x] plan",
+		InputTokens:  300,
+		OutputTokens: 40,
+		Turns:        []TurnCheckpoint{{MsgID: 1}, {MsgID: 5, InputTokens: 100, OutputTokens: 10}},
+	}
+	if err := c.SaveCheckpoint("s1", want); err != nil {
+		t.Fatalf("SaveCheckpoint: %v", err)
+	}
+	c.Close()
+
+	c = openAt(t, dbPath)
+	defer c.Close()
+	got, ok, err := c.LoadCheckpoint("s1")
+	if err != nil || !ok {
+		t.Fatalf("LoadCheckpoint: ok=%v, %v", ok, err)
+	}
+	if got.Scratchpad != want.Scratchpad || got.InputTokens != 300 || got.OutputTokens != 40 || !slices.Equal(got.Turns, want.Turns) {
+		t.Errorf("LoadCheckpoint = %+v, want %+v", got, want)
+	}
+	if pad, _ := c.LoadScratchpad("s1"); pad != want.Scratchpad {
+		t.Errorf("scratchpad = %q", pad)
+	}
+	if _, ok, err := c.LoadCheckpoint("missing"); ok || err != nil {
+		t.Errorf("missing session: ok=%v, %v", ok, err)
+	}
+}
+
+func TestWorkDir_SaveLoad(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	c := openAt(t, dbPath)
+	if err := c.CreateSession("s1"); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if got, err := c.LoadWorkDir("s1"); err != nil || got != "" {
+		t.Errorf("unset work dir = %q, %v", got, err)
+	}
+	if err := c.SaveWorkDir("s1", "/src/repo"); err != nil {
+		t.Fatalf("SaveWorkDir: %v", err)
+	}
+	c.Close()
+
+	c = openAt(t, dbPath)
+	defer c.Close()
+	if got, err := c.LoadWorkDir("s1"); err != nil || got != "/src/repo" {
+		t.Errorf("LoadWorkDir = %q, %v", got, err)
+	}
+	if got, err := c.LoadWorkDir("missing"); err != nil || got != "" {
+		t.Errorf("missing session: got %q, %v", got, err)
+	}
+}
+
+func TestScratchpad_MigratesOldSchema(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "old.db")
+	c := openAt(t, dbPath)
+	// Simulate a pre-scratchpad database.
+	if _, err := c.db.Exec("ALTER TABLE sessions DROP COLUMN scratchpad"); err != nil {
+		t.Fatalf("drop column: %v", err)
+	}
+	c.Close()
+
+	c = openAt(t, dbPath)
+	defer c.Close()
+	if !hasColumn(c.db, "sessions", "scratchpad") {
+		t.Fatal("expected scratchpad column after migration")
+	}
+}
+
+func TestInputHistory(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	c := openAt(t, dbPath)
+	for _, text := range []string{"one", "two\nlines", "three"} {
+		if err := c.AddInputHistory("s1", text); err != nil {
+			t.Fatalf("AddInputHistory: %v", err)
+		}
+	}
+	if err := c.AddInputHistory("s2", "other"); err != nil {
+		t.Fatalf("AddInputHistory: %v", err)
+	}
+	c.Close()
+
+	// Survives reopening, stays per session, and keeps the newest entries.
+	c = openAt(t, dbPath)
+	defer c.Close()
+	got, err := c.LoadInputHistory("s1", 2)
+	if err != nil {
+		t.Fatalf("LoadInputHistory: %v", err)
+	}
+	if !sliceEqual(got, []string{"two\nlines", "three"}) {
+		t.Errorf("got %q, want the last two entries oldest first", got)
+	}
+	if got, _ := c.LoadInputHistory("missing", 10); len(got) != 0 {
+		t.Errorf("missing session: got %q", got)
+	}
+}
+
+func TestFileAccess(t *testing.T) {
+	c := openAt(t, filepath.Join(t.TempDir(), "test.db"))
+	defer c.Close()
+	for _, a := range []FileAccess{{"a.go", "read", 1, time.Time{}}, {"a.go", "edit", 1, time.Time{}}, {"b.go", "create", 4, time.Time{}}} {
+		if err := c.RecordFileAccess("s1", a.TurnID, a.Path, a.Op); err != nil {
+			t.Fatalf("RecordFileAccess: %v", err)
+		}
+	}
+	if err := c.RecordFileAccess("s2", 9, "c.go", "read"); err != nil {
+		t.Fatalf("RecordFileAccess: %v", err)
+	}
+
+	got, err := c.LoadFileAccesses("s1")
+	if err != nil {
+		t.Fatalf("LoadFileAccesses: %v", err)
+	}
+	if len(got) != 3 || got[0].Op != "read" || got[1].Op != "edit" || got[2].Path != "b.go" || got[2].TurnID != 4 {
+		t.Errorf("got %+v", got)
+	}
+	if got[0].Created.IsZero() {
+		t.Error("access has no timestamp")
+	}
+}
+
+func openAt(t *testing.T, dbPath string) *Cache {
+	t.Helper()
+	c, err := Open(dbPath, time.Hour)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return c
+}
+
+func TestListSessions_Summary(t *testing.T) {
+	c := openAt(t, filepath.Join(t.TempDir(), "test.db"))
+	defer c.Close()
+	if err := c.CreateSession("s1"); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	for _, m := range []SessionMessage{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "first question"},
+		{Role: "assistant", Content: "answer"},
+		{Role: "user", Content: "follow up"},
+	} {
+		if _, err := c.SaveMessageSync("s1", m); err != nil {
+			t.Fatalf("SaveMessageSync: %v", err)
+		}
+	}
+
+	sessions, err := c.ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("got %d sessions, want 1", len(sessions))
+	}
+	s := sessions[0]
+	if s.ID != "s1" || s.Preview != "follow up" || s.Messages != 4 {
+		t.Errorf("got %+v, want s1 / %q / 4 messages", s, "follow up")
+	}
+}
+
+func TestSaveSystemPrompt(t *testing.T) {
+	c := openAt(t, filepath.Join(t.TempDir(), "test.db"))
+	defer c.Close()
+	if err := c.CreateSession("s1"); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if err := c.SaveMessages("s1", []SessionMessage{
+		{Role: "system", Content: "old"},
+		{Role: "user", Content: "hi"},
+	}); err != nil {
+		t.Fatalf("SaveMessages: %v", err)
+	}
+	if err := c.SaveSystemPrompt("s1", "new"); err != nil {
+		t.Fatalf("SaveSystemPrompt: %v", err)
+	}
+	msgs, err := c.LoadMessages("s1")
+	if err != nil || len(msgs) != 2 {
+		t.Fatalf("LoadMessages = %+v, %v", msgs, err)
+	}
+	if msgs[0].Content != "new" || msgs[1].Content != "hi" {
+		t.Errorf("contents = %q, %q; want new, hi", msgs[0].Content, msgs[1].Content)
+	}
+}
+
+func TestLoadMessages_IsError(t *testing.T) {
+	c := openAt(t, filepath.Join(t.TempDir(), "test.db"))
+	defer c.Close()
+	if err := c.CreateSession("s1"); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if err := c.SaveMessages("s1", []SessionMessage{
+		{Role: "tool", Content: "Error: boom", ToolCallID: "1", IsError: true},
+		{Role: "tool", Content: "ok", ToolCallID: "2"},
+	}); err != nil {
+		t.Fatalf("SaveMessages: %v", err)
+	}
+	msgs, err := c.LoadMessages("s1")
+	if err != nil || len(msgs) != 2 {
+		t.Fatalf("LoadMessages = %+v, %v", msgs, err)
+	}
+	if !msgs[0].IsError || msgs[1].IsError {
+		t.Errorf("IsError = %v, %v; want true, false", msgs[0].IsError, msgs[1].IsError)
+	}
+}
+
+func TestLoadMessages_Segments(t *testing.T) {
+	c := openAt(t, filepath.Join(t.TempDir(), "test.db"))
+	defer c.Close()
+	if err := c.CreateSession("s1"); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	segs := []provider.Segment{{Reasoning: true, Len: 1}, {Len: 1}, {Reasoning: true, Len: 1}}
+	if err := c.SaveMessages("s1", []SessionMessage{
+		FromProviderMessage(provider.Message{Role: "assistant", Reasoning: "ab", Content: "c", Segments: segs}),
+		{Role: "user", Content: "next"},
+	}); err != nil {
+		t.Fatalf("SaveMessages: %v", err)
+	}
+	msgs, err := c.LoadMessages("s1")
+	if err != nil || len(msgs) != 2 {
+		t.Fatalf("LoadMessages = %+v, %v", msgs, err)
+	}
+	got := ToProviderMessages(msgs)
+	if !slices.Equal(got[0].Segments, segs) || got[1].Segments != nil {
+		t.Errorf("segments = %+v, %+v; want %+v, nil", got[0].Segments, got[1].Segments, segs)
+	}
+}
+
+func TestForkSession(t *testing.T) {
+	c := openAt(t, filepath.Join(t.TempDir(), "test.db"))
+	defer c.Close()
+	if err := c.CreateSession("src"); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if err := c.SaveScratchpad("src", "plan"); err != nil {
+		t.Fatalf("SaveScratchpad: %v", err)
+	}
+	if err := c.SaveWorkDir("src", "/repo"); err != nil {
+		t.Fatalf("SaveWorkDir: %v", err)
+	}
+	for _, m := range []SessionMessage{
+		{Role: "user", Content: "one"},
+		{Role: "assistant", Content: "a1"},
+		{Role: "user", Content: "two"},
+		{Role: "assistant", Content: "a2"},
+	} {
+		if _, err := c.SaveMessageSync("src", m); err != nil {
+			t.Fatalf("SaveMessageSync: %v", err)
+		}
+	}
+
+	ids, err := c.UserMessageIDs("src")
+	if err != nil || len(ids) != 2 {
+		t.Fatalf("UserMessageIDs = %v, %v", ids, err)
+	}
+
+	// Keep only the first turn.
+	forkID, err := c.ForkSession("src", ids[1]-1)
+	if err != nil {
+		t.Fatalf("ForkSession: %v", err)
+	}
+	msgs, _ := c.LoadMessages(forkID)
+	if len(msgs) != 2 || msgs[0].Content != "one" || msgs[1].Content != "a1" {
+		t.Errorf("fork messages = %+v, want first turn only", msgs)
+	}
+	if pad, _ := c.LoadScratchpad(forkID); pad != "plan" {
+		t.Errorf("fork scratchpad = %q, want %q", pad, "plan")
+	}
+	if dir, _ := c.LoadWorkDir(forkID); dir != "/repo" {
+		t.Errorf("fork work dir = %q, want %q", dir, "/repo")
+	}
+
+	// The original is untouched; a full fork copies everything.
+	if orig, _ := c.LoadMessages("src"); len(orig) != 4 {
+		t.Errorf("source has %d messages, want 4", len(orig))
+	}
+	fullID, err := c.ForkSession("src", 0)
+	if err != nil {
+		t.Fatalf("ForkSession all: %v", err)
+	}
+	if all, _ := c.LoadMessages(fullID); len(all) != 4 {
+		t.Errorf("full fork has %d messages, want 4", len(all))
+	}
+
+	if _, err := c.ForkSession("missing", 0); err == nil {
+		t.Error("expected error forking a missing session")
+	}
+}
+
+func TestLoadTurnsBefore(t *testing.T) {
+	c := openAt(t, filepath.Join(t.TempDir(), "test.db"))
+	defer c.Close()
+	if err := c.CreateSession("s"); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	for _, content := range []string{"u1", "a1", "u2", "a2", "u3", "a3"} {
+		role := "assistant"
+		if content[0] == 'u' {
+			role = "user"
+		}
+		if _, err := c.SaveMessageSync("s", SessionMessage{Role: role, Content: content}); err != nil {
+			t.Fatalf("SaveMessageSync: %v", err)
+		}
+	}
+	ids, _ := c.UserMessageIDs("s")
+
+	msgs, first, err := c.LoadTurnsBefore("s", ids[2], 1)
+	if err != nil || first != ids[1] {
+		t.Fatalf("LoadTurnsBefore = first %d, %v; want %d", first, err, ids[1])
+	}
+	if len(msgs) != 2 || msgs[0].Content != "u2" || msgs[1].Content != "a2" {
+		t.Errorf("one turn before u3 = %+v, want u2/a2", msgs)
+	}
+
+	msgs, _, _ = c.LoadTurnsBefore("s", ids[2], 5)
+	if len(msgs) != 4 || msgs[0].Content != "u1" {
+		t.Errorf("all turns before u3 = %+v, want u1..a2", msgs)
+	}
+
+	if msgs, first, _ := c.LoadTurnsBefore("s", ids[0], 5); len(msgs) != 0 || first != 0 {
+		t.Errorf("before first turn = %d msgs, first %d; want none", len(msgs), first)
+	}
+}
+
+func TestSaveMessagesSync_ReturnsLastID(t *testing.T) {
+	c := openTestCache(t, time.Hour)
+	if err := c.CreateSession("s1"); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	id, err := c.SaveMessagesSync("s1", []SessionMessage{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "hi"},
+	})
+	if err != nil {
+		t.Fatalf("SaveMessagesSync: %v", err)
+	}
+	ids, _ := c.UserMessageIDs("s1")
+	if len(ids) != 1 || ids[0] != id {
+		t.Errorf("returned ID %d, user IDs %v", id, ids)
+	}
+	if id, err := c.SaveMessagesSync("s1", nil); id != 0 || err != nil {
+		t.Errorf("empty batch = %d, %v", id, err)
+	}
+}
@@ -31,6 +31,7 @@ type Options struct {
 	Prompt        string
 	Type          string
 	MaxIterations int
+	Confirm       llm.ConfirmFunc // Optional: approval gate for the sub-agent's tool calls
 }
 
 // Result reports a sub-agent run outcome.
@@ -91,6 +92,7 @@ func Run(ctx context.Context, opts Options) (Result, error) {
 			totalIn += in
 			totalOut += out
 		},
+		Confirm:       opts.Confirm,
 		MaxToolRounds: maxIter,
 		Depth:         MaxSubAgentDepth,
 	})
@@ -133,11 +135,11 @@ func FilterToolsForType(tools []mcp.Tool, agentType string) []mcp.Tool {
 	base := FilterTools(tools)
 	switch agentType {
 	case "explore":
-		return filterByName(base, "Read", "Grep", "Shell")
+		return filterByName(base, "Read", "Grep", "Outline", "RecentFiles", "Shell")
 	case "editor":
-		return filterByName(base, "Read", "Edit", "Grep", "Shell")
+		return filterByName(base, "Read", "Edit", "Grep", "Outline", "Shell", "Test", "Build")
 	case "reviewer":
-		return filterByName(base, "Read", "Grep", "Shell")
+		return filterByName(base, "Read", "Grep", "Outline", "RecentFiles", "Shell")
 	case "web":
 		return filterByName(base, "web_search_exa", "get_code_context_exa")
 	default:
@@ -134,3 +134,79 @@ func formatFileCompact(syms []Symbol) string {
 	}
 	return g.render()
 }
+
+// FormatFileOutline renders one file's symbols as an indented outline with
+// line ranges, in source order. Methods and interface methods are nested
+// under their type; methods whose type is declared elsewhere stay at the
+// top level.
+//
+// Example output:
+//
+//	3-9 type Proxy struct_type
+//	  11-20 func (p *Proxy) CallTool(ctx context.Context) error
+//	22-25 func NewProxy() *Proxy
+func FormatFileOutline(syms []Symbol) string {
+	types := make(map[string]bool)
+	for _, s := range syms {
+		if s.Kind == KindType || s.Kind == KindStruct || s.Kind == KindInterface {
+			types[s.Name] = true
+		}
+	}
+	methods := make(map[string][]Symbol)
+	for _, s := range syms {
+		if recv := receiverName(s.Receiver); s.Kind == KindMethod && types[recv] {
+			methods[recv] = append(methods[recv], s)
+		}
+	}
+
+	var b strings.Builder
+	for _, s := range syms {
+		switch s.Kind {
+		case KindPackage, KindImport:
+			continue
+		case KindMethod:
+			if types[receiverName(s.Receiver)] {
+				continue
+			}
+		}
+		writeOutlineLine(&b, s, 0)
+		if s.Kind == KindInterface {
+			for _, c := range s.Children {
+				writeOutlineLine(&b, c, 1)
+			}
+		}
+		for _, m := range methods[s.Name] {
+			writeOutlineLine(&b, m, 1)
+		}
+	}
+	return b.String()
+}
+
+// writeOutlineLine writes "start-end signature" indented by depth.
+func writeOutlineLine(b *strings.Builder, s Symbol, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	if s.EndLine > s.StartLine {
+		fmt.Fprintf(b, "%d-%d ", s.StartLine, s.EndLine)
+	} else {
+		fmt.Fprintf(b, "%d ", s.StartLine)
+	}
+	switch {
+	case s.Signature != "":
+		b.WriteString(s.Signature)
+	case s.Kind == KindConst || s.Kind == KindVar:
+		b.WriteString(s.Kind.String() + " " + s.Name)
+	default:
+		b.WriteString(s.Name)
+	}
+	b.WriteByte('\n')
+}
+
+// receiverName strips pointer and type parameters from a method receiver
+// type: "*List[T]" becomes "List".
+func receiverName(recv string) string {
+	recv = strings.TrimPrefix(recv, "*")
+	if i := strings.IndexByte(recv, '['); i >= 0 {
+		recv = recv[:i]
+	}
+	return recv
+}
@@ -1,41 +1,104 @@
 package treesitter
 
 import (
+	"bytes"
+	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 
+	"github.com/rs/zerolog/log"
+	sitter "github.com/smacker/go-tree-sitter"
 	"github.com/xonecas/symb/internal/filesearch"
 )
 
+// maxCachedTrees bounds how many parse trees are kept for incremental reparsing.
+const maxCachedTrees = 32
+
+// defaultMaxFileSize is the largest file parsed unless SetLimits says otherwise.
+const defaultMaxFileSize = 1 << 20
+
+// parsedFile is a retained syntax tree and the source it was parsed from.
+type parsedFile struct {
+	tree *sitter.Tree
+	src  []byte
+}
+
 // Index holds a project-wide symbol map built from tree-sitter parsing.
 type Index struct {
 	mu    sync.RWMutex
-	files map[string][]Symbol // relPath -> symbols
+	files map[string][]Symbol    // relPath -> symbols
+	trees map[string]*parsedFile // relPath -> tree of recently read/edited files
 	root  string
+	ready atomic.Bool // set once Build completes
+
+	maxSize int64    // files larger than this are not parsed
+	skip    []string // glob patterns of files not to parse
 }
 
 // NewIndex creates an empty index rooted at dir.
 func NewIndex(root string) *Index {
 	return &Index{
-		files: make(map[string][]Symbol),
-		root:  root,
+		files:   make(map[string][]Symbol),
+		trees:   make(map[string]*parsedFile),
+		root:    root,
+		maxSize: defaultMaxFileSize,
 	}
 }
 
+// SetLimits sets the largest file size to parse and glob patterns of files
+// to leave out of the index. A pattern without a slash matches the file
+// name anywhere; one with a slash matches the path from the root. A
+// maxSize <= 0 keeps the default of 1 MB. Call before Build.
+func (idx *Index) SetLimits(maxSize int64, skip []string) {
+	if maxSize > 0 {
+		idx.maxSize = maxSize
+	}
+	idx.skip = skip
+}
+
+// skipped reports whether a file of the given size is left out of the
+// index, logging the reason at debug level.
+func (idx *Index) skipped(rel string, size int64) bool {
+	if size > idx.maxSize {
+		log.Debug().Str("file", rel).Int64("size", size).Msg("treesitter: skipping large file")
+		return true
+	}
+	for _, pattern := range idx.skip {
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = filepath.Base(rel)
+		}
+		if ok, _ := filepath.Match(pattern, filepath.ToSlash(name)); ok {
+			log.Debug().Str("file", rel).Str("pattern", pattern).Msg("treesitter: skipping file")
+			return true
+		}
+	}
+	return false
+}
+
+// progressEvery is how many indexed files pass between progress callbacks.
+const progressEvery = 100
+
 // Build walks the project tree, parsing every supported file.
-// Respects .gitignore via filesearch.GitignoreMatcher.
-func (idx *Index) Build() error {
+// Respects .gitignore via filesearch.GitignoreMatcher. Files are added as
+// they are parsed, so the index is usable (partially) while Build runs.
+// progress, if non-nil, is called periodically with the number of files
+// indexed so far. Cancelling ctx aborts the walk and returns ctx.Err().
+func (idx *Index) Build(ctx context.Context, progress func(files int)) error {
 	gitignorePath := filepath.Join(idx.root, ".gitignore")
 	matcher, err := filesearch.NewGitignoreMatcher(gitignorePath)
 	if err != nil {
 		matcher, _ = filesearch.NewGitignoreMatcher("")
 	}
 
-	idx.mu.Lock()
-	defer idx.mu.Unlock()
-
-	return filepath.WalkDir(idx.root, func(path string, d os.DirEntry, walkErr error) error {
+	indexed := 0
+	err = filepath.WalkDir(idx.root, func(path string, d os.DirEntry, walkErr error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if walkErr != nil {
 			return nil
 		}
@@ -61,9 +124,8 @@ func (idx *Index) Build() error {
 			return nil
 		}
 
-		// Skip large files (>1MB).
 		info, err := d.Info()
-		if err != nil || info.Size() > 1<<20 {
+		if err != nil || idx.skipped(rel, info.Size()) {
 			return nil
 		}
 
@@ -71,27 +133,115 @@ func (idx *Index) Build() error {
 		if err != nil || len(syms) == 0 {
 			return nil
 		}
+		idx.mu.Lock()
 		idx.files[rel] = syms
+		idx.mu.Unlock()
+
+		indexed++
+		if progress != nil && indexed%progressEvery == 0 {
+			progress(indexed)
+		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	idx.ready.Store(true)
+	return nil
 }
 
+// Ready reports whether the initial Build has finished.
+func (idx *Index) Ready() bool { return idx.ready.Load() }
+
 // UpdateFile re-parses a single file and updates the index.
+// The tree is retained so a following EditFile can reparse incrementally.
 func (idx *Index) UpdateFile(absPath string) {
 	rel, err := filepath.Rel(idx.root, absPath)
 	if err != nil || !Supported(absPath) {
 		return
 	}
-	syms, err := ParseFile(absPath)
+	info, err := os.Stat(absPath)
+	if err != nil || idx.skipped(rel, info.Size()) {
+		idx.store(rel, nil, nil)
+		return
+	}
+	src, err := os.ReadFile(absPath)
+	if err != nil {
+		idx.store(rel, nil, nil)
+		return
+	}
+	tree, _ := parseTree(absPath, src, nil)
+	idx.store(rel, tree, src)
+}
+
+// EditFile updates the index after an in-place edit from oldSrc to newSrc.
+// When a tree for oldSrc is retained, the changed byte range is fed to
+// tree-sitter's edit API so only the affected subtrees reparse; otherwise
+// the new content is parsed in full.
+func (idx *Index) EditFile(absPath string, oldSrc, newSrc []byte) {
+	rel, err := filepath.Rel(idx.root, absPath)
+	if err != nil || !Supported(absPath) {
+		return
+	}
+	if idx.skipped(rel, int64(len(newSrc))) {
+		idx.store(rel, nil, nil)
+		return
+	}
+
+	// Take the tree out of the cache — trees are not safe for concurrent use.
+	idx.mu.Lock()
+	pf := idx.trees[rel]
+	delete(idx.trees, rel)
+	idx.mu.Unlock()
+
+	var oldTree *sitter.Tree
+	if pf != nil {
+		if bytes.Equal(pf.src, oldSrc) {
+			pf.tree.Edit(editInput(oldSrc, newSrc))
+			oldTree = pf.tree
+		} else {
+			pf.tree.Close()
+		}
+	}
+	tree, _ := parseTree(absPath, newSrc, oldTree)
+	if oldTree != nil {
+		oldTree.Close()
+	}
+	idx.store(rel, tree, newSrc)
+}
+
+// store records symbols extracted from tree and retains the tree for later
+// incremental edits. A nil tree removes the file from the index.
+func (idx *Index) store(rel string, tree *sitter.Tree, src []byte) {
+	var syms []Symbol
+	if tree != nil {
+		syms = extractGo(tree.RootNode(), src)
+	}
 
 	idx.mu.Lock()
 	defer idx.mu.Unlock()
 
-	if err != nil || len(syms) == 0 {
+	if old, ok := idx.trees[rel]; ok {
+		old.tree.Close()
+		delete(idx.trees, rel)
+	}
+	if len(syms) == 0 {
 		delete(idx.files, rel)
+	} else {
+		idx.files[rel] = syms
+	}
+	if tree == nil {
 		return
 	}
-	idx.files[rel] = syms
+	// Evict an arbitrary tree when full; evicted files fall back to a full parse.
+	if len(idx.trees) >= maxCachedTrees {
+		for k, v := range idx.trees {
+			v.tree.Close()
+			delete(idx.trees, k)
+			break
+		}
+	}
+	idx.trees[rel] = &parsedFile{tree: tree, src: src}
 }
 
 // Files returns a snapshot of all indexed file paths (sorted is not guaranteed).
@@ -112,6 +262,41 @@ func (idx *Index) Symbols(relPath string) []Symbol {
 	return idx.files[relPath]
 }
 
+// FileSymbols returns symbols for the file at absPath, or nil if it is
+// outside the root or not indexed.
+func (idx *Index) FileSymbols(absPath string) []Symbol {
+	rel, err := filepath.Rel(idx.root, absPath)
+	if err != nil {
+		return nil
+	}
+	return idx.Symbols(rel)
+}
+
+// Enclosing returns the innermost symbol in the file at absPath whose line
+// span contains start..end, ignoring package and import entries.
+func (idx *Index) Enclosing(absPath string, start, end int) (Symbol, bool) {
+	rel, err := filepath.Rel(idx.root, absPath)
+	if err != nil {
+		return Symbol{}, false
+	}
+	var best Symbol
+	found := false
+	var walk func(syms []Symbol)
+	walk = func(syms []Symbol) {
+		for _, s := range syms {
+			if s.Kind == KindPackage || s.Kind == KindImport || s.StartLine > start || s.EndLine < end {
+				continue
+			}
+			if !found || s.EndLine-s.StartLine < best.EndLine-best.StartLine {
+				best, found = s, true
+			}
+			walk(s.Children)
+		}
+	}
+	walk(idx.Symbols(rel))
+	return best, found
+}
+
 // Snapshot returns a copy of the full index map.
 func (idx *Index) Snapshot() map[string][]Symbol {
 	idx.mu.RLock()
@@ -0,0 +1,159 @@
+package treesitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEditFile_MatchesFullParse(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	oldSrc := []byte("package main\n\nfunc A() {}\n\nfunc C() {}\n")
+	newSrc := []byte("package main\n\nfunc A() {}\n\nfunc B() int { return 1 }\n\nfunc C() {}\n")
+
+	if err := os.WriteFile(path, oldSrc, 0600); err != nil {
+		t.Fatal(err)
+	}
+	idx := NewIndex(dir)
+	idx.UpdateFile(path) // retains the tree for incremental reparse
+	if err := os.WriteFile(path, newSrc, 0600); err != nil {
+		t.Fatal(err)
+	}
+	idx.EditFile(path, oldSrc, newSrc)
+
+	want, err := ParseSource(path, newSrc)
+	if err != nil {
+		t.Fatalf("ParseSource: %v", err)
+	}
+	if got := idx.Symbols("main.go"); !reflect.DeepEqual(got, want) {
+		t.Errorf("incremental symbols = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestEditFile_FallbackWithoutTree(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	newSrc := []byte("package main\n\nfunc B() {}\n")
+
+	idx := NewIndex(dir)
+	// No prior tree, and a stale oldSrc: must still parse newSrc in full.
+	idx.EditFile(path, []byte("stale"), newSrc)
+
+	want, _ := ParseSource(path, newSrc)
+	if got := idx.Symbols("main.go"); !reflect.DeepEqual(got, want) {
+		t.Errorf("fallback symbols = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestEditInput(t *testing.T) {
+	in := editInput([]byte("ab\ncd\nef"), []byte("ab\ncXYd\nef"))
+	if in.StartIndex != 4 || in.OldEndIndex != 4 || in.NewEndIndex != 6 {
+		t.Errorf("byte range = %d/%d/%d, want 4/4/6", in.StartIndex, in.OldEndIndex, in.NewEndIndex)
+	}
+	if in.StartPoint.Row != 1 || in.StartPoint.Column != 1 {
+		t.Errorf("start point = %+v, want row 1 col 1", in.StartPoint)
+	}
+	if in.NewEndPoint.Row != 1 || in.NewEndPoint.Column != 3 {
+		t.Errorf("new end point = %+v, want row 1 col 3", in.NewEndPoint)
+	}
+}
+
+func TestBuild_Cancel(t *testing.T) {
+	dir := t.TempDir()
+	const total = 3 * progressEvery
+	for i := 0; i < total; i++ {
+		src := fmt.Sprintf("package p\n\nfunc F%d() {}\n", i)
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%d.go", i)), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idx := NewIndex(dir)
+
+	start := time.Now()
+	err := idx.Build(ctx, func(int) { cancel() }) // cancel at the first progress report
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Build error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Build took %v after cancel", elapsed)
+	}
+	if n := len(idx.Files()); n >= total {
+		t.Errorf("indexed %d files, expected the walk to stop early", n)
+	}
+	if idx.Ready() {
+		t.Error("cancelled build must not report ready")
+	}
+}
+
+func TestEnclosing(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	src := "package main\n\ntype T struct {\n\tA int\n}\n\nfunc (t *T) M() {\n\tprintln(1)\n\tprintln(2)\n}\n"
+	if err := os.WriteFile(path, []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+	idx := NewIndex(dir)
+	idx.UpdateFile(path)
+
+	tests := []struct {
+		start, end int
+		want       string
+	}{
+		{8, 9, "M"},
+		{4, 4, "A"},
+		{3, 5, "T"},
+		{1, 10, ""},
+	}
+	for _, tt := range tests {
+		s, ok := idx.Enclosing(path, tt.start, tt.end)
+		if got := s.Name; !ok && tt.want != "" || ok && got != tt.want {
+			t.Errorf("Enclosing(%d, %d) = %q, %v; want %q", tt.start, tt.end, got, ok, tt.want)
+		}
+	}
+}
+
+func TestIndexLimits(t *testing.T) {
+	dir := t.TempDir()
+	src := []byte("package p\n\nfunc F() {}\n")
+	big := append([]byte("package p\n\nfunc Big() {}\n// "), make([]byte, 2048)...)
+	files := map[string][]byte{
+		"keep.go":          src,
+		"api_generated.go": src,
+		"gen/x.go":         src,
+		"big.go":           big,
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	idx := NewIndex(dir)
+	idx.SetLimits(1024, []string{"*_generated.go", "gen/*.go"})
+	if err := idx.Build(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := idx.Files(); len(got) != 1 || got[0] != "keep.go" {
+		t.Errorf("indexed %v, want only keep.go", got)
+	}
+
+	// UpdateFile and EditFile apply the same limits.
+	idx.UpdateFile(filepath.Join(dir, "api_generated.go"))
+	idx.EditFile(filepath.Join(dir, "keep.go"), src, big)
+	if got := idx.Files(); len(got) != 0 {
+		t.Errorf("after updates indexed %v, want none", got)
+	}
+}
@@ -36,6 +36,70 @@ func ParseFile(path string) ([]Symbol, error) {
 
 // ParseSource parses source bytes and returns top-level symbols.
 func ParseSource(path string, src []byte) ([]Symbol, error) {
+	tree, err := parseTree(path, src, nil)
+	if tree == nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	return extractGo(tree.RootNode(), src), nil
+}
+
+// nonCodeTypes are the node types of comments and string literals, which
+// hold text rather than code.
+var nonCodeTypes = map[string]bool{
+	"comment":                    true,
+	"interpreted_string_literal": true,
+	"raw_string_literal":         true,
+	"rune_literal":               true,
+}
+
+// Span is a region of source from a start to an end point, each a 0-indexed
+// row and byte column. The end is exclusive.
+type Span struct {
+	StartRow, StartCol int
+	EndRow, EndCol     int
+}
+
+// Contains reports whether the byte at row and col lies inside the span.
+func (s Span) Contains(row, col int) bool {
+	if row < s.StartRow || row > s.EndRow {
+		return false
+	}
+	if row == s.StartRow && col < s.StartCol {
+		return false
+	}
+	return row < s.EndRow || col < s.EndCol
+}
+
+// NonCode returns the comments and string literals in src, in source
+// order. ok is false when path has no grammar.
+func NonCode(path string, src []byte) (spans []Span, ok bool) {
+	tree, _ := parseTree(path, src, nil)
+	if tree == nil {
+		return nil, false
+	}
+	defer tree.Close()
+
+	var walk func(n *sitter.Node)
+	walk = func(n *sitter.Node) {
+		if nonCodeTypes[n.Type()] {
+			start, end := n.StartPoint(), n.EndPoint()
+			spans = append(spans, Span{int(start.Row), int(start.Column), int(end.Row), int(end.Column)})
+			return
+		}
+		for i := range int(n.ChildCount()) {
+			walk(n.Child(i))
+		}
+	}
+	walk(tree.RootNode())
+	return spans, true
+}
+
+// parseTree parses src into a syntax tree. If oldTree is non-nil it must
+// already be adjusted with Tree.Edit; tree-sitter then reuses its unchanged
+// subtrees. Returns a nil tree for unsupported files. Caller closes the tree.
+func parseTree(path string, src []byte, oldTree *sitter.Tree) (*sitter.Tree, error) {
 	lang := langForExt(strings.ToLower(filepath.Ext(path)))
 	if lang == nil {
 		return nil, nil
@@ -45,13 +109,43 @@ func ParseSource(path string, src []byte) ([]Symbol, error) {
 	defer parser.Close()
 	parser.SetLanguage(lang)
 
-	tree, err := parser.ParseCtx(context.Background(), nil, src)
-	if err != nil {
-		return nil, err
+	return parser.ParseCtx(context.Background(), oldTree, src)
+}
+
+// editInput computes the tree-sitter edit that turns oldSrc into newSrc by
+// trimming their common prefix and suffix.
+func editInput(oldSrc, newSrc []byte) sitter.EditInput {
+	start := 0
+	for start < len(oldSrc) && start < len(newSrc) && oldSrc[start] == newSrc[start] {
+		start++
 	}
-	defer tree.Close()
+	oldEnd, newEnd := len(oldSrc), len(newSrc)
+	for oldEnd > start && newEnd > start && oldSrc[oldEnd-1] == newSrc[newEnd-1] {
+		oldEnd--
+		newEnd--
+	}
+	return sitter.EditInput{
+		StartIndex:  uint32(start),
+		OldEndIndex: uint32(oldEnd),
+		NewEndIndex: uint32(newEnd),
+		StartPoint:  pointAt(oldSrc, start),
+		OldEndPoint: pointAt(oldSrc, oldEnd),
+		NewEndPoint: pointAt(newSrc, newEnd),
+	}
+}
 
-	return extractGo(tree.RootNode(), src), nil
+// pointAt returns the row/byte-column of a byte offset in src.
+func pointAt(src []byte, offset int) sitter.Point {
+	var p sitter.Point
+	for _, b := range src[:offset] {
+		if b == '\n' {
+			p.Row++
+			p.Column = 0
+		} else {
+			p.Column++
+		}
+	}
+	return p
 }
 
 // extractGo walks a Go AST root and extracts top-level symbols.
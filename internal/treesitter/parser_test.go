@@ -113,3 +113,42 @@ func TestFormatOutline(t *testing.T) {
 		t.Errorf("missing *Server: Start in outline:\n%s", out)
 	}
 }
+
+func TestFormatFileOutline(t *testing.T) {
+	src := []byte(`package main
+
+import "fmt"
+
+type Handler interface {
+	Handle(req string) string
+}
+
+type Server struct {
+	addr string
+}
+
+func main() {
+	fmt.Println("hello")
+}
+
+func (s *Server) Start() error {
+	return nil
+}
+
+const Version = "1.0"
+`)
+	syms, err := ParseSource("main.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `5-7 type Handler interface_type
+  6 Handle(req string) string
+9-11 type Server struct_type
+  17-19 func (s *Server) Start() error
+13-15 func main()
+21 const Version
+`
+	if got := FormatFileOutline(syms); got != want {
+		t.Errorf("outline:\n%s\nwant:\n%s", got, want)
+	}
+}
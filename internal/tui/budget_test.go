@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/xonecas/symb/internal/config"
+	"github.com/xonecas/symb/internal/provider"
+)
+
+// TestSessionBudgetRefusesTurn verifies that submitting once the session
+// token cap is spent keeps the input and sends nothing.
+func TestSessionBudgetRefusesTurn(t *testing.T) {
+	initTheme("vulcan")
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{SessionTokens: 1000}, config.SessionConfig{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+
+	m.totalInputTokens = 900
+	if m.sessionBudgetSpent() {
+		t.Fatal("budget spent too early")
+	}
+	if got := m.remainingTokens(); got != 100 {
+		t.Errorf("remainingTokens = %d, want 100", got)
+	}
+
+	m.totalOutputTokens = 100
+	m.agentInput.SetValue("hello")
+	mdl, cmd, handled := m.handleSubmit()
+	if !handled || cmd != nil {
+		t.Fatalf("handled=%v cmd=%v, want refusal without a command", handled, cmd != nil)
+	}
+	if mdl.agentInput.Value() != "hello" {
+		t.Errorf("input cleared on refusal: %q", mdl.agentInput.Value())
+	}
+}
+
+// TestContextGauge verifies the status bar shows the last call's share of
+// the model's context window, honouring configured overrides.
+func TestContextGauge(t *testing.T) {
+	initTheme("vulcan")
+	provider.SetContextWindows(map[string]int{"tiny-model": 10_000})
+	defer provider.SetContextWindows(nil)
+	m := New(nil, nil, nil, nil, "tiny-model", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+
+	status := func() string {
+		var b strings.Builder
+		m.renderStatusBar(&b, m.styles.BgFill)
+		return ansi.Strip(b.String())
+	}
+	if strings.Contains(status(), "ctx ") {
+		t.Error("gauge shown before any API call")
+	}
+	m.turnContextTokens = 8_500
+	if got := m.contextUtilization(); got != 0.85 {
+		t.Errorf("utilization = %v, want 0.85", got)
+	}
+	if !strings.Contains(status(), "ctx 85%") {
+		t.Error("status bar missing the ctx 85% gauge")
+	}
+}
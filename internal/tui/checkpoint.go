@@ -0,0 +1,122 @@
+package tui
+
+import (
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/symb/internal/store"
+)
+
+// checkpointLoadedMsg carries the checkpoint saved for the session and
+// the DB ids of its user messages, oldest first.
+type checkpointLoadedMsg struct {
+	cp      store.Checkpoint
+	userIDs []int64
+}
+
+// loadCheckpointCmd reads the session's last checkpoint from the store.
+func (m Model) loadCheckpointCmd() tea.Cmd {
+	if m.store == nil {
+		return nil
+	}
+	db, sessionID := m.store, m.sessionID
+	return func() tea.Msg {
+		cp, ok, err := db.LoadCheckpoint(sessionID)
+		if err != nil {
+			log.Warn().Err(err).Str("session", sessionID).Msg("failed to load checkpoint")
+			return nil
+		}
+		if !ok {
+			return nil
+		}
+		userIDs, err := db.UserMessageIDs(sessionID)
+		if err != nil {
+			log.Warn().Err(err).Str("session", sessionID).Msg("failed to load user message ids")
+		}
+		return checkpointLoadedMsg{cp: cp, userIDs: userIDs}
+	}
+}
+
+// handleCheckpointLoaded restores the token totals and turn boundaries
+// of a resumed session, unless a turn already ran. A turn is restored
+// only if its user message is still in the displayed history, so undo
+// works on the turns of an earlier run.
+func (m Model) handleCheckpointLoaded(msg checkpointLoadedMsg) Model {
+	if m.totalInputTokens+m.totalOutputTokens > 0 || len(m.turnBoundaries) > 0 {
+		return m
+	}
+	m.totalInputTokens, m.totalOutputTokens = msg.cp.InputTokens, msg.cp.OutputTokens
+	// The ids and the resumed user messages pair up only if both hold
+	// the whole session.
+	if len(msg.userIDs) != len(m.historyTurns) {
+		return m
+	}
+	convIdx := make(map[int64]int, len(msg.userIDs))
+	for i, id := range msg.userIDs {
+		convIdx[id] = m.historyTurns[i]
+	}
+	for _, tc := range msg.cp.Turns {
+		idx, ok := convIdx[tc.MsgID]
+		if !ok {
+			continue
+		}
+		m.turnBoundaries = append(m.turnBoundaries, turnBoundary{
+			convIdx:      idx,
+			dbMsgID:      tc.MsgID,
+			inputTokens:  tc.InputTokens,
+			outputTokens: tc.OutputTokens,
+		})
+	}
+	return m
+}
+
+// markCheckpoint notes that state a checkpoint covers has changed, and
+// counts a finished turn towards the next one.
+func (m *Model) markCheckpoint(now time.Time, turnDone bool) {
+	m.checkpointDirtyAt = now
+	if turnDone {
+		m.checkpointTurnsDone++
+	}
+}
+
+// tickCheckpoint saves a checkpoint once checkpointTurns turns have
+// finished since the last one, or once the changed state has sat for
+// checkpointIdle with no turn running. Ticks debounce the writes.
+func (m *Model) tickCheckpoint(now time.Time) tea.Cmd {
+	if m.store == nil || m.checkpointDirtyAt.IsZero() || m.llmInFlight {
+		return nil
+	}
+	turns := m.checkpointTurns > 0 && m.checkpointTurnsDone >= m.checkpointTurns
+	idle := m.checkpointIdle > 0 && now.Sub(m.checkpointDirtyAt) >= m.checkpointIdle
+	if !turns && !idle {
+		return nil
+	}
+	return m.saveCheckpointCmd()
+}
+
+// checkpoint collects the session's plan, token totals and turn
+// boundaries.
+func (m Model) checkpoint() store.Checkpoint {
+	cp := store.Checkpoint{InputTokens: m.totalInputTokens, OutputTokens: m.totalOutputTokens}
+	if m.scratchpad != nil {
+		cp.Scratchpad = m.scratchpad.Content()
+	}
+	for _, tb := range m.turnBoundaries {
+		cp.Turns = append(cp.Turns, store.TurnCheckpoint{MsgID: tb.dbMsgID, InputTokens: tb.inputTokens, OutputTokens: tb.outputTokens})
+	}
+	return cp
+}
+
+// saveCheckpointCmd persists the session's checkpoint.
+func (m *Model) saveCheckpointCmd() tea.Cmd {
+	m.checkpointDirtyAt, m.checkpointTurnsDone = time.Time{}, 0
+	cp := m.checkpoint()
+	db, sessionID := m.store, m.sessionID
+	return func() tea.Msg {
+		if err := db.SaveCheckpoint(sessionID, cp); err != nil {
+			log.Warn().Err(err).Str("session", sessionID).Msg("failed to save checkpoint")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// clipboard copies and pastes through a native tool when one is usable,
+// otherwise through the terminal with OSC 52 (which works over SSH/tmux).
+type clipboard struct {
+	copyCmd  []string // nil = OSC 52
+	pasteCmd []string // nil = OSC 52 read
+}
+
+// clipboardTool is a native clipboard command pair.
+type clipboardTool struct {
+	copy, paste []string
+}
+
+// nativeClipboardTools returns candidate tools for this platform, in order
+// of preference.
+func nativeClipboardTools() []clipboardTool {
+	switch runtime.GOOS {
+	case "darwin":
+		return []clipboardTool{{copy: []string{"pbcopy"}, paste: []string{"pbpaste"}}}
+	case "windows":
+		return []clipboardTool{{copy: []string{"clip.exe"}}}
+	}
+	var tools []clipboardTool
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		tools = append(tools, clipboardTool{copy: []string{"wl-copy"}, paste: []string{"wl-paste", "--no-newline"}})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		tools = append(tools,
+			clipboardTool{copy: []string{"xclip", "-selection", "clipboard"}, paste: []string{"xclip", "-selection", "clipboard", "-o"}},
+			clipboardTool{copy: []string{"xsel", "--clipboard", "--input"}, paste: []string{"xsel", "--clipboard", "--output"}},
+		)
+	}
+	return tools
+}
+
+// detectClipboard picks the clipboard backend at startup. mode is "auto",
+// "native" or "osc52". In auto mode remote (SSH) sessions use OSC 52, since
+// a native tool would write to the remote machine's clipboard.
+func detectClipboard(mode string, lookPath func(string) (string, error)) clipboard {
+	if mode == "osc52" {
+		return clipboard{}
+	}
+	if mode != "native" && (os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != "") {
+		return clipboard{}
+	}
+	for _, t := range nativeClipboardTools() {
+		if _, err := lookPath(t.copy[0]); err != nil {
+			continue
+		}
+		c := clipboard{copyCmd: t.copy}
+		if t.paste != nil {
+			if _, err := lookPath(t.paste[0]); err == nil {
+				c.pasteCmd = t.paste
+			}
+		}
+		return c
+	}
+	return clipboard{}
+}
+
+// copy writes text to the clipboard, falling back to OSC 52 if the native
+// tool fails.
+func (c clipboard) copy(text string) tea.Cmd {
+	if c.copyCmd == nil {
+		return tea.SetClipboard(text)
+	}
+	return func() tea.Msg {
+		//nolint:gosec // G204: command comes from the fixed tool list
+		cmd := exec.Command(c.copyCmd[0], c.copyCmd[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			log.Warn().Err(err).Str("cmd", c.copyCmd[0]).Msg("native clipboard copy failed; using OSC 52")
+			return tea.SetClipboard(text)()
+		}
+		return nil
+	}
+}
+
+// paste reads the clipboard and delivers it as a tea.ClipboardMsg.
+func (c clipboard) paste() tea.Cmd {
+	if c.pasteCmd == nil {
+		return tea.ReadClipboard
+	}
+	return func() tea.Msg {
+		//nolint:gosec // G204: command comes from the fixed tool list
+		out, err := exec.Command(c.pasteCmd[0], c.pasteCmd[1:]...).Output()
+		if err != nil {
+			log.Warn().Err(err).Str("cmd", c.pasteCmd[0]).Msg("native clipboard paste failed; using OSC 52")
+			return tea.ReadClipboard()
+		}
+		return tea.ClipboardMsg{Content: string(out)}
+	}
+}
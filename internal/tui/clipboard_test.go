@@ -0,0 +1,38 @@
+package tui
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+)
+
+func TestDetectClipboard(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("tool candidates are platform specific")
+	}
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("DISPLAY", ":0")
+	t.Setenv("SSH_TTY", "")
+	t.Setenv("SSH_CONNECTION", "")
+	onlyXsel := func(name string) (string, error) {
+		if name == "xsel" {
+			return "/usr/bin/xsel", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	if c := detectClipboard("auto", onlyXsel); len(c.copyCmd) == 0 || c.copyCmd[0] != "xsel" || c.pasteCmd == nil {
+		t.Errorf("auto picked %+v, want xsel", c)
+	}
+	if c := detectClipboard("osc52", onlyXsel); c.copyCmd != nil {
+		t.Errorf("osc52 picked native %v", c.copyCmd)
+	}
+
+	t.Setenv("SSH_TTY", "/dev/pts/1")
+	if c := detectClipboard("auto", onlyXsel); c.copyCmd != nil {
+		t.Errorf("auto over SSH picked native %v, want OSC 52", c.copyCmd)
+	}
+	if c := detectClipboard("native", onlyXsel); c.copyCmd == nil {
+		t.Error("native over SSH should still use the tool")
+	}
+}
@@ -0,0 +1,26 @@
+package tui
+
+import (
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/colorprofile"
+)
+
+// colorProfiles maps the ui.colors setting to a terminal color profile.
+var colorProfiles = map[string]colorprofile.Profile{
+	"truecolor": colorprofile.TrueColor,
+	"256":       colorprofile.ANSI256,
+	"16":        colorprofile.ANSI,
+	"none":      colorprofile.Ascii,
+}
+
+// ColorOptions returns the program options for the ui.colors setting. For
+// "auto" it returns none and Bubble Tea detects the profile itself; either
+// way the renderer downsamples every style, highlighted code included, to
+// the profile.
+func ColorOptions(mode string) []tea.ProgramOption {
+	p, ok := colorProfiles[mode]
+	if !ok {
+		return nil
+	}
+	return []tea.ProgramOption{tea.WithColorProfile(p)}
+}
@@ -0,0 +1,211 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/xonecas/symb/internal/hashline"
+	"github.com/xonecas/symb/internal/llm"
+	"github.com/xonecas/symb/internal/provider"
+	"github.com/xonecas/symb/internal/tui/modal"
+)
+
+// llmConfirmMsg asks the user to approve a tool call. The turn goroutine
+// blocks on reply until the user answers or the turn is cancelled.
+type llmConfirmMsg struct {
+	call  provider.ToolCall
+	reply chan<- confirmReply
+}
+
+type confirmReply struct {
+	approved bool
+	reason   string
+}
+
+// toolConfirm is the open approval prompt.
+type toolConfirm struct {
+	view     modal.ToolView
+	proposal string
+	reply    chan<- confirmReply
+	typing   bool // collecting a rejection reason
+	reason   []rune
+}
+
+const confirmHint = "y approve · n reject · r reject with reason"
+
+// confirmToolCall returns the llm.ConfirmFunc for a turn, or nil when no
+// tools require approval.
+func confirmToolCall(ch chan<- tea.Msg, tools []string) llm.ConfirmFunc {
+	if len(tools) == 0 {
+		return nil
+	}
+	return func(ctx context.Context, call provider.ToolCall) (bool, string) {
+		if !slices.Contains(tools, call.Name) {
+			return true, ""
+		}
+		reply := make(chan confirmReply, 1)
+		select {
+		case ch <- llmConfirmMsg{call: call, reply: reply}:
+		case <-ctx.Done():
+			return false, "turn cancelled"
+		}
+		select {
+		case r := <-reply:
+			return r.approved, r.reason
+		case <-ctx.Done():
+			return false, "turn cancelled"
+		}
+	}
+}
+
+func (m *Model) openConfirmModal(msg llmConfirmMsg) {
+	proposal := confirmProposal(msg.call)
+	m.confirmModal = &toolConfirm{
+		view: modal.NewToolView("Approve "+msg.call.Name+"?  "+confirmHint, proposal, modal.Colors{
+			Fg:     palette.Fg,
+			Bg:     palette.Bg,
+			Dim:    palette.Dim,
+			SelFg:  palette.Bg,
+			SelBg:  palette.Fg,
+			Border: palette.Border,
+		}),
+		proposal: proposal,
+		reply:    msg.reply,
+	}
+}
+
+// answerConfirm replies to the waiting turn and closes the prompt.
+func (m *Model) answerConfirm(approved bool, reason string) {
+	if m.confirmModal == nil {
+		return
+	}
+	m.confirmModal.reply <- confirmReply{approved: approved, reason: reason}
+	m.confirmModal = nil
+}
+
+func (m *Model) updateConfirmModal(msg tea.Msg) (Model, tea.Cmd, bool) {
+	c := m.confirmModal
+	if c == nil {
+		return *m, nil, false
+	}
+	kp, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		if _, isMouse := msg.(tea.MouseMsg); isMouse {
+			c.view.HandleMsg(msg)
+			return *m, nil, true
+		}
+		return *m, nil, false
+	}
+
+	if c.typing {
+		switch kp.Keystroke() {
+		case "enter":
+			m.answerConfirm(false, strings.TrimSpace(string(c.reason)))
+		case "esc":
+			c.typing = false
+			c.reason = nil
+			c.view.SetContent(c.proposal)
+		case "backspace":
+			if len(c.reason) > 0 {
+				c.reason = c.reason[:len(c.reason)-1]
+			}
+			c.view.SetContent(c.proposal + "\n\nReason: " + string(c.reason) + "▏")
+		default:
+			if kp.Text != "" {
+				c.reason = append(c.reason, []rune(kp.Text)...)
+				c.view.SetContent(c.proposal + "\n\nReason: " + string(c.reason) + "▏")
+			}
+		}
+		return *m, nil, true
+	}
+
+	switch kp.Keystroke() {
+	case "y", "enter":
+		m.answerConfirm(true, "")
+	case "n", "esc":
+		m.answerConfirm(false, "")
+	case "r":
+		c.typing = true
+		c.view.SetContent(c.proposal + "\n\nReason: ▏")
+	default:
+		// Scroll keys; ToolView's close keys are handled above.
+		c.view.HandleMsg(msg)
+	}
+	return *m, nil, true
+}
+
+// confirmProposal renders what a tool call is about to do: the command for
+// Shell, a line diff for Edit, and the raw arguments otherwise.
+func confirmProposal(call provider.ToolCall) string {
+	switch call.Name {
+	case "Shell":
+		var args struct {
+			Command string `json:"command"`
+		}
+		if json.Unmarshal(call.Arguments, &args) == nil && args.Command != "" {
+			return "$ " + args.Command
+		}
+	case "Edit":
+		if p := editProposal(call.Arguments); p != "" {
+			return p
+		}
+	}
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, call.Arguments, "", "  "); err != nil {
+		return string(call.Arguments)
+	}
+	return pretty.String()
+}
+
+// editProposal renders an Edit call as a diff against the file on disk.
+func editProposal(raw json.RawMessage) string {
+	var args struct {
+		File      string `json:"file"`
+		Operation string `json:"operation"`
+		Start     string `json:"start"`
+		End       string `json:"end"`
+		After     string `json:"after"`
+		Content   string `json:"content"`
+	}
+	if json.Unmarshal(raw, &args) != nil || args.File == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n\n", args.Operation, args.File)
+
+	var removed []string
+	if args.Operation == "replace" || args.Operation == "delete" {
+		start, err1 := hashline.ParseAnchor(args.Start)
+		end, err2 := hashline.ParseAnchor(args.End)
+		//nolint:gosec // G304: path comes from the tool call being reviewed
+		data, err3 := os.ReadFile(args.File)
+		if err1 == nil && err2 == nil && err3 == nil {
+			lines := strings.Split(string(data), "\n")
+			if start.Num >= 1 && end.Num >= start.Num && end.Num <= len(lines) {
+				removed = lines[start.Num-1 : end.Num]
+			}
+		}
+		if removed == nil {
+			fmt.Fprintf(&b, "lines %s..%s\n", args.Start, args.End)
+		}
+	}
+	if args.Operation == "insert" {
+		fmt.Fprintf(&b, "after %s\n", args.After)
+	}
+	for _, l := range removed {
+		b.WriteString("- " + l + "\n")
+	}
+	if args.Operation != "delete" {
+		for _, l := range strings.Split(args.Content, "\n") {
+			b.WriteString("+ " + l + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/xonecas/symb/internal/config"
+	"github.com/xonecas/symb/internal/provider"
+)
+
+// TestConfirmToolCall verifies that gated tools round-trip through the
+// approval prompt and ungated tools pass straight through.
+func TestConfirmToolCall(t *testing.T) {
+	initTheme("vulcan")
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+	m.llmInFlight = true
+
+	ch := make(chan tea.Msg, 1)
+	confirm := confirmToolCall(ch, []string{"Shell"})
+
+	if ok, _ := confirm(context.Background(), provider.ToolCall{Name: "Read"}); !ok {
+		t.Fatal("ungated tool should be approved without prompting")
+	}
+
+	type result struct {
+		ok     bool
+		reason string
+	}
+	done := make(chan result, 1)
+	call := provider.ToolCall{Name: "Shell", Arguments: json.RawMessage(`{"command":"rm -rf build"}`)}
+	go func() {
+		ok, reason := confirm(context.Background(), call)
+		done <- result{ok, reason}
+	}()
+
+	updated, _ = m.Update(llmBatchMsg{<-ch})
+	m = updated.(Model)
+	if m.confirmModal == nil {
+		t.Fatal("confirm modal not opened")
+	}
+	if m.confirmModal.proposal != "$ rm -rf build" {
+		t.Errorf("proposal = %q", m.confirmModal.proposal)
+	}
+
+	for _, k := range []tea.KeyPressMsg{
+		{Code: 'r', Text: "r"},
+		{Code: 'n', Text: "n"},
+		{Code: 'o', Text: "o"},
+		{Code: tea.KeyEnter},
+	} {
+		updated, _ = m.Update(k)
+		m = updated.(Model)
+	}
+	if m.confirmModal != nil {
+		t.Fatal("confirm modal still open after answering")
+	}
+	got := <-done
+	if got.ok || got.reason != "no" {
+		t.Errorf("got approved=%v reason=%q, want rejected with reason %q", got.ok, got.reason, "no")
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"charm.land/lipgloss/v2"
 	"github.com/xonecas/symb/internal/highlight"
+	"github.com/xonecas/symb/internal/llm"
 	"github.com/xonecas/symb/internal/provider"
 )
 
@@ -34,6 +35,44 @@ func highlightMarkdown(text string, fallback lipgloss.Style) []string {
 	return highlight.SplitLines(hl)
 }
 
+// highlightStreamingMarkdown highlights partial markdown that may still be
+// arriving. An unclosed code fence is temporarily closed so Chroma tokenises
+// the open block as code instead of prose; the synthetic fence line is
+// dropped from the output. The final pass in applyAssistantMsg re-renders
+// the complete text.
+func highlightStreamingMarkdown(text string, fallback lipgloss.Style) []string {
+	fence := openFence(text)
+	if fence == "" {
+		return highlightMarkdown(text, fallback)
+	}
+	lines := highlightMarkdown(text+"\n"+fence, fallback)
+	if len(lines) == 0 {
+		return lines
+	}
+	return lines[:len(lines)-1]
+}
+
+// openFence returns the fence marker (``` or ~~~) of a code block left open
+// at the end of text, or "" if all fences are closed.
+func openFence(text string) string {
+	var open string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		for _, marker := range []string{"```", "~~~"} {
+			if !strings.HasPrefix(trimmed, marker) {
+				continue
+			}
+			if open == "" {
+				open = marker
+			} else if open == marker && strings.TrimSpace(trimmed) == marker {
+				open = ""
+			}
+			break
+		}
+	}
+	return open
+}
+
 // styledLines applies a lipgloss style to each line in a multi-line text.
 // No wrapping — lines are stored raw for later wrapping at render time.
 func styledLines(text string, style lipgloss.Style) []string {
@@ -55,48 +94,160 @@ func textEntries(lines ...string) []convEntry {
 }
 
 // appendConv appends entries and returns whether we were at bottom
-// (for sticky scroll).
+// (for sticky scroll). Scrolled away from the bottom, the view stays on
+// the lines being read unless auto_scroll is "always".
 func (m *Model) appendConv(entries ...convEntry) bool {
+	if m.autoScrollAlways {
+		m.scrollOffset = 0
+	}
 	atBottom := m.scrollOffset == 0
 	if !atBottom {
-		addedLines := 0
-		w := m.convWidth()
-		for _, entry := range entries {
-			if entry.display == "" {
-				addedLines++
-				continue
-			}
-			if w <= 0 {
-				addedLines++
-				continue
-			}
-			addedLines += len(wrapANSI(entry.display, w))
-		}
-		m.scrollOffset += addedLines
+		m.scrollOffset += m.wrappedHeight(entries)
 	}
 	m.convEntries = append(m.convEntries, entries...)
 	return atBottom
 }
 
+// wrappedHeight returns how many conversation lines entries take.
+func (m *Model) wrappedHeight(entries []convEntry) int {
+	lines := 0
+	w := m.convWidth()
+	for _, entry := range entries {
+		if entry.display == "" || w <= 0 {
+			lines++
+			continue
+		}
+		lines += len(wrapANSI(entry.display, w))
+	}
+	return lines
+}
+
 // appendText is a convenience to append plain text entries.
 func (m *Model) appendText(lines ...string) bool {
 	return m.appendConv(textEntries(lines...)...)
 }
 
+// streamSegment is a run of consecutive reasoning or content deltas.
+type streamSegment struct {
+	reasoning bool
+	text      string
+}
+
+// appendStreamDelta adds a delta to the current run, starting a new run
+// whenever the stream switches between reasoning and content.
+func (m *Model) appendStreamDelta(reasoning bool, text string) {
+	m.streamSegments = appendSegment(m.streamSegments, streamSegment{reasoning: reasoning, text: text})
+}
+
+// appendSegment adds seg to segs, joining it to the last segment when both
+// are reasoning or both content.
+func appendSegment(segs []streamSegment, seg streamSegment) []streamSegment {
+	if n := len(segs); n > 0 && segs[n-1].reasoning == seg.reasoning {
+		segs[n-1].text += seg.text
+		return segs
+	}
+	return append(segs, seg)
+}
+
+// messageSegments splits an assistant message's reasoning and content into
+// the runs they streamed in, as recorded in order. Without an order that
+// accounts for the whole message, reasoning goes first.
+func messageSegments(reasoning, content string, order []provider.Segment) []streamSegment {
+	fallback := []streamSegment{{reasoning: true, text: reasoning}, {text: content}}
+	var segs []streamSegment
+	for _, o := range order {
+		rest := &content
+		if o.Reasoning {
+			rest = &reasoning
+		}
+		if o.Len < 0 || o.Len > len(*rest) {
+			return fallback
+		}
+		segs = appendSegment(segs, streamSegment{reasoning: o.Reasoning, text: (*rest)[:o.Len]})
+		*rest = (*rest)[o.Len:]
+	}
+	if len(segs) == 0 || reasoning != "" || content != "" {
+		return fallback
+	}
+	return segs
+}
+
+// joinFences keeps each code block in one content run: reasoning that
+// streamed while a code fence was open is moved after the content that
+// closes it.
+func joinFences(segs []streamSegment) []streamSegment {
+	var out, held []streamSegment
+	open := false
+	for _, seg := range segs {
+		switch {
+		case !open:
+			out = appendSegment(out, seg)
+			open = !seg.reasoning && openFence(out[len(out)-1].text) != ""
+		case seg.reasoning:
+			held = appendSegment(held, seg)
+		default:
+			out = appendSegment(out, seg)
+			if openFence(out[len(out)-1].text) == "" {
+				for _, h := range held {
+					out = appendSegment(out, h)
+				}
+				held, open = nil, false
+			}
+		}
+	}
+	for _, h := range held {
+		out = appendSegment(out, h)
+	}
+	return out
+}
+
+// segmentEntries styles one segment: reasoning muted, content as markdown.
+// Final content is rendered as finished assistant prose with file links.
+func segmentEntries(seg streamSegment, final bool, sty Styles) []convEntry {
+	text := strings.Trim(seg.text, "\n")
+	switch {
+	case seg.reasoning:
+		return textEntries(styledLines(text, sty.Muted)...)
+	case final:
+		return assistantEntries(text, sty)
+	}
+	return textEntries(highlightStreamingMarkdown(text, sty.Text)...)
+}
+
 // rebuildStreamEntries replaces any existing streaming entries with fresh
-// styled entries from the current streamingReasoning and streamingContent.
+// styled entries for the stream segments, in arrival order and separated
+// by blank lines.
 // Wrapping is deferred to View() — this only updates convEntries.
 func (m *Model) rebuildStreamEntries() {
+	if m.autoScrollAlways {
+		m.scrollOffset = 0
+	}
+	// Scrolled away from the bottom, keep the view still as the stream
+	// grows: the offset counts lines up from the bottom.
+	held := m.scrollOffset > 0
+
 	// Remove old streaming entries.
 	if m.streamEntryStart >= 0 && m.streamEntryStart <= len(m.convEntries) {
+		if held {
+			m.scrollOffset -= m.wrappedHeight(m.convEntries[m.streamEntryStart:])
+		}
 		m.convEntries = m.convEntries[:m.streamEntryStart]
 	}
 
-	if m.streamingReasoning != "" {
-		m.convEntries = append(m.convEntries, textEntries(styledLines(m.streamingReasoning, m.styles.Muted)...)...)
+	rebuilt := len(m.convEntries)
+	first := true
+	for _, seg := range joinFences(m.streamSegments) {
+		if strings.TrimSpace(seg.text) == "" {
+			continue
+		}
+		if !first {
+			m.convEntries = append(m.convEntries, convEntry{kind: entryText})
+		}
+		first = false
+		m.convEntries = append(m.convEntries, segmentEntries(seg, false, m.styles)...)
 	}
-	if m.streamingContent != "" {
-		m.convEntries = append(m.convEntries, textEntries(highlightMarkdown(m.streamingContent, m.styles.Text)...)...)
+	if held {
+		m.scrollOffset = max(m.scrollOffset+m.wrappedHeight(m.convEntries[rebuilt:]), 0)
 	}
 }
 
@@ -115,6 +266,11 @@ func (m *Model) wrappedConvLines() []string {
 			source = append(source, i)
 		} else {
 			wrapped := wrapANSI(entry.display, w)
+			if entry.kind == entryToolResult && entry.expanded {
+				for _, el := range m.expandedToolResultLines(entry) {
+					wrapped = append(wrapped, wrapANSI(el, w)...)
+				}
+			}
 			for range wrapped {
 				source = append(source, i)
 			}
@@ -126,6 +282,75 @@ func (m *Model) wrappedConvLines() []string {
 	return lines
 }
 
+// maxExpandLines caps how many body lines an expanded tool result shows inline.
+// Anything longer is left to the [view] modal.
+const maxExpandLines = 10
+
+// toolResultCaret renders the expand/collapse control for a tool result.
+func toolResultCaret(sty Styles, expanded bool) string {
+	if expanded {
+		return sty.ToolArrow.Render("▾")
+	}
+	return sty.ToolArrow.Render("▸")
+}
+
+// toolResultArrow renders the "←" marker of a tool result, in the error
+// color when the call failed.
+func toolResultArrow(sty Styles, isError bool) string {
+	if isError {
+		return sty.Error.Render("←")
+	}
+	return sty.ToolArrow.Render("←")
+}
+
+// toolResultBody returns the lines of a tool result below its summary line,
+// excluding the LSP diagnostics block (rendered as separate entries).
+// Returns nil when there is nothing to expand.
+func toolResultBody(content string) []string {
+	body, _ := extractDiagLines(content)
+	_, rest, ok := strings.Cut(body, "\n")
+	if !ok {
+		return nil
+	}
+	rest = strings.Trim(rest, "\n")
+	if strings.TrimSpace(rest) == "" {
+		return nil
+	}
+	return strings.Split(rest, "\n")
+}
+
+// expandedToolResultLines renders the bounded inline expansion of a tool result.
+func (m *Model) expandedToolResultLines(entry convEntry) []string {
+	body := toolResultBody(entry.full)
+	shown := body
+	if len(shown) > maxExpandLines {
+		shown = shown[:maxExpandLines]
+	}
+	indent := m.styles.BgFill.Render("   ")
+	sty := m.styles.Dim
+	if entry.isError {
+		sty = m.styles.Error
+	}
+	out := make([]string, 0, len(shown)+1)
+	for _, l := range shown {
+		out = append(out, indent+sty.Render(strings.ReplaceAll(l, "\t", "    ")))
+	}
+	if more := len(body) - len(shown); more > 0 {
+		out = append(out, indent+m.styles.Dim.Render(fmt.Sprintf("… %d more lines", more)))
+	}
+	return out
+}
+
+// toggleToolResult flips the expanded state of the tool result at entryIdx,
+// swapping the caret in its display line.
+func (m *Model) toggleToolResult(entryIdx int) {
+	entry := &m.convEntries[entryIdx]
+	oldCaret := toolResultCaret(m.styles, entry.expanded)
+	entry.expanded = !entry.expanded
+	entry.display = toolResultCaret(m.styles, entry.expanded) + strings.TrimPrefix(entry.display, oldCaret)
+	m.frameLines = nil
+}
+
 // formatTokens formats a token count for display (e.g. 1234 -> "1.2k").
 func formatTokens(n int) string {
 	if n < 1000 {
@@ -188,29 +413,35 @@ func (m *Model) visibleStartLine() int {
 }
 
 // historyConvEntries rebuilds conversation display entries from loaded history.
-func historyConvEntries(msgs []provider.Message, sty Styles) []convEntry {
-	var entries []convEntry
+// userStarts holds the entry index at which each user message starts.
+func historyConvEntries(msgs []provider.Message, sty Styles) (entries []convEntry, userStarts []int) {
+	calls := make(map[string]provider.ToolCall)
 	for _, msg := range msgs {
 		switch msg.Role {
 		case "system":
 			// not displayed
 		case "user":
+			userStarts = append(userStarts, len(entries))
 			if msg.Content == "" {
 				continue
 			}
+			if strings.HasPrefix(msg.Content, llm.AutoContinuePrompt) {
+				entries = append(entries, textEntries("", sty.Dim.Render("(auto-continuing)"), "")...)
+				continue
+			}
 			entries = append(entries, convEntry{display: "", kind: entryText})
 			entries = append(entries, textEntries(highlightMarkdown(msg.Content, sty.Text)...)...)
 			entries = append(entries, convEntry{display: "", kind: entryText})
 		case roleAssistant:
-			if msg.Reasoning != "" {
-				entries = append(entries, textEntries(styledLines(msg.Reasoning, sty.Muted)...)...)
-				entries = append(entries, convEntry{display: "", kind: entryText})
-			}
-			if msg.Content != "" {
-				entries = append(entries, textEntries(highlightMarkdown(msg.Content, sty.Text)...)...)
+			for _, seg := range joinFences(messageSegments(msg.Reasoning, msg.Content, msg.Segments)) {
+				if strings.TrimSpace(seg.text) == "" {
+					continue
+				}
+				entries = append(entries, segmentEntries(seg, true, sty)...)
 				entries = append(entries, convEntry{display: "", kind: entryText})
 			}
 			for _, tc := range msg.ToolCalls {
+				calls[tc.ID] = tc
 				display := sty.ToolArrow.Render("→ ") + sty.BgFill.Render("  ") + sty.ToolCall.Render(formatToolCall(tc))
 				entries = append(entries, convEntry{display: display, kind: entryToolCall})
 			}
@@ -221,21 +452,29 @@ func historyConvEntries(msgs []provider.Message, sty Styles) []convEntry {
 				if len(body) > 200 {
 					body = body[:200] + "…"
 				}
-				arrow := sty.ToolArrow.Render("← ") + sty.BgFill.Render("  ")
-				display := arrow + sty.Dim.Render(body) + sty.BgFill.Render("  ") + sty.Clickable.Render("view")
-
-				var filePath string
-				if sm := toolResultFileRe.FindStringSubmatch(msg.Content); sm != nil {
-					filePath = sm[1]
+				arrow := toolResultArrow(sty, msg.IsError) + sty.BgFill.Render("   ")
+				if len(toolResultBody(msg.Content)) > 0 {
+					arrow = toolResultCaret(sty, false) + sty.BgFill.Render(" ") + arrow
 				}
+				bodySty := sty.Dim
+				if msg.IsError {
+					bodySty = sty.Error
+				}
+				display := arrow + bodySty.Render(body) + sty.BgFill.Render("  ") + sty.Clickable.Render("view")
+
+				tc := calls[msg.ToolCallID]
+				filePath, line := toolResultLocation(tc, msg.Content)
 				entries = append(entries, convEntry{
 					display:  display,
 					kind:     entryToolResult,
 					filePath: filePath,
 					full:     msg.Content,
+					line:     line,
+					toolName: tc.Name,
+					isError:  msg.IsError,
 				})
 			}
 		}
 	}
-	return entries
+	return entries, userStarts
 }
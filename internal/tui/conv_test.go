@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/xonecas/symb/internal/config"
+	"github.com/xonecas/symb/internal/provider"
+)
+
+func TestOpenFence(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"plain text", ""},
+		{"```go\nfunc main() {}\n```", ""},
+		{"intro\n```go\nfunc main() {", "```"},
+		{"~~~\ncode", "~~~"},
+		{"```\n~~~\nstill code", "```"},
+		{"```\ncode\n```\nmore\n```sh", "```"},
+	}
+	for _, tt := range tests {
+		if got := openFence(tt.text); got != tt.want {
+			t.Errorf("openFence(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+// TestAutoScrollHold verifies that a reply streaming in leaves the view
+// alone once the user has scrolled up, follows again at the bottom, and
+// always follows with auto_scroll = "always".
+func TestAutoScrollHold(t *testing.T) {
+	initTheme("vulcan")
+	setup := func(ui config.UIConfig) Model {
+		m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, ui, config.LimitsConfig{}, config.SessionConfig{})
+		updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+		m = updated.(Model)
+		for i := range 100 {
+			m.appendText(fmt.Sprintf("line %d", i))
+		}
+		m.llmInFlight = true
+		return m
+	}
+	stream := func(m *Model, text string) {
+		updated, _ := m.Update(llmBatchMsg{llmContentDeltaMsg{content: text}})
+		*m = updated.(Model)
+		m.rebuildStreamEntries()
+		m.frameLines = nil
+	}
+
+	m := setup(config.UIConfig{})
+	stream(&m, "first\n")
+	m.scrollConv(10, len(m.wrappedConvLines()))
+	m.frameLines = nil
+	start := m.visibleStartLine()
+	stream(&m, "more\nand more\nand more\n")
+	if got := m.visibleStartLine(); got != start || m.scrollOffset == 0 {
+		t.Errorf("scrolled-up view moved: start %d -> %d, offset %d", start, got, m.scrollOffset)
+	}
+	updated, _ := m.Update(llmBatchMsg{llmAssistantMsg{content: "first\nmore\nand more\nand more\ndone"}})
+	m = updated.(Model)
+	m.frameLines = nil
+	if got := m.visibleStartLine(); got != start {
+		t.Errorf("final message moved the view: start %d -> %d", start, got)
+	}
+
+	m.scrollConv(-m.scrollOffset, len(m.wrappedConvLines()))
+	stream(&m, "next reply\n")
+	if m.scrollOffset != 0 {
+		t.Errorf("at the bottom, offset = %d, want 0", m.scrollOffset)
+	}
+
+	m = setup(config.UIConfig{AutoScroll: "always"})
+	stream(&m, "first\n")
+	m.scrollConv(10, len(m.wrappedConvLines()))
+	stream(&m, "more\n")
+	if m.scrollOffset != 0 {
+		t.Errorf(`auto_scroll "always": offset = %d, want 0`, m.scrollOffset)
+	}
+}
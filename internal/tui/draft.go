@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// draftDebounce is how long the input must sit unchanged before it is saved
+// as the session's draft.
+const draftDebounce = time.Second
+
+// draftLoadedMsg carries the draft saved for the session.
+type draftLoadedMsg struct {
+	text string
+}
+
+// loadDraftCmd reads the session's unsent input from the store.
+func (m Model) loadDraftCmd() tea.Cmd {
+	if m.store == nil {
+		return nil
+	}
+	db, sessionID := m.store, m.sessionID
+	return func() tea.Msg {
+		text, err := db.LoadDraft(sessionID)
+		if err != nil {
+			log.Warn().Err(err).Str("session", sessionID).Msg("failed to load draft")
+			return nil
+		}
+		return draftLoadedMsg{text: text}
+	}
+}
+
+// handleDraftLoaded restores the draft unless the user already started
+// typing.
+func (m Model) handleDraftLoaded(msg draftLoadedMsg) Model {
+	if msg.text == "" || m.agentInput.Value() != "" {
+		return m
+	}
+	m.agentInput.SetValue(msg.text)
+	m.draftSeen, m.draftSaved = msg.text, msg.text
+	return m
+}
+
+// tickDraft saves the input as the session's draft once it has been left
+// unchanged for draftDebounce, so typing doesn't write on every key.
+func (m *Model) tickDraft(now time.Time) tea.Cmd {
+	if m.store == nil {
+		return nil
+	}
+	text := m.agentInput.Value()
+	if text != m.draftSeen {
+		m.draftSeen, m.draftSeenAt = text, now
+		return nil
+	}
+	if text == m.draftSaved || now.Sub(m.draftSeenAt) < draftDebounce {
+		return nil
+	}
+	return m.saveDraftCmd(text)
+}
+
+// saveDraftCmd persists text as the session's draft; "" clears it.
+func (m *Model) saveDraftCmd(text string) tea.Cmd {
+	if m.store == nil {
+		return nil
+	}
+	m.draftSaved = text
+	db, sessionID := m.store, m.sessionID
+	return func() tea.Msg {
+		if err := db.SaveDraft(sessionID, text); err != nil {
+			log.Warn().Err(err).Str("session", sessionID).Msg("failed to save draft")
+		}
+		return nil
+	}
+}
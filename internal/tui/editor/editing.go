@@ -1,5 +1,11 @@
 package editor
 
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
 // ---------------------------------------------------------------------------
 // Editing operations
 // ---------------------------------------------------------------------------
@@ -22,11 +28,24 @@ func (m *Model) InsertText(text string) {
 	}
 }
 
+// InsertPaste inserts pasted text literally, replacing any selection. Line
+// breaks (\r\n, \r or \n) start new lines as-is: pasted indentation is kept
+// exactly and none of the typed-key indent handling applies.
+func (m *Model) InsertPaste(text string) {
+	if m.ReadOnly {
+		return
+	}
+	m.DeleteSelection()
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	m.InsertText(strings.ReplaceAll(text, "\r", "\n"))
+}
+
 func (m *Model) insertRune(r rune) {
 	if m.ReadOnly {
 		return
 	}
 	line := m.currentLine()
+	m.col = graphemeStart(line, m.col)
 	newLine := make([]rune, 0, len(line)+1)
 	newLine = append(newLine, line[:m.col]...)
 	newLine = append(newLine, r)
@@ -59,8 +78,9 @@ func (m *Model) deleteBack() {
 	}
 	if m.col > 0 {
 		line := m.currentLine()
-		m.lines[m.row] = append(line[:m.col-1], line[m.col:]...)
-		m.col--
+		start := prevGrapheme(line, m.col)
+		m.lines[m.row] = append(line[:start], line[m.col:]...)
+		m.col = start
 	} else if m.row > 0 {
 		// Merge with previous line
 		prev := m.lines[m.row-1]
@@ -77,7 +97,7 @@ func (m *Model) deleteForward() {
 	}
 	line := m.currentLine()
 	if m.col < len(line) {
-		m.lines[m.row] = append(line[:m.col], line[m.col+1:]...)
+		m.lines[m.row] = append(line[:m.col], line[nextGrapheme(line, m.col):]...)
 	} else if m.row < len(m.lines)-1 {
 		// Merge with next line
 		m.lines[m.row] = append(line, m.lines[m.row+1]...)
@@ -109,3 +129,111 @@ func (m *Model) tabIndent() {
 		m.insertRune(r)
 	}
 }
+
+// selectedRows returns the buffer rows covered by the selection, or the
+// cursor row when there is none.
+func (m *Model) selectedRows() (first, last int) {
+	if !m.HasSelection() {
+		return m.row, m.row
+	}
+	s, e := m.sel.ordered()
+	return s.row, e.row
+}
+
+// moveLines swaps the current line (or every selected line) with its
+// neighbor in dir (-1 up, +1 down). The cursor and selection follow the
+// moved text. No-op at the buffer edge.
+func (m *Model) moveLines(dir int) {
+	if m.ReadOnly {
+		return
+	}
+	first, last := m.selectedRows()
+	if first+dir < 0 || last+dir >= len(m.lines) {
+		return
+	}
+	if dir < 0 {
+		neighbor := m.lines[first-1]
+		copy(m.lines[first-1:last], m.lines[first:last+1])
+		m.lines[last] = neighbor
+	} else {
+		neighbor := m.lines[last+1]
+		copy(m.lines[first+1:last+2], m.lines[first:last+1])
+		m.lines[first] = neighbor
+	}
+	m.row += dir
+	if m.sel != nil {
+		m.sel.anchor.row += dir
+		m.sel.active.row += dir
+	}
+}
+
+// duplicate copies the current line, or the selected text, directly below
+// it and moves the cursor onto the copy. A duplicated selection stays
+// selected so it can be duplicated again or moved.
+func (m *Model) duplicate() {
+	if m.ReadOnly {
+		return
+	}
+	if !m.HasSelection() {
+		line := make([]rune, len(m.currentLine()))
+		copy(line, m.currentLine())
+		m.lines = append(m.lines[:m.row+1], append([][]rune{line}, m.lines[m.row+1:]...)...)
+		m.row++
+		return
+	}
+	s, e := m.sel.ordered()
+	text := m.textInRange(s, e)
+	m.ClearSelection()
+	m.row, m.col = e.row, e.col
+	m.InsertText(text)
+	m.sel = &selection{anchor: e, active: pos{row: m.row, col: m.col}}
+}
+
+// graphemeBounds returns the rune range of the grapheme cluster containing
+// rune index col, so emoji sequences and combining marks move as one unit.
+// Past the end it returns (len(line), len(line)).
+func graphemeBounds(line []rune, col int) (start, end int) {
+	s := string(line)
+	for s != "" {
+		cluster, _ := ansi.FirstGraphemeCluster(s, ansi.GraphemeWidth)
+		n := len([]rune(cluster))
+		if col < start+n {
+			return start, start + n
+		}
+		start += n
+		s = s[len(cluster):]
+	}
+	return len(line), len(line)
+}
+
+// graphemeStart snaps col back to the start of the cluster it falls in.
+func graphemeStart(line []rune, col int) int {
+	if col >= len(line) {
+		return col
+	}
+	start, _ := graphemeBounds(line, col)
+	return start
+}
+
+// graphemeEnd snaps col forward to the end of the cluster it falls in.
+func graphemeEnd(line []rune, col int) int {
+	if start, end := graphemeBounds(line, col); start != col {
+		return end
+	}
+	return col
+}
+
+// prevGrapheme returns the start of the cluster before col.
+func prevGrapheme(line []rune, col int) int {
+	if col <= 0 {
+		return 0
+	}
+	start, _ := graphemeBounds(line, col-1)
+	return start
+}
+
+// nextGrapheme returns the end of the cluster at col.
+func nextGrapheme(line []rune, col int) int {
+	_, end := graphemeBounds(line, col)
+	return end
+}
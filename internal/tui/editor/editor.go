@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"image/color"
 	"strings"
+	"unicode/utf8"
 
 	"charm.land/bubbles/v2/cursor"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/xonecas/symb/internal/highlight"
 )
 
@@ -31,10 +33,16 @@ type Model struct {
 	// Public configuration — set before first Update/View.
 	ReadOnly        bool
 	ShowLineNumbers bool
-	SubmitOnEnter   bool   // Enter is a no-op (parent handles submit); shift+enter inserts newline
-	Language        string // Chroma lexer name (empty = no highlighting)
-	SyntaxTheme     string // Chroma style name (empty = no highlighting)
-	Placeholder     string // Shown when empty and blurred
+	SubmitOnEnter   bool    // Enter is a no-op (parent handles submit); shift+enter inserts newline
+	Language        string  // Chroma lexer name (empty = no highlighting)
+	SyntaxTheme     string  // Chroma style name (empty = no highlighting)
+	Placeholder     string  // Shown when empty and blurred
+	PageOverlap     int     // Rows carried over between pages on pgup/pgdown
+	WheelStep       float64 // Rows per wheel event; fractions accumulate (0 = 3)
+
+	// Highlight limits — keep rendering responsive on huge buffers.
+	NoHighlight       bool // Runtime toggle: render plain text even when Language is set
+	MaxHighlightLines int  // Fall back to plain rendering above this many lines (0 = no limit)
 
 	// Styles — set by parent.
 	CursorStyle    lipgloss.Style // Foreground for the cursor character
@@ -60,6 +68,9 @@ type Model struct {
 	col    int      // Cursor column (0-indexed into line runes)
 	scroll int      // First visible row
 
+	wheelRem float64 // Fractional wheel rows not yet scrolled
+	wheelDir int     // Direction of the last wheel scroll; a change drops wheelRem
+
 	width  int // Viewport width (cells)
 	height int // Viewport height (rows)
 
@@ -75,6 +86,15 @@ type Model struct {
 	gutterWidth int // Width of line number gutter (0 if disabled)
 }
 
+// Highlighting reports whether syntax highlighting is active for the
+// current buffer, taking NoHighlight and MaxHighlightLines into account.
+func (m Model) Highlighting() bool {
+	if m.Language == "" || m.SyntaxTheme == "" || m.NoHighlight {
+		return false
+	}
+	return m.MaxHighlightLines <= 0 || len(m.lines) <= m.MaxHighlightLines
+}
+
 type pos struct{ row, col int }
 
 // selection tracks a text selection via anchor+active points.
@@ -198,24 +218,25 @@ func (m *Model) ClearSelection() {
 	m.dragging = false
 }
 
-// textInRange extracts text between two buffer positions.
+// textInRange extracts text between two buffer positions, widened to whole
+// grapheme clusters.
 func (m *Model) textInRange(start, end pos) string {
 	if start.row == end.row {
 		line := m.lines[start.row]
-		sc := clampMax(start.col, len(line))
-		ec := clampMax(end.col, len(line))
+		sc := graphemeStart(line, clampMax(start.col, len(line)))
+		ec := graphemeEnd(line, clampMax(end.col, len(line)))
 		return string(line[sc:ec])
 	}
 	var sb strings.Builder
 	first := m.lines[start.row]
-	sb.WriteString(string(first[clampMax(start.col, len(first)):]))
+	sb.WriteString(string(first[graphemeStart(first, clampMax(start.col, len(first))):]))
 	for r := start.row + 1; r < end.row; r++ {
 		sb.WriteByte('\n')
 		sb.WriteString(string(m.lines[r]))
 	}
 	sb.WriteByte('\n')
 	last := m.lines[end.row]
-	sb.WriteString(string(last[:clampMax(end.col, len(last))]))
+	sb.WriteString(string(last[:graphemeEnd(last, clampMax(end.col, len(last)))]))
 	return sb.String()
 }
 
@@ -229,6 +250,39 @@ func (m Model) CursorOffset() int {
 	return offset
 }
 
+// CursorPos returns the cursor's 0-indexed row and column (in runes).
+func (m Model) CursorPos() (row, col int) {
+	return m.row, m.col
+}
+
+// VisualRowCount returns the number of screen rows the buffer wraps to at
+// the current width.
+func (m Model) VisualRowCount() int {
+	return m.visualRowCount()
+}
+
+// LineCount returns the number of lines in the buffer.
+func (m Model) LineCount() int {
+	return len(m.lines)
+}
+
+// WordCount returns the number of whitespace-separated words in the buffer.
+func (m Model) WordCount() int {
+	n := 0
+	for _, line := range m.lines {
+		n += len(strings.Fields(string(line)))
+	}
+	return n
+}
+
+// SelectionLen returns the number of runes selected, or 0 if none.
+func (m Model) SelectionLen() int {
+	if !m.HasSelection() {
+		return 0
+	}
+	return utf8.RuneCountInString(m.SelectedText())
+}
+
 // DeleteSelection removes the selected text and positions the cursor at the
 // start of the deleted range. Returns true if a selection was deleted.
 func (m *Model) DeleteSelection() bool {
@@ -316,6 +370,31 @@ func (m *Model) clampCursor() {
 	if m.col > len(m.currentLine()) {
 		m.col = len(m.currentLine())
 	}
+	m.col = graphemeStart(m.currentLine(), m.col)
+}
+
+// smartHome moves the cursor to the first non-blank character of the line,
+// or to column 0 if it is already there. Blank lines always go to column 0.
+func (m *Model) smartHome() {
+	line := m.currentLine()
+	indent := 0
+	for indent < len(line) && (line[indent] == ' ' || line[indent] == '\t') {
+		indent++
+	}
+	if indent == len(line) || m.col == indent {
+		m.col = 0
+		return
+	}
+	m.col = indent
+}
+
+// page moves the cursor and viewport one page in dir (-1 up, +1 down),
+// keeping PageOverlap rows of the previous page on screen.
+func (m *Model) page(dir int) {
+	step := max(m.height-m.PageOverlap, 1)
+	m.row += dir * step
+	m.scroll += dir * step
+	m.clampCursor()
 }
 
 // clampScrollBounds enforces scroll min/max without snapping to the cursor.
@@ -365,18 +444,21 @@ func (m *Model) clampScroll() {
 
 const tabWidth = 4
 
-// expandTabs replaces tabs with spaces (tabWidth-aligned).
+// expandTabs replaces tabs with spaces (tabWidth-aligned). Columns are
+// display cells, so wide characters count as two.
 func expandTabs(s string) string {
 	var b strings.Builder
 	col := 0
-	for _, r := range s {
-		if r == '\t' {
+	for s != "" {
+		cluster, w := ansi.FirstGraphemeCluster(s, ansi.GraphemeWidth)
+		s = s[len(cluster):]
+		if cluster == "\t" {
 			spaces := tabWidth - (col % tabWidth)
 			b.WriteString(strings.Repeat(" ", spaces))
 			col += spaces
 		} else {
-			b.WriteRune(r)
-			col++
+			b.WriteString(cluster)
+			col += w
 		}
 	}
 	return b.String()
@@ -429,25 +511,44 @@ func (m Model) renderGutterMark(bufRow int, lineNumSty lipgloss.Style) string {
 // ---------------------------------------------------------------------------
 
 // wrapPlain splits a plain-text string into visual rows of at most `width`
-// runes. No ANSI awareness needed — call this on the raw expanded-tab text.
+// display cells. A wide character that doesn't fit at the end of a row moves
+// to the next one, so rows can be a cell short. No ANSI awareness needed —
+// call this on the raw expanded-tab text.
 func wrapPlain(s string, width int) []string {
-	if width <= 0 {
-		return []string{s}
-	}
-	runes := []rune(s)
-	if len(runes) <= width {
+	if width <= 0 || ansi.StringWidth(s) <= width {
 		return []string{s}
 	}
 	var rows []string
-	for len(runes) > 0 {
-		end := width
-		if end > len(runes) {
-			end = len(runes)
+	start, rowW := 0, 0
+	for i := 0; i < len(s); {
+		cluster, w := ansi.FirstGraphemeCluster(s[i:], ansi.GraphemeWidth)
+		if rowW+w > width && rowW > 0 {
+			rows = append(rows, s[start:i])
+			start, rowW = i, 0
+		}
+		rowW += w
+		i += len(cluster)
+	}
+	return append(rows, s[start:])
+}
+
+// lineRows returns the wrapped visual rows of a buffer line.
+func (m *Model) lineRows(bufRow, tw int) []string {
+	return wrapPlain(expandTabs(string(m.lines[bufRow])), tw)
+}
+
+// subRowAt returns the wrapped row of rows that shows the cell column col.
+// A cursor at the end of a full last row belongs to the row after it.
+func subRowAt(rows []string, col, tw int) int {
+	off := 0
+	for i, row := range rows {
+		w := ansi.StringWidth(row)
+		if col < off+w || (i == len(rows)-1 && col == off+w && w < tw) {
+			return i
 		}
-		rows = append(rows, string(runes[:end]))
-		runes = runes[end:]
+		off += w
 	}
-	return rows
+	return len(rows)
 }
 
 // visualRowCount returns the total number of visual rows across all buffer
@@ -455,14 +556,8 @@ func wrapPlain(s string, width int) []string {
 func (m *Model) visualRowCount() int {
 	tw := m.textWidth()
 	total := 0
-	for _, line := range m.lines {
-		expanded := expandTabs(string(line))
-		n := len([]rune(expanded))
-		if n <= tw {
-			total++
-		} else {
-			total += (n + tw - 1) / tw
-		}
+	for i := range m.lines {
+		total += len(m.lineRows(i, tw))
 	}
 	return total
 }
@@ -472,76 +567,70 @@ func (m *Model) cursorVisualRow() int {
 	tw := m.textWidth()
 	vr := 0
 	for i := 0; i < m.row && i < len(m.lines); i++ {
-		expanded := expandTabs(string(m.lines[i]))
-		n := len([]rune(expanded))
-		if n <= tw {
-			vr++
-		} else {
-			vr += (n + tw - 1) / tw
-		}
+		vr += len(m.lineRows(i, tw))
 	}
 	// Add the sub-row within the cursor's line
 	if m.row < len(m.lines) {
-		// The cursor col in the expanded string
-		prefix := expandTabs(string(m.lines[m.row][:m.col]))
-		runeCol := len([]rune(prefix))
-		vr += runeCol / tw
+		vr += subRowAt(m.lineRows(m.row, tw), m.bufferColToExpandedCol(m.row, m.col), tw)
 	}
 	return vr
 }
 
-// visualToBuffer converts a visual row index to a buffer row and the rune
-// offset (in the expanded line) at the start of that visual sub-row.
-func (m *Model) visualToBuffer(visRow int) (bufRow, runeOffset int) {
+// visualToBuffer converts a visual row index to a buffer row, the wrapped
+// sub-row within it, and the cell offset (in the expanded line) at the start
+// of that sub-row.
+func (m *Model) visualToBuffer(visRow int) (bufRow, subRow, cellOffset int) {
 	tw := m.textWidth()
 	vr := 0
-	for i, line := range m.lines {
-		expanded := expandTabs(string(line))
-		n := len([]rune(expanded))
-		rows := 1
-		if n > tw {
-			rows = (n + tw - 1) / tw
-		}
-		if vr+rows > visRow {
-			return i, (visRow - vr) * tw
+	for i := range m.lines {
+		rows := m.lineRows(i, tw)
+		if vr+len(rows) > visRow {
+			sub := visRow - vr
+			for _, row := range rows[:sub] {
+				cellOffset += ansi.StringWidth(row)
+			}
+			return i, sub, cellOffset
 		}
-		vr += rows
+		vr += len(rows)
 	}
 	// Past end — return last line
-	return len(m.lines) - 1, 0
+	return len(m.lines) - 1, 0, 0
 }
 
-// expandedColToBufferCol maps a column in the expanded-tab string back to the
-// corresponding rune index in the original buffer line.
+// expandedColToBufferCol maps a cell column in the expanded-tab string back
+// to the corresponding rune index in the original buffer line. A column
+// inside a wide character maps to that character.
 func (m *Model) expandedColToBufferCol(bufRow, expandedCol int) int {
 	if bufRow < 0 || bufRow >= len(m.lines) {
 		return 0
 	}
-	line := m.lines[bufRow]
+	s := string(m.lines[bufRow])
 	col := 0 // visual column in expanded space
-	for i, r := range line {
-		if col >= expandedCol {
-			return i
+	i := 0   // rune index
+	for s != "" {
+		cluster, w := ansi.FirstGraphemeCluster(s, ansi.GraphemeWidth)
+		s = s[len(cluster):]
+		if cluster == "\t" {
+			w = tabWidth - (col % tabWidth)
 		}
-		if r == '\t' {
-			col += tabWidth - (col % tabWidth)
-		} else {
-			col++
+		if expandedCol < col+w {
+			return i
 		}
+		col += w
+		i += utf8.RuneCountInString(cluster)
 	}
-	return len(line)
+	return len(m.lines[bufRow])
 }
 
 // bufferColToExpandedCol converts a buffer column (rune index) to expanded-tab
-// column (visual column) for a given buffer row.
+// column (visual cell column) for a given buffer row.
 func (m *Model) bufferColToExpandedCol(bufRow, bufCol int) int {
 	if bufRow < 0 || bufRow >= len(m.lines) {
 		return 0
 	}
 	line := m.lines[bufRow]
 	c := clampMax(bufCol, len(line))
-	prefix := expandTabs(string(line[:c]))
-	return len([]rune(prefix))
+	return ansi.StringWidth(expandTabs(string(line[:c])))
 }
 
 // bgForRender returns the background as a lipgloss style. Prefers the syntax
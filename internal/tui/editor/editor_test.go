@@ -1,9 +1,11 @@
 package editor
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
+	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 )
 
@@ -126,3 +128,423 @@ func TestExpandTabs(t *testing.T) {
 		}
 	}
 }
+
+func TestWideCharacters(t *testing.T) {
+	// Wrap boundaries count cells: a wide character that doesn't fit moves
+	// to the next row instead of being split.
+	wraps := []struct {
+		in    string
+		width int
+		want  []string
+	}{
+		{"日本語テキスト", 6, []string{"日本語", "テキス", "ト"}},
+		{"a日本語", 6, []string{"a日本", "語"}},
+		{"ok 👍 done", 4, []string{"ok ", "👍 d", "one"}},
+		{"👨‍👩‍👧 x", 3, []string{"👨‍👩‍👧 ", "x"}},
+	}
+	for _, tc := range wraps {
+		got := wrapPlain(tc.in, tc.width)
+		if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("wrapPlain(%q, %d) = %q, want %q", tc.in, tc.width, got, tc.want)
+		}
+	}
+
+	if got := expandTabs("日\tx"); got != "日  x" {
+		t.Errorf("expandTabs after wide char = %q, want %q", got, "日  x")
+	}
+
+	ed := New()
+	ed.SetWidth(6)
+	ed.SetHeight(5)
+	ed.SetValue("a日本語テキスト")
+	ed.Focus()
+
+	if got := ed.visualRowCount(); got != 3 { // "a日本", "語テキ", "スト"
+		t.Errorf("visualRowCount() = %d, want 3", got)
+	}
+	for _, tc := range []struct{ col, exp, vrow int }{
+		{0, 0, 0}, {2, 3, 0}, {3, 5, 1}, {6, 11, 2}, {8, 15, 2},
+	} {
+		ed.row, ed.col = 0, tc.col
+		if got := ed.cursorExpanded(); got != tc.exp {
+			t.Errorf("col %d: cursorExpanded() = %d, want %d", tc.col, got, tc.exp)
+		}
+		if got := ed.cursorVisualRow(); got != tc.vrow {
+			t.Errorf("col %d: cursorVisualRow() = %d, want %d", tc.col, got, tc.vrow)
+		}
+		if got := ed.expandedColToBufferCol(0, tc.exp); got != tc.col {
+			t.Errorf("expandedColToBufferCol(%d) = %d, want %d", tc.exp, got, tc.col)
+		}
+	}
+
+	// Both halves of a wide character map to it; clicks past the end of a
+	// short wrapped row stay on that row.
+	if got := ed.screenToPos(2, 0); got.col != 1 {
+		t.Errorf("click on right half of 日: col %d, want 1", got.col)
+	}
+	if got := ed.screenToPos(5, 0); got.col != 2 {
+		t.Errorf("click past short row end: col %d, want 2", got.col)
+	}
+
+	ed.row, ed.col = 0, 4
+	for i, line := range strings.Split(ed.View(), "\n") {
+		if w := lipgloss.Width(line); w != 6 {
+			t.Errorf("visual row %d: width=%d (want 6): %q", i, w, line)
+		}
+	}
+}
+
+func TestGraphemeClusters(t *testing.T) {
+	const flag = "\U0001F1EF\U0001F1F5" // 🇯🇵, two regional indicators
+	const accent = "e\u0301"            // e + combining acute
+	const family = "👨\u200d👩\u200d👧"    // ZWJ sequence
+
+	key := func(ed *Model, k string) {
+		if !ed.handleShiftNav(k) && !ed.handlePlainNav(k) {
+			ed.handleEditKey(k)
+		}
+	}
+
+	ed := New()
+	ed.SetValue("a" + flag + accent + family + "b")
+	ed.Focus()
+	ed.row, ed.col = 0, len(ed.lines[0])
+
+	key(&ed, "left")
+	key(&ed, "backspace")
+	if got, want := ed.Value(), "a"+flag+accent+"b"; got != want {
+		t.Fatalf("backspace over ZWJ emoji: %q, want %q", got, want)
+	}
+	key(&ed, "backspace")
+	if got, want := ed.Value(), "a"+flag+"b"; got != want {
+		t.Fatalf("backspace over accented char: %q, want %q", got, want)
+	}
+	key(&ed, "left")
+	key(&ed, "delete")
+	if got, want := ed.Value(), "ab"; got != want {
+		t.Fatalf("delete over flag: %q, want %q", got, want)
+	}
+
+	ed.SetValue(flag + accent + "x")
+	ed.row, ed.col = 0, 0
+	key(&ed, "shift+right")
+	key(&ed, "shift+right")
+	if got, want := ed.SelectedText(), flag+accent; got != want {
+		t.Errorf("selected %q, want %q", got, want)
+	}
+	ed.sel = &selection{anchor: pos{0, 1}, active: pos{0, 3}} // both ends mid-cluster
+	if got, want := ed.SelectedText(), flag+accent; got != want {
+		t.Errorf("mid-cluster selection = %q, want %q", got, want)
+	}
+	ed.ClearSelection()
+
+	// Vertical moves never leave the cursor inside a cluster.
+	ed.SetValue("abc\n" + flag + "x")
+	ed.row, ed.col = 0, 1
+	key(&ed, "down")
+	if ed.col != 0 {
+		t.Errorf("down into flag: col %d, want 0", ed.col)
+	}
+	ed.InsertText("y")
+	if got, want := ed.Value(), "abc\ny"+flag+"x"; got != want {
+		t.Errorf("insert at cluster boundary: %q, want %q", got, want)
+	}
+}
+
+func TestInsertPaste(t *testing.T) {
+	block := "func f() {\n\tif x {\n\t\treturn\n\t}\n    // spaces\n}"
+
+	ed := New()
+	ed.SetValue("\tprefix ")
+	ed.Focus()
+	ed.row, ed.col = 0, len(ed.lines[0])
+	ed.InsertPaste(strings.ReplaceAll(block, "\n", "\r\n"))
+	if got, want := ed.Value(), "\tprefix "+block; got != want {
+		t.Errorf("paste with CRLF:\n got %q\nwant %q", got, want)
+	}
+
+	// Bare \r line breaks (some terminals) still split lines, and the paste
+	// replaces the selection.
+	ed.SetValue("old")
+	ed.sel = &selection{anchor: pos{0, 0}, active: pos{0, 3}}
+	ed.InsertPaste("a\r\tb")
+	if got, want := ed.Value(), "a\n\tb"; got != want {
+		t.Errorf("paste with CR = %q, want %q", got, want)
+	}
+}
+
+func TestHighlightingLimits(t *testing.T) {
+	ed := New()
+	ed.Language = "go"
+	ed.SyntaxTheme = "github-dark"
+	ed.SetValue("a\nb\nc")
+
+	if !ed.Highlighting() {
+		t.Fatal("expected highlighting with language and theme set")
+	}
+
+	ed.MaxHighlightLines = 2
+	if ed.Highlighting() {
+		t.Error("expected plain rendering above MaxHighlightLines")
+	}
+
+	ed.MaxHighlightLines = 0
+	ed.NoHighlight = true
+	if ed.Highlighting() {
+		t.Error("expected plain rendering with NoHighlight")
+	}
+}
+
+func TestCursorAndSelectionInfo(t *testing.T) {
+	ed := New()
+	ed.SetWidth(40)
+	ed.SetHeight(5)
+	ed.SetValue("héllo world\nsecond line here")
+	ed.Focus()
+
+	if n := ed.LineCount(); n != 2 {
+		t.Errorf("LineCount = %d, want 2", n)
+	}
+	if n := ed.WordCount(); n != 5 {
+		t.Errorf("WordCount = %d, want 5", n)
+	}
+
+	ed.GotoLine(2)
+	ed.col = 6
+	if row, col := ed.CursorPos(); row != 1 || col != 6 {
+		t.Errorf("CursorPos = %d,%d, want 1,6", row, col)
+	}
+
+	if n := ed.SelectionLen(); n != 0 {
+		t.Errorf("SelectionLen without selection = %d", n)
+	}
+	ed.sel = &selection{anchor: pos{0, 1}, active: pos{0, 5}}
+	if n := ed.SelectionLen(); n != 4 {
+		t.Errorf("SelectionLen = %d, want 4 runes", n)
+	}
+}
+
+func TestPageOverlap(t *testing.T) {
+	ed := New()
+	ed.SetWidth(40)
+	ed.SetHeight(10)
+	ed.PageOverlap = 2
+	ed.SetValue(strings.Repeat("line\n", 29) + "line")
+	ed.Focus()
+	ed.row, ed.col, ed.scroll = 0, 0, 0
+
+	pgdown := tea.KeyPressMsg{Code: tea.KeyPgDown}
+	pgup := tea.KeyPressMsg{Code: tea.KeyPgUp}
+
+	ed, _ = ed.Update(pgdown)
+	if ed.row != 8 || ed.scroll != 8 {
+		t.Errorf("after pgdown row=%d scroll=%d, want 8/8 (two lines of overlap)", ed.row, ed.scroll)
+	}
+
+	for range 5 {
+		ed, _ = ed.Update(pgdown)
+	}
+	if ed.row != 29 || ed.scroll != 20 {
+		t.Errorf("at end row=%d scroll=%d, want clamped to 29/20", ed.row, ed.scroll)
+	}
+
+	ed, _ = ed.Update(pgup)
+	if ed.row != 21 || ed.scroll != 12 {
+		t.Errorf("after pgup row=%d scroll=%d, want 21/12", ed.row, ed.scroll)
+	}
+
+	for range 5 {
+		ed, _ = ed.Update(pgup)
+	}
+	if ed.row != 0 || ed.scroll != 0 {
+		t.Errorf("at start row=%d scroll=%d, want clamped to 0/0", ed.row, ed.scroll)
+	}
+}
+
+func TestSmartHome(t *testing.T) {
+	home := tea.KeyPressMsg{Code: tea.KeyHome}
+	tests := []struct {
+		name  string
+		line  string
+		start int
+		want  []int // col after each successive press
+	}{
+		{"spaces", "    foo()", 7, []int{4, 0, 4}},
+		{"tabs", "\t\tfoo()", 5, []int{2, 0, 2}},
+		{"from column 0", "  x", 0, []int{2, 0}},
+		{"no indent", "foo", 2, []int{0, 0}},
+		{"blank", "", 0, []int{0, 0}},
+		{"whitespace only", "   ", 3, []int{0, 0}},
+	}
+	for _, tt := range tests {
+		ed := New()
+		ed.SetWidth(40)
+		ed.SetHeight(3)
+		ed.SetValue(tt.line)
+		ed.Focus()
+		ed.col = tt.start
+		for i, want := range tt.want {
+			ed, _ = ed.Update(home)
+			if ed.col != want {
+				t.Errorf("%s: press %d col = %d, want %d", tt.name, i+1, ed.col, want)
+			}
+		}
+	}
+
+	// shift+home extends the selection to the indent.
+	ed := New()
+	ed.SetWidth(40)
+	ed.SetHeight(3)
+	ed.SetValue("  foo")
+	ed.Focus()
+	ed.col = 5
+	ed, _ = ed.Update(tea.KeyPressMsg{Code: tea.KeyHome, Mod: tea.ModShift})
+	if got := ed.SelectedText(); got != "foo" {
+		t.Errorf("shift+home selected %q, want %q", got, "foo")
+	}
+}
+
+func TestMoveLines(t *testing.T) {
+	up := tea.KeyPressMsg{Code: tea.KeyUp, Mod: tea.ModAlt}
+	down := tea.KeyPressMsg{Code: tea.KeyDown, Mod: tea.ModAlt}
+	newEd := func() Model {
+		ed := New()
+		ed.SetWidth(40)
+		ed.SetHeight(5)
+		ed.SetValue("a\nb\nc\nd")
+		ed.Focus()
+		return ed
+	}
+
+	ed := newEd()
+	ed.row, ed.col = 0, 1
+	ed, _ = ed.Update(up)
+	if ed.Value() != "a\nb\nc\nd" || ed.row != 0 {
+		t.Errorf("moving the first line up: %q row=%d, want no-op", ed.Value(), ed.row)
+	}
+	ed, _ = ed.Update(down)
+	if ed.Value() != "b\na\nc\nd" || ed.row != 1 || ed.col != 1 {
+		t.Errorf("moving a down: %q row=%d col=%d", ed.Value(), ed.row, ed.col)
+	}
+
+	ed = newEd()
+	ed.row = 3
+	ed, _ = ed.Update(down)
+	if ed.Value() != "a\nb\nc\nd" || ed.row != 3 {
+		t.Errorf("moving the last line down: %q row=%d, want no-op", ed.Value(), ed.row)
+	}
+
+	// A selection spanning b..c moves as a block and stays selected.
+	ed = newEd()
+	ed.sel = &selection{anchor: pos{1, 0}, active: pos{2, 1}}
+	ed.row, ed.col = 2, 1
+	ed, _ = ed.Update(up)
+	if ed.Value() != "b\nc\na\nd" {
+		t.Errorf("block up: %q", ed.Value())
+	}
+	if got := ed.SelectedText(); got != "b\nc" {
+		t.Errorf("selection after block up = %q, want %q", got, "b\nc")
+	}
+	ed, _ = ed.Update(down)
+	ed, _ = ed.Update(down)
+	if ed.Value() != "a\nd\nb\nc" || ed.row != 3 {
+		t.Errorf("block down twice: %q row=%d", ed.Value(), ed.row)
+	}
+	ed, _ = ed.Update(down)
+	if ed.Value() != "a\nd\nb\nc" {
+		t.Errorf("block at the end moved: %q", ed.Value())
+	}
+
+	ed = newEd()
+	ed.ReadOnly = true
+	ed, _ = ed.Update(down)
+	if ed.Value() != "a\nb\nc\nd" {
+		t.Errorf("read-only buffer changed: %q", ed.Value())
+	}
+}
+
+func TestDuplicate(t *testing.T) {
+	dup := tea.KeyPressMsg{Code: 'd', Mod: tea.ModCtrl | tea.ModShift}
+	newEd := func() Model {
+		ed := New()
+		ed.SetWidth(40)
+		ed.SetHeight(5)
+		ed.SetValue("one\ntwo\nthree")
+		ed.Focus()
+		return ed
+	}
+
+	ed := newEd()
+	ed.row, ed.col = 1, 2
+	ed, _ = ed.Update(dup)
+	if ed.Value() != "one\ntwo\ntwo\nthree" || ed.row != 2 || ed.col != 2 {
+		t.Errorf("line duplicate: %q row=%d col=%d", ed.Value(), ed.row, ed.col)
+	}
+
+	// A selection spanning "ne\ntw" is inserted right after itself.
+	ed = newEd()
+	ed.sel = &selection{anchor: pos{0, 1}, active: pos{1, 2}}
+	ed.row, ed.col = 1, 2
+	ed, _ = ed.Update(dup)
+	if want := "one\ntwne\ntwo\nthree"; ed.Value() != want {
+		t.Errorf("selection duplicate: %q, want %q", ed.Value(), want)
+	}
+	if got := ed.SelectedText(); got != "ne\ntw" {
+		t.Errorf("copy selected = %q, want %q", got, "ne\ntw")
+	}
+	if ed.row != 2 || ed.col != 2 {
+		t.Errorf("cursor at %d:%d, want end of the copy 2:2", ed.row, ed.col)
+	}
+
+	ed = newEd()
+	ed.ReadOnly = true
+	ed, _ = ed.Update(dup)
+	if ed.Value() != "one\ntwo\nthree" {
+		t.Errorf("read-only buffer changed: %q", ed.Value())
+	}
+}
+
+func TestWheelStep(t *testing.T) {
+	ed := New()
+	ed.SetWidth(40)
+	ed.SetHeight(10)
+	ed.SetValue(strings.Repeat("line\n", 49) + "line")
+	ed.Focus()
+
+	down := tea.MouseWheelMsg{Button: tea.MouseWheelDown}
+	ed, _ = ed.Update(down)
+	if ed.scroll != 3 {
+		t.Errorf("default wheel step scrolled %d rows, want 3", ed.scroll)
+	}
+
+	ed.scroll = 0
+	ed.WheelStep = 0.5
+	var got []int
+	for range 4 {
+		ed, _ = ed.Update(down)
+		got = append(got, ed.scroll)
+	}
+	if want := []int{0, 1, 1, 2}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("fractional wheel scrolls = %v, want %v", got, want)
+	}
+
+	// A half row left over going down must not count towards going up.
+	ed, _ = ed.Update(down)
+	ed, _ = ed.Update(tea.MouseWheelMsg{Button: tea.MouseWheelUp})
+	if ed.scroll != 2 {
+		t.Errorf("reversing direction scrolled to %d, want 2 (leftover dropped)", ed.scroll)
+	}
+
+	ed.scroll = 0
+	ed.PageOverlap = 2
+	ed, _ = ed.Update(tea.MouseWheelMsg{Button: tea.MouseWheelDown, Mod: tea.ModShift})
+	if ed.scroll != 8 {
+		t.Errorf("shift+wheel scrolled %d rows, want a page of 8", ed.scroll)
+	}
+
+	ed.scroll = 0
+	ed, _ = ed.Update(tea.MouseWheelMsg{Button: tea.MouseWheelUp, Mod: tea.ModShift})
+	if ed.scroll != 0 {
+		t.Errorf("wheel up at the top scrolled to %d, want clamped to 0", ed.scroll)
+	}
+}
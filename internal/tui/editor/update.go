@@ -1,6 +1,9 @@
 package editor
 
-import tea "charm.land/bubbletea/v2"
+import (
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
 
 // ---------------------------------------------------------------------------
 // Update
@@ -71,7 +74,7 @@ func (m *Model) handleShiftNav(key string) bool {
 	case "shift+left":
 		m.startOrExtendSelection()
 		if m.col > 0 {
-			m.col--
+			m.col = prevGrapheme(m.currentLine(), m.col)
 		} else if m.row > 0 {
 			m.row--
 			m.col = len(m.currentLine())
@@ -80,7 +83,7 @@ func (m *Model) handleShiftNav(key string) bool {
 	case "shift+right":
 		m.startOrExtendSelection()
 		if m.col < len(m.currentLine()) {
-			m.col++
+			m.col = nextGrapheme(m.currentLine(), m.col)
 		} else if m.row < len(m.lines)-1 {
 			m.row++
 			m.col = 0
@@ -88,7 +91,7 @@ func (m *Model) handleShiftNav(key string) bool {
 		m.updateSelectionActive()
 	case "shift+home":
 		m.startOrExtendSelection()
-		m.col = 0
+		m.smartHome()
 		m.updateSelectionActive()
 	case "shift+end":
 		m.startOrExtendSelection()
@@ -96,13 +99,11 @@ func (m *Model) handleShiftNav(key string) bool {
 		m.updateSelectionActive()
 	case "shift+pgup":
 		m.startOrExtendSelection()
-		m.row -= m.height
-		m.clampCursor()
+		m.page(-1)
 		m.updateSelectionActive()
 	case "shift+pgdown":
 		m.startOrExtendSelection()
-		m.row += m.height
-		m.clampCursor()
+		m.page(1)
 		m.updateSelectionActive()
 	default:
 		return false
@@ -124,7 +125,7 @@ func (m *Model) handlePlainNav(key string) bool {
 	case "left":
 		m.ClearSelection()
 		if m.col > 0 {
-			m.col--
+			m.col = prevGrapheme(m.currentLine(), m.col)
 		} else if m.row > 0 {
 			m.row--
 			m.col = len(m.currentLine())
@@ -132,25 +133,23 @@ func (m *Model) handlePlainNav(key string) bool {
 	case "right":
 		m.ClearSelection()
 		if m.col < len(m.currentLine()) {
-			m.col++
+			m.col = nextGrapheme(m.currentLine(), m.col)
 		} else if m.row < len(m.lines)-1 {
 			m.row++
 			m.col = 0
 		}
 	case "home", "ctrl+a":
 		m.ClearSelection()
-		m.col = 0
+		m.smartHome()
 	case "end", "ctrl+e":
 		m.ClearSelection()
 		m.col = len(m.currentLine())
 	case "pgup":
 		m.ClearSelection()
-		m.row -= m.height
-		m.clampCursor()
+		m.page(-1)
 	case "pgdown":
 		m.ClearSelection()
-		m.row += m.height
-		m.clampCursor()
+		m.page(1)
 	case "ctrl+home":
 		m.ClearSelection()
 		m.row = 0
@@ -165,7 +164,8 @@ func (m *Model) handlePlainNav(key string) bool {
 	return true
 }
 
-// handleEditKey handles backspace, delete, enter, tab.
+// handleEditKey handles backspace, delete, enter, tab, line moves and
+// duplication.
 func (m *Model) handleEditKey(key string) bool {
 	switch key {
 	case "backspace", "ctrl+h":
@@ -196,6 +196,12 @@ func (m *Model) handleEditKey(key string) bool {
 	case "tab":
 		m.DeleteSelection()
 		m.tabIndent()
+	case "alt+up":
+		m.moveLines(-1)
+	case "alt+down":
+		m.moveLines(1)
+	case "ctrl+shift+d":
+		m.duplicate()
 	default:
 		return false
 	}
@@ -229,15 +235,35 @@ func (m *Model) handleEditorMouse(msg tea.Msg) {
 		}
 	case tea.MouseWheelMsg:
 		if msg.Button == tea.MouseWheelUp {
-			m.scroll -= 3
+			m.scroll -= m.wheelRows(msg, -1)
 			m.clampScrollBounds()
 		} else if msg.Button == tea.MouseWheelDown {
-			m.scroll += 3
+			m.scroll += m.wheelRows(msg, 1)
 			m.clampScrollBounds()
 		}
 	}
 }
 
+// wheelRows returns how many rows a wheel event scrolls: a page with
+// shift held, otherwise WheelStep plus any fraction carried over from
+// scrolling the same direction dir.
+func (m *Model) wheelRows(msg tea.MouseWheelMsg, dir int) int {
+	if msg.Mod.Contains(tea.ModShift) {
+		return max(m.height-m.PageOverlap, 1)
+	}
+	step := m.WheelStep
+	if step <= 0 {
+		step = 3
+	}
+	if dir != m.wheelDir {
+		m.wheelRem, m.wheelDir = 0, dir
+	}
+	m.wheelRem += step
+	n := int(m.wheelRem)
+	m.wheelRem -= float64(n)
+	return n
+}
+
 // screenToPos converts screen-relative x,y to a buffer row,col.
 // x,y are relative to the editor component origin.
 func (m *Model) screenToPos(x, y int) pos {
@@ -245,15 +271,17 @@ func (m *Model) screenToPos(x, y int) pos {
 	if visRow < 0 {
 		visRow = 0
 	}
-	bufRow, runeOffset := m.visualToBuffer(visRow)
+	bufRow, subRow, cellOffset := m.visualToBuffer(visRow)
 
 	col := x - m.gutterWidth
 	if col < 0 {
 		col = 0
 	}
-	// runeOffset is in expanded-tab space; convert back to buffer col.
-	// We need to find which buffer rune corresponds to runeOffset + col
-	// in the expanded string.
-	bufCol := m.expandedColToBufferCol(bufRow, runeOffset+col)
+	// Clicks past the end of a wrapped row stay on that row.
+	if rows := m.lineRows(bufRow, m.textWidth()); subRow < len(rows)-1 {
+		col = min(col, ansi.StringWidth(rows[subRow])-1)
+	}
+	// cellOffset is in expanded-tab space; convert back to buffer col.
+	bufCol := m.expandedColToBufferCol(bufRow, cellOffset+col)
 	return pos{row: bufRow, col: bufCol}
 }
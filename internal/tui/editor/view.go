@@ -19,8 +19,9 @@ type visualRow struct {
 	subRow   int    // 0 = first wrap segment, 1 = second, etc.
 	text     string // plain text (expanded tabs) for this segment
 	fullHL   string // full-line highlighted ANSI (shared across sub-rows)
-	segStart int    // rune offset of this segment in the full line
-	segEnd   int    // rune end offset
+	segStart int    // cell offset of this segment in the full line
+	segEnd   int    // cell end offset
+	last     bool   // last wrap segment of the buffer line
 }
 
 // selRange holds pre-computed selection bounds in expanded-tab space.
@@ -88,12 +89,8 @@ func (m Model) View() string {
 // maintains cross-line state (important for markdown fenced blocks, but
 // harmless and slightly fewer calls for other languages too).
 func (m Model) buildVisualRows(tw int) []visualRow {
-	hasSyntax := m.Language != "" && m.SyntaxTheme != ""
-	startBuf, startRuneOff := m.visualToBuffer(m.scroll)
-	startSubRow := 0
-	if startRuneOff > 0 && tw > 0 {
-		startSubRow = startRuneOff / tw
-	}
+	hasSyntax := m.Highlighting()
+	startBuf, startSubRow, _ := m.visualToBuffer(m.scroll)
 
 	// First pass: collect visible buffer lines and their segments.
 	type bufLine struct {
@@ -138,25 +135,43 @@ func (m Model) buildVisualRows(tw int) []visualRow {
 		if vl.idx == startBuf {
 			firstSub = startSubRow
 		}
-		runeOff := firstSub * tw
+		cellOff := 0
+		for _, seg := range vl.segments[:firstSub] {
+			cellOff += ansi.StringWidth(seg)
+		}
 		for subIdx := firstSub; subIdx < len(vl.segments) && len(rows) < m.height; subIdx++ {
-			segLen := len([]rune(vl.segments[subIdx]))
+			segLen := ansi.StringWidth(vl.segments[subIdx])
 			rows = append(rows, visualRow{
 				bufRow: vl.idx, subRow: subIdx, text: vl.segments[subIdx],
-				fullHL: fullHL, segStart: runeOff, segEnd: runeOff + segLen,
+				fullHL: fullHL, segStart: cellOff, segEnd: cellOff + segLen,
+				last: subIdx == len(vl.segments)-1,
 			})
-			runeOff += segLen
+			cellOff += segLen
 		}
 	}
 	return rows
 }
 
-// cursorExpanded returns the cursor column in expanded-tab rune space, or -1 if unfocused.
+// cursorExpanded returns the cursor column in expanded-tab cell space, or -1 if unfocused.
 func (m Model) cursorExpanded() int {
 	if !m.focus || m.row < 0 || m.row >= len(m.lines) {
 		return -1
 	}
-	return len([]rune(expandTabs(string(m.lines[m.row][:m.col]))))
+	return ansi.StringWidth(expandTabs(string(m.lines[m.row][:m.col])))
+}
+
+// clusterAt returns the grapheme cluster of s covering cell col, its start
+// cell and its width. Past the end it returns a blank one-cell cursor.
+func clusterAt(s string, col int) (start int, cluster string, width int) {
+	for s != "" {
+		c, w := ansi.FirstGraphemeCluster(s, ansi.GraphemeWidth)
+		if col < start+w {
+			return start, c, w
+		}
+		start += w
+		s = s[len(c):]
+	}
+	return start, " ", 1
 }
 
 // renderGutter writes the gutter (line number + marker) for one visual row.
@@ -182,21 +197,20 @@ func (m Model) renderGutter(b *strings.Builder, vr visualRow, lineNumSty lipglos
 
 // renderSegment produces the rendered ANSI string for one visual row's text.
 func (m Model) renderSegment(vr visualRow, tw, cursorExpandedCol int, sr *selRange, bg lipgloss.Style) string {
-	segRuneOff := vr.subRow * tw
-	segLen := len([]rune(vr.text))
+	segLen := vr.segEnd - vr.segStart
 	hasSyntax := m.Language != "" && m.SyntaxTheme != ""
 
 	// Selection intersection
-	rowHasSel, selColStart, selColEnd := m.segmentSelection(vr.bufRow, segRuneOff, segLen, sr)
+	rowHasSel, selColStart, selColEnd := m.segmentSelection(vr.bufRow, vr.segStart, segLen, sr)
 
-	isCursorHere := m.isCursorOnSegment(vr.bufRow, segRuneOff, segLen, tw, cursorExpandedCol)
+	isCursorHere := m.isCursorOnSegment(vr.bufRow, vr.segStart, segLen, tw, vr.last, cursorExpandedCol)
 
 	if rowHasSel {
 		return m.renderSelectedSegment(vr.text, vr.fullHL, vr.segStart, segLen,
-			selColStart, selColEnd, m.SelectionStyle, bg, isCursorHere, cursorExpandedCol-segRuneOff)
+			selColStart, selColEnd, m.SelectionStyle, bg, isCursorHere, cursorExpandedCol-vr.segStart)
 	}
 	if isCursorHere {
-		return m.renderCursorSegment(vr.text, vr.fullHL, vr.segStart, cursorExpandedCol-segRuneOff)
+		return m.renderCursorSegment(vr.text, vr.fullHL, vr.segStart, cursorExpandedCol-vr.segStart)
 	}
 	if hasSyntax && vr.fullHL != "" {
 		return ansi.Cut(vr.fullHL, vr.segStart, vr.segEnd)
@@ -205,7 +219,7 @@ func (m Model) renderSegment(vr visualRow, tw, cursorExpandedCol int, sr *selRan
 }
 
 // segmentSelection computes selection column bounds for a segment. Returns (hasSel, start, end).
-func (m Model) segmentSelection(bufRow, segRuneOff, segLen int, sr *selRange) (bool, int, int) {
+func (m Model) segmentSelection(bufRow, segCellOff, segLen int, sr *selRange) (bool, int, int) {
 	if sr == nil || bufRow < sr.startRow || bufRow > sr.endRow {
 		return false, 0, 0
 	}
@@ -213,12 +227,12 @@ func (m Model) segmentSelection(bufRow, segRuneOff, segLen int, sr *selRange) (b
 	if bufRow == sr.startRow {
 		absSelStart = sr.startExp
 	}
-	absSelEnd := segRuneOff + segLen
+	absSelEnd := segCellOff + segLen
 	if bufRow == sr.endRow {
 		absSelEnd = sr.endExp
 	}
-	localStart := absSelStart - segRuneOff
-	localEnd := absSelEnd - segRuneOff
+	localStart := absSelStart - segCellOff
+	localEnd := absSelEnd - segCellOff
 	if localStart < 0 {
 		localStart = 0
 	}
@@ -232,35 +246,28 @@ func (m Model) segmentSelection(bufRow, segRuneOff, segLen int, sr *selRange) (b
 }
 
 // isCursorOnSegment returns true if the cursor falls within this segment.
-func (m Model) isCursorOnSegment(bufRow, segRuneOff, segLen, tw, cursorExpandedCol int) bool {
+// A cursor at the end of the line shows on the last segment if it has room.
+func (m Model) isCursorOnSegment(bufRow, segCellOff, segLen, tw int, last bool, cursorExpandedCol int) bool {
 	if !m.focus || bufRow != m.row || cursorExpandedCol < 0 {
 		return false
 	}
-	if cursorExpandedCol >= segRuneOff && cursorExpandedCol < segRuneOff+tw {
+	if cursorExpandedCol >= segCellOff && cursorExpandedCol < segCellOff+segLen {
 		return true
 	}
-	return cursorExpandedCol == segRuneOff+segLen && segLen < tw
+	return last && cursorExpandedCol == segCellOff+segLen && segLen < tw
 }
 
 // renderCursorSegment renders a text segment with the cursor at localCol.
-// localCol is a rune index within the segment's plain text.
-// fullHL is the full-line highlighted ANSI string; segStart is the rune offset
+// localCol is a cell column within the segment's plain text; the cursor
+// covers the whole (possibly wide) character there.
+// fullHL is the full-line highlighted ANSI string; segStart is the cell offset
 // of this segment within it. Uses ansi.Cut to extract correctly-highlighted
 // before/after portions so syntax coloring is never broken.
 func (m Model) renderCursorSegment(segText, fullHL string, segStart, localCol int) string {
 	bg := m.bgForRender()
-	runes := []rune(segText)
+	segLen := ansi.StringWidth(segText)
 
-	col := localCol
-	if col > len(runes) {
-		col = len(runes)
-	}
-
-	// Extract the cursor character from the plain text.
-	cursorChar := " "
-	if col < len(runes) {
-		cursorChar = string(runes[col])
-	}
+	col, cursorChar, cw := clusterAt(segText, localCol)
 
 	hasSyntax := m.Language != "" && m.SyntaxTheme != ""
 	var before, after string
@@ -269,11 +276,11 @@ func (m Model) renderCursorSegment(segText, fullHL string, segStart, localCol in
 		// Cut from the full-line highlight at absolute positions.
 		absCursorCol := segStart + col
 		before = ansi.Cut(fullHL, segStart, absCursorCol)
-		after = ansi.Cut(fullHL, absCursorCol+1, segStart+len(runes))
+		after = ansi.Cut(fullHL, absCursorCol+cw, segStart+segLen)
 	} else {
 		highlighted := bg.Render(segText)
 		before = ansi.Truncate(highlighted, col, "")
-		after = ansi.TruncateLeft(highlighted, col+1, "")
+		after = ansi.TruncateLeft(highlighted, col+cw, "")
 	}
 
 	// Render cursor character
@@ -287,7 +294,8 @@ func (m Model) renderCursorSegment(segText, fullHL string, segStart, localCol in
 
 // segRenderer holds rendering helpers for a single segment.
 type segRenderer struct {
-	runes     []rune
+	text      string
+	width     int
 	fullHL    string
 	segStart  int
 	hasSyntax bool
@@ -302,7 +310,7 @@ func (sr segRenderer) renderRange(from, to int, sty lipgloss.Style) string {
 		cut := ansi.Cut(sr.fullHL, sr.segStart+from, sr.segStart+to)
 		return sty.Render(ansi.Strip(cut))
 	}
-	return sty.Render(string(sr.runes[from:to]))
+	return sty.Render(ansi.Cut(sr.text, from, to))
 }
 
 func (sr segRenderer) renderNormal(from, to int) string {
@@ -312,21 +320,21 @@ func (sr segRenderer) renderNormal(from, to int) string {
 	if sr.hasSyntax && sr.fullHL != "" {
 		return ansi.Cut(sr.fullHL, sr.segStart+from, sr.segStart+to)
 	}
-	return sr.bg.Render(string(sr.runes[from:to]))
+	return sr.bg.Render(ansi.Cut(sr.text, from, to))
 }
 
 // renderSelectedSegment renders a text segment with a selection highlight
-// (and optionally a cursor). selStart/selEnd are segment-local rune offsets.
+// (and optionally a cursor). selStart/selEnd are segment-local cell offsets.
 func (m Model) renderSelectedSegment(
 	segText, fullHL string, segStart, segLen, selStart, selEnd int,
 	selSty, bg lipgloss.Style, hasCursor bool, cursorLocalCol int,
 ) string {
 	sr := segRenderer{
-		runes: []rune(segText), fullHL: fullHL, segStart: segStart,
+		text: segText, width: ansi.StringWidth(segText), fullHL: fullHL, segStart: segStart,
 		hasSyntax: m.Language != "" && m.SyntaxTheme != "", bg: bg,
 	}
 
-	if hasCursor && cursorLocalCol >= 0 && cursorLocalCol <= len(sr.runes) {
+	if hasCursor && cursorLocalCol >= 0 && cursorLocalCol <= sr.width {
 		return m.renderSelWithCursor(sr, selStart, selEnd, selSty, cursorLocalCol)
 	}
 
@@ -340,11 +348,8 @@ func (m Model) renderSelectedSegment(
 
 // renderSelWithCursor handles the cursor-in-selection case.
 func (m Model) renderSelWithCursor(sr segRenderer, selStart, selEnd int, selSty lipgloss.Style, cc int) string {
-	nRunes := len(sr.runes)
-	cursorChar := " "
-	if cc < nRunes {
-		cursorChar = string(sr.runes[cc])
-	}
+	segW := sr.width
+	cc, cursorChar, cw := clusterAt(sr.text, cc)
 	m.cursor.SetChar(cursorChar)
 	if cc >= selStart && cc < selEnd {
 		m.cursor.TextStyle = selSty
@@ -358,16 +363,16 @@ func (m Model) renderSelWithCursor(sr segRenderer, selStart, selEnd int, selSty
 	case cc < selStart:
 		sb.WriteString(sr.renderNormal(0, cc))
 		sb.WriteString(cv)
-		sb.WriteString(sr.renderNormal(cc+1, selStart))
+		sb.WriteString(sr.renderNormal(cc+cw, selStart))
 		sb.WriteString(sr.renderRange(selStart, selEnd, selSty))
-		sb.WriteString(sr.renderNormal(selEnd, nRunes))
+		sb.WriteString(sr.renderNormal(selEnd, segW))
 	case cc >= selEnd:
 		sb.WriteString(sr.renderNormal(0, selStart))
 		sb.WriteString(sr.renderRange(selStart, selEnd, selSty))
 		sb.WriteString(sr.renderNormal(selEnd, cc))
 		sb.WriteString(cv)
-		if cc+1 <= nRunes {
-			sb.WriteString(sr.renderNormal(cc+1, nRunes))
+		if cc+cw <= segW {
+			sb.WriteString(sr.renderNormal(cc+cw, segW))
 		}
 	default:
 		sb.WriteString(sr.renderNormal(0, selStart))
@@ -375,10 +380,10 @@ func (m Model) renderSelWithCursor(sr segRenderer, selStart, selEnd int, selSty
 			sb.WriteString(sr.renderRange(selStart, cc, selSty))
 		}
 		sb.WriteString(cv)
-		if cc+1 < selEnd {
-			sb.WriteString(sr.renderRange(cc+1, selEnd, selSty))
+		if cc+cw < selEnd {
+			sb.WriteString(sr.renderRange(cc+cw, selEnd, selSty))
 		}
-		sb.WriteString(sr.renderNormal(selEnd, nRunes))
+		sb.WriteString(sr.renderNormal(selEnd, segW))
 	}
 	return sb.String()
 }
@@ -407,12 +412,12 @@ func (m Model) placeholderView() string {
 	// First line: cursor (if focused) then placeholder text
 	if m.focus {
 		// Render cursor on first character of placeholder
-		phRunes := []rune(m.Placeholder)
-		m.cursor.SetChar(string(phRunes[0]))
+		first, _ := ansi.FirstGraphemeCluster(m.Placeholder, ansi.GraphemeWidth)
+		m.cursor.SetChar(first)
 		m.cursor.TextStyle = m.PlaceholderSty
 		m.cursor.Style = m.CursorStyle
 		b.WriteString(m.cursor.View())
-		rest := m.PlaceholderSty.Render(string(phRunes[1:]))
+		rest := m.PlaceholderSty.Render(m.Placeholder[len(first):])
 		rw := lipgloss.Width(m.cursor.View()) + lipgloss.Width(rest)
 		b.WriteString(rest)
 		if rw < tw {
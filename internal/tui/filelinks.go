@@ -0,0 +1,177 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/xonecas/symb/internal/hashline"
+)
+
+// fileRef is a "path:line" reference in assistant prose, between cells
+// start and end of its line.
+type fileRef struct {
+	path       string
+	line       int
+	start, end int
+}
+
+// fileRefsMsg reports which reference paths name files in the working
+// directory.
+type fileRefsMsg struct {
+	found map[string]bool
+}
+
+// assistantEntries renders finalized assistant prose as markdown. Lines
+// outside code fences that mention a "path:line" carry it as a ref, which
+// linkFileRefs turns into a link once the file is known to exist.
+func assistantEntries(content string, sty Styles) []convEntry {
+	raw := strings.Split(content, "\n")
+	lines := highlightMarkdown(content, sty.Text)
+	if len(lines) != len(raw) {
+		// Keep one entry per line so each ref lands on its own line.
+		lines = styledLines(content, sty.Text)
+	}
+	entries := textEntries(lines...)
+	fenced := false
+	for i, line := range raw {
+		trimmed := strings.TrimLeft(line, " ")
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			continue
+		}
+		loc := fileRefRe.FindStringSubmatchIndex(line)
+		if loc == nil {
+			continue
+		}
+		if ansi.Strip(entries[i].display) != line {
+			// The cells must match the text to splice the link in.
+			entries[i].display = sty.Text.Render(line)
+		}
+		n, _ := strconv.Atoi(line[loc[4]:loc[5]])
+		entries[i].ref = &fileRef{
+			path:  line[loc[2]:loc[3]],
+			line:  n,
+			start: ansi.StringWidth(line[:loc[0]]),
+			end:   ansi.StringWidth(line[:loc[1]]),
+		}
+	}
+	return entries
+}
+
+// linkFileRefs links the refs in entries whose file is known to exist and
+// queues unchecked paths for tickFileRefs.
+func (m *Model) linkFileRefs(entries []convEntry) {
+	for i := range entries {
+		ref := entries[i].ref
+		if ref == nil {
+			continue
+		}
+		found, checked := m.fileRefs[ref.path]
+		switch {
+		case found:
+			m.linkEntry(&entries[i])
+		case !checked:
+			if m.fileRefs == nil {
+				m.fileRefs = make(map[string]bool)
+			}
+			m.fileRefs[ref.path] = false
+			m.fileRefsQueue = append(m.fileRefsQueue, ref.path)
+		}
+	}
+}
+
+// linkEntry styles e's ref as a link over its highlighted line and makes
+// a click open the file there.
+func (m *Model) linkEntry(e *convEntry) {
+	ref, d := e.ref, e.display
+	e.display = ansi.Cut(d, 0, ref.start) +
+		m.styles.Clickable.Render(ansi.Strip(ansi.Cut(d, ref.start, ref.end))) +
+		ansi.Cut(d, ref.end, ansi.StringWidth(d))
+	e.filePath, e.line, e.ref = ref.path, ref.line, nil
+}
+
+// tickFileRefs checks the queued reference paths off the update loop.
+func (m *Model) tickFileRefs() tea.Cmd {
+	if len(m.fileRefsQueue) == 0 {
+		return nil
+	}
+	paths := m.fileRefsQueue
+	m.fileRefsQueue = nil
+	return func() tea.Msg {
+		found := make(map[string]bool)
+		for _, path := range paths {
+			if _, ok := resolveFileRef(path); ok {
+				found[path] = true
+			}
+		}
+		if len(found) == 0 {
+			return nil
+		}
+		return fileRefsMsg{found: found}
+	}
+}
+
+// handleFileRefs links the conversation's refs to files found to exist.
+func (m *Model) handleFileRefs(msg fileRefsMsg) {
+	for path := range msg.found {
+		m.fileRefs[path] = true
+	}
+	for i := range m.convEntries {
+		if ref := m.convEntries[i].ref; ref != nil && msg.found[ref.path] {
+			m.linkEntry(&m.convEntries[i])
+		}
+	}
+}
+
+// resolveFileRef returns the absolute path of a referenced file if it is a
+// regular file inside the working directory.
+func resolveFileRef(path string) (string, bool) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	abs := path
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(cwd, path)
+	}
+	rel, err := filepath.Rel(cwd, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	info, err := os.Stat(abs)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	return abs, true
+}
+
+// openFileCmd reads a referenced file and shows it in the tool view,
+// scrolled to line.
+func openFileCmd(path string, line int) tea.Cmd {
+	return func() tea.Msg {
+		abs, ok := resolveFileRef(path)
+		if !ok {
+			return nil
+		}
+		//nolint:gosec // G304: path was checked to be inside the working directory
+		data, err := os.ReadFile(abs)
+		if err != nil {
+			return nil
+		}
+		return openToolViewMsg{
+			title:    fmt.Sprintf("%s:%d", path, line),
+			content:  hashline.FormatTagged(hashline.TagLines(string(data), 1)),
+			filePath: path,
+			absPath:  abs,
+			line:     line,
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package tui
+
+import (
+	tea "charm.land/bubbletea/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// inputHistoryLimit bounds how many past submissions are loaded for recall.
+const inputHistoryLimit = 200
+
+// inputHistoryMsg carries the session's saved input history.
+type inputHistoryMsg struct {
+	history []string
+}
+
+// loadInputHistoryCmd reads the session's past submissions from the store.
+func (m Model) loadInputHistoryCmd() tea.Cmd {
+	if m.store == nil {
+		return nil
+	}
+	db, sessionID := m.store, m.sessionID
+	return func() tea.Msg {
+		history, err := db.LoadInputHistory(sessionID, inputHistoryLimit)
+		if err != nil {
+			log.Warn().Err(err).Str("session", sessionID).Msg("failed to load input history")
+			return nil
+		}
+		return inputHistoryMsg{history: history}
+	}
+}
+
+// handleInputHistory puts loaded history ahead of anything submitted since
+// startup.
+func (m Model) handleInputHistory(msg inputHistoryMsg) Model {
+	m.inputHistory = append(msg.history, m.inputHistory...)
+	m.historyPos = -1
+	return m
+}
+
+// inputSent records a submission in the history and clears the saved draft.
+func (m *Model) inputSent(text string) tea.Cmd {
+	return tea.Batch(m.recordInput(text), m.saveDraftCmd(""))
+}
+
+// recordInput adds a submission to the history and persists it. Repeating
+// the previous entry is not recorded again.
+func (m *Model) recordInput(text string) tea.Cmd {
+	m.historyPos = -1
+	if n := len(m.inputHistory); n > 0 && m.inputHistory[n-1] == text {
+		return nil
+	}
+	m.inputHistory = append(m.inputHistory, text)
+	if m.store == nil {
+		return nil
+	}
+	db, sessionID := m.store, m.sessionID
+	return func() tea.Msg {
+		if err := db.AddInputHistory(sessionID, text); err != nil {
+			log.Warn().Err(err).Str("session", sessionID).Msg("failed to save input history")
+		}
+		return nil
+	}
+}
+
+// recalling reports whether the input shows an unedited history entry.
+func (m *Model) recalling() bool {
+	return m.historyPos >= 0 && m.historyPos < len(m.inputHistory) &&
+		m.agentInput.Value() == m.inputHistory[m.historyPos]
+}
+
+// handleHistoryUp recalls the previous submission when the input is empty,
+// or steps further back from an unedited recalled entry with the cursor on
+// its first line. Otherwise up moves the cursor as usual.
+func (m *Model) handleHistoryUp() (Model, tea.Cmd, bool) {
+	if !m.agentInput.Focused() || len(m.inputHistory) == 0 {
+		return Model{}, nil, false
+	}
+	row, _ := m.agentInput.CursorPos()
+	pos := len(m.inputHistory) - 1
+	switch {
+	case m.recalling() && row == 0:
+		pos = max(m.historyPos-1, 0)
+	case m.agentInput.Value() != "":
+		return Model{}, nil, false
+	}
+	m.historyPos = pos
+	m.agentInput.SetValue(m.inputHistory[pos])
+	return *m, nil, true
+}
+
+// handleHistoryDown steps forward from an unedited recalled entry with the
+// cursor on its last line, clearing the input past the newest entry.
+func (m *Model) handleHistoryDown() (Model, tea.Cmd, bool) {
+	if !m.agentInput.Focused() || !m.recalling() {
+		return Model{}, nil, false
+	}
+	if row, _ := m.agentInput.CursorPos(); row != m.agentInput.LineCount()-1 {
+		return Model{}, nil, false
+	}
+	m.historyPos++
+	if m.historyPos >= len(m.inputHistory) {
+		m.historyPos = -1
+		m.agentInput.Reset()
+		return *m, nil, true
+	}
+	m.agentInput.SetValue(m.inputHistory[m.historyPos])
+	return *m, nil, true
+}
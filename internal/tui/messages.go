@@ -2,20 +2,22 @@ package tui
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/rs/zerolog/log"
 	"github.com/xonecas/symb/internal/delta"
+	"github.com/xonecas/symb/internal/git"
 	"github.com/xonecas/symb/internal/llm"
 	"github.com/xonecas/symb/internal/mcp"
 	"github.com/xonecas/symb/internal/provider"
 	"github.com/xonecas/symb/internal/store"
+	"github.com/xonecas/symb/internal/treesitter"
 )
 
 // ---------------------------------------------------------------------------
@@ -25,17 +27,20 @@ import (
 type llmUserMsg struct {
 	display string // raw text shown in conversation (with @tokens)
 	content string // expanded text sent to LLM (@ tokens replaced with file content)
+	note    string // shown dimmed under the message, not stored (e.g. attached context)
 }
 
 type llmAssistantMsg struct {
 	reasoning string
 	content   string
+	segments  []provider.Segment // streaming order of reasoning and content
 	toolCalls []provider.ToolCall
 }
 
 type llmToolResultMsg struct {
 	toolCallID string
 	content    string
+	isError    bool
 }
 
 type llmDoneMsg struct {
@@ -54,9 +59,17 @@ type llmUsageMsg struct {
 type storeBatch struct {
 	sessionID string
 	msgs      []store.SessionMessage
+	saved     chan<- storeSaved // optional: receives the last row ID
+}
+
+// storeSaved reports a storeBatch write to a waiting sender.
+type storeSaved struct {
+	id  int64
+	err error
 }
 
 type llmHistoryMsg struct{ msg provider.Message }
+type llmAutoContinueMsg struct{}
 type llmErrorMsg struct{ err error }
 
 type modelsFetchedMsg struct {
@@ -68,6 +81,8 @@ type modelSwitchedMsg struct {
 	modelName    string
 	providerName string
 	prov         provider.Provider
+	opts         provider.Options
+	profile      string // set when a /profile switch produced this
 	err          error
 }
 
@@ -94,8 +109,11 @@ type undoMsg struct{}
 
 // openToolViewMsg is sent when the user clicks the [view] button on a tool result.
 type openToolViewMsg struct {
-	title   string
-	content string
+	title    string
+	content  string
+	filePath string // location to scroll to, if any
+	absPath  string // filePath made absolute, to match LSP diagnostics
+	line     int
 }
 
 // llmBatchMsg carries multiple messages drained from updateChan in one go.
@@ -104,12 +122,23 @@ type llmBatchMsg []tea.Msg
 // UpdateToolsMsg is exported so main.go can send it via program.Send.
 type UpdateToolsMsg struct{ Tools []mcp.Tool }
 
-// LSPDiagnosticsMsg carries diagnostic line severities from the LSP manager to the TUI.
+// LSPDiagnosticsMsg carries diagnostic line severities and messages from the
+// LSP manager to the TUI.
 type LSPDiagnosticsMsg struct {
-	FilePath string      // absolute path of the file
-	Lines    map[int]int // bufRow (0-indexed) -> max severity (1=error, 2=warning)
+	FilePath string         // absolute path of the file
+	Lines    map[int]int    // bufRow (0-indexed) -> max severity (1=error, 2=warning)
+	Messages map[int]string // bufRow (0-indexed) -> message of the most severe diagnostic
 }
 
+// IndexProgressMsg reports how many files the background symbol index has parsed.
+type IndexProgressMsg struct{ Files int }
+
+// IndexDoneMsg signals that the background symbol index build has finished.
+type IndexDoneMsg struct{ Err error }
+
+// FilesChangedMsg lists absolute paths of files changed outside symb.
+type FilesChangedMsg struct{ Paths []string }
+
 // gitBranchMsg carries the current git branch and dirty status.
 type gitBranchMsg struct {
 	branch   string
@@ -123,35 +152,25 @@ type gitBranchMsg struct {
 // ELM commands
 // ---------------------------------------------------------------------------
 
-// frameTick returns a command that fires a tickMsg after ~16ms (~60fps).
-func frameTick() tea.Cmd {
-	return tea.Tick(16*time.Millisecond, func(t time.Time) tea.Msg {
+// frameTick returns a command that fires a tickMsg after one frame interval.
+func frameTick(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
 
 func gitStatusCounts() (added int, modified int, removed int) {
-	out, err := exec.Command("git", "status", "--porcelain").Output()
+	changes, err := git.Status()
 	if err != nil {
 		return 0, 0, 0
 	}
-	trimmed := strings.TrimSpace(string(out))
-	if trimmed == "" {
-		return 0, 0, 0
-	}
-	for _, line := range strings.Split(trimmed, "\n") {
-		if line == "" {
-			continue
-		}
-		if strings.HasPrefix(line, "??") {
+	for _, c := range changes {
+		if c.Code == "??" {
 			added++
 			continue
 		}
-		if len(line) < 2 {
-			continue
-		}
-		x := line[0]
-		y := line[1]
+		x := c.Code[0]
+		y := c.Code[1]
 		if x == 'A' || y == 'A' {
 			added++
 		}
@@ -198,12 +217,15 @@ func gitBranchTick() tea.Cmd {
 	})
 }
 
-func (m Model) sendToLLM(display, content string) tea.Cmd {
-	return func() tea.Msg { return llmUserMsg{display: display, content: content} }
+func (m Model) sendToLLM(display, content, note string) tea.Cmd {
+	return func() tea.Msg { return llmUserMsg{display: display, content: content, note: note} }
 }
 
 func (m Model) waitForLLMUpdate() tea.Cmd {
-	ch := m.updateChan
+	if m.replay != nil {
+		return nil // replayCmd feeds the batches; no turn writes updateChan
+	}
+	ch, rec := m.updateChan, m.recorder
 	return func() tea.Msg {
 		// Block until at least one message arrives.
 		first := <-ch
@@ -214,6 +236,7 @@ func (m Model) waitForLLMUpdate() tea.Cmd {
 			case msg := <-ch:
 				batch = append(batch, msg)
 			default:
+				rec.record(batch...)
 				return batch
 			}
 		}
@@ -239,6 +262,12 @@ type llmTurnDeps struct {
 	dt        *delta.Tracker
 	pad       llm.ScratchpadReader
 	systemMsg *provider.Message
+	modelID   string
+	idx       *treesitter.Index
+	confirm   llm.ConfirmFunc
+	timeout   time.Duration
+	budget    int
+	autoCont  int
 }
 
 type usageTracker struct {
@@ -261,6 +290,12 @@ func (m Model) llmTurnDeps() llmTurnDeps {
 		dt:        m.deltaTracker,
 		pad:       m.scratchpad,
 		systemMsg: m.initialSystemMsg,
+		modelID:   m.currentModelName,
+		idx:       m.tsIndex,
+		confirm:   confirmToolCall(m.updateChan, m.confirmTools),
+		timeout:   time.Duration(m.limits.TurnSeconds) * time.Second,
+		budget:    m.remainingTokens(),
+		autoCont:  m.limits.AutoContinue,
 	}
 }
 
@@ -271,6 +306,7 @@ func (m Model) runLLMTurn(deps llmTurnDeps, extra []provider.Message) {
 		return
 	}
 	history = ensureSystemMessage(history, deps.systemMsg)
+	refreshSystemPrompt(history, deps)
 	if len(extra) > 0 {
 		// extra carries the current user message in its expanded form. The DB
 		// already saved the display form as the last entry, so trim it to avoid
@@ -286,15 +322,22 @@ func (m Model) runLLMTurn(deps llmTurnDeps, extra []provider.Message) {
 	start := time.Now()
 	usage := &usageTracker{}
 	err = llm.ProcessTurn(deps.ctx, llm.ProcessTurnOptions{
-		Provider:   deps.provider,
-		Proxy:      deps.proxy,
-		Tools:      deps.tools,
-		History:    history,
-		Scratchpad: deps.pad,
+		Provider:     deps.provider,
+		Proxy:        deps.proxy,
+		Tools:        deps.tools,
+		History:      history,
+		Scratchpad:   deps.pad,
+		Confirm:      deps.confirm,
+		TurnTimeout:  deps.timeout,
+		TokenBudget:  deps.budget,
+		AutoContinue: deps.autoCont,
 		OnDelta: func(evt provider.StreamEvent) {
 			dispatchStreamEvent(deps.ch, evt)
 		},
 		OnUsage: usage.onUsage(deps.ch),
+		OnAutoContinue: func(string) {
+			deps.ch <- llmAutoContinueMsg{}
+		},
 		OnMessage: func(msg provider.Message) {
 			dispatchHistoryMessage(deps.ch, msg)
 		},
@@ -337,6 +380,26 @@ func ensureSystemMessage(history []provider.Message, systemMsg *provider.Message
 	return append([]provider.Message{*systemMsg}, history...)
 }
 
+// refreshSystemPrompt rebuilds the system prompt in history, and the saved
+// copy, once the symbol index that was still building when it was saved is
+// ready, so the stale indexing note is not replayed on later turns or resume.
+func refreshSystemPrompt(history []provider.Message, deps llmTurnDeps) {
+	for i, msg := range history {
+		if msg.Role != "system" {
+			continue
+		}
+		prompt, ok := llm.RefreshSystemPrompt(msg.Content, deps.modelID, deps.idx)
+		if !ok {
+			return
+		}
+		history[i].Content = prompt
+		if err := deps.store.SaveSystemPrompt(deps.sessionID, prompt); err != nil {
+			log.Warn().Err(err).Str("session", deps.sessionID).Msg("failed to save system prompt")
+		}
+		return
+	}
+}
+
 func snapshotBeforeTurn(dt *delta.Tracker) (map[string]delta.FileSnapshot, string) {
 	if dt == nil || dt.TurnID() == 0 {
 		return nil, ""
@@ -372,12 +435,14 @@ func dispatchHistoryMessage(ch chan tea.Msg, msg provider.Message) {
 		ch <- llmAssistantMsg{
 			reasoning: msg.Reasoning,
 			content:   msg.Content,
+			segments:  msg.Segments,
 			toolCalls: msg.ToolCalls,
 		}
 	case "tool":
 		ch <- llmToolResultMsg{
 			toolCallID: msg.ToolCallID,
 			content:    msg.Content,
+			isError:    msg.IsError,
 		}
 	}
 }
@@ -391,51 +456,41 @@ func (u *usageTracker) onUsage(ch chan tea.Msg) func(int, int) {
 	}
 }
 
-// messageToStore converts a provider.Message to a store.SessionMessage.
-func messageToStore(msg provider.Message) store.SessionMessage {
-	var tc json.RawMessage
-	if len(msg.ToolCalls) > 0 {
-		encoded, err := json.Marshal(msg.ToolCalls)
-		if err != nil {
-			log.Warn().Err(err).Msg("failed to marshal tool calls")
-		} else {
-			tc = encoded
-		}
-	}
-	return store.SessionMessage{
-		Role:         msg.Role,
-		Content:      msg.Content,
-		Reasoning:    msg.Reasoning,
-		ToolCalls:    tc,
-		ToolCallID:   msg.ToolCallID,
-		CreatedAt:    msg.CreatedAt,
-		InputTokens:  msg.InputTokens,
-		OutputTokens: msg.OutputTokens,
-	}
-}
-
-func (m *Model) saveMessages(msgs []provider.Message) {
+// saveMessagesCmd queues msgs for the store worker right away, so batches
+// are written in the order Update saw them. If the queue can't take them,
+// the returned Cmd writes them directly instead.
+func (m *Model) saveMessagesCmd(msgs []provider.Message) tea.Cmd {
 	if m.store == nil || len(msgs) == 0 {
-		return
+		return nil
 	}
+	m.lastSaved = msgs[len(msgs)-1]
 	stored := make([]store.SessionMessage, 0, len(msgs))
 	for _, msg := range msgs {
-		stored = append(stored, messageToStore(msg))
+		stored = append(stored, store.FromProviderMessage(msg))
 	}
 	if enqueueStoreBatch(m.storeQueue, storeBatch{sessionID: m.sessionID, msgs: stored}) {
-		return
+		return nil
 	}
-	if err := m.store.SaveMessages(m.sessionID, stored); err != nil {
-		log.Warn().Err(err).Msg("failed to save message batch")
+	db, sessionID, failures := m.store, m.sessionID, m.storeFailures
+	return func() tea.Msg {
+		if err := db.SaveMessages(sessionID, stored); err != nil {
+			failures.Add(1)
+			log.Warn().Err(err).Msg("failed to save message batch")
+		}
+		return nil
 	}
 }
 
-func (m Model) saveMessagesCmd(msgs []provider.Message) tea.Cmd {
-	if m.store == nil || len(msgs) == 0 {
+// saveScratchpadCmd persists the agent's plan to the active session.
+func (m Model) saveScratchpadCmd() tea.Cmd {
+	if m.store == nil || m.scratchpad == nil {
 		return nil
 	}
+	db, sessionID, content := m.store, m.sessionID, m.scratchpad.Content()
 	return func() tea.Msg {
-		m.saveMessages(msgs)
+		if err := db.SaveScratchpad(sessionID, content); err != nil {
+			log.Warn().Err(err).Str("session", sessionID).Msg("failed to save scratchpad")
+		}
 		return nil
 	}
 }
@@ -453,22 +508,35 @@ func enqueueStoreBatch(queue chan storeBatch, batch storeBatch) (ok bool) {
 	case queue <- batch:
 		return true
 	default:
-		log.Warn().Msg("store queue full; dropping message batch")
+		log.Warn().Msg("store queue full; saving message batch directly")
 		return false
 	}
 }
 
-func startStoreWorker(db *store.Cache, queue <-chan storeBatch) <-chan struct{} {
+// startStoreWorker writes queued batches in order, each in one transaction.
+// Batches that still fail after retries are counted in failures.
+func startStoreWorker(db *store.Cache, queue <-chan storeBatch, failures *atomic.Int64) <-chan struct{} {
 	done := make(chan struct{})
 	go func() {
 		defer close(done)
 		for batch := range queue {
+			if batch.saved != nil {
+				// SaveMessagesSync retries on busy itself.
+				var r storeSaved
+				if r.id, r.err = db.SaveMessagesSync(batch.sessionID, batch.msgs); r.err != nil {
+					failures.Add(1)
+					log.Warn().Err(r.err).Msg("failed to save message batch")
+				}
+				batch.saved <- r
+				continue
+			}
 			for attempt := 0; attempt <= store.SQLiteBusyMaxRetries; attempt++ {
 				err := db.SaveMessages(batch.sessionID, batch.msgs)
 				if err == nil {
 					break
 				}
 				if !store.IsSQLiteBusy(err) || attempt == store.SQLiteBusyMaxRetries {
+					failures.Add(1)
 					log.Warn().Err(err).Msg("failed to save message batch")
 					break
 				}
@@ -483,3 +551,28 @@ func startStoreWorker(db *store.Cache, queue <-chan storeBatch) <-chan struct{}
 	}()
 	return done
 }
+
+// storeBacklogged reports whether the save queue is more than half full.
+func (m Model) storeBacklogged() bool {
+	return m.storeQueue != nil && len(m.storeQueue) > cap(m.storeQueue)/2
+}
+
+// FlushStore closes the save queue and waits up to timeout for queued
+// messages to be written, then saves a checkpoint. It reports whether the
+// queue drained. Safe to call more than once.
+func (m Model) FlushStore(timeout time.Duration) bool {
+	if m.storeQueue == nil {
+		return true
+	}
+	m.closeStoreQueue()
+	drained := true
+	select {
+	case <-m.storeQueueDone:
+	case <-time.After(timeout):
+		drained = false
+	}
+	if err := m.store.SaveCheckpoint(m.sessionID, m.checkpoint()); err != nil {
+		log.Warn().Err(err).Str("session", m.sessionID).Msg("failed to save checkpoint")
+	}
+	return drained
+}
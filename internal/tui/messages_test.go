@@ -0,0 +1,294 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/xonecas/symb/internal/config"
+	"github.com/xonecas/symb/internal/llm"
+	"github.com/xonecas/symb/internal/provider"
+	"github.com/xonecas/symb/internal/store"
+	"github.com/xonecas/symb/internal/treesitter"
+)
+
+// TestStoreWorker verifies that queued saves report row IDs in order and
+// that failed writes are counted for the "unsaved" indicator.
+func TestStoreWorker(t *testing.T) {
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CreateSession("s"); err != nil {
+		t.Fatal(err)
+	}
+	queue := make(chan storeBatch, 4)
+	var failures atomic.Int64
+	done := startStoreWorker(db, queue, &failures)
+
+	queue <- storeBatch{sessionID: "s", msgs: []store.SessionMessage{{Role: "assistant", Content: "a"}}}
+	saved := make(chan storeSaved, 1)
+	queue <- storeBatch{sessionID: "s", msgs: []store.SessionMessage{{Role: "user", Content: "u"}}, saved: saved}
+	r := <-saved
+	if r.err != nil || r.id == 0 {
+		t.Fatalf("saved = %+v, want a row ID", r)
+	}
+	ids, _ := db.UserMessageIDs("s")
+	if len(ids) != 1 || ids[0] != r.id {
+		t.Errorf("user IDs = %v, want [%d]", ids, r.id)
+	}
+	if msgs, _ := db.LoadMessages("s"); len(msgs) != 2 || msgs[0].Content != "a" {
+		t.Errorf("messages = %+v, want queued order a, u", msgs)
+	}
+
+	db.Close()
+	queue <- storeBatch{sessionID: "s", msgs: []store.SessionMessage{{Role: "assistant", Content: "lost"}}}
+	close(queue)
+	<-done
+	if failures.Load() != 1 {
+		t.Errorf("failures = %d, want 1", failures.Load())
+	}
+}
+
+// TestSaveQueuedInUpdate verifies batches are queued when Update asks, not
+// when their Cmds run, so an interrupted turn's patch lands after the
+// messages it follows.
+func TestSaveQueuedInUpdate(t *testing.T) {
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	m := &Model{store: db, sessionID: "s", storeQueue: make(chan storeBatch, 4), storeFailures: &atomic.Int64{}}
+
+	call := provider.Message{Role: roleAssistant, ToolCalls: []provider.ToolCall{{ID: "c1", Name: "Read"}}}
+	if cmd := m.saveMessagesCmd([]provider.Message{call}); cmd != nil {
+		t.Error("queued batch returned a Cmd")
+	}
+	if cmd := m.patchInterruptedHistoryCmd(); cmd != nil {
+		t.Error("queued patch returned a Cmd")
+	}
+	if len(m.storeQueue) != 2 {
+		t.Fatalf("queue holds %d batches, want 2", len(m.storeQueue))
+	}
+	<-m.storeQueue
+	if patch := <-m.storeQueue; patch.msgs[0].Content != "The user interrupted me." {
+		t.Errorf("second batch = %+v, want the patch", patch.msgs)
+	}
+
+	m.saveMessagesCmd([]provider.Message{{Role: roleAssistant, Content: "done"}})
+	<-m.storeQueue
+	if cmd := m.patchInterruptedHistoryCmd(); cmd != nil || len(m.storeQueue) != 0 {
+		t.Error("patched a turn that ended with a reply")
+	}
+}
+
+// TestRefreshSystemPrompt verifies a system prompt saved while the symbol
+// index was building is rebuilt, in the turn and in the store, once the
+// index is ready.
+func TestRefreshSystemPrompt(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n\nfunc Hello() {}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.CreateSession("s"); err != nil {
+		t.Fatal(err)
+	}
+	idx := treesitter.NewIndex(dir)
+	stale := llm.BuildSystemPrompt("m", idx)
+	if err := db.SaveMessages("s", []store.SessionMessage{{Role: "system", Content: stale}, {Role: "user", Content: "hi"}}); err != nil {
+		t.Fatal(err)
+	}
+	deps := llmTurnDeps{store: db, sessionID: "s", modelID: "m", idx: idx}
+
+	history, _ := loadHistory(db, "s")
+	refreshSystemPrompt(history, deps)
+	if history[0].Content != stale {
+		t.Error("rebuilt the prompt before the index was ready")
+	}
+
+	if err := idx.Build(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	refreshSystemPrompt(history, deps)
+	if strings.Contains(history[0].Content, "indexing...") || !strings.Contains(history[0].Content, "Hello") {
+		t.Errorf("turn prompt not rebuilt:\n%s", history[0].Content)
+	}
+	if saved, _ := loadHistory(db, "s"); saved[0].Content != history[0].Content || saved[1].Content != "hi" {
+		t.Errorf("saved history = %+v, want the rebuilt prompt", saved)
+	}
+}
+
+// TestTickSpinnerReducedMotion verifies the spinner holds still when idle
+// and steps slowly during a turn with reduced motion on.
+func TestTickSpinnerReducedMotion(t *testing.T) {
+	m := &Model{reducedMotion: true}
+	start := time.Now()
+	m.tickSpinner(start.Add(time.Second))
+	if m.spinFrame != 0 {
+		t.Errorf("idle spinner moved to frame %d", m.spinFrame)
+	}
+	m.llmInFlight = true
+	m.spinFrameAt = start
+	m.tickSpinner(start.Add(200 * time.Millisecond))
+	if m.spinFrame != 0 {
+		t.Errorf("spinner stepped after 200ms")
+	}
+	m.tickSpinner(start.Add(400 * time.Millisecond))
+	if m.spinFrame != 1 {
+		t.Errorf("spinner frame = %d after 400ms, want 1", m.spinFrame)
+	}
+}
+
+// TestTickStreamingFlush verifies streamed text is redrawn at most once per
+// flush interval while the accumulated text stays complete.
+func TestTickStreamingFlush(t *testing.T) {
+	m := &Model{streamFlush: 100 * time.Millisecond, streamEntryStart: -1}
+	start := time.Now()
+	m.ensureStreaming()
+	m.appendStreamDelta(false, "a")
+	m.streamDirty = true
+	m.tickStreaming(start)
+	if m.streamDirty {
+		t.Fatal("first delta not drawn")
+	}
+	m.appendStreamDelta(false, "b")
+	m.streamDirty = true
+	m.tickStreaming(start.Add(50 * time.Millisecond))
+	if !m.streamDirty {
+		t.Error("redrawn before the flush interval")
+	}
+	m.appendStreamDelta(false, "c")
+	m.tickStreaming(start.Add(100 * time.Millisecond))
+	if m.streamDirty || len(m.streamSegments) != 1 || m.streamSegments[0].text != "abc" {
+		t.Errorf("dirty = %v, segments = %+v after the flush interval", m.streamDirty, m.streamSegments)
+	}
+}
+
+// TestInterleavedReasoning verifies reasoning and content keep their
+// arrival order while streaming, once the message is final and when the
+// session is resumed.
+func TestInterleavedReasoning(t *testing.T) {
+	initTheme("vulcan")
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+	m.llmInFlight = true
+
+	updated, _ = m.Update(llmBatchMsg{
+		llmReasoningDeltaMsg{content: "think "},
+		llmReasoningDeltaMsg{content: "one"},
+		llmContentDeltaMsg{content: "answer one"},
+		llmReasoningDeltaMsg{content: "think two"},
+		llmContentDeltaMsg{content: "answer two"},
+	})
+	m = updated.(Model)
+	want := []string{"think one", "answer one", "think two", "answer two"}
+	order := func() []string {
+		var got []string
+		for _, e := range m.convEntries {
+			if text := strings.TrimSpace(ansi.Strip(e.display)); text != "" {
+				got = append(got, text)
+			}
+		}
+		return got
+	}
+
+	m.rebuildStreamEntries()
+	if got := order(); !reflect.DeepEqual(got, want) {
+		t.Errorf("streaming order = %q, want %q", got, want)
+	}
+
+	segs := []provider.Segment{{Reasoning: true, Len: 9}, {Len: 10}, {Reasoning: true, Len: 9}, {Len: 10}}
+	updated, _ = m.Update(llmBatchMsg{llmAssistantMsg{reasoning: "think onethink two", content: "answer oneanswer two", segments: segs}})
+	m = updated.(Model)
+	if got := order(); !reflect.DeepEqual(got, want) {
+		t.Errorf("final order = %q, want %q", got, want)
+	}
+
+	stored := store.ToProviderMessages([]store.SessionMessage{store.FromProviderMessage(provider.Message{
+		Role: "assistant", Reasoning: "think onethink two", Content: "answer oneanswer two", Segments: segs,
+	})})
+	m.convEntries, _ = historyConvEntries(stored, m.styles)
+	if got := order(); !reflect.DeepEqual(got, want) {
+		t.Errorf("resumed order = %q, want %q", got, want)
+	}
+}
+
+func TestMessageSegments(t *testing.T) {
+	tests := []struct {
+		name               string
+		reasoning, content string
+		order              []provider.Segment
+		want               []streamSegment
+	}{
+		{"no order", "r", "c", nil, []streamSegment{{reasoning: true, text: "r"}, {text: "c"}}},
+		{"interleaved", "r1r2", "c1", []provider.Segment{{Reasoning: true, Len: 2}, {Len: 2}, {Reasoning: true, Len: 2}},
+			[]streamSegment{{reasoning: true, text: "r1"}, {text: "c1"}, {reasoning: true, text: "r2"}}},
+		{"short order", "r1r2", "c1", []provider.Segment{{Reasoning: true, Len: 2}, {Len: 2}},
+			[]streamSegment{{reasoning: true, text: "r1r2"}, {text: "c1"}}},
+		{"too long", "r", "c", []provider.Segment{{Len: 5}},
+			[]streamSegment{{reasoning: true, text: "r"}, {text: "c"}}},
+	}
+	for _, tt := range tests {
+		if got := messageSegments(tt.reasoning, tt.content, tt.order); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestJoinFences verifies reasoning streamed inside an open code fence is
+// moved after the content that closes it.
+func TestJoinFences(t *testing.T) {
+	got := joinFences([]streamSegment{
+		{text: "Here:\n```go\nfunc a() {"},
+		{reasoning: true, text: "hmm"},
+		{text: "}\n```\nDone."},
+		{reasoning: true, text: "more"},
+	})
+	want := []streamSegment{{text: "Here:\n```go\nfunc a() {}\n```\nDone."}, {reasoning: true, text: "hmmmore"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("joinFences = %+v, want %+v", got, want)
+	}
+}
+
+// TestAutoContinueNote verifies an auto-continued turn shows its note
+// between the reply that stopped and the one that carries on.
+func TestAutoContinueNote(t *testing.T) {
+	initTheme("vulcan")
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+	m.llmInFlight = true
+
+	updated, _ = m.Update(llmBatchMsg{
+		llmContentDeltaMsg{content: "Did a."},
+		llmAssistantMsg{content: "Did a."},
+		llmAutoContinueMsg{},
+		llmContentDeltaMsg{content: "Did b."},
+		llmAssistantMsg{content: "Did b."},
+	})
+	m = updated.(Model)
+	var got []string
+	for _, e := range m.convEntries {
+		if text := strings.TrimSpace(ansi.Strip(e.display)); text != "" {
+			got = append(got, text)
+		}
+	}
+	if want := []string{"Did a.", "(auto-continuing)", "Did b."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("conversation = %q, want %q", got, want)
+	}
+}
@@ -34,6 +34,8 @@ type Colors struct {
 	SelFg  string
 	SelBg  string
 	Border string
+	Error  string // error diagnostics
+	Warn   string // warning diagnostics
 }
 
 const (
@@ -1,9 +1,11 @@
 package modal
 
 import (
+	"strings"
 	"testing"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
 )
 
 var testColors = Colors{Fg: "#ccc", Bg: "#1a1a2e", Dim: "#666", SelFg: "#fff", SelBg: "#444", Border: "#555"}
@@ -176,3 +178,15 @@ func TestEmptyResultsEnterNoAction(t *testing.T) {
 		t.Fatalf("expected nil action, got %T", a)
 	}
 }
+
+func TestToolViewDiagnosticText(t *testing.T) {
+	tv := NewToolView("a.go", "1:ab|x := 1\n2:cd|y := 2", testColors)
+	tv.SetDiagnostics(map[int]Diagnostic{0: {Severity: 1, Message: "declared and not used: x"}})
+	view := ansi.Strip(tv.View(80, 20))
+	if !strings.Contains(view, "x := 1  declared and not used: x") {
+		t.Errorf("diagnostic not shown after its line:\n%s", view)
+	}
+	if strings.Count(view, "declared") != 1 {
+		t.Errorf("diagnostic shown more than once:\n%s", view)
+	}
+}
@@ -1,10 +1,14 @@
 package modal
 
 import (
+	"image/color"
+	"slices"
 	"strings"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/xonecas/symb/internal/highlight"
 )
 
 // ToolView is a simple read-only modal that displays a tool call and its result.
@@ -13,6 +17,19 @@ type ToolView struct {
 	content string
 	scroll  int
 	colors  Colors
+	focus   int    // content line to bring into view on the next render, 1-indexed; 0 = none
+	visible [2]int // first and last content lines shown by the last render, 0-indexed
+	lang    string // Chroma lexer name (empty = no highlighting)
+	theme   string // Chroma style name
+	diags   []int  // content lines with a diagnostic, ascending, 0-indexed
+	diagAt  int    // content line of the last diagnostic jumped to; -1 = none
+	diagMsg map[int]Diagnostic
+}
+
+// Diagnostic is an LSP diagnostic on one content line.
+type Diagnostic struct {
+	Severity int // 1=error, 2=warning
+	Message  string
 }
 
 // NewToolView creates a new tool viewer modal.
@@ -21,9 +38,70 @@ func NewToolView(title, content string, colors Colors) ToolView {
 		title:   title,
 		content: content,
 		colors:  colors,
+		diagAt:  -1,
 	}
 }
 
+// SetContent replaces the displayed content, keeping the scroll position.
+func (t *ToolView) SetContent(content string) {
+	t.content = content
+}
+
+// SetSyntax highlights the content as lang with the Chroma theme. An empty
+// lang shows it plain.
+func (t *ToolView) SetSyntax(lang, theme string) {
+	t.lang, t.theme = lang, theme
+}
+
+// SetDiagnostics replaces the diagnostics, keyed by 0-indexed content line.
+func (t *ToolView) SetDiagnostics(diags map[int]Diagnostic) {
+	t.diagMsg = diags
+	t.diags = t.diags[:0]
+	for row := range diags {
+		t.diags = append(t.diags, row)
+	}
+	slices.Sort(t.diags)
+}
+
+// jumpDiagnostic scrolls to the next (dir 1) or previous (dir -1) line with
+// a diagnostic after the last one jumped to, or after the top visible line,
+// wrapping around.
+func (t *ToolView) jumpDiagnostic(dir int) {
+	if len(t.diags) == 0 {
+		return
+	}
+	from := t.diagAt
+	if from < 0 {
+		from = t.visible[0] - 1
+		if dir < 0 {
+			from = t.visible[0]
+		}
+	}
+	i, found := slices.BinarySearch(t.diags, from)
+	if dir > 0 {
+		if found {
+			i++
+		}
+		i %= len(t.diags)
+	} else {
+		i = (i - 1 + len(t.diags)) % len(t.diags)
+	}
+	t.diagAt = t.diags[i]
+	t.ScrollToLine(t.diagAt)
+}
+
+// ScrollToLine scrolls so the given 0-indexed content line is near the top
+// once the modal is rendered (wrapping depends on the render width).
+func (t *ToolView) ScrollToLine(i int) {
+	t.focus = i + 1
+}
+
+// VisibleLines returns the first and last 0-indexed content lines shown by
+// the last render.
+func (t *ToolView) VisibleLines() (first, last int) {
+	return t.visible[0], t.visible[1]
+}
+
 // HandleMsg processes key events. Returns ActionClose when the modal should close.
 func (t *ToolView) HandleMsg(msg tea.Msg) (Action, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -44,6 +122,10 @@ func (t *ToolView) HandleMsg(msg tea.Msg) (Action, tea.Cmd) {
 			}
 		case "pgdown":
 			t.scroll += 10
+		case "f8":
+			t.jumpDiagnostic(1)
+		case "shift+f8":
+			t.jumpDiagnostic(-1)
 		}
 	case tea.MouseWheelMsg:
 		if msg.Button == tea.MouseWheelUp {
@@ -81,17 +163,25 @@ func (t *ToolView) View(appWidth, appHeight int) string {
 	// Wrap content lines to innerW.
 	rawLines := strings.Split(t.content, "\n")
 	var wrapped []string
-	for _, line := range rawLines {
+	var src []int // content line of each wrapped line
+	for i, line := range rawLines {
+		if i == t.focus-1 {
+			t.scroll = max(len(wrapped)-2, 0) // keep a little context above
+			t.focus = 0
+		}
 		if lipgloss.Width(line) <= innerW {
 			wrapped = append(wrapped, line)
+			src = append(src, i)
 		} else {
 			// Simple character-level wrap.
 			for len(line) > 0 {
 				if len(line) <= innerW {
 					wrapped = append(wrapped, line)
+					src = append(src, i)
 					break
 				}
 				wrapped = append(wrapped, line[:innerW])
+				src = append(src, i)
 				line = line[innerW:]
 			}
 		}
@@ -125,9 +215,28 @@ func (t *ToolView) View(appWidth, appHeight int) string {
 	if end > len(wrapped) {
 		end = len(wrapped)
 	}
-	for _, l := range wrapped[t.scroll:end] {
+	if end > t.scroll {
+		t.visible = [2]int{src[t.scroll], src[end-1]}
+	}
+	for k := t.scroll; k < end; k++ {
+		l := wrapped[k]
+		var vt string
+		if k+1 == len(wrapped) || src[k+1] != src[k] {
+			vt = t.diagText(src[k], innerW-lipgloss.Width(l), bg)
+		}
+		if t.lang != "" {
+			// Line by line, after wrapping, so the escapes don't throw off
+			// the wrap widths.
+			l = highlight.Highlight(l, t.lang, t.theme, t.colors.Bg)
+		}
 		sb.WriteByte('\n')
-		sb.WriteString(fgStyle.Render(padRight(l, innerW)))
+		if vt == "" {
+			sb.WriteString(fgStyle.Render(padRight(l, innerW)))
+			continue
+		}
+		// Pad after the message separately: its reset would drop the fill's bg.
+		fill := max(innerW-lipgloss.Width(l)-lipgloss.Width(vt), 0)
+		sb.WriteString(fgStyle.Render(l) + vt + fgStyle.Render(strings.Repeat(" ", fill)))
 	}
 	// Pad remaining lines so the box has consistent height.
 	rendered := end - t.scroll
@@ -163,6 +272,22 @@ func (t *ToolView) View(appWidth, appHeight int) string {
 		lipgloss.WithWhitespaceStyle(lipgloss.NewStyle().Background(bg)))
 }
 
+// diagText renders content line row's diagnostic message as virtual text
+// for the avail columns left after it, or "" if there is none or no room.
+func (t *ToolView) diagText(row, avail int, bg color.Color) string {
+	d, ok := t.diagMsg[row]
+	const pad = 2
+	if !ok || d.Message == "" || avail < pad+4 {
+		return ""
+	}
+	color := t.colors.Warn
+	if d.Severity == 1 {
+		color = t.colors.Error
+	}
+	sty := lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Background(bg)
+	return sty.Render(strings.Repeat(" ", pad) + ansi.Truncate(d.Message, avail-pad, "…"))
+}
+
 func truncate(s string, maxW int) string {
 	if lipgloss.Width(s) <= maxW {
 		return s
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"path/filepath"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
@@ -75,7 +76,7 @@ func (m *Model) handleConvMouse(msg tea.MouseMsg, x, y int) tea.Cmd {
 	case tea.MouseReleaseMsg:
 		return m.handleConvRelease(x, y, totalLines)
 	case tea.MouseWheelMsg:
-		m.handleConvWheel(ev, totalLines)
+		return m.handleConvWheel(ev, totalLines)
 	}
 	return nil
 }
@@ -105,17 +106,42 @@ func (m *Model) handleConvRelease(x, y, totalLines int) tea.Cmd {
 	return nil
 }
 
-func (m *Model) handleConvWheel(ev tea.MouseWheelMsg, totalLines int) {
-	convH := m.layout.conv.Dy()
-	if ev.Button == tea.MouseWheelUp {
-		maxScroll := totalLines - convH
-		if maxScroll < 0 {
-			maxScroll = 0
-		}
-		m.scrollOffset = min(m.scrollOffset+5, maxScroll)
-	} else if ev.Button == tea.MouseWheelDown {
-		m.scrollOffset = max(m.scrollOffset-5, 0)
+// handleConvWheel scrolls the conversation by wheelStep lines, or a page
+// with shift held.
+func (m *Model) handleConvWheel(ev tea.MouseWheelMsg, totalLines int) tea.Cmd {
+	dir := 0
+	switch ev.Button {
+	case tea.MouseWheelUp:
+		dir = 1
+	case tea.MouseWheelDown:
+		dir = -1
+	default:
+		return nil
+	}
+	if ev.Mod.Contains(tea.ModShift) {
+		return m.pageConv(dir)
+	}
+	if dir != m.wheelDir {
+		m.wheelRem, m.wheelDir = 0, dir
 	}
+	m.wheelRem += m.wheelStep
+	n := int(m.wheelRem)
+	m.wheelRem -= float64(n)
+	if n == 0 {
+		return nil
+	}
+	return m.scrollConv(dir*n, totalLines)
+}
+
+// scrollConv moves the conversation by delta lines (positive = up).
+// Reaching the top loads the previous trimmed turns from the DB.
+func (m *Model) scrollConv(delta, totalLines int) tea.Cmd {
+	maxScroll := max(totalLines-m.layout.conv.Dy(), 0)
+	m.scrollOffset = min(max(m.scrollOffset+delta, 0), maxScroll)
+	if delta > 0 && m.scrollOffset == maxScroll {
+		return m.loadOlderTurnsCmd()
+	}
+	return nil
 }
 
 // convPosFromScreen converts screen x,y to a convPos.
@@ -181,7 +207,9 @@ func (m *Model) isClickableLine(lineIdx int) bool {
 		return true
 	case entryUndo:
 		return true
-	case entryToolDiag, entryToolCall, entrySeparator:
+	case entryText, entryToolDiag:
+		return entry.filePath != ""
+	case entryToolCall, entrySeparator:
 		return false
 	default:
 		return false
@@ -189,8 +217,8 @@ func (m *Model) isClickableLine(lineIdx int) bool {
 }
 
 // handleConvClick resolves a click on a wrapped conversation line.
-// Tool result [view] buttons open the relevant content in the editor.
-// Undo buttons trigger an undo.
+// Tool result carets toggle the inline expansion, [view] buttons open the
+// relevant content in the tool viewer. Undo buttons trigger an undo.
 func (m *Model) handleConvClick(wrappedLine, col int) tea.Cmd {
 	m.wrappedConvLines() // ensure convLineSource is fresh
 	src := m.convLineSource
@@ -211,13 +239,24 @@ func (m *Model) handleConvClick(wrappedLine, col int) tea.Cmd {
 		return nil
 
 	case entryToolResult:
-		// Only trigger on the [view] label at the end of the line.
+		// The caret at the start of the line toggles the inline expansion.
+		if col == 0 && len(toolResultBody(entry.full)) > 0 {
+			m.toggleToolResult(entryIdx)
+			return nil
+		}
+		// Otherwise only trigger on the [view] label at the end of the line.
 		if m.isClickOnViewLabel(entry.display, col) {
 			return m.handleToolResultView(entry)
 		}
 		return nil
 
-	case entryToolDiag, entryToolCall, entrySeparator:
+	case entryText, entryToolDiag:
+		if entry.filePath != "" {
+			return openFileCmd(entry.filePath, entry.line)
+		}
+		return nil
+
+	case entryToolCall, entrySeparator:
 		return nil
 
 	default:
@@ -256,6 +295,10 @@ func (m *Model) handleToolResultView(entry convEntry) tea.Cmd {
 		title = "Tool Result"
 	}
 	return func() tea.Msg {
-		return openToolViewMsg{title: title, content: entry.full}
+		var abs string
+		if entry.filePath != "" {
+			abs, _ = filepath.Abs(entry.filePath)
+		}
+		return openToolViewMsg{title: title, content: entry.full, filePath: entry.filePath, absPath: abs, line: entry.line}
 	}
 }
@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/xonecas/symb/internal/hashline"
+	"github.com/xonecas/symb/internal/tui/modal"
+)
+
+// viewedFile is the file last shown in the tool viewer.
+type viewedFile struct {
+	path        string
+	absPath     string
+	content     string // viewer content, hashline-tagged
+	first, last int    // viewer content lines visible when it closed, 0-indexed
+}
+
+// visibleRange maps the visible viewer lines to the file lines they show,
+// from their hashline tags. It returns 0, 0 when no tagged line was visible.
+func (v viewedFile) visibleRange() (start, end int) {
+	rows := strings.Split(v.content, "\n")
+	for _, row := range rows[min(v.first, len(rows)):min(v.last+1, len(rows))] {
+		tag, _, ok := strings.Cut(row, "|")
+		if !ok {
+			continue
+		}
+		a, err := hashline.ParseAnchor(tag)
+		if err != nil {
+			continue
+		}
+		if start == 0 {
+			start = a.Num
+		}
+		end = a.Num
+	}
+	return start, end
+}
+
+// diagnostics maps d's file lines to the viewer content lines that show
+// them, from their hashline tags.
+func (v viewedFile) diagnostics(d LSPDiagnosticsMsg) map[int]modal.Diagnostic {
+	out := make(map[int]modal.Diagnostic)
+	for i, row := range strings.Split(v.content, "\n") {
+		tag, _, ok := strings.Cut(row, "|")
+		if !ok {
+			continue
+		}
+		a, err := hashline.ParseAnchor(tag)
+		if err != nil {
+			continue
+		}
+		if sev, ok := d.Lines[a.Num-1]; ok {
+			out[i] = modal.Diagnostic{Severity: sev, Message: d.Messages[a.Num-1]}
+		}
+	}
+	return out
+}
+
+// handleCtrlO toggles sending the viewed file with the next message.
+func (m *Model) handleCtrlO() (Model, tea.Cmd, bool) {
+	m.openFileContext = !m.openFileContext
+	state := "off"
+	if m.openFileContext {
+		state = "on"
+	}
+	m.appendText("", m.styles.Dim.Render("open-file context "+state), "")
+	return *m, nil, true
+}
+
+// takeOpenFileContext returns the viewed file, marked and hashline-tagged,
+// to append to the outgoing message, and a note saying what was sent. At
+// most openFileMaxLines lines are sent, centred on the lines that were
+// visible. The file is sent once; it returns "" when the option is off or
+// no file was viewed since the last message.
+func (m *Model) takeOpenFileContext() (content, note string) {
+	v := m.viewedFile
+	m.viewedFile = viewedFile{}
+	if !m.openFileContext || v.path == "" {
+		return "", ""
+	}
+	abs, ok := resolveFileRef(v.path)
+	if !ok {
+		return "", ""
+	}
+	//nolint:gosec // G304: path was checked to be inside the working directory
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return "", ""
+	}
+	tagged := hashline.TagLines(strings.TrimRight(string(data), "\n"), 1)
+	if len(tagged) == 0 {
+		return "", ""
+	}
+
+	start, end := v.visibleRange()
+	if start == 0 {
+		start, end = 1, 1
+	}
+	start, end = max(start, 1), min(end, len(tagged))
+	if extra := m.openFileMaxLines - (end - start + 1); extra > 0 {
+		start = max(start-extra/2, 1)
+		end = min(start+m.openFileMaxLines-1, len(tagged))
+		start = max(end-m.openFileMaxLines+1, 1)
+	} else {
+		end = start + m.openFileMaxLines - 1
+	}
+
+	content = fmt.Sprintf("\n\n[Open in the viewer: %s, lines %d-%d of %d]\n%s",
+		v.path, start, end, len(tagged), hashline.FormatTagged(tagged[start-1:end]))
+	return content, fmt.Sprintf("+ %s:%d-%d as context", v.path, start, end)
+}
+
+// handleFilesChanged reloads the tool viewer when the file it shows changed
+// outside symb, keeping the first visible line in view.
+func (m *Model) handleFilesChanged(msg FilesChangedMsg) tea.Cmd {
+	if m.toolViewModal == nil || m.viewedFile.path == "" {
+		return nil
+	}
+	abs, ok := resolveFileRef(m.viewedFile.path)
+	if !ok || !slices.Contains(msg.Paths, abs) {
+		return nil
+	}
+	v := m.viewedFile
+	v.first, v.last = m.toolViewModal.VisibleLines()
+	start, _ := v.visibleRange()
+	return openFileCmd(v.path, max(start, 1))
+}
@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/xonecas/symb/internal/store"
+	"github.com/xonecas/symb/internal/tui/modal"
+)
+
+// sessionPicker is a standalone program that lists stored sessions in a
+// filterable modal and reports the chosen ID.
+type sessionPicker struct {
+	modal  modal.Model
+	ids    map[modal.Item]string
+	chosen string
+	width  int
+	height int
+}
+
+// PickSession runs the session picker before the main TUI starts. It
+// returns the selected session ID, or "" if the user cancelled.
+func PickSession(db *store.Cache, syntaxTheme string, opts ...tea.ProgramOption) (string, error) {
+	sessions, err := db.ListSessions()
+	if err != nil {
+		return "", err
+	}
+	if len(sessions) == 0 {
+		return "", fmt.Errorf("no sessions found")
+	}
+	initTheme(syntaxTheme)
+
+	p := newSessionPicker(sessions)
+	final, err := tea.NewProgram(p, opts...).Run()
+	if err != nil {
+		return "", err
+	}
+	return final.(sessionPicker).chosen, nil
+}
+
+func newSessionPicker(sessions []store.SessionSummary) sessionPicker {
+	items := make([]modal.Item, len(sessions))
+	ids := make(map[modal.Item]string, len(sessions))
+	for i, s := range sessions {
+		items[i] = sessionItem(s)
+		ids[items[i]] = s.ID
+	}
+	searchFn := func(query string) []modal.Item {
+		if query == "" {
+			return items
+		}
+		q := strings.ToLower(query)
+		var filtered []modal.Item
+		for _, item := range items {
+			if strings.Contains(strings.ToLower(item.Name), q) ||
+				strings.Contains(strings.ToLower(item.Desc), q) {
+				filtered = append(filtered, item)
+			}
+		}
+		return filtered
+	}
+	md := modal.New(searchFn, "Session: ", modal.Colors{
+		Fg:     palette.Fg,
+		Bg:     palette.Bg,
+		Dim:    palette.Dim,
+		SelFg:  palette.Bg,
+		SelBg:  palette.Fg,
+		Border: palette.Border,
+	})
+	md.WidthPct = 80
+	return sessionPicker{modal: md, ids: ids}
+}
+
+// sessionItem renders a session as "title" / "timestamp · N msgs · id".
+func sessionItem(s store.SessionSummary) modal.Item {
+	title := s.Title
+	if title == "" {
+		title = strings.ReplaceAll(s.Preview, "\n", " ")
+	}
+	return modal.Item{
+		Name: title,
+		Desc: fmt.Sprintf("%s · %d msgs · %s", s.Timestamp.Format("2006-01-02 15:04"), s.Messages, s.ID),
+	}
+}
+
+func (p sessionPicker) Init() tea.Cmd { return nil }
+
+func (p sessionPicker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width, p.height = msg.Width, msg.Height
+		return p, nil
+	case tea.KeyPressMsg:
+		if msg.Keystroke() == "ctrl+c" {
+			return p, tea.Quit
+		}
+	}
+	action, cmd := p.modal.HandleMsg(msg)
+	switch a := action.(type) {
+	case modal.ActionClose:
+		return p, tea.Quit
+	case modal.ActionSelect:
+		p.chosen = p.ids[a.Item]
+		return p, tea.Quit
+	}
+	return p, cmd
+}
+
+func (p sessionPicker) View() tea.View {
+	v := tea.NewView("")
+	if p.width > 0 {
+		v = tea.NewView(p.modal.View(p.width, p.height))
+	}
+	v.AltScreen = true
+	return v
+}
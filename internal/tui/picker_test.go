@@ -0,0 +1,25 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/xonecas/symb/internal/store"
+)
+
+// TestSessionPickerSelects verifies navigation picks the highlighted session.
+func TestSessionPickerSelects(t *testing.T) {
+	initTheme("vulcan")
+	p := newSessionPicker([]store.SessionSummary{
+		{ID: "aaa", Preview: "fix the parser", Timestamp: time.Now(), Messages: 4},
+		{ID: "bbb", Preview: "add a flag", Timestamp: time.Now(), Messages: 9},
+	})
+	var mdl tea.Model = p
+	for _, k := range []tea.KeyPressMsg{{Code: tea.KeyDown}, {Code: tea.KeyDown}, {Code: tea.KeyEnter}} {
+		mdl, _ = mdl.Update(k)
+	}
+	if got := mdl.(sessionPicker).chosen; got != "bbb" {
+		t.Errorf("chosen = %q, want %q", got, "bbb")
+	}
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/xonecas/symb/internal/provider"
+)
+
+// preflightTimeout bounds the startup provider check.
+const preflightTimeout = 10 * time.Second
+
+// providerCheckMsg reports the startup provider check.
+type providerCheckMsg struct {
+	name string
+	err  error
+}
+
+// preflightCmd checks the provider in the background at startup so a bad
+// endpoint or key shows up before the first turn instead of failing it.
+func (m Model) preflightCmd() tea.Cmd {
+	prov, name := m.provider, m.providerConfigName
+	if prov == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), preflightTimeout)
+		defer cancel()
+		_, err := provider.Check(ctx, prov)
+		return providerCheckMsg{name: name, err: err}
+	}
+}
+
+// handleProviderCheck surfaces a failed startup check; success is silent.
+func (m Model) handleProviderCheck(msg providerCheckMsg) Model {
+	if msg.err == nil {
+		return m
+	}
+	text := fmt.Sprintf("provider %s unreachable: %v", msg.name, msg.err)
+	if status := provider.HTTPStatus(msg.err); status != 0 {
+		text = fmt.Sprintf("provider %s check failed (HTTP %d): %v", msg.name, status, msg.err)
+	}
+	m.appendText("", m.styles.Error.Render(text), "")
+	return m
+}
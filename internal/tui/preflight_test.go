@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/xonecas/symb/internal/config"
+	"github.com/xonecas/symb/internal/provider"
+)
+
+// TestHandleProviderCheck verifies a failed startup check is reported with
+// its HTTP status and a passing one stays silent.
+func TestHandleProviderCheck(t *testing.T) {
+	initTheme("vulcan")
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	if m.preflightCmd() != nil {
+		t.Error("preflightCmd should be nil without a provider")
+	}
+
+	n := len(m.convEntries)
+	m = m.handleProviderCheck(providerCheckMsg{name: "p"})
+	if len(m.convEntries) != n {
+		t.Error("a passing check should not add output")
+	}
+
+	m = m.handleProviderCheck(providerCheckMsg{name: "p", err: &provider.StatusError{Op: "list models", StatusCode: 401, Body: "bad key"}})
+	if got := m.convEntries[len(m.convEntries)-2].display; !strings.Contains(got, "HTTP 401") {
+		t.Errorf("failure entry = %q, want the HTTP status", got)
+	}
+
+	m = m.handleProviderCheck(providerCheckMsg{name: "p", err: errors.New("connection refused")})
+	if got := m.convEntries[len(m.convEntries)-2].display; !strings.Contains(got, "unreachable") {
+		t.Errorf("failure entry = %q, want unreachable", got)
+	}
+}
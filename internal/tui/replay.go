@@ -0,0 +1,226 @@
+package tui
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/symb/internal/provider"
+)
+
+// recordedEvent is one line of a recording: a user message or a message a
+// turn sent the TUI, AtMS milliseconds after recording started. Events
+// drained from updateChan together share a Batch.
+type recordedEvent struct {
+	AtMS          int64               `json:"at_ms"`
+	Batch         int                 `json:"batch"`
+	Type          string              `json:"type"`
+	Text          string              `json:"text,omitempty"`
+	Reasoning     string              `json:"reasoning,omitempty"`
+	Segments      []provider.Segment  `json:"segments,omitempty"`
+	ToolCalls     []provider.ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID    string              `json:"tool_call_id,omitempty"`
+	IsError       bool                `json:"is_error,omitempty"`
+	InputTokens   int                 `json:"input_tokens,omitempty"`
+	OutputTokens  int                 `json:"output_tokens,omitempty"`
+	ContextTokens int                 `json:"context_tokens,omitempty"`
+	DurationMS    int64               `json:"duration_ms,omitempty"`
+	Timestamp     string              `json:"timestamp,omitempty"`
+}
+
+// recorder writes the messages the TUI receives to a recording, one JSON
+// event per line. Confirm prompts and history saves are left out: replay
+// neither asks nor persists.
+type recorder struct {
+	mu    sync.Mutex
+	enc   *json.Encoder
+	start time.Time
+	batch int
+	err   error // first write error; recording stops there
+}
+
+// record writes msgs as one batch.
+func (r *recorder) record(msgs ...tea.Msg) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	r.batch++
+	at := time.Since(r.start).Milliseconds()
+	for _, raw := range msgs {
+		evt, ok := recordEvent(raw)
+		if !ok {
+			continue
+		}
+		evt.AtMS, evt.Batch = at, r.batch
+		if err := r.enc.Encode(evt); err != nil {
+			r.err = err
+			log.Warn().Err(err).Msg("recording stopped")
+			return
+		}
+	}
+}
+
+// recordEvent converts a TUI message to an event, or false if it is not
+// recorded.
+func recordEvent(raw tea.Msg) (recordedEvent, bool) {
+	switch msg := raw.(type) {
+	case llmUserMsg:
+		return recordedEvent{Type: "user", Text: msg.display}, true
+	case llmContentDeltaMsg:
+		return recordedEvent{Type: "content", Text: msg.content}, true
+	case llmReasoningDeltaMsg:
+		return recordedEvent{Type: "reasoning", Text: msg.content}, true
+	case llmAssistantMsg:
+		return recordedEvent{Type: "assistant", Text: msg.content, Reasoning: msg.reasoning, Segments: msg.segments, ToolCalls: msg.toolCalls}, true
+	case llmToolResultMsg:
+		return recordedEvent{Type: "tool_result", Text: msg.content, ToolCallID: msg.toolCallID, IsError: msg.isError}, true
+	case llmUsageMsg:
+		return recordedEvent{Type: "usage", InputTokens: msg.inputTokens, OutputTokens: msg.outputTokens}, true
+	case llmAutoContinueMsg:
+		return recordedEvent{Type: "auto_continue"}, true
+	case llmErrorMsg:
+		return recordedEvent{Type: "error", Text: msg.err.Error()}, true
+	case llmDoneMsg:
+		return recordedEvent{
+			Type:          "done",
+			InputTokens:   msg.inputTokens,
+			OutputTokens:  msg.outputTokens,
+			ContextTokens: msg.contextTokens,
+			DurationMS:    msg.duration.Milliseconds(),
+			Timestamp:     msg.timestamp,
+		}, true
+	}
+	return recordedEvent{}, false
+}
+
+// replayMsg converts a recorded event back to the message it came from.
+func (e recordedEvent) replayMsg() tea.Msg {
+	switch e.Type {
+	case "user":
+		return llmUserMsg{display: e.Text, content: e.Text}
+	case "content":
+		return llmContentDeltaMsg{content: e.Text}
+	case "reasoning":
+		return llmReasoningDeltaMsg{content: e.Text}
+	case "assistant":
+		return llmAssistantMsg{content: e.Text, reasoning: e.Reasoning, segments: e.Segments, toolCalls: e.ToolCalls}
+	case "tool_result":
+		return llmToolResultMsg{toolCallID: e.ToolCallID, content: e.Text, isError: e.IsError}
+	case "usage":
+		return llmUsageMsg{inputTokens: e.InputTokens, outputTokens: e.OutputTokens}
+	case "auto_continue":
+		return llmAutoContinueMsg{}
+	case "error":
+		return llmErrorMsg{err: errors.New(e.Text)}
+	case "done":
+		return llmDoneMsg{
+			duration:      time.Duration(e.DurationMS) * time.Millisecond,
+			timestamp:     e.Timestamp,
+			inputTokens:   e.InputTokens,
+			outputTokens:  e.OutputTokens,
+			contextTokens: e.ContextTokens,
+		}
+	}
+	return nil
+}
+
+// WithRecording returns m recording every turn it runs to w, for playback
+// with WithReplay.
+func (m Model) WithRecording(w io.Writer) Model {
+	m.recorder = &recorder{enc: json.NewEncoder(w), start: time.Now()}
+	return m
+}
+
+// Recording is a recorded session, as read by LoadRecording.
+type Recording struct {
+	batches [][]recordedEvent
+}
+
+// LoadRecording reads a recording written by a Model WithRecording.
+func LoadRecording(r io.Reader) (Recording, error) {
+	var rec Recording
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64<<20)
+	for n := 1; scanner.Scan(); n++ {
+		var evt recordedEvent
+		if err := json.Unmarshal(scanner.Bytes(), &evt); err != nil {
+			return Recording{}, fmt.Errorf("line %d: %w", n, err)
+		}
+		if evt.replayMsg() == nil {
+			return Recording{}, fmt.Errorf("line %d: unknown event type %q", n, evt.Type)
+		}
+		if last := len(rec.batches) - 1; last >= 0 && rec.batches[last][0].Batch == evt.Batch {
+			rec.batches[last] = append(rec.batches[last], evt)
+		} else {
+			rec.batches = append(rec.batches, []recordedEvent{evt})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Recording{}, err
+	}
+	return rec, nil
+}
+
+// WithReplay returns m playing rec back at speed times the recorded pace
+// (<= 0 plays without pauses). Replay only drives the display: m should
+// have no provider, store or delta tracker, so nothing is sent, saved or
+// written to files again.
+func (m Model) WithReplay(rec Recording, speed float64) Model {
+	m.replay = rec.batches
+	m.replaySpeed = speed
+	return m
+}
+
+// replayMsg plays back batch i of the recording.
+type replayMsg struct{ i int }
+
+// replayCmd schedules batch i after its recorded delay from batch i-1.
+func (m Model) replayCmd(i int) tea.Cmd {
+	if i >= len(m.replay) {
+		return nil
+	}
+	var delay time.Duration
+	if i > 0 && m.replaySpeed > 0 {
+		ms := m.replay[i][0].AtMS - m.replay[i-1][0].AtMS
+		delay = time.Duration(float64(ms)/m.replaySpeed) * time.Millisecond
+	}
+	return tea.Tick(delay, func(time.Time) tea.Msg { return replayMsg{i: i} })
+}
+
+// handleReplay feeds batch msg.i to the UI as if a turn had sent it.
+func (m Model) handleReplay(msg replayMsg) (Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	var batch llmBatchMsg
+	for _, evt := range m.replay[msg.i] {
+		switch raw := evt.replayMsg().(type) {
+		case llmUserMsg:
+			var cmd tea.Cmd
+			m, cmd = m.handleUserMsg(raw)
+			m.llmInFlight = true
+			m.turnStart, m.turnEnd = time.Now(), time.Time{}
+			cmds = append(cmds, cmd)
+		default:
+			batch = append(batch, raw)
+		}
+	}
+	if len(batch) > 0 {
+		updated, cmd := m.handleLLMBatch(batch)
+		m = updated.(Model)
+		cmds = append(cmds, cmd)
+	}
+	if msg.i == len(m.replay)-1 {
+		m.appendText(m.styles.Dim.Render("Replay finished."), "")
+	}
+	return m, tea.Batch(append(cmds, m.replayCmd(msg.i+1))...)
+}
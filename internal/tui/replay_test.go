@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/xonecas/symb/internal/config"
+	"github.com/xonecas/symb/internal/provider"
+)
+
+// TestRecordReplay verifies a recorded turn plays back into a fresh model
+// in its recorded batches, ending idle, with confirm prompts left out.
+func TestRecordReplay(t *testing.T) {
+	initTheme("vulcan")
+	newModel := func() Model {
+		m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+		updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+		return updated.(Model)
+	}
+
+	var buf bytes.Buffer
+	rec := newModel().WithRecording(&buf).recorder
+	call := provider.ToolCall{ID: "c1", Name: "Read", Arguments: json.RawMessage(`{"file":"a.go"}`)}
+	rec.record(llmUserMsg{display: "what is in a.go?", content: "expanded"})
+	rec.record(llmContentDeltaMsg{content: "Let me "}, llmContentDeltaMsg{content: "look."})
+	rec.record(llmAssistantMsg{content: "Let me look.", toolCalls: []provider.ToolCall{call}},
+		llmConfirmMsg{call: call}, llmUsageMsg{inputTokens: 10, outputTokens: 5})
+	rec.record(llmToolResultMsg{toolCallID: "c1", content: "Read a.go (1 lines):\n\n1:ab|package a"})
+	rec.record(llmContentDeltaMsg{content: "It is package a."}, llmAssistantMsg{content: "It is package a."},
+		llmDoneMsg{duration: 2 * time.Second, timestamp: "12:00", inputTokens: 10, outputTokens: 5})
+
+	recording, err := LoadRecording(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recording.batches) != 5 {
+		t.Fatalf("batches = %d, want 5", len(recording.batches))
+	}
+	if n := len(recording.batches[2]); n != 2 {
+		t.Errorf("confirm prompt was recorded: batch has %d events", n)
+	}
+
+	m := newModel().WithReplay(recording, 0)
+	for i := range recording.batches {
+		updated, _ := m.Update(replayMsg{i: i})
+		m = updated.(Model)
+	}
+	if m.llmInFlight {
+		t.Error("turn still in flight after replay")
+	}
+	if m.totalInputTokens != 10 || m.totalOutputTokens != 5 {
+		t.Errorf("tokens = %d in, %d out", m.totalInputTokens, m.totalOutputTokens)
+	}
+	var conv strings.Builder
+	for _, e := range m.convEntries {
+		conv.WriteString(ansi.Strip(e.display) + "\n")
+	}
+	for _, want := range []string{"what is in a.go?", "Let me look.", "It is package a.", "Replay finished."} {
+		if !strings.Contains(conv.String(), want) {
+			t.Errorf("conversation missing %q:\n%s", want, conv.String())
+		}
+	}
+
+	if _, err := LoadRecording(strings.NewReader(`{"type":"bogus"}` + "\n")); err == nil {
+		t.Error("unknown event type accepted")
+	}
+}
@@ -1,11 +1,13 @@
 package tui
 
 import (
+	"fmt"
 	"strings"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/x/ansi"
+	"github.com/xonecas/symb/internal/hashline"
 )
 
 // ---------------------------------------------------------------------------
@@ -13,19 +15,101 @@ import (
 // ---------------------------------------------------------------------------
 
 // copySelection copies the active selection (from any component) to the
-// clipboard using both OSC 52 (for SSH/tmux) and native clipboard.
+// clipboard backend picked at startup (native tool or OSC 52).
 func (m *Model) copySelection() tea.Cmd {
-	var text string
+	text, _ := m.selectedText()
+	if text == "" {
+		return nil
+	}
+	return m.clipboard.copy(text)
+}
+
+// selectedText returns the active selection's text and whether it is in
+// the agent input rather than the conversation.
+func (m *Model) selectedText() (text string, inInput bool) {
 	switch {
 	case m.agentInput.HasSelection():
-		text = m.agentInput.SelectedText()
+		return m.agentInput.SelectedText(), true
 	case m.convSel != nil && !m.convSel.empty():
-		text = m.selectedConvText()
+		return m.selectedConvText(), false
 	}
-	if text == "" {
-		return nil
+	return "", false
+}
+
+// quoteSelection seeds the agent input with the active selection quoted as
+// context for a question and focuses the input. A selection in the input is
+// quoted in place; a conversation selection is appended, and when it covers
+// hashline-tagged file contents the tags become a path and line range.
+// It returns false when nothing is selected.
+func (m *Model) quoteSelection() bool {
+	text, inInput := m.selectedText()
+	if strings.TrimSpace(text) == "" {
+		return false
+	}
+	if inInput {
+		m.agentInput.InsertPaste(quoteForPrompt(text, ""))
+		return true
+	}
+
+	code, start, end := stripHashlineTags(text)
+	loc := ""
+	if path := m.selectedConvFile(); path != "" && start > 0 {
+		loc = fmt.Sprintf("%s:%d-%d", path, start, end)
+	}
+	quote := quoteForPrompt(code, loc)
+	if existing := strings.TrimRight(m.agentInput.Value(), "\n"); existing != "" {
+		quote = existing + "\n\n" + quote
+	}
+	m.convSel = nil
+	m.agentInput.Reset()
+	m.agentInput.InsertText(quote)
+	m.agentInput.Focus()
+	return true
+}
+
+// quoteForPrompt wraps text in a fence under a "Regarding this code" lead,
+// naming loc when known, and leaves a blank line for the question.
+func quoteForPrompt(text, loc string) string {
+	lead := "Regarding this code:"
+	if loc != "" {
+		lead = "Regarding this code (" + loc + "):"
+	}
+	return lead + "\n```\n" + strings.TrimRight(text, "\n") + "\n```\n\n"
+}
+
+// stripHashlineTags removes "line:hash|" prefixes from text and returns the
+// first and last tagged line numbers, or 0, 0 if no line was tagged.
+func stripHashlineTags(text string) (code string, start, end int) {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		tag, rest, ok := strings.Cut(line, "|")
+		if !ok {
+			continue
+		}
+		a, err := hashline.ParseAnchor(strings.TrimSpace(tag))
+		if err != nil {
+			continue
+		}
+		lines[i] = rest
+		if start == 0 {
+			start = a.Num
+		}
+		end = a.Num
+	}
+	return strings.Join(lines, "\n"), start, end
+}
+
+// selectedConvFile returns the file of the first conversation entry under
+// the selection that points at one.
+func (m *Model) selectedConvFile() string {
+	m.wrappedConvLines()
+	s, e := m.convSel.ordered()
+	for i := max(s.line, 0); i <= e.line && i < len(m.convLineSource); i++ {
+		if idx := m.convLineSource[i]; idx >= 0 && idx < len(m.convEntries) && m.convEntries[idx].filePath != "" {
+			return m.convEntries[idx].filePath
+		}
 	}
-	return tea.SetClipboard(text) // OSC 52 — works through SSH/tmux
+	return ""
 }
 
 // selectedConvText returns the plain text of the conversation selection.
@@ -75,8 +159,8 @@ func (m Model) applyClickableStyle(line string, lineIdx int, _ lipgloss.Style) s
 		return line
 	}
 	entry := m.convEntries[entryIdx]
-	// Undo and tool results are pre-styled with clickable elements.
-	if entry.kind == entryUndo || entry.kind == entryToolResult {
+	// Undo, tool results and file links are pre-styled with clickable elements.
+	if entry.kind == entryUndo || entry.kind == entryToolResult || entry.filePath != "" {
 		return line
 	}
 	// Plain text with file path reference — highlight the whole line.
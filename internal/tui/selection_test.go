@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/xonecas/symb/internal/config"
+	"github.com/xonecas/symb/internal/hashline"
+	"github.com/xonecas/symb/internal/provider"
+)
+
+// TestQuoteSelection verifies ctrl+q quotes a conversation selection over
+// file contents into the input with its path and line range, and quotes an
+// input selection in place.
+func TestQuoteSelection(t *testing.T) {
+	initTheme("vulcan")
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+	press := func() {
+		updated, _ := m.Update(tea.KeyPressMsg{Code: 'q', Mod: tea.ModCtrl})
+		m = updated.(Model)
+	}
+
+	tagged := strings.Split(hashline.FormatTagged(hashline.TagLines("package main\n\nfunc main() {}\n", 1)), "\n")
+	for _, line := range tagged {
+		m.convEntries = append(m.convEntries, convEntry{display: line, filePath: "main.go", line: 1})
+	}
+	m.frameLines = nil
+	first := -1
+	lines := m.wrappedConvLines()
+	for i, l := range lines {
+		if strings.Contains(l, "|package main") {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		t.Fatal("tagged lines not rendered")
+	}
+
+	m.agentInput.SetValue("draft")
+	m.convSel = &convSelection{anchor: convPos{first, 0}, active: convPos{first + 2, 1 << 10}}
+	press()
+	want := "draft\n\nRegarding this code (main.go:1-3):\n```\npackage main\n\nfunc main() {}\n```\n\n"
+	if got := m.agentInput.Value(); got != want {
+		t.Fatalf("input = %q, want %q", got, want)
+	}
+	if m.convSel != nil {
+		t.Error("conversation selection not cleared")
+	}
+
+	m.agentInput.SetValue("x := 1")
+	m.agentInput.GotoLine(1)
+	updated, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEnd, Mod: tea.ModShift})
+	m = updated.(Model)
+	press()
+	if got := m.agentInput.Value(); got != "Regarding this code:\n```\nx := 1\n```\n\n" {
+		t.Errorf("input selection quoted as %q", got)
+	}
+}
@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/xonecas/symb/internal/highlight"
+)
+
+// toolViewSyntax picks how the tool view shows content: JSON pretty-printed
+// and highlighted, diffs as diffs, and Grep matches in the language of the
+// file they hit. Anything else is shown as is, with no language.
+func toolViewSyntax(content string) (lang, shown string) {
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var buf bytes.Buffer
+		if json.Indent(&buf, []byte(trimmed), "", "  ") == nil {
+			return "json", buf.String()
+		}
+	}
+	if isDiff(content) {
+		return "diff", content
+	}
+	if strings.HasPrefix(content, "Found ") {
+		if sm := grepHitRe.FindStringSubmatch(content); sm != nil {
+			if lang := highlight.DetectLanguage(sm[1]); lang != "text" {
+				return lang, content
+			}
+		}
+	}
+	return "", content
+}
+
+// isDiff reports whether content holds a unified diff: a "diff --git"
+// header, or a "---"/"+++" file pair.
+func isDiff(content string) bool {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "diff --git ") {
+			return true
+		}
+		if strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ") {
+			return true
+		}
+	}
+	return false
+}
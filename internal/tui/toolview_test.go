@@ -1,9 +1,16 @@
 package tui
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/xonecas/symb/internal/config"
 	"github.com/xonecas/symb/internal/provider"
 )
 
@@ -11,7 +18,7 @@ import (
 // on a tool result entry opens the tool view modal.
 func TestToolViewModalOpensOnViewClick(t *testing.T) {
 	initTheme("vulcan")
-	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, "vulcan")
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
 	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
 	m = updated.(Model)
 
@@ -77,3 +84,379 @@ func TestToolViewModalOpensOnViewClick(t *testing.T) {
 		t.Fatal("toolViewModal is nil after dispatching openToolViewMsg")
 	}
 }
+
+// TestToolResultErrorStyle verifies that failed tool calls render their
+// result in the error style, live and when rebuilt from history.
+func TestToolResultErrorStyle(t *testing.T) {
+	initTheme("vulcan")
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+
+	errArrow := m.styles.Error.Render("←")
+	m.applyToolResultMsg(llmToolResultMsg{toolCallID: "1", content: "Error: connection refused", isError: true})
+	m.applyToolResultMsg(llmToolResultMsg{toolCallID: "2", content: "Read a.go"})
+	failed, ok := m.convEntries[len(m.convEntries)-2], m.convEntries[len(m.convEntries)-1]
+	if !failed.isError || !strings.Contains(failed.display, errArrow) {
+		t.Errorf("failed result not styled as an error: %q", failed.display)
+	}
+	if ok.isError || strings.Contains(ok.display, errArrow) {
+		t.Errorf("successful result styled as an error: %q", ok.display)
+	}
+
+	history, _ := historyConvEntries([]provider.Message{
+		{Role: "tool", ToolCallID: "1", Content: "Error: connection refused", IsError: true},
+	}, m.styles)
+	if len(history) != 1 || !history[0].isError || !strings.Contains(history[0].display, errArrow) {
+		t.Errorf("history entry not styled as an error: %+v", history)
+	}
+}
+
+// TestToolResultLocation verifies that Read, Edit and Grep results carry the
+// file and line they point at, and that the tool view scrolls to that row.
+func TestToolResultLocation(t *testing.T) {
+	grep := "Found 2 match(es):\n\ninternal/a.go:12:foo\ninternal/b.go:3:foo\n"
+	edit := "Edited a.go (80 lines, +1/-1, showing 20–60):\n\n20:ab|x\n40:cd|y\n41:ef|z"
+	tests := []struct {
+		name     string
+		call     provider.ToolCall
+		content  string
+		wantFile string
+		wantLine int
+		wantRow  int
+	}{
+		{"grep", provider.ToolCall{Name: "Grep"}, grep, "internal/a.go", 12, 2},
+		{"read", provider.ToolCall{Name: "Read"}, "Read a.go (lines 5-6 of 9)\n\n5:ab|x\n6:cd|y", "a.go", 5, 2},
+		{"edit", provider.ToolCall{Name: "Edit", Arguments: json.RawMessage(`{"operation":"replace","start":"40:cd","end":"40:cd"}`)}, edit, "a.go", 40, 3},
+		{"insert", provider.ToolCall{Name: "Edit", Arguments: json.RawMessage(`{"operation":"insert","after":"40:cd"}`)}, edit, "a.go", 41, 4},
+		{"shell", provider.ToolCall{Name: "Shell"}, "a.go:1:x", "", 0, -1},
+		{"read many", provider.ToolCall{Name: "Read"}, "Read 2 files:\n- a.go (1 lines)\n- b.go (1 lines)\n\n==> a.go <==\nRead a.go (1 lines):\n\n1:ab|x", "", 0, -1},
+	}
+	for _, tt := range tests {
+		file, line := toolResultLocation(tt.call, tt.content)
+		if file != tt.wantFile || line != tt.wantLine {
+			t.Errorf("%s: location = %s:%d, want %s:%d", tt.name, file, line, tt.wantFile, tt.wantLine)
+		}
+		if row := locationRow(tt.content, file, line); row != tt.wantRow {
+			t.Errorf("%s: row = %d, want %d", tt.name, row, tt.wantRow)
+		}
+	}
+}
+
+func TestEditDiffSummary(t *testing.T) {
+	tests := []struct{ content, want string }{
+		{"Edited a.go (12 lines, +3/-1):\n\n1:ab|x", "+3/-1 lines in a.go"},
+		{"Edited a.go (80 lines, +0/-2, showing 20–60):\n\n20:ab|x", "+0/-2 lines in a.go"},
+		{"Created a.go (4 lines):\n\n1:ab|x", "+4/-0 lines in a.go"},
+		{"Edited a.go (12 lines):\n\n1:ab|x", ""},
+	}
+	for _, tt := range tests {
+		if got := editDiffSummary(tt.content, "a.go"); got != tt.want {
+			t.Errorf("editDiffSummary(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+// TestAssistantFileLinks verifies that file:line references in assistant
+// prose become links only for existing files outside code fences, keeping
+// the line's highlighting, and that following one opens the file at that
+// line.
+func TestAssistantFileLinks(t *testing.T) {
+	initTheme("vulcan")
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n\nfunc A() {}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := Model{styles: DefaultStyles()}
+	content := "See `x` in a.go:3 for A.\n```\nx a.go:1\n```\nAlso missing.go:2.\nNot ../a.go:1."
+	m.convEntries = assistantEntries(content, m.styles)
+	m.linkFileRefs(m.convEntries)
+	for _, e := range m.convEntries {
+		if e.filePath != "" {
+			t.Fatalf("linked before the files were checked: %s", e.filePath)
+		}
+	}
+	refs, ok := m.tickFileRefs()().(fileRefsMsg)
+	if !ok {
+		t.Fatal("tickFileRefs found no files")
+	}
+	m.handleFileRefs(refs)
+
+	var links []string
+	for _, e := range m.convEntries {
+		if e.filePath != "" {
+			links = append(links, fmt.Sprintf("%s:%d", e.filePath, e.line))
+		}
+	}
+	if len(links) != 1 || links[0] != "a.go:3" {
+		t.Fatalf("links = %v, want [a.go:3]", links)
+	}
+	linked := m.convEntries[0].display
+	if ansi.Strip(linked) != "See `x` in a.go:3 for A." || !strings.Contains(linked, m.styles.Clickable.Render("a.go:3")) {
+		t.Errorf("linked line = %q", linked)
+	}
+	if before := assistantEntries(content, m.styles)[0].display; !strings.HasPrefix(linked, before[:strings.Index(before, " in ")]) {
+		t.Errorf("linked line lost its markdown highlighting: %q", linked)
+	}
+
+	// Known files link at once, with nothing left to check.
+	again := assistantEntries("a.go:1", m.styles)
+	m.linkFileRefs(again)
+	if again[0].filePath != "a.go" || m.tickFileRefs() != nil {
+		t.Errorf("cached ref: filePath = %q", again[0].filePath)
+	}
+
+	msg, ok := openFileCmd("a.go", 3)().(openToolViewMsg)
+	if !ok {
+		t.Fatal("openFileCmd did not return openToolViewMsg")
+	}
+	if msg.title != "a.go:3" || locationRow(msg.content, msg.filePath, msg.line) != 2 {
+		t.Errorf("title = %q, row = %d", msg.title, locationRow(msg.content, msg.filePath, msg.line))
+	}
+}
+
+// TestFormatToolCall verifies the compact per-tool call summaries and the
+// truncating generic fallback.
+func TestFormatToolCall(t *testing.T) {
+	tests := []struct {
+		name, args, want string
+	}{
+		{"Edit", `{"file":"a.go","operation":"replace","start":"12:ab","end":"14:cd","content":"x\ny\n"}`, "Edit(a.go:12 (replace 3 lines with 2))"},
+		{"Edit", `{"file":"a.go","operation":"insert","after":"40:cd","content":"x"}`, "Edit(a.go:41 (insert 1 line))"},
+		{"Edit", `{"file":"a.go","operation":"delete","start":"5:ab","end":"5:ab"}`, "Edit(a.go:5 (delete 1 line))"},
+		{"Edit", `{"file":"new.go","operation":"create","content":"package a\n\nfunc A() {}\n"}`, "Edit(new.go (create, 3 lines))"},
+		{"Edit", `{"file":"a.go","operation":"replace","start":"bad"}`, "Edit(file=a.go, operation=replace, start=bad)"},
+		{"Read", `{"file":"a.go","start":5,"end":9}`, "Read(a.go:5-9)"},
+		{"Read", `{"file":"a.go"}`, "Read(a.go)"},
+		{"Shell", `{"command":"go test ./...\ngo vet ./...","description":"check"}`, "Shell($ go test ./...… (+1 lines))"},
+		{"web_search_exa", `{"query":"bubbletea v2 paste","numResults":5}`, `web_search_exa("bubbletea v2 paste")`},
+		{"Grep", `{"pattern":"` + strings.Repeat("x", 50) + `"}`, "Grep(pattern=" + strings.Repeat("x", 40) + "…)"},
+		{"Grep", `not json`, "Grep(...)"},
+	}
+	for _, tt := range tests {
+		got := formatToolCall(provider.ToolCall{Name: tt.name, Arguments: json.RawMessage(tt.args)})
+		if got != tt.want {
+			t.Errorf("formatToolCall(%s %s) = %q, want %q", tt.name, tt.args, got, tt.want)
+		}
+	}
+}
+
+// TestOpenFileContext verifies that with open_file_context on, the next
+// message carries a bounded window of the file last open in the viewer
+// around the visible lines, and only that message does.
+func TestOpenFileContext(t *testing.T) {
+	initTheme("vulcan")
+	dir := t.TempDir()
+	t.Chdir(dir)
+	var src strings.Builder
+	for i := 1; i <= 300; i++ {
+		fmt.Fprintf(&src, "line %d\n", i)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte(src.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ui := config.UIConfig{OpenFileContext: true, OpenFileContextLines: 100}
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, ui, config.LimitsConfig{}, config.SessionConfig{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+
+	updated, _ = m.Update(openFileCmd("a.go", 150)())
+	m = updated.(Model)
+	m.View() // lays out the viewer, recording the visible lines
+	updated, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	m = updated.(Model)
+
+	send := func(text string) llmUserMsg {
+		m.agentInput.SetValue(text)
+		updated, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+		m = updated.(Model)
+		msgs := []tea.Msg{cmd()}
+		if batch, ok := msgs[0].(tea.BatchMsg); ok {
+			msgs = msgs[:0]
+			for _, c := range batch {
+				if c != nil {
+					msgs = append(msgs, c())
+				}
+			}
+		}
+		for _, msg := range msgs {
+			if msg, ok := msg.(llmUserMsg); ok {
+				return msg
+			}
+		}
+		t.Fatal("no llmUserMsg sent")
+		return llmUserMsg{}
+	}
+
+	msg := send("fix this")
+	if msg.display != "fix this" {
+		t.Errorf("display = %q", msg.display)
+	}
+	header, body, _ := strings.Cut(strings.TrimPrefix(msg.content, "fix this\n\n"), "\n")
+	lines := strings.Split(strings.TrimRight(body, "\n"), "\n")
+	if !strings.HasPrefix(header, "[Open in the viewer: a.go, lines ") || len(lines) != 100 {
+		t.Fatalf("context header %q with %d lines", header, len(lines))
+	}
+	if !strings.Contains(body, "|line 150\n") || !strings.Contains(body, "|line 170\n") {
+		t.Errorf("context does not cover the visible lines: %s", header)
+	}
+	if msg.note == "" {
+		t.Error("no note about the attached context")
+	}
+
+	m.turnPending = false
+	if msg := send("again"); msg.content != "again" {
+		t.Errorf("context sent twice: %q", msg.content)
+	}
+}
+
+// TestFilesChangedReloadsViewer verifies an external change to the file in
+// the viewer reloads it in place, and changes to other files are ignored.
+func TestFilesChangedReloadsViewer(t *testing.T) {
+	initTheme("vulcan")
+	dir := t.TempDir()
+	t.Chdir(dir)
+	path := filepath.Join(dir, "a.go")
+	if err := os.WriteFile(path, []byte("package a\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+	updated, _ = m.Update(openFileCmd("a.go", 1)())
+	m = updated.(Model)
+	m.View()
+
+	if err := os.WriteFile(path, []byte("package b\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, cmd := m.Update(FilesChangedMsg{Paths: []string{filepath.Join(dir, "other.go")}}); cmd != nil {
+		t.Error("change to another file reloaded the viewer")
+	}
+	_, cmd := m.Update(FilesChangedMsg{Paths: []string{path}})
+	if cmd == nil {
+		t.Fatal("viewer not reloaded")
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if m.toolViewModal == nil || !strings.Contains(m.viewedFile.content, "|package b") {
+		t.Errorf("viewer content = %q", m.viewedFile.content)
+	}
+}
+
+// TestBuildDiagnostics verifies Build diagnostics are split out of the
+// result body like LSP diagnostics, and those naming a file in the working
+// directory open it at the line when clicked.
+func TestBuildDiagnostics(t *testing.T) {
+	initTheme("vulcan")
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n\nfunc f() { x }\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+
+	content := "FAIL (exit 1): go build ./...\n2 error(s), 0 warning(s)\n\nOutput (last 1 of 1 lines):\na.go:3:12: undefined: x\n\n" +
+		"Build diagnostics:\nERROR a.go:3:12: undefined: x\nERROR gone.go:1:1: missing"
+	if body := toolResultBody(content); strings.Contains(strings.Join(body, "\n"), "ERROR") {
+		t.Errorf("body = %q, want the output without diagnostics", body)
+	}
+	m.applyToolResultMsg(llmToolResultMsg{toolCallID: "1", content: content, isError: true})
+	diags := m.convEntries[len(m.convEntries)-2:]
+	if diags[0].kind != entryToolDiag || diags[0].filePath != "a.go" || diags[0].line != 3 {
+		t.Errorf("diagnostic entry = %+v, want a link to a.go:3", diags[0])
+	}
+	if diags[1].filePath != "" {
+		t.Errorf("missing file linked: %+v", diags[1])
+	}
+}
+
+// TestToolViewSyntax verifies the tool view pretty-prints JSON, highlights
+// diffs and Grep matches, and leaves other results plain.
+func TestToolViewSyntax(t *testing.T) {
+	tests := []struct {
+		name, content, lang, shown string
+	}{
+		{"json", `{"a":1,"b":[true]}`, "json", "{\n  \"a\": 1,\n  \"b\": [\n    true\n  ]\n}"},
+		{"diff", "diff --git a/x.go b/x.go\n--- a/x.go\n+++ b/x.go\n@@ -1 +1 @@\n-a\n+b", "diff", ""},
+		{"grep", "Found 1 match(es):\n\nmain.go:3:func main() {}\n", "go", ""},
+		{"grep files", "Found 1 file(s):\n\nmain.go\n", "", ""},
+		{"read", "Read a.go (1 lines):\n\n1:ab|package a", "", ""},
+		{"broken json", `{"a":`, "", ""},
+	}
+	for _, tt := range tests {
+		lang, shown := toolViewSyntax(tt.content)
+		if tt.shown == "" {
+			tt.shown = tt.content
+		}
+		if lang != tt.lang || shown != tt.shown {
+			t.Errorf("%s: got %q, %q; want %q, %q", tt.name, lang, shown, tt.lang, tt.shown)
+		}
+	}
+
+	initTheme("vulcan")
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	updated, _ := m.Update(openToolViewMsg{title: "WebFetch", content: `{"status":"ok"}`})
+	m = updated.(Model)
+	view := m.toolViewModal.View(120, 40)
+	if !strings.Contains(ansi.Strip(view), `"status": "ok"`) {
+		t.Errorf("view does not show pretty-printed JSON:\n%s", ansi.Strip(view))
+	}
+	if strings.Contains(view, `"status": "ok"`) {
+		t.Error("JSON is not highlighted")
+	}
+
+	// Results tied to a file are shown as they are, so the viewed content
+	// and the line rows match the text in the viewer.
+	content := `{"a":1}`
+	updated, _ = m.Update(openToolViewMsg{title: "a.json", content: content, filePath: "a.json", line: 1})
+	m = updated.(Model)
+	if m.viewedFile.content != content {
+		t.Errorf("viewed content = %q, want %q", m.viewedFile.content, content)
+	}
+	if !strings.Contains(ansi.Strip(m.toolViewModal.View(120, 40)), `{"a":1}`) {
+		t.Error("file view was reformatted")
+	}
+}
+
+// TestFileViewDiagnostics verifies f8 and shift+f8 step through the LSP
+// diagnostics of the file shown in the viewer, wrapping around.
+func TestFileViewDiagnostics(t *testing.T) {
+	initTheme("vulcan")
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte(strings.Repeat("x\n", 200)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+
+	open := openFileCmd("a.go", 1)().(openToolViewMsg)
+	updated, _ = m.Update(open)
+	m = updated.(Model)
+	updated, _ = m.Update(LSPDiagnosticsMsg{FilePath: open.absPath, Lines: map[int]int{99: 1, 149: 2}, Messages: map[int]string{99: "bad", 149: "meh"}})
+	m = updated.(Model)
+	m.View()
+
+	shows := func(key tea.KeyPressMsg, row int) {
+		t.Helper()
+		updated, _ := m.Update(key)
+		m = updated.(Model)
+		m.View()
+		if first, last := m.toolViewModal.VisibleLines(); row < first || row > last {
+			t.Errorf("after %s, lines %d-%d visible; want %d", key, first, last, row)
+		}
+	}
+	f8 := tea.KeyPressMsg{Code: tea.KeyF8}
+	shiftF8 := tea.KeyPressMsg{Code: tea.KeyF8, Mod: tea.ModShift}
+	shows(f8, 99)
+	shows(f8, 149)
+	shows(f8, 99)
+	shows(shiftF8, 149)
+}
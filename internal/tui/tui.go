@@ -3,12 +3,15 @@ package tui
 import (
 	"context"
 	"image"
+	"os/exec"
 	"regexp"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/xonecas/symb/internal/config"
 	"github.com/xonecas/symb/internal/delta"
 	"github.com/xonecas/symb/internal/filesearch"
 	"github.com/xonecas/symb/internal/llm"
@@ -33,25 +36,27 @@ type layout struct {
 }
 
 const (
-	inputRows       = 3 // Agent input height
-	statusRows      = 2 // Status separator + status bar
-	maxDisplayTurns = 5 // Max conversation turns kept in memory; older turns live in DB
+	inputRows  = 3 // Minimum agent input height
+	statusRows = 2 // Status separator + status bar
+
+	storeFlushTimeout = 5 * time.Second // Max wait for queued saves on quit
 
 	roleAssistant = "assistant"
 )
 
-// generateLayout computes all regions from terminal size.
-func generateLayout(width, height int) layout {
+// generateLayout computes all regions from terminal size and the agent
+// input's current height.
+func generateLayout(width, height, inputH int) layout {
 	contentH := height - statusRows
 	if contentH < 1 {
 		contentH = 1
 	}
 
-	sepY := contentH - inputRows - 1
+	sepY := contentH - inputH - 1
 	if sepY < 0 {
 		sepY = 0
 	}
-	inputY := contentH - inputRows
+	inputY := contentH - inputH
 	if inputY < 0 {
 		inputY = 0
 	}
@@ -59,7 +64,7 @@ func generateLayout(width, height int) layout {
 	return layout{
 		conv:  image.Rect(0, 0, width, sepY),
 		sep:   image.Rect(0, sepY, width, sepY+1),
-		input: image.Rect(0, inputY, width, inputY+inputRows),
+		input: image.Rect(0, inputY, width, inputY+inputH),
 	}
 }
 
@@ -78,8 +83,8 @@ type entryKind int
 const (
 	entryText       entryKind = iota // Plain text (user, assistant, reasoning)
 	entryToolCall                    // Tool call arrow line (→ ToolName(...))
-	entryToolResult                  // Tool result summary (← summary [view])
-	entryToolDiag                    // Tool diagnostics — non-clickable
+	entryToolResult                  // Tool result summary (▸ ← summary [view]), expandable inline
+	entryToolDiag                    // Tool diagnostics — clickable when they name a file
 	entryUndo                        // Undo button — small clickable label
 	entrySeparator                   // Turn-end separator (timestamp + tokens)
 )
@@ -92,11 +97,29 @@ type convEntry struct {
 	full     string    // Full raw content (for editor viewing or undo separator restore)
 	line     int       // Target line (1-indexed) for cursor positioning on click (0 = none)
 	toolName string    // Tool name for view button context (Read, Edit, Shell, etc.)
+	expanded bool      // Tool result body shown inline below the summary
+	isError  bool      // Tool result from a failed call
+	ref      *fileRef  // "path:line" reference not yet linked (assistant prose)
 }
 
 // toolResultFileRe extracts the file path from "Read path ..." / "Edited path ..." / "Created path ..." headers.
 var toolResultFileRe = regexp.MustCompile(`^(?:Read|Edited|Created)\s+(\S+)`)
 
+// readManyRe matches the header of a multi-file Read, which names no single file.
+var readManyRe = regexp.MustCompile(`^Read \d+ files:\n`)
+
+// grepHitRe matches a "path:line:text" Grep match line.
+var grepHitRe = regexp.MustCompile(`(?m)^([^\s:]+):(\d+):`)
+
+// fileRefRe matches a "path/to/file.ext:line" reference in prose.
+var fileRefRe = regexp.MustCompile(`([\w./-]+\.\w+):(\d+)`)
+
+// createdLinesRe extracts the line count from a "Created path (N lines)" header.
+var createdLinesRe = regexp.MustCompile(`^Created \S+ \((\d+) lines\)`)
+
+// editedChangeRe extracts the "+N/-M" line count from an Edit result header.
+var editedChangeRe = regexp.MustCompile(`^Edited \S+ \(\d+ lines, \+(\d+)/-(\d+)`)
+
 // toolResultLineRe extracts the start line from "(lines N-M)" in tool result headers.
 var toolResultLineRe = regexp.MustCompile(`\(lines\s+(\d+)-\d+\)`)
 
@@ -172,6 +195,8 @@ type Model struct {
 	store            *store.Cache
 	storeQueue       chan storeBatch
 	storeQueueDone   <-chan struct{}
+	closeStoreQueue  func()        // closes storeQueue once, from any Model copy
+	storeFailures    *atomic.Int64 // message writes that failed; shown as "unsaved"
 	sessionID        string
 	initialSystemMsg *provider.Message
 
@@ -180,11 +205,14 @@ type Model struct {
 	convLineSource []int       // Maps each wrapped line -> index in convEntries (recomputed each frame)
 	scrollOffset   int         // Lines from bottom (0 = pinned)
 
+	// New output jumps to the bottom even when scrolled away (auto_scroll
+	// "always"); otherwise the view holds until the user returns.
+	autoScrollAlways bool
+
 	// Streaming state: raw text accumulated during streaming, styled at render time
-	streamingReasoning string // In-progress reasoning text
-	streamingContent   string // In-progress content text
-	streaming          bool   // Whether we're currently streaming
-	streamEntryStart   int    // Index in convEntries where streaming entries begin (-1 = none)
+	streamSegments   []streamSegment // Reasoning and content runs in arrival order
+	streaming        bool            // Whether we're currently streaming
+	streamEntryStart int             // Index in convEntries where streaming entries begin (-1 = none)
 
 	// Token usage tracking
 	turnInputTokens   int // accumulated input tokens for current turn
@@ -201,6 +229,9 @@ type Model struct {
 	turnBoundaries []turnBoundary
 	fileTracker    FileReadResetter // for clearing read-tracking on undo
 	tsIndex        *treesitter.Index
+	indexing       bool // background symbol index build in progress
+	indexedFiles   int  // files indexed so far (progress)
+	systemSaved    bool // initialSystemMsg persisted (deferred to the first user message)
 
 	// File finder modal
 	fileModal *modal.Model
@@ -209,22 +240,84 @@ type Model struct {
 	keybindsModal *modal.Model
 	// Models modal
 	modelsModal *modal.Model
+	// Prompt template picker, opened by typing "/prompt "
+	promptModal *modal.Model
 	// Tool viewer modal
 	toolViewModal *modal.ToolView
-	searcher      *filesearch.Searcher
+	// File shown in the tool viewer, sent with the next message when
+	// openFileContext is on.
+	viewedFile       viewedFile
+	diagnostics      map[string]LSPDiagnosticsMsg // latest LSP diagnostics by absolute path
+	fileRefs         map[string]bool              // "path:line" reference paths checked so far: true if the file exists
+	fileRefsQueue    []string                     // reference paths to check on the next tick
+	openFileContext  bool
+	openFileMaxLines int
+	// Scratchpad viewer modal (live-updated on TodoWrite)
+	scratchpadModal *modal.ToolView
+	searcher        *filesearch.Searcher
 
 	// Provider switching
 	registry         *provider.Registry
 	providerOpts     provider.Options
+	profiles         map[string]config.ProfileConfig // named provider/model/options presets for /profile
+	prompts          map[string]string               // named prompt templates for /prompt
 	currentModelName string
 	cachedModels     []provider.TaggedModel // cached across all providers
 	sharedProvider   *atomic.Pointer[provider.Provider]
 	// Pending tool calls: maps tool call ID → arguments for line extraction
 	pendingToolCalls map[string]provider.ToolCall
+	// Latest diagnostic lines per file edited this turn (for the rollup).
+	turnDiags map[string][]string
+	// Warnings (not just errors) mark the rollup as failing.
+	diagBlockWarnings bool
+
+	maxDisplayTurns int              // turns kept in convEntries; older turns live in DB (<0 = keep all)
+	olderBefore     int64            // DB id of the oldest displayed turn when older turns were trimmed (0 = none)
+	historyTurns    []int            // convIdx of each resumed user message, for restoring turn boundaries
+	lastSaved       provider.Message // last message queued for the store, to patch an interrupted turn
+	loadingOlder    bool             // an older-turns load is in flight
+	pageOverlap     int              // lines carried over when paging the conversation
+	wheelStep       float64          // lines per wheel event in the conversation
+	frameInterval   time.Duration    // render tick: streaming redraw and spinner
+	reducedMotion   bool             // no idle spinner, slow busy spinner
+	streamFlush     time.Duration    // minimum time between streaming redraws
+	streamFlushedAt time.Time        // last streaming redraw
+	wheelRem        float64          // fractional wheel lines not yet scrolled
+	wheelDir        int              // direction of the last wheel scroll; a change drops wheelRem
+	inputMaxRows    int              // tallest the agent input grows
+	submitKey       string           // key that sends the input; enter otherwise adds a newline
+	statusLeft      []string         // status-bar segments on the left, in order
+	statusRight     []string         // status-bar segments on the right, in order
+	inputHistory    []string         // past submissions, oldest first
+	historyPos      int              // index of the recalled entry in inputHistory (-1 = none)
+	draftSeen       string           // input as of the last tick
+	draftSeenAt     time.Time        // when draftSeen last changed
+	draftSaved      string           // input last saved as the session draft
+
+	// Session checkpoints: plan, token totals and turn boundaries.
+	checkpointTurns     int           // finished turns between checkpoints (0 = off)
+	checkpointIdle      time.Duration // idle time before a checkpoint (0 = off)
+	checkpointDirtyAt   time.Time     // when unsaved state last changed (zero = saved)
+	checkpointTurnsDone int           // turns finished since the last checkpoint
+
+	// Recording and playback of turns (see replay.go).
+	recorder    *recorder         // nil = not recording
+	replay      [][]recordedEvent // batches to play back; nil = live session
+	replaySpeed float64           // playback pace relative to the recording
 
 	// Conversation selection
 	convSel      *convSelection
 	convDragging bool
+	mouseEnabled bool // false = no mouse tracking; terminal handles selection/scroll
+	clipboard    clipboard
+
+	// Tool approval: calls to these tools wait on confirmModal.
+	confirmTools []string
+	confirmModal *toolConfirm
+
+	limits    config.LimitsConfig // turn time and session token caps (0 = off)
+	turnStart time.Time
+	turnEnd   time.Time // zero while the turn runs; freezes the turn readout after
 
 	// Frame loop
 	streamDirty  bool     // New streaming content arrived since last rebuild
@@ -251,7 +344,8 @@ type Model struct {
 // New creates a new TUI model.
 // If resumeHistory is non-nil, the session is being resumed and messages are
 // loaded from the database instead of creating a fresh system prompt.
-func New(prov provider.Provider, sharedProvider *atomic.Pointer[provider.Provider], proxy *mcp.Proxy, tools []mcp.Tool, modelID string, db *store.Cache, sessionID string, idx *treesitter.Index, dt *delta.Tracker, ft FileReadResetter, providerConfigName string, pad llm.ScratchpadReader, resumeHistory []provider.Message, registry *provider.Registry, providerOpts provider.Options, syntaxTheme string) Model {
+func New(prov provider.Provider, sharedProvider *atomic.Pointer[provider.Provider], proxy *mcp.Proxy, tools []mcp.Tool, modelID string, db *store.Cache, sessionID string, idx *treesitter.Index, dt *delta.Tracker, ft FileReadResetter, providerConfigName string, pad llm.ScratchpadReader, resumeHistory []provider.Message, registry *provider.Registry, providerOpts provider.Options, profiles map[string]config.ProfileConfig, prompts map[string]string, ui config.UIConfig, limits config.LimitsConfig, session config.SessionConfig) Model {
+	syntaxTheme := ui.SyntaxThemeOrDefault()
 	initTheme(syntaxTheme)
 	sty := DefaultStyles()
 	cursorStyle := lipgloss.NewStyle().Foreground(ColorHighlight)
@@ -259,10 +353,14 @@ func New(prov provider.Provider, sharedProvider *atomic.Pointer[provider.Provide
 	selStyle := sty.Selection
 
 	ai := editor.New()
-	ai.Placeholder = "Ask anything... (CTRL+h for keybinds)"
-	ai.SubmitOnEnter = true
+	ai.Placeholder = ui.PlaceholderOrDefault()
+	ai.SubmitOnEnter = ui.SubmitKeyOrDefault() == "enter"
 	ai.Language = "markdown"
 	ai.SyntaxTheme = syntaxTheme
+	ai.NoHighlight = !ui.HighlightOrDefault()
+	ai.MaxHighlightLines = ui.HighlightMaxLinesOrDefault()
+	ai.PageOverlap = ui.PageOverlapOrDefault()
+	ai.WheelStep = ui.InputScrollLinesOrDefault()
 	ai.CursorStyle = cursorStyle
 	ai.SelectionStyle = selStyle
 	ai.PlaceholderSty = lipgloss.NewStyle().Foreground(ColorDim).Background(ColorBg)
@@ -272,37 +370,44 @@ func New(prov provider.Provider, sharedProvider *atomic.Pointer[provider.Provide
 	ch := make(chan tea.Msg, 500)
 	var storeQueue chan storeBatch
 	var storeQueueDone <-chan struct{}
+	closeStoreQueue := func() {}
+	storeFailures := &atomic.Int64{}
 	if db != nil {
 		storeQueue = make(chan storeBatch, 256)
-		storeQueueDone = startStoreWorker(db, storeQueue)
+		storeQueueDone = startStoreWorker(db, storeQueue, storeFailures)
+		closeStoreQueue = sync.OnceFunc(func() { close(storeQueue) })
 	}
 	ctx, cancel := context.WithCancel(context.Background())
 
 	var entries []convEntry
+	var historyTurns []int
 	var initialSystemMsg *provider.Message
 	if resumeHistory != nil {
-		entries = historyConvEntries(resumeHistory, sty)
+		entries, historyTurns = historyConvEntries(resumeHistory, sty)
 	} else {
 		systemPrompt := llm.BuildSystemPrompt(modelID, idx)
 		systemMsg := provider.Message{Role: "system", Content: systemPrompt, CreatedAt: time.Now()}
 		initialSystemMsg = &systemMsg
 	}
 
-	return Model{
+	m := Model{
 		agentInput: ai,
 		styles:     sty,
 
-		provider:    prov,
-		mcpProxy:    proxy,
-		mcpTools:    tools,
-		convEntries: entries,
-		updateChan:  ch,
-		ctx:         ctx,
-		cancel:      cancel,
+		provider:     prov,
+		mcpProxy:     proxy,
+		mcpTools:     tools,
+		convEntries:  entries,
+		historyTurns: historyTurns,
+		updateChan:   ch,
+		ctx:          ctx,
+		cancel:       cancel,
 
 		store:            db,
 		storeQueue:       storeQueue,
 		storeQueueDone:   storeQueueDone,
+		closeStoreQueue:  closeStoreQueue,
+		storeFailures:    storeFailures,
 		sessionID:        sessionID,
 		initialSystemMsg: initialSystemMsg,
 
@@ -310,17 +415,43 @@ func New(prov provider.Provider, sharedProvider *atomic.Pointer[provider.Provide
 		deltaTracker: dt,
 		fileTracker:  ft,
 		tsIndex:      idx,
+		indexing:     idx != nil && !idx.Ready(),
 
 		searcher:         newSearcherOrNil("."),
 		registry:         registry,
 		providerOpts:     providerOpts,
+		profiles:         profiles,
+		prompts:          prompts,
 		currentModelName: modelID,
 		sharedProvider:   sharedProvider,
 
-		streamEntryStart: -1,
+		streamEntryStart:  -1,
+		mouseEnabled:      ui.MouseOrDefault(),
+		confirmTools:      ui.ConfirmTools,
+		diagBlockWarnings: ui.DiagnosticsBlockOrDefault() == "warning",
+		maxDisplayTurns:   ui.MaxDisplayTurnsOrDefault(),
+		pageOverlap:       ui.PageOverlapOrDefault(),
+		wheelStep:         ui.ConvScrollLinesOrDefault(),
+		autoScrollAlways:  ui.AutoScrollOrDefault() == "always",
+		frameInterval:     time.Duration(ui.FrameMSOrDefault()) * time.Millisecond,
+		reducedMotion:     ui.ReducedMotion,
+		streamFlush:       time.Duration(ui.StreamFlushMS) * time.Millisecond,
+		inputMaxRows:      ui.InputMaxRowsOrDefault(),
+		submitKey:         ui.SubmitKeyOrDefault(),
+		statusLeft:        ui.StatusLeftOrDefault(),
+		statusRight:       ui.StatusRightOrDefault(),
+		openFileContext:   ui.OpenFileContext,
+		openFileMaxLines:  ui.OpenFileContextLinesOrDefault(),
+		historyPos:        -1,
+		clipboard:         detectClipboard(ui.ClipboardOrDefault(), exec.LookPath),
+		limits:            limits,
+		checkpointTurns:   session.CheckpointTurnsOrDefault(),
+		checkpointIdle:    time.Duration(session.CheckpointIdleOrDefault()) * time.Second,
 
 		providerConfigName: providerConfigName,
 	}
+	m.linkFileRefs(m.convEntries)
+	return m
 }
 
 func newSearcherOrNil(root string) *filesearch.Searcher {
@@ -332,10 +463,8 @@ func newSearcherOrNil(root string) *filesearch.Searcher {
 }
 
 // Init starts the 60fps frame loop and periodic git branch polling.
+// The system message is persisted with the first user message, so its
+// project outline reflects the index built in the background meanwhile.
 func (m Model) Init() tea.Cmd {
-	cmds := []tea.Cmd{frameTick(), gitBranchCmd()}
-	if m.initialSystemMsg != nil {
-		cmds = append(cmds, m.saveMessagesCmd([]provider.Message{*m.initialSystemMsg}))
-	}
-	return tea.Batch(cmds...)
+	return tea.Batch(frameTick(m.frameInterval), gitBranchCmd(), m.preflightCmd(), m.loadInputHistoryCmd(), m.loadDraftCmd(), m.loadCheckpointCmd(), m.replayCmd(0))
 }
@@ -11,7 +11,17 @@ import (
 // ---------------------------------------------------------------------------
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	mdl, cmd := m.update(msg)
+	// Any message may have changed the input's content (typing, paste,
+	// completion, submit), so resize it to fit.
+	if next, ok := mdl.(Model); ok {
+		next.fitInput()
+		return next, cmd
+	}
+	return mdl, cmd
+}
 
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	m.frameLines = nil // invalidate per-frame wrap cache
 
 	if mdl, cmd, handled := m.handleModalMsg(msg); handled {
@@ -32,6 +42,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleModalMsg(msg tea.Msg) (tea.Model, tea.Cmd, bool) {
+	// Tool approval prompt takes priority: the turn is blocked on it.
+	if mdl, cmd, handled := m.updateConfirmModal(msg); handled {
+		return mdl, cmd, true
+	}
 	// Keybinds modal intercepts all input when open.
 	if mdl, cmd, handled := m.updateKeybindsModal(msg); handled {
 		return mdl, cmd, true
@@ -44,10 +58,18 @@ func (m Model) handleModalMsg(msg tea.Msg) (tea.Model, tea.Cmd, bool) {
 	if mdl, cmd, handled := m.updateModelsModal(msg); handled {
 		return mdl, cmd, true
 	}
+	// Prompt picker intercepts all input when open.
+	if mdl, cmd, handled := m.updatePromptModal(msg); handled {
+		return mdl, cmd, true
+	}
 	// Tool viewer modal intercepts all input when open.
 	if mdl, cmd, handled := m.updateToolViewModal(msg); handled {
 		return mdl, cmd, true
 	}
+	// Scratchpad modal intercepts all input when open.
+	if mdl, cmd, handled := m.updateScratchpadModal(msg); handled {
+		return mdl, cmd, true
+	}
 	return m, nil, false
 }
 
@@ -68,9 +90,9 @@ func (m Model) handleUIEvent(msg tea.Msg) (tea.Model, tea.Cmd, bool) {
 		}
 		return m, nil, false
 	case tickMsg:
-		m.tickStreaming()
+		m.tickStreaming(time.Time(msg))
 		m.tickSpinner(time.Time(msg))
-		return m, frameTick(), true
+		return m, tea.Batch(frameTick(m.frameInterval), m.tickDraft(time.Time(msg)), m.tickCheckpoint(time.Time(msg)), m.tickFileRefs()), true
 	}
 	return m, nil, false
 }
@@ -86,14 +108,26 @@ func (m Model) handleLLMEvent(msg tea.Msg) (tea.Model, tea.Cmd, bool) {
 	case userMsgSavedMsg:
 		mdl, cmd := m.handleUserMsgSaved(msg)
 		return mdl, cmd, true
+	case replayMsg:
+		mdl, cmd := m.handleReplay(msg)
+		return mdl, cmd, true
 	}
 	return m, nil, false
 }
 
 func (m Model) handleSystemEvent(msg tea.Msg) (tea.Model, tea.Cmd, bool) {
 	switch msg := msg.(type) {
+	case olderTurnsMsg:
+		return m.handleOlderTurns(msg), nil, true
 	case LSPDiagnosticsMsg:
 		return m.handleLSPDiag(msg), nil, true
+	case IndexProgressMsg:
+		m.indexedFiles = msg.Files
+		return m, nil, true
+	case IndexDoneMsg:
+		return m.handleIndexDone(msg), nil, true
+	case FilesChangedMsg:
+		return m, m.handleFilesChanged(msg), true
 	case UpdateToolsMsg:
 		m.mcpTools = msg.Tools
 		return m, nil, true
@@ -101,10 +135,27 @@ func (m Model) handleSystemEvent(msg tea.Msg) (tea.Model, tea.Cmd, bool) {
 		mdl, cmd := m.handleUndo()
 		return mdl, cmd, true
 	case openToolViewMsg:
-		m.openToolViewModal(msg.title, msg.content)
+		shown, lang := msg.content, ""
+		if msg.filePath == "" {
+			lang, shown = toolViewSyntax(msg.content)
+		}
+		m.openToolViewModal(msg.title, shown)
+		if lang != "" {
+			m.toolViewModal.SetSyntax(lang, syntaxThemeName)
+		}
+		m.viewedFile = viewedFile{path: msg.filePath, absPath: msg.absPath, content: shown}
+		if d, ok := m.diagnostics[msg.absPath]; ok {
+			m.toolViewModal.SetDiagnostics(m.viewedFile.diagnostics(d))
+		}
+		if row := locationRow(shown, msg.filePath, msg.line); row >= 0 {
+			m.toolViewModal.ScrollToLine(row)
+		}
 		return m, nil, true
 	case undoResultMsg:
 		return m.handleUndoResult(msg), nil, true
+	case fileRefsMsg:
+		m.handleFileRefs(msg)
+		return m, nil, true
 	case gitBranchMsg:
 		mdl, cmd := m.handleGitBranch(msg)
 		return mdl, cmd, true
@@ -112,6 +163,18 @@ func (m Model) handleSystemEvent(msg tea.Msg) (tea.Model, tea.Cmd, bool) {
 		return m.handleModelsFetched(msg), nil, true
 	case modelSwitchedMsg:
 		return m.handleModelSwitched(msg), nil, true
+	case draftLoadedMsg:
+		return m.handleDraftLoaded(msg), nil, true
+	case checkpointLoadedMsg:
+		return m.handleCheckpointLoaded(msg), nil, true
+	case inputHistoryMsg:
+		return m.handleInputHistory(msg), nil, true
+	case sessionForkedMsg:
+		return m.handleSessionForked(msg), nil, true
+	case fileAccessesMsg:
+		return m.handleFileAccesses(msg), nil, true
+	case providerCheckMsg:
+		return m.handleProviderCheck(msg), nil, true
 	}
 	return m, nil, false
 }
@@ -142,6 +205,5 @@ func (m *Model) insertPaste(text string) {
 	if text == "" {
 		return
 	}
-	m.agentInput.DeleteSelection()
-	m.agentInput.InsertText(text)
+	m.agentInput.InsertPaste(text)
 }
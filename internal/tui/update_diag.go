@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// recordTurnDiags remembers the latest diagnostics for a file edited this
+// turn. Each Edit result carries the file's full diagnostic set, so later
+// results replace earlier ones.
+func (m *Model) recordTurnDiags(filePath string, diagLines []string) {
+	if m.turnDiags == nil {
+		m.turnDiags = make(map[string][]string)
+	}
+	m.turnDiags[filePath] = diagLines
+}
+
+// appendDiagSummary adds a turn-level diagnostics rollup when the turn
+// edited more than one file. The [view] button lists every diagnostic.
+func (m *Model) appendDiagSummary() {
+	diags := m.turnDiags
+	m.turnDiags = nil
+	if len(diags) < 2 {
+		return
+	}
+	summary, detail, errs, warns := diagSummary(diags)
+	sty := m.styles.Dim
+	switch {
+	case errs > 0:
+		sty = m.styles.Error
+	case warns > 0 && m.diagBlockWarnings:
+		sty = m.styles.Warning
+	}
+	arrow := m.styles.ToolArrow.Render("◆") + m.styles.BgFill.Render("  ")
+	if detail != "" {
+		arrow = toolResultCaret(m.styles, false) + m.styles.BgFill.Render(" ") + arrow
+	}
+	display := arrow + sty.Render(summary)
+	if detail != "" {
+		display += m.styles.BgFill.Render("  ") + m.styles.Clickable.Render("view")
+	}
+	m.appendConv(convEntry{display: display, kind: entryToolResult, full: detail, toolName: "Diagnostics"})
+}
+
+// diagSummary totals ERROR/WARNING lines across files; INFO/HINT lines
+// count as notes. detail is the
+// summary followed by each affected file and its diagnostic lines, or ""
+// when the files are clean.
+func diagSummary(files map[string][]string) (summary, detail string, errs, warns int) {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+
+	var b strings.Builder
+	affected, notes := 0, 0
+	for _, p := range paths {
+		lines := files[p]
+		if len(lines) == 0 {
+			continue
+		}
+		affected++
+		b.WriteString(p + "\n")
+		for _, dl := range lines {
+			switch {
+			case strings.HasPrefix(dl, "ERROR "):
+				errs++
+			case strings.HasPrefix(dl, "WARNING "):
+				warns++
+			default:
+				notes++
+			}
+			b.WriteString("  " + dl + "\n")
+		}
+	}
+	if affected == 0 {
+		return fmt.Sprintf("no diagnostics across %d edited files", len(files)), "", 0, 0
+	}
+	summary = plural(errs, "error") + ", " + plural(warns, "warning")
+	if notes > 0 {
+		summary += ", " + plural(notes, "note")
+	}
+	summary += " across " + plural(affected, "file")
+	return summary, summary + "\n" + strings.TrimRight(b.String(), "\n"), errs, warns
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
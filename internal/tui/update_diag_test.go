@@ -0,0 +1,28 @@
+package tui
+
+import "testing"
+
+func TestDiagSummary(t *testing.T) {
+	files := map[string][]string{
+		"b.go": {"WARNING [3:1] unused parameter"},
+		"a.go": {"ERROR [1:5] undefined: x", "ERROR [9:2] missing return"},
+		"c.go": nil,
+		"d.go": {"HINT [2:1] could simplify"},
+	}
+	summary, detail, errs, warns := diagSummary(files)
+	if errs != 2 || warns != 1 {
+		t.Errorf("errs=%d warns=%d, want 2 and 1", errs, warns)
+	}
+	if want := "2 errors, 1 warning, 1 note across 3 files"; summary != want {
+		t.Errorf("summary = %q, want %q", summary, want)
+	}
+	want := summary + "\na.go\n  ERROR [1:5] undefined: x\n  ERROR [9:2] missing return\nb.go\n  WARNING [3:1] unused parameter\nd.go\n  HINT [2:1] could simplify"
+	if detail != want {
+		t.Errorf("detail = %q, want %q", detail, want)
+	}
+
+	summary, detail, _, _ = diagSummary(map[string][]string{"a.go": nil, "b.go": nil})
+	if summary != "no diagnostics across 2 edited files" || detail != "" {
+		t.Errorf("clean files: summary=%q detail=%q", summary, detail)
+	}
+}
@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/xonecas/symb/internal/store"
+)
+
+// fileAccessesMsg carries a session's file audit for /files.
+type fileAccessesMsg struct {
+	accesses []store.FileAccess
+	err      error
+}
+
+// fileAccessOps orders the operations in a /files line. The tools record
+// them as they run; see delta.Tracker.RecordAccess.
+var fileAccessOps = []string{"read", "edit", "create", "remove"}
+
+// displayPath shortens an absolute path under the working directory to a
+// relative one.
+func displayPath(path string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// isFilesCommand recognises "/files".
+func isFilesCommand(input string) bool {
+	return strings.TrimSpace(input) == "/files"
+}
+
+// handleFiles lists the files touched in this session. It needs the session
+// store.
+func (m *Model) handleFiles() (Model, tea.Cmd) {
+	if m.store == nil {
+		m.appendText("", m.styles.Error.Render("files: no session store available"), "")
+		return *m, nil
+	}
+	db, sessionID := m.store, m.sessionID
+	return *m, func() tea.Msg {
+		accesses, err := db.LoadFileAccesses(sessionID)
+		return fileAccessesMsg{accesses: accesses, err: err}
+	}
+}
+
+// handleFileAccesses renders the file audit: one clickable line per file,
+// in the order files were first touched, with per-operation counts and the
+// time of the last access.
+func (m Model) handleFileAccesses(msg fileAccessesMsg) Model {
+	if msg.err != nil {
+		m.appendText("", m.styles.Error.Render("files: "+msg.err.Error()), "")
+		return m
+	}
+	if len(msg.accesses) == 0 {
+		m.appendText("", m.styles.Dim.Render("no files read or changed in this session"), "")
+		return m
+	}
+
+	type fileSummary struct {
+		counts map[string]int
+		last   store.FileAccess
+	}
+	var order []string
+	files := make(map[string]*fileSummary)
+	for _, a := range msg.accesses {
+		f, ok := files[a.Path]
+		if !ok {
+			f = &fileSummary{counts: make(map[string]int)}
+			files[a.Path] = f
+			order = append(order, a.Path)
+		}
+		f.counts[a.Op]++
+		f.last = a
+	}
+
+	m.appendText("", m.styles.Dim.Render(fmt.Sprintf("files touched in this session (%s):", plural(len(order), "file"))))
+	for _, path := range order {
+		f := files[path]
+		var parts []string
+		for _, op := range fileAccessOps {
+			if n := f.counts[op]; n > 0 {
+				parts = append(parts, fmt.Sprintf("%s ×%d", op, n))
+			}
+		}
+		detail := fmt.Sprintf("  %s · last %s", strings.Join(parts, ", "), f.last.Created.Format("15:04"))
+		m.appendConv(convEntry{
+			display:  m.styles.BgFill.Render("  ") + m.styles.Clickable.Render(displayPath(path)) + m.styles.Dim.Render(detail),
+			kind:     entryText,
+			filePath: path,
+			line:     1,
+		})
+	}
+	m.appendText("")
+	m.scrollOffset = 0
+	return m
+}
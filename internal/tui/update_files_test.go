@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/xonecas/symb/internal/config"
+	"github.com/xonecas/symb/internal/provider"
+	"github.com/xonecas/symb/internal/store"
+)
+
+// TestFileAudit verifies that /files lists each recorded file once, linked,
+// relative to the working directory, with per-operation counts.
+func TestFileAudit(t *testing.T) {
+	initTheme("vulcan")
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	m := New(nil, nil, nil, nil, "test", db, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+
+	cwd, _ := os.Getwd()
+	a, b, c := filepath.Join(cwd, "a.go"), filepath.Join(cwd, "b.go"), filepath.Join(cwd, "c.go")
+	for _, r := range []struct{ path, op string }{
+		{a, "read"}, {a, "edit"}, {b, "read"}, {a, "edit"}, {c, "create"}, {c, "remove"},
+	} {
+		if err := db.RecordFileAccess("s", 1, r.path, r.op); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m.agentInput.SetValue("/files")
+	updated, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = updated.(Model)
+	var listed tea.Msg
+	if batch, ok := cmd().(tea.BatchMsg); ok {
+		for _, c := range batch {
+			if c == nil {
+				continue
+			}
+			if msg, ok := c().(fileAccessesMsg); ok {
+				listed = msg
+			}
+		}
+	}
+	if listed == nil {
+		t.Fatal("/files did not load the audit")
+	}
+	updated, _ = m.Update(listed)
+	m = updated.(Model)
+
+	var lines []string
+	for _, e := range m.convEntries {
+		if e.filePath != "" {
+			lines = append(lines, e.filePath+" "+stripANSI(e.display))
+		}
+	}
+	if len(lines) != 3 || !strings.Contains(lines[0], " a.go  read ×1, edit ×2") || !strings.HasPrefix(lines[1], b) || !strings.Contains(lines[2], "c.go  create ×1, remove ×1") {
+		t.Errorf("audit lines = %q", lines)
+	}
+}
@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/symb/internal/provider"
+	"github.com/xonecas/symb/internal/store"
+)
+
+// sessionForkedMsg reports the result of a /fork.
+type sessionForkedMsg struct {
+	sessionID string
+	turns     int                // turns kept in the fork
+	history   []provider.Message // set when the fork dropped later turns
+	err       error
+}
+
+// parseForkCommand recognises "/fork" and "/fork N" input. N is the number
+// of turns to keep; 0 keeps everything.
+func parseForkCommand(input string) (turns int, ok bool, err error) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 || fields[0] != "/fork" {
+		return 0, false, nil
+	}
+	switch len(fields) {
+	case 1:
+		return 0, true, nil
+	case 2:
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n < 1 {
+			return 0, true, fmt.Errorf("usage: /fork [turns]")
+		}
+		return n, true, nil
+	}
+	return 0, true, fmt.Errorf("usage: /fork [turns]")
+}
+
+// forkSessionCmd copies the current session (up to the first turns turns,
+// or all of it) into a new session.
+func (m *Model) forkSessionCmd(turns int) tea.Cmd {
+	db, srcID, transcript := m.store, m.sessionID, m.providerOpts.Transcript
+	return func() tea.Msg {
+		ids, err := db.UserMessageIDs(srcID)
+		if err != nil {
+			return sessionForkedMsg{err: err}
+		}
+		var upTo int64
+		truncated := turns > 0 && turns < len(ids)
+		if truncated {
+			upTo = ids[turns] - 1
+		} else {
+			turns = len(ids)
+		}
+		newID, err := db.ForkSession(srcID, upTo)
+		if err != nil {
+			return sessionForkedMsg{err: err}
+		}
+		if t, ok := transcript.(provider.SessionTranscript); ok {
+			if err := t.SetSession(newID); err != nil {
+				log.Warn().Err(err).Str("session", newID).Msg("fork: failed to reopen transcript")
+			}
+		}
+		msg := sessionForkedMsg{sessionID: newID, turns: turns}
+		if truncated {
+			stored, err := db.LoadMessages(newID)
+			if err != nil {
+				return sessionForkedMsg{err: err}
+			}
+			msg.history = store.ToProviderMessages(stored)
+		}
+		return msg
+	}
+}
+
+// handleFork starts a /fork. Forking needs the session store.
+func (m *Model) handleFork(turns int) (Model, tea.Cmd) {
+	if m.store == nil {
+		m.appendText("", m.styles.Error.Render("fork: no session store available"), "")
+		return *m, nil
+	}
+	m.turnPending = true
+	return *m, m.forkSessionCmd(turns)
+}
+
+// handleSessionForked switches the TUI to the forked session.
+func (m Model) handleSessionForked(msg sessionForkedMsg) Model {
+	m.turnPending = false
+	if msg.err != nil {
+		m.appendText("", m.styles.Error.Render("fork failed: "+msg.err.Error()), "")
+		return m
+	}
+	m.sessionID = msg.sessionID
+	if m.deltaTracker != nil {
+		m.deltaTracker.SetSession(msg.sessionID)
+	}
+	if msg.history != nil {
+		m.convEntries, _ = historyConvEntries(msg.history, m.styles)
+		m.linkFileRefs(m.convEntries)
+	}
+	// Undo can't reach across the fork: deltas stay with the original.
+	// Trimmed turns have different IDs in the fork, so drop scrollback too.
+	m.turnBoundaries = nil
+	m.historyTurns = nil
+	m.olderBefore = 0
+	// The fork may drop turns, so the last call's context size no longer
+	// applies; the next call measures it again.
+	m.turnContextTokens = 0
+	m.appendText("", m.styles.Dim.Render(fmt.Sprintf("forked %d turns into session %s", msg.turns, msg.sessionID)), "")
+	m.scrollOffset = 0
+	return m
+}
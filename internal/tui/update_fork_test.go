@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xonecas/symb/internal/config"
+	"github.com/xonecas/symb/internal/provider"
+	"github.com/xonecas/symb/internal/store"
+)
+
+func TestParseForkCommand(t *testing.T) {
+	tests := []struct {
+		in      string
+		turns   int
+		ok      bool
+		wantErr bool
+	}{
+		{"/fork", 0, true, false},
+		{"  /fork 3 ", 3, true, false},
+		{"/fork 0", 0, true, true},
+		{"/fork x", 0, true, true},
+		{"/fork 1 2", 0, true, true},
+		{"/forkx", 0, false, false},
+		{"fork the repo", 0, false, false},
+	}
+	for _, tt := range tests {
+		turns, ok, err := parseForkCommand(tt.in)
+		if turns != tt.turns || ok != tt.ok || (err != nil) != tt.wantErr {
+			t.Errorf("parseForkCommand(%q) = %d, %v, %v", tt.in, turns, ok, err)
+		}
+	}
+}
+
+// fakeTranscript records the sessions a fork moves it to.
+type fakeTranscript struct {
+	sessions []string
+}
+
+func (f *fakeTranscript) Write(p []byte) (int, error) { return len(p), nil }
+
+func (f *fakeTranscript) SetSession(id string) error {
+	f.sessions = append(f.sessions, id)
+	return nil
+}
+
+// TestForkReopensTranscript verifies a fork moves the transcript to the new
+// session and forgets the old context size.
+func TestForkReopensTranscript(t *testing.T) {
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.CreateSession("s"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveMessages("s", []store.SessionMessage{{Role: "user", Content: "hi"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := &fakeTranscript{}
+	m := New(nil, nil, nil, nil, "test", db, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{Transcript: tr}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	msg, ok := m.forkSessionCmd(0)().(sessionForkedMsg)
+	if !ok || msg.err != nil {
+		t.Fatalf("fork = %#v", msg)
+	}
+	if len(tr.sessions) != 1 || tr.sessions[0] != msg.sessionID {
+		t.Errorf("transcript sessions = %q, want [%q]", tr.sessions, msg.sessionID)
+	}
+
+	m.turnContextTokens = 8_500
+	if m = m.handleSessionForked(msg); m.turnContextTokens != 0 || m.contextUtilization() != 0 {
+		t.Errorf("context tokens = %d after fork, want 0", m.turnContextTokens)
+	}
+}
@@ -1,6 +1,8 @@
 package tui
 
 import (
+	"fmt"
+
 	tea "charm.land/bubbletea/v2"
 	"github.com/rs/zerolog/log"
 )
@@ -15,16 +17,27 @@ func (m *Model) handleKeyPress(msg tea.KeyPressMsg) (Model, tea.Cmd, bool) {
 }
 
 func (m *Model) keyPressHandlers() map[string]func(*Model) (Model, tea.Cmd, bool) {
-	return map[string]func(*Model) (Model, tea.Cmd, bool){
+	handlers := map[string]func(*Model) (Model, tea.Cmd, bool){
 		"ctrl+c":       (*Model).handleCtrlC,
 		"ctrl+shift+c": (*Model).handleCtrlShiftC,
 		"ctrl+shift+v": (*Model).handleCtrlShiftV,
 		"esc":          (*Model).handleEsc,
-		"enter":        (*Model).handleEnter,
 		"@":            (*Model).handleAtSign,
+		"space":        (*Model).handleSpace,
 		"ctrl+h":       (*Model).handleCtrlH,
 		"ctrl+m":       (*Model).handleCtrlM,
+		"ctrl+t":       (*Model).handleCtrlT,
+		"ctrl+g":       (*Model).handleCtrlG,
+		"ctrl+l":       (*Model).handleCtrlL,
+		"ctrl+o":       (*Model).handleCtrlO,
+		"ctrl+q":       (*Model).handleCtrlQ,
+		"pgup":         (*Model).handlePgUp,
+		"pgdown":       (*Model).handlePgDown,
+		"up":           (*Model).handleHistoryUp,
+		"down":         (*Model).handleHistoryDown,
 	}
+	handlers[m.submitKey] = (*Model).handleSubmit
+	return handlers
 }
 
 func (m *Model) handleCtrlC() (Model, tea.Cmd, bool) {
@@ -38,8 +51,14 @@ func (m *Model) handleCtrlShiftC() (Model, tea.Cmd, bool) {
 	return *m, nil, true
 }
 
+// handleCtrlQ quotes the selection into the input to ask about it.
+func (m *Model) handleCtrlQ() (Model, tea.Cmd, bool) {
+	m.quoteSelection()
+	return *m, nil, true
+}
+
 func (m *Model) handleCtrlShiftV() (Model, tea.Cmd, bool) {
-	return *m, tea.ReadClipboard, true
+	return *m, m.clipboard.paste(), true
 }
 
 func (m *Model) handleEsc() (Model, tea.Cmd, bool) {
@@ -63,13 +82,74 @@ func (m *Model) cancelProgramCmd() tea.Cmd {
 	}
 }
 
-func (m *Model) handleEnter() (Model, tea.Cmd, bool) {
-	if m.agentInput.Value() != "" && m.turnCancel == nil && !m.turnPending && !m.undoInFlight {
-		display := m.agentInput.Value()
+// handleSubmit sends the input on the submit key. An empty input or a busy
+// turn swallows the key so it never falls through to the editor.
+func (m *Model) handleSubmit() (Model, tea.Cmd, bool) {
+	if input := m.agentInput.Value(); input != "" && m.turnCancel == nil && !m.turnPending && !m.undoInFlight {
+		if turns, ok, err := parseForkCommand(input); ok {
+			if err != nil {
+				m.appendText("", m.styles.Error.Render(err.Error()), "")
+				return *m, nil, true
+			}
+			m.agentInput.Reset()
+			saved := m.inputSent(input)
+			mdl, cmd := m.handleFork(turns)
+			return mdl, tea.Batch(cmd, saved), true
+		}
+		if name, ok, err := parseProfileCommand(input); ok {
+			if err != nil {
+				m.appendText("", m.styles.Error.Render(err.Error()), "")
+				return *m, nil, true
+			}
+			m.agentInput.Reset()
+			saved := m.inputSent(input)
+			mdl, cmd := m.handleProfile(name)
+			return mdl, tea.Batch(cmd, saved), true
+		}
+		if isFilesCommand(input) {
+			m.agentInput.Reset()
+			saved := m.inputSent(input)
+			mdl, cmd := m.handleFiles()
+			return mdl, tea.Batch(cmd, saved), true
+		}
+		if temp, ok, err := parseTempCommand(input); ok {
+			if err != nil {
+				m.appendText("", m.styles.Error.Render(err.Error()), "")
+				return *m, nil, true
+			}
+			m.agentInput.Reset()
+			saved := m.inputSent(input)
+			mdl, cmd := m.handleTemp(temp)
+			return mdl, tea.Batch(cmd, saved), true
+		}
+		// A prompt template is sent as its expansion; history keeps the command.
+		sent := input
+		if name, args, ok := parsePromptCommand(input); ok {
+			if name == "" {
+				m.agentInput.Reset()
+				saved := m.inputSent(input)
+				mdl, cmd := m.handlePromptList()
+				return mdl, tea.Batch(cmd, saved), true
+			}
+			text, err := m.promptText(name, args)
+			if err != nil {
+				m.appendText("", m.styles.Error.Render(err.Error()), "")
+				return *m, nil, true
+			}
+			input = text
+		}
+		if m.sessionBudgetSpent() {
+			m.appendText("", m.styles.Error.Render(fmt.Sprintf(
+				"Session token limit reached (%s of %s). Raise limits.session_tokens or start a new session.",
+				formatTokens(m.totalInputTokens+m.totalOutputTokens), formatTokens(m.limits.SessionTokens))), "")
+			m.scrollOffset = 0
+			return *m, nil, true
+		}
 		m.agentInput.Reset()
-		return *m, m.sendToLLM(display, expandAtMentions(display)), true
+		fileContext, note := m.takeOpenFileContext()
+		return *m, tea.Batch(m.sendToLLM(input, expandAtMentions(input)+fileContext, note), m.inputSent(sent)), true
 	}
-	return Model{}, nil, false
+	return *m, nil, true
 }
 
 func (m *Model) handleAtSign() (Model, tea.Cmd, bool) {
@@ -83,6 +163,17 @@ func (m *Model) handleAtSign() (Model, tea.Cmd, bool) {
 	return *m, nil, true
 }
 
+// handleSpace opens the prompt template picker once "/prompt" is typed.
+func (m *Model) handleSpace() (Model, tea.Cmd, bool) {
+	if len(m.prompts) == 0 || !m.agentInput.Focused() || m.agentInput.Value() != "/prompt" ||
+		m.agentInput.CursorOffset() != len("/prompt") {
+		return Model{}, nil, false
+	}
+	m.agentInput.InsertText(" ")
+	m.openPromptModal()
+	return *m, nil, true
+}
+
 func (m *Model) handleCtrlH() (Model, tea.Cmd, bool) {
 	m.openKeybindsModal()
 	return *m, nil, true
@@ -96,17 +187,58 @@ func (m *Model) handleCtrlM() (Model, tea.Cmd, bool) {
 	return *m, m.fetchModelsCmd(), true
 }
 
+func (m *Model) handleCtrlT() (Model, tea.Cmd, bool) {
+	m.openScratchpadModal()
+	return *m, nil, true
+}
+
+// handleCtrlG toggles mouse capture so the terminal can handle native selection.
+func (m *Model) handleCtrlG() (Model, tea.Cmd, bool) {
+	m.mouseEnabled = !m.mouseEnabled
+	m.convSel = nil
+	m.convDragging = false
+	return *m, nil, true
+}
+
+// handleCtrlL toggles syntax highlighting in the input editor.
+func (m *Model) handleCtrlL() (Model, tea.Cmd, bool) {
+	m.agentInput.NoHighlight = !m.agentInput.NoHighlight
+	return *m, nil, true
+}
+
 func (m *Model) flushAndQuit() tea.Cmd {
-	queue := m.storeQueue
-	done := m.storeQueueDone
+	mdl := *m
+	draft := m.agentInput.Value()
 	return func() tea.Msg {
-		if queue != nil {
-			close(queue)
-			queue = nil
-		}
-		if done != nil {
-			<-done
+		if mdl.store != nil && draft != mdl.draftSaved {
+			if err := mdl.store.SaveDraft(mdl.sessionID, draft); err != nil {
+				log.Warn().Err(err).Msg("failed to save draft on quit")
+			}
 		}
+		mdl.FlushStore(storeFlushTimeout)
 		return tea.Quit()
 	}
 }
+
+// handlePgUp pages the conversation up when the input is unfocused;
+// otherwise the editor pages its own buffer.
+func (m *Model) handlePgUp() (Model, tea.Cmd, bool) {
+	if m.agentInput.Focused() {
+		return Model{}, nil, false
+	}
+	return *m, m.pageConv(1), true
+}
+
+func (m *Model) handlePgDown() (Model, tea.Cmd, bool) {
+	if m.agentInput.Focused() {
+		return Model{}, nil, false
+	}
+	return *m, m.pageConv(-1), true
+}
+
+// pageConv scrolls the conversation one page in dir (+1 up, -1 down),
+// keeping pageOverlap lines of the previous page on screen.
+func (m *Model) pageConv(dir int) tea.Cmd {
+	step := max(m.layout.conv.Dy()-m.pageOverlap, 1)
+	return m.scrollConv(dir*step, len(m.wrappedConvLines()))
+}
@@ -0,0 +1,300 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/xonecas/symb/internal/config"
+	"github.com/xonecas/symb/internal/provider"
+	"github.com/xonecas/symb/internal/store"
+)
+
+// TestSubmitKey verifies that with a modifier submit key, enter inserts a
+// newline, the submit key sends, and empty or busy submits are ignored.
+func TestSubmitKey(t *testing.T) {
+	initTheme("vulcan")
+	ui := config.UIConfig{SubmitKey: "ctrl+enter"}
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, ui, config.LimitsConfig{}, config.SessionConfig{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+
+	press := func(k tea.KeyPressMsg) tea.Cmd {
+		updated, cmd := m.Update(k)
+		m = updated.(Model)
+		return cmd
+	}
+	submit := tea.KeyPressMsg{Code: tea.KeyEnter, Mod: tea.ModCtrl}
+
+	if cmd := press(submit); cmd != nil || m.agentInput.Value() != "" {
+		t.Fatalf("empty submit: cmd=%v value=%q", cmd != nil, m.agentInput.Value())
+	}
+
+	press(tea.KeyPressMsg{Code: 'a', Text: "a"})
+	press(tea.KeyPressMsg{Code: tea.KeyEnter})
+	press(tea.KeyPressMsg{Code: 'b', Text: "b"})
+	if got := m.agentInput.Value(); got != "a\nb" {
+		t.Fatalf("enter should insert a newline, input = %q", got)
+	}
+
+	m.turnPending = true
+	if cmd := press(submit); cmd != nil || m.agentInput.Value() != "a\nb" {
+		t.Fatalf("submit during a pending turn: cmd=%v value=%q", cmd != nil, m.agentInput.Value())
+	}
+	m.turnPending = false
+
+	cmd := press(submit)
+	if cmd == nil {
+		t.Fatal("submit key did not send")
+	}
+	if msg, ok := cmd().(llmUserMsg); !ok || msg.display != "a\nb" {
+		t.Errorf("sent %#v, want llmUserMsg with the input", msg)
+	}
+	if m.agentInput.Value() != "" {
+		t.Errorf("input not cleared after submit: %q", m.agentInput.Value())
+	}
+}
+
+// TestInputHistoryRecall verifies up/down recall of past submissions, that
+// recall only starts from an empty input, and that history is persisted.
+func TestInputHistoryRecall(t *testing.T) {
+	initTheme("vulcan")
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.AddInputHistory("s", "one"); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New(nil, nil, nil, nil, "test", db, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+	updated, _ = m.Update(m.loadInputHistoryCmd()())
+	m = updated.(Model)
+
+	press := func(k tea.KeyPressMsg) tea.Cmd {
+		updated, cmd := m.Update(k)
+		m = updated.(Model)
+		return cmd
+	}
+	up, down := tea.KeyPressMsg{Code: tea.KeyUp}, tea.KeyPressMsg{Code: tea.KeyDown}
+
+	m.agentInput.SetValue("two\nlines")
+	runCmd(press(tea.KeyPressMsg{Code: tea.KeyEnter}))
+
+	steps := []struct {
+		key  tea.KeyPressMsg
+		want string
+	}{
+		{up, "two\nlines"},
+		{up, "one"},
+		{up, "one"}, // stays at the oldest
+		{down, "two\nlines"},
+		{down, "two\nlines"}, // cursor moves to the last line first
+		{down, ""},
+	}
+	for i, st := range steps {
+		press(st.key)
+		if got := m.agentInput.Value(); got != st.want {
+			t.Fatalf("step %d: input = %q, want %q", i, got, st.want)
+		}
+	}
+
+	// Content the user typed is never replaced.
+	m.agentInput.SetValue("draft")
+	press(up)
+	if got := m.agentInput.Value(); got != "draft" {
+		t.Errorf("up with content recalled history: %q", got)
+	}
+
+	got, err := db.LoadInputHistory("s", 10)
+	if err != nil || len(got) != 2 || got[1] != "two\nlines" {
+		t.Errorf("persisted history = %q, %v", got, err)
+	}
+}
+
+// runCmd runs cmd and any commands it batches, discarding their messages.
+func runCmd(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	if batch, ok := cmd().(tea.BatchMsg); ok {
+		for _, c := range batch {
+			runCmd(c)
+		}
+	}
+}
+
+// TestDraftAutosave verifies the input is saved as a draft only after it
+// stops changing, restored for the session, and cleared on submit.
+func TestDraftAutosave(t *testing.T) {
+	initTheme("vulcan")
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.CreateSession("s"); err != nil {
+		t.Fatal(err)
+	}
+	newModel := func() Model {
+		m := New(nil, nil, nil, nil, "test", db, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+		updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+		return updated.(Model)
+	}
+	draft := func() string {
+		text, err := db.LoadDraft("s")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return text
+	}
+
+	m := newModel()
+	now := time.Now()
+	m.agentInput.SetValue("long prompt")
+	runCmd(m.tickDraft(now))
+	runCmd(m.tickDraft(now.Add(draftDebounce / 2)))
+	if got := draft(); got != "" {
+		t.Fatalf("draft saved before the input settled: %q", got)
+	}
+	runCmd(m.tickDraft(now.Add(draftDebounce)))
+	if got := draft(); got != "long prompt" {
+		t.Fatalf("draft = %q, want %q", got, "long prompt")
+	}
+
+	// A fresh model for the session picks the draft back up.
+	m = newModel()
+	updated, _ := m.Update(m.loadDraftCmd()())
+	m = updated.(Model)
+	if got := m.agentInput.Value(); got != "long prompt" {
+		t.Fatalf("restored input = %q", got)
+	}
+
+	updated, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = updated.(Model)
+	runCmd(cmd)
+	if got := draft(); got != "" {
+		t.Errorf("draft not cleared on submit: %q", got)
+	}
+}
+
+// TestCheckpoint verifies session state is checkpointed after the configured
+// turns or once it sits idle, and a resumed model restores the totals.
+func TestCheckpoint(t *testing.T) {
+	initTheme("vulcan")
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.CreateSession("s"); err != nil {
+		t.Fatal(err)
+	}
+	session := config.SessionConfig{CheckpointTurns: 2, CheckpointIdleSeconds: 10}
+	newModel := func() Model {
+		return New(nil, nil, nil, nil, "test", db, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, session)
+	}
+	saved := func() (store.Checkpoint, bool) {
+		cp, ok, err := db.LoadCheckpoint("s")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cp, ok
+	}
+
+	m := newModel()
+	now := time.Now()
+	m.totalInputTokens, m.totalOutputTokens = 100, 10
+	m.turnBoundaries = []turnBoundary{{dbMsgID: 1}}
+	m.markCheckpoint(now, true)
+	runCmd(m.tickCheckpoint(now.Add(time.Second)))
+	if _, ok := saved(); ok {
+		t.Fatal("checkpoint saved before the turn count or idle time")
+	}
+	m.markCheckpoint(now, true)
+	runCmd(m.tickCheckpoint(now.Add(time.Second)))
+	if cp, ok := saved(); !ok || cp.InputTokens != 100 || len(cp.Turns) != 1 || cp.Turns[0].MsgID != 1 {
+		t.Fatalf("after 2 turns: %+v, %v", cp, ok)
+	}
+
+	m.totalInputTokens = 200
+	m.markCheckpoint(now, false)
+	runCmd(m.tickCheckpoint(now.Add(5 * time.Second)))
+	if cp, _ := saved(); cp.InputTokens != 100 {
+		t.Fatalf("checkpoint saved before idle: %+v", cp)
+	}
+	runCmd(m.tickCheckpoint(now.Add(10 * time.Second)))
+	if cp, _ := saved(); cp.InputTokens != 200 {
+		t.Fatalf("idle checkpoint = %+v", cp)
+	}
+
+	m = newModel()
+	updated, _ := m.Update(m.loadCheckpointCmd()())
+	m = updated.(Model)
+	if m.totalInputTokens != 200 || m.totalOutputTokens != 10 {
+		t.Errorf("restored totals = %d/%d", m.totalInputTokens, m.totalOutputTokens)
+	}
+}
+
+// TestCheckpointRestoresTurns verifies the checkpoint saved on quit brings
+// back the turn boundaries of a resumed session, so undo reaches them.
+func TestCheckpointRestoresTurns(t *testing.T) {
+	initTheme("vulcan")
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.CreateSession("s"); err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for _, msg := range []store.SessionMessage{
+		{Role: "user", Content: "one"},
+		{Role: "assistant", Content: "first answer"},
+		{Role: "user", Content: "two"},
+		{Role: "assistant", Content: "second answer"},
+	} {
+		id, err := db.SaveMessageSync("s", msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	newModel := func(history []provider.Message) Model {
+		return New(nil, nil, nil, nil, "test", db, "s", nil, nil, nil, "p", nil, history, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	}
+
+	m := newModel(nil)
+	m.totalInputTokens, m.totalOutputTokens = 300, 30
+	m.turnBoundaries = []turnBoundary{{dbMsgID: ids[0]}, {dbMsgID: ids[2], inputTokens: 100, outputTokens: 10}}
+	if !m.FlushStore(time.Second) {
+		t.Fatal("FlushStore timed out")
+	}
+
+	stored, err := db.LoadMessages("s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = newModel(store.ToProviderMessages(stored))
+	updated, _ := m.Update(m.loadCheckpointCmd()())
+	m = updated.(Model)
+	if m.totalInputTokens != 300 || len(m.turnBoundaries) != 2 {
+		t.Fatalf("restored totals %d, boundaries %+v", m.totalInputTokens, m.turnBoundaries)
+	}
+	second := m.turnBoundaries[1]
+	if second.dbMsgID != ids[2] || strings.TrimSpace(ansi.Strip(m.convEntries[second.convIdx+1].display)) != "two" {
+		t.Fatalf("second boundary = %+v", second)
+	}
+
+	m.handleUndo()
+	if len(m.convEntries) != second.convIdx || m.totalInputTokens != 100 || m.totalOutputTokens != 10 {
+		t.Errorf("after undo: %d entries, totals %d/%d", len(m.convEntries), m.totalInputTokens, m.totalOutputTokens)
+	}
+}
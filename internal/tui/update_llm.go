@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
 	"github.com/xonecas/symb/internal/hashline"
+	"github.com/xonecas/symb/internal/llm"
 	"github.com/xonecas/symb/internal/provider"
 	"github.com/xonecas/symb/internal/store"
 )
@@ -22,6 +25,7 @@ func (m Model) handleLLMUser(msg llmUserMsg) (tea.Model, tea.Cmd) {
 
 // handleUserMsg records a user message in the conversation display.
 func (m *Model) handleUserMsg(msg llmUserMsg) (Model, tea.Cmd) {
+	m.recorder.record(msg)
 	now := time.Now()
 	llmMsg := provider.Message{Role: "user", Content: msg.content, CreatedAt: now}
 	storeMsg := provider.Message{Role: "user", Content: msg.display, CreatedAt: now}
@@ -41,28 +45,86 @@ func (m *Model) handleUserMsg(msg llmUserMsg) (Model, tea.Cmd) {
 
 	m.appendText("")
 	m.appendText(highlightMarkdown(msg.display, m.styles.Text)...)
+	if msg.note != "" {
+		m.appendText(m.styles.Dim.Render(msg.note))
+	}
 	wasBottom := m.appendText("")
+	m.turnDiags = nil
 	m.turnInputTokens = 0
 	m.turnOutputTokens = 0
-	m.turnContextTokens = 0
 	if wasBottom {
 		m.scrollOffset = 0
 	}
-	cmd := m.saveUserMessageCmd(llmMsg, storeMsg, convIdx)
+	cmd := m.saveUserMessageCmd(llmMsg, storeMsg, convIdx, m.takeSystemMsg())
 	return *m, cmd
 }
 
-func (m *Model) saveUserMessageCmd(llmMsg, storeMsg provider.Message, convIdx int) tea.Cmd {
-	store := m.store
+// remainingTokens returns how many tokens the session may still spend, or 0
+// when no session cap is configured.
+func (m *Model) remainingTokens() int {
+	if m.limits.SessionTokens <= 0 {
+		return 0
+	}
+	return max(m.limits.SessionTokens-(m.totalInputTokens+m.totalOutputTokens), 1)
+}
+
+// sessionBudgetSpent reports whether the session token cap has been reached.
+func (m *Model) sessionBudgetSpent() bool {
+	return m.limits.SessionTokens > 0 && m.totalInputTokens+m.totalOutputTokens >= m.limits.SessionTokens
+}
+
+// contextUtilization returns the share of the current model's context
+// window filled by the last API call, or 0 before the first call.
+func (m *Model) contextUtilization() float64 {
+	if m.turnContextTokens <= 0 {
+		return 0
+	}
+	return float64(m.turnContextTokens) / float64(provider.ContextWindow(m.currentModelName))
+}
+
+// takeSystemMsg returns the not-yet-persisted system message, rebuilt so its
+// project outline uses the current symbol index, or nil if already saved.
+func (m *Model) takeSystemMsg() *provider.Message {
+	if m.initialSystemMsg == nil || m.systemSaved {
+		return nil
+	}
+	m.systemSaved = true
+	m.initialSystemMsg.Content = llm.BuildSystemPrompt(m.currentModelName, m.tsIndex)
+	sys := *m.initialSystemMsg
+	return &sys
+}
+
+// saveUserMessageCmd persists the user message (preceded by systemMsg, if
+// non-nil, so ordering is preserved) and reports its row ID for undo.
+func (m *Model) saveUserMessageCmd(llmMsg, storeMsg provider.Message, convIdx int, systemMsg *provider.Message) tea.Cmd {
+	db := m.store
 	sessionID := m.sessionID
-	if store == nil {
+	queue := m.storeQueue
+	failures := m.storeFailures
+	if db == nil {
 		return func() tea.Msg {
 			return userMsgSavedMsg{convIdx: convIdx, dbMsgID: 0, userMsg: llmMsg}
 		}
 	}
+	var msgs []store.SessionMessage
+	if systemMsg != nil {
+		msgs = append(msgs, store.FromProviderMessage(*systemMsg))
+	}
+	msgs = append(msgs, store.FromProviderMessage(storeMsg))
+	m.lastSaved = storeMsg
+	// Queue it now so the turn lands after earlier batches; only the
+	// wait for its row ID runs as a Cmd.
+	saved := make(chan storeSaved, 1)
+	if enqueueStoreBatch(queue, storeBatch{sessionID: sessionID, msgs: msgs, saved: saved}) {
+		return func() tea.Msg {
+			r := <-saved
+			return userMsgSavedMsg{convIdx: convIdx, dbMsgID: r.id, userMsg: llmMsg, err: r.err}
+		}
+	}
 	return func() tea.Msg {
-		id, err := store.SaveMessageSync(sessionID, messageToStore(storeMsg))
+		id, err := db.SaveMessagesSync(sessionID, msgs)
 		if err != nil {
+			failures.Add(1)
 			return userMsgSavedMsg{convIdx: convIdx, dbMsgID: 0, userMsg: llmMsg, err: err}
 		}
 		return userMsgSavedMsg{convIdx: convIdx, dbMsgID: id, userMsg: llmMsg}
@@ -84,6 +146,8 @@ func (m Model) handleUserMsgSaved(msg userMsgSavedMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	m.llmInFlight = true
+	m.turnStart = time.Now()
+	m.turnEnd = time.Time{}
 	m.turnCtx, m.turnCancel = context.WithCancel(context.Background())
 	// Always supply the current user message via extra so the LLM receives the
 	// expanded form (@ mentions replaced with file content). When the store is
@@ -99,12 +163,20 @@ func (m Model) handleUserMsgSaved(msg userMsgSavedMsg) (tea.Model, tea.Cmd) {
 // they clear streaming state.
 func (m Model) handleLLMBatch(batch llmBatchMsg) (tea.Model, tea.Cmd) {
 	var history []provider.Message
+	padWritten := false
 	for _, raw := range batch {
 		if msg, ok := raw.(llmHistoryMsg); ok {
 			history = append(history, msg.msg)
+			if msg.msg.Role == "tool" && msg.msg.FunctionName == "TodoWrite" {
+				padWritten = true
+			}
 		}
 	}
 	saveCmd := m.saveMessagesCmd(history)
+	if padWritten {
+		saveCmd = tea.Batch(saveCmd, m.saveScratchpadCmd())
+		m.markCheckpoint(time.Now(), false)
+	}
 	if !m.llmInFlight {
 		return m.drainCancelled(batch, saveCmd)
 	}
@@ -112,12 +184,12 @@ func (m Model) handleLLMBatch(batch llmBatchMsg) (tea.Model, tea.Cmd) {
 		switch msg := raw.(type) {
 		case llmReasoningDeltaMsg:
 			m.ensureStreaming()
-			m.streamingReasoning += msg.content
+			m.appendStreamDelta(true, msg.content)
 			m.streamDirty = true
 
 		case llmContentDeltaMsg:
 			m.ensureStreaming()
-			m.streamingContent += msg.content
+			m.appendStreamDelta(false, msg.content)
 			m.streamDirty = true
 
 		case llmHistoryMsg:
@@ -129,6 +201,12 @@ func (m Model) handleLLMBatch(batch llmBatchMsg) (tea.Model, tea.Cmd) {
 		case llmToolResultMsg:
 			m.applyToolResultMsg(msg)
 
+		case llmConfirmMsg:
+			m.openConfirmModal(msg)
+
+		case llmAutoContinueMsg:
+			m.appendText("", m.styles.Dim.Render("(auto-continuing)"), "")
+
 		case llmErrorMsg:
 			m.finishTurn()
 			m.lastNetError = msg.err.Error()
@@ -146,6 +224,7 @@ func (m Model) handleLLMBatch(batch llmBatchMsg) (tea.Model, tea.Cmd) {
 			m.finishTurn()
 			m.lastNetError = ""
 			m.demoteOldUndo()
+			m.appendDiagSummary()
 			m.appendText("")
 			m.turnContextTokens = msg.contextTokens
 			sep := makeSeparator(m.styles, msg.duration.Round(time.Second).String(), msg.timestamp,
@@ -178,47 +257,31 @@ func (m Model) drainCancelled(batch llmBatchMsg, saveCmd tea.Cmd) (tea.Model, te
 
 // finishTurn clears in-flight state and cancels the turn context.
 func (m *Model) finishTurn() {
+	if m.llmInFlight {
+		m.turnEnd = time.Now()
+	}
 	m.llmInFlight = false
+	m.confirmModal = nil
 	if m.turnCancel != nil {
 		m.turnCancel()
 		m.turnCancel = nil
 	}
 	m.turnCtx = nil
+	m.markCheckpoint(time.Now(), true)
 }
 
-// patchInterruptedHistory appends a synthetic assistant message if the
+// patchInterruptedHistoryCmd appends a synthetic assistant message if the
 // interrupted turn left history in an invalid API state (trailing tool_use
 // without result, or trailing tool result with no assistant follow-up).
 func (m *Model) patchInterruptedHistoryCmd() tea.Cmd {
-	if m.store == nil {
-		return nil
-	}
-	cache := m.store
-	sessionID := m.sessionID
-	storeQueue := m.storeQueue
-	return func() tea.Msg {
-		last, err := cache.LoadLastMessage(sessionID)
-		if err != nil || last == nil {
-			return nil
-		}
-		hasToolCalls := len(last.ToolCalls) > 0 && string(last.ToolCalls) != "[]"
-		needsPatch := last.Role != roleAssistant || hasToolCalls
-		if needsPatch {
-			interruptMsg := provider.Message{
-				Role:      roleAssistant,
-				Content:   "The user interrupted me.",
-				CreatedAt: time.Now(),
-			}
-			stored := []store.SessionMessage{messageToStore(interruptMsg)}
-			if enqueueStoreBatch(storeQueue, storeBatch{sessionID: sessionID, msgs: stored}) {
-				return nil
-			}
-			if err := cache.SaveMessages(sessionID, stored); err != nil {
-				return nil
-			}
-		}
+	if m.lastSaved.Role == roleAssistant && len(m.lastSaved.ToolCalls) == 0 {
 		return nil
 	}
+	return m.saveMessagesCmd([]provider.Message{{
+		Role:      roleAssistant,
+		Content:   "The user interrupted me.",
+		CreatedAt: time.Now(),
+	}})
 }
 
 // brailleFrames is the spinner animation sequence.
@@ -226,22 +289,31 @@ var brailleFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "
 
 // tickSpinner advances the braille spinner frame based on elapsed time.
 // Slow (~500ms/frame) when idle, fast (~100ms/frame) during LLM turns.
+// With reduced motion it holds still when idle and steps every 400ms.
 func (m *Model) tickSpinner(now time.Time) {
 	interval := 500 * time.Millisecond
 	if m.llmInFlight {
 		interval = 100 * time.Millisecond
 	}
+	if m.reducedMotion {
+		if !m.llmInFlight {
+			return
+		}
+		interval = 400 * time.Millisecond
+	}
 	if now.Sub(m.spinFrameAt) >= interval {
 		m.spinFrame = (m.spinFrame + 1) % len(brailleFrames)
 		m.spinFrameAt = now
 	}
 }
 
-// tickStreaming rebuilds streaming entries when new content has arrived.
-func (m *Model) tickStreaming() {
-	if m.streamDirty {
+// tickStreaming rebuilds streaming entries when new content has arrived,
+// at most once per streamFlush.
+func (m *Model) tickStreaming(now time.Time) {
+	if m.streamDirty && now.Sub(m.streamFlushedAt) >= m.streamFlush {
 		m.rebuildStreamEntries()
 		m.streamDirty = false
+		m.streamFlushedAt = now
 	}
 }
 
@@ -252,23 +324,22 @@ func (m *Model) ensureStreaming() {
 	}
 	m.streaming = true
 	m.streamEntryStart = len(m.convEntries)
-	m.streamingReasoning = ""
-	m.streamingContent = ""
+	m.streamSegments = nil
 }
 
 // applyAssistantMsg finalizes streaming state and appends the assistant's
 // response entries. Extracted so handleLLMBatch can reuse the logic.
+// Reasoning and content keep the order they streamed in, as the message
+// records it; see messageSegments.
 func (m *Model) applyAssistantMsg(msg llmAssistantMsg) {
 	m.clearStreaming()
-	if msg.reasoning != "" {
-		wasBottom := m.appendText(styledLines(msg.reasoning, m.styles.Muted)...)
-		m.appendText("")
-		if wasBottom {
-			m.scrollOffset = 0
+	for _, seg := range joinFences(messageSegments(msg.reasoning, msg.content, msg.segments)) {
+		if strings.TrimSpace(seg.text) == "" {
+			continue
 		}
-	}
-	if msg.content != "" {
-		wasBottom := m.appendText(highlightMarkdown(msg.content, m.styles.Text)...)
+		entries := segmentEntries(seg, true, m.styles)
+		m.linkFileRefs(entries)
+		wasBottom := m.appendConv(entries...)
 		m.appendText("")
 		if wasBottom {
 			m.scrollOffset = 0
@@ -317,11 +388,13 @@ func (m *Model) clearStreaming() {
 	}
 	m.streaming = false
 	if m.streamEntryStart >= 0 && m.streamEntryStart <= len(m.convEntries) {
+		if m.scrollOffset > 0 {
+			m.scrollOffset = max(m.scrollOffset-m.wrappedHeight(m.convEntries[m.streamEntryStart:]), 0)
+		}
 		m.convEntries = m.convEntries[:m.streamEntryStart]
 	}
 	m.streamEntryStart = -1
-	m.streamingReasoning = ""
-	m.streamingContent = ""
+	m.streamSegments = nil
 }
 
 // applyToolResultMsg appends tool result display entries.
@@ -329,27 +402,14 @@ func (m *Model) clearStreaming() {
 func (m *Model) applyToolResultMsg(msg llmToolResultMsg) {
 	m.clearStreaming()
 
-	var filePath string
-	if sm := toolResultFileRe.FindStringSubmatch(msg.content); sm != nil {
-		filePath = sm[1]
-	}
-
 	// Resolve the tool name from the pending call.
-	var toolName string
-	if tc, ok := m.pendingToolCalls[msg.toolCallID]; ok {
-		toolName = tc.Name
-	}
+	tc := m.pendingToolCalls[msg.toolCallID]
+	toolName := tc.Name
+	filePath, startLine := toolResultLocation(tc, msg.content)
 
-	// Extract target line for cursor positioning.
-	// Read results: from "(lines N-M)". Edit results: from the tool call arguments.
-	var startLine int
-	if sm := toolResultLineRe.FindStringSubmatch(msg.content); sm != nil {
-		startLine, _ = strconv.Atoi(sm[1])
-	} else if tc, ok := m.pendingToolCalls[msg.toolCallID]; ok && tc.Name == "Edit" {
-		startLine = toolCallEditLine(tc.Arguments)
-	}
-	if startLine == 0 && filePath != "" {
-		startLine = toolResultHashlineStart(msg.content, filePath)
+	// Keep an open plan panel in sync with the agent's latest TodoWrite.
+	if toolName == "TodoWrite" && m.scratchpadModal != nil {
+		m.scratchpadModal.SetContent(m.scratchpadContent())
 	}
 
 	body, diagLines := extractDiagLines(msg.content)
@@ -357,9 +417,15 @@ func (m *Model) applyToolResultMsg(msg llmToolResultMsg) {
 		body = body[:idx]
 	}
 
-	// Build display: "← summary  [view]"
-	arrow := m.styles.ToolArrow.Render("←") + m.styles.BgFill.Render("  ")
+	// Build display: "[▸ ]← summary  [view]", in red when the call failed.
+	arrow := toolResultArrow(m.styles, msg.isError) + m.styles.BgFill.Render("  ")
+	if len(toolResultBody(msg.content)) > 0 {
+		arrow = toolResultCaret(m.styles, false) + m.styles.BgFill.Render(" ") + arrow
+	}
 	summary := arrow + m.styleToolResultLine(body)
+	if msg.isError {
+		summary = arrow + m.styles.Error.Render(body)
+	}
 	viewBtn := m.styles.BgFill.Render("  ") + m.styles.Clickable.Render("view")
 	display := summary + viewBtn
 
@@ -370,25 +436,46 @@ func (m *Model) applyToolResultMsg(msg llmToolResultMsg) {
 		full:     msg.content,
 		line:     startLine,
 		toolName: toolName,
+		isError:  msg.isError,
 	}
 	wasBottom := m.appendConv(entry)
+	if toolName == "Edit" && filePath != "" {
+		if ds := editDiffSummary(msg.content, filePath); ds != "" {
+			m.appendConv(convEntry{display: m.styleToolResultLine(ds), kind: entryToolDiag, full: msg.content})
+		}
+	}
+	if toolName == "Edit" && filePath != "" {
+		m.recordTurnDiags(filePath, diagLines)
+	}
 	for _, dl := range diagLines {
-		m.appendConv(convEntry{display: m.styleToolResultLine(dl), kind: entryToolDiag, full: msg.content})
+		m.appendConv(m.diagEntry(dl, msg.content))
 	}
 	if wasBottom {
 		m.scrollOffset = 0
 	}
 }
 
+// diagPrefixes are the severity labels lsp.FormatDiagnostics emits.
+var diagPrefixes = []string{"ERROR ", "WARNING ", "INFO ", "HINT "}
+
+// diagBlockHeaders start the diagnostics block of an Edit (LSP) or Build
+// tool result.
+var diagBlockHeaders = []string{"\nLSP diagnostics:", "\nBuild diagnostics:"}
+
 // extractDiagLines splits diagnostic lines from tool result content.
-// Returns the body without the diagnostics block and the ERROR/WARNING lines.
+// Returns the body without the diagnostics block and the ERROR/WARNING/INFO/HINT lines.
 func extractDiagLines(content string) (body string, diags []string) {
-	idx := strings.Index(content, "\nLSP diagnostics:")
+	idx := -1
+	for _, header := range diagBlockHeaders {
+		if idx = strings.Index(content, header); idx >= 0 {
+			break
+		}
+	}
 	if idx < 0 {
 		return content, nil
 	}
 	for _, dl := range strings.Split(content[idx+1:], "\n") {
-		if strings.HasPrefix(dl, "ERROR ") || strings.HasPrefix(dl, "WARNING ") {
+		if slices.ContainsFunc(diagPrefixes, func(p string) bool { return strings.HasPrefix(dl, p) }) {
 			diags = append(diags, dl)
 		}
 	}
@@ -398,50 +485,104 @@ func extractDiagLines(content string) (body string, diags []string) {
 // styleToolResultLine applies semantic styling to a tool result line.
 // Diagnostic lines (ERROR/WARNING) get colored; everything else is dim.
 func (m *Model) styleToolResultLine(line string) string {
+	return m.toolResultLineStyle(line).Render(line)
+}
+
+func (m *Model) toolResultLineStyle(line string) lipgloss.Style {
 	switch {
 	case strings.HasPrefix(line, "ERROR "):
-		return m.styles.Error.Render(line)
+		return m.styles.Error
 	case strings.HasPrefix(line, "WARNING "):
-		return m.styles.Warning.Render(line)
+		return m.styles.Warning
 	default:
-		return m.styles.Dim.Render(line)
+		return m.styles.Dim
+	}
+}
+
+// diagEntry renders a diagnostic line. Build diagnostics name their file,
+// so the reference is a link that opens the file at the line.
+func (m *Model) diagEntry(line, full string) convEntry {
+	sty := m.toolResultLineStyle(line)
+	entry := convEntry{display: sty.Render(line), kind: entryToolDiag, full: full}
+	loc := fileRefRe.FindStringSubmatchIndex(line)
+	if loc == nil {
+		return entry
+	}
+	path := line[loc[2]:loc[3]]
+	if _, ok := resolveFileRef(path); !ok {
+		return entry
+	}
+	entry.display = sty.Render(line[:loc[0]]) + m.styles.Clickable.Render(line[loc[0]:loc[1]]) + sty.Render(line[loc[1]:])
+	entry.filePath = path
+	entry.line, _ = strconv.Atoi(line[loc[4]:loc[5]])
+	return entry
+}
+
+// toolResultLocation returns the file and line a tool result points at.
+// Read/Edit results name the file in their header; the line comes from the
+// Read range, the Edit anchors, or the first tagged line. Grep results point
+// at their first match.
+func toolResultLocation(call provider.ToolCall, content string) (string, int) {
+	if call.Name == "Grep" {
+		if sm := grepHitRe.FindStringSubmatch(content); sm != nil {
+			line, _ := strconv.Atoi(sm[2])
+			return sm[1], line
+		}
+		return "", 0
+	}
+	if readManyRe.MatchString(content) {
+		return "", 0
+	}
+	sm := toolResultFileRe.FindStringSubmatch(content)
+	if sm == nil {
+		return "", 0
+	}
+	filePath := sm[1]
+	var line int
+	if sm := toolResultLineRe.FindStringSubmatch(content); sm != nil {
+		line, _ = strconv.Atoi(sm[1])
+	} else if call.Name == "Edit" {
+		line = toolCallEditLine(call.Arguments)
+	}
+	if line == 0 {
+		line = toolResultHashlineStart(content, filePath)
 	}
+	return filePath, line
 }
 
-// toolCallEditLine extracts the target line from an Edit tool call's arguments.
-// Returns the start line of the edit operation (replace/insert/delete), or 0.
+// toolCallEditLine extracts the first line an Edit tool call changes: the
+// start anchor for replace/delete, the line after the anchor for insert.
+// Returns 0 when the arguments carry no anchor.
 func toolCallEditLine(args json.RawMessage) int {
 	var parsed struct {
-		Replace *struct {
-			Start struct {
-				Line int `json:"line"`
-			} `json:"start"`
-		} `json:"replace"`
-		Insert *struct {
-			After struct {
-				Line int `json:"line"`
-			} `json:"after"`
-		} `json:"insert"`
-		Delete *struct {
-			Start struct {
-				Line int `json:"line"`
-			} `json:"start"`
-		} `json:"delete"`
+		Start string `json:"start"`
+		After string `json:"after"`
 	}
 	if json.Unmarshal(args, &parsed) != nil {
 		return 0
 	}
-	switch {
-	case parsed.Replace != nil:
-		return parsed.Replace.Start.Line
-	case parsed.Insert != nil:
-		return parsed.Insert.After.Line
-	case parsed.Delete != nil:
-		return parsed.Delete.Start.Line
+	if a, err := hashline.ParseAnchor(parsed.Start); err == nil {
+		return a.Num
+	}
+	if a, err := hashline.ParseAnchor(parsed.After); err == nil {
+		return a.Num + 1
 	}
 	return 0
 }
 
+// editDiffSummary derives a compact "+N/-M lines in file" summary from a
+// successful Edit result, whose header counts the lines the edit changed.
+// Returns "" when the result has no count.
+func editDiffSummary(content, filePath string) string {
+	if sm := editedChangeRe.FindStringSubmatch(content); sm != nil {
+		return fmt.Sprintf("+%s/-%s lines in %s", sm[1], sm[2], filePath)
+	}
+	if sm := createdLinesRe.FindStringSubmatch(content); sm != nil {
+		return fmt.Sprintf("+%s/-0 lines in %s", sm[1], filePath)
+	}
+	return ""
+}
+
 // toolResultHashlineStart scans a tool result body for the first hashline and
 // returns its line number if it matches the provided file path.
 func toolResultHashlineStart(content, filePath string) int {
@@ -474,10 +615,26 @@ func toolResultHashlineStart(content, filePath string) int {
 	return 0
 }
 
-// formatToolCall renders a tool call as Name(key="val", key2="val2").
-// Long values are truncated. Falls back to Name(...) on parse errors.
+// toolCallSummaries render the arguments of well-known tools compactly.
+// Each returns "" when the arguments don't fit its shape, falling back to
+// the generic key=value rendering.
+var toolCallSummaries = map[string]func(json.RawMessage) string{
+	"Edit":                 editCallSummary,
+	"Read":                 readCallSummary,
+	"Shell":                shellCallSummary,
+	"web_search_exa":       webQueryCallSummary,
+	"get_code_context_exa": webQueryCallSummary,
+}
+
+// formatToolCall renders a tool call as Name(summary) for well-known tools
+// and Name(key=val, key2=val2) otherwise. Long or multi-line values are
+// truncated with a marker. Falls back to Name(...) on parse errors.
 func formatToolCall(tc provider.ToolCall) string {
-	const maxVal = 40
+	if summarize, ok := toolCallSummaries[tc.Name]; ok {
+		if s := summarize(tc.Arguments); s != "" {
+			return tc.Name + "(" + s + ")"
+		}
+	}
 	var args map[string]json.RawMessage
 	if err := json.Unmarshal(tc.Arguments, &args); err != nil || len(args) == 0 {
 		return tc.Name + "(...)"
@@ -493,11 +650,115 @@ func formatToolCall(tc provider.ToolCall) string {
 		if err := json.Unmarshal(args[k], &v); err != nil {
 			continue
 		}
-		s := fmt.Sprintf("%v", v)
-		if len(s) > maxVal {
-			s = s[:maxVal] + "…"
-		}
-		parts = append(parts, k+"="+s)
+		parts = append(parts, k+"="+truncateArg(fmt.Sprintf("%v", v)))
 	}
 	return tc.Name + "(" + strings.Join(parts, ", ") + ")"
 }
+
+// truncateArg shortens a tool argument to its first line and at most 40
+// runes, noting how many lines were dropped.
+func truncateArg(s string) string {
+	const maxVal = 40
+	first, rest, multi := strings.Cut(s, "\n")
+	if r := []rune(first); len(r) > maxVal {
+		first = string(r[:maxVal]) + "…"
+	} else if multi {
+		first += "…"
+	}
+	if multi {
+		first += fmt.Sprintf(" (+%d lines)", strings.Count(rest, "\n")+1)
+	}
+	return first
+}
+
+// editCallSummary renders an Edit call as "file:line (op N lines)".
+func editCallSummary(raw json.RawMessage) string {
+	var args struct {
+		File      string `json:"file"`
+		Operation string `json:"operation"`
+		Start     string `json:"start"`
+		End       string `json:"end"`
+		After     string `json:"after"`
+		Content   string `json:"content"`
+	}
+	if json.Unmarshal(raw, &args) != nil || args.File == "" {
+		return ""
+	}
+	contentLines := 0
+	if args.Content != "" {
+		contentLines = strings.Count(strings.TrimSuffix(args.Content, "\n"), "\n") + 1
+	}
+	start, startErr := hashline.ParseAnchor(args.Start)
+	end, endErr := hashline.ParseAnchor(args.End)
+	switch args.Operation {
+	case "replace", "delete":
+		if startErr != nil || endErr != nil {
+			return ""
+		}
+		n := end.Num - start.Num + 1
+		if args.Operation == "replace" {
+			return fmt.Sprintf("%s:%d (replace %s with %d)", args.File, start.Num, plural(n, "line"), contentLines)
+		}
+		return fmt.Sprintf("%s:%d (delete %s)", args.File, start.Num, plural(n, "line"))
+	case "insert":
+		after, err := hashline.ParseAnchor(args.After)
+		if err != nil {
+			return ""
+		}
+		return fmt.Sprintf("%s:%d (insert %s)", args.File, after.Num+1, plural(contentLines, "line"))
+	case "create":
+		return fmt.Sprintf("%s (create, %s)", args.File, plural(contentLines, "line"))
+	}
+	return ""
+}
+
+// readCallSummary renders a Read call as "file", "file:start-end", or a
+// comma-separated list for a multi-file Read.
+func readCallSummary(raw json.RawMessage) string {
+	var args struct {
+		File  string   `json:"file"`
+		Files []string `json:"files"`
+		Start int      `json:"start"`
+		End   int      `json:"end"`
+	}
+	if json.Unmarshal(raw, &args) != nil {
+		return ""
+	}
+	if len(args.Files) > 0 {
+		return strings.Join(args.Files, ", ")
+	}
+	if args.File == "" {
+		return ""
+	}
+	switch {
+	case args.Start > 0 && args.End > 0:
+		return fmt.Sprintf("%s:%d-%d", args.File, args.Start, args.End)
+	case args.Start > 0:
+		return fmt.Sprintf("%s:%d-", args.File, args.Start)
+	case args.End > 0:
+		return fmt.Sprintf("%s:1-%d", args.File, args.End)
+	}
+	return args.File
+}
+
+// shellCallSummary renders a Shell call as "$ command".
+func shellCallSummary(raw json.RawMessage) string {
+	var args struct {
+		Command string `json:"command"`
+	}
+	if json.Unmarshal(raw, &args) != nil || args.Command == "" {
+		return ""
+	}
+	return "$ " + truncateArg(args.Command)
+}
+
+// webQueryCallSummary renders an Exa web tool call as its quoted query.
+func webQueryCallSummary(raw json.RawMessage) string {
+	var args struct {
+		Query string `json:"query"`
+	}
+	if json.Unmarshal(raw, &args) != nil || args.Query == "" {
+		return ""
+	}
+	return strconv.Quote(truncateArg(args.Query))
+}
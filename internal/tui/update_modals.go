@@ -2,6 +2,8 @@ package tui
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"strings"
 
 	tea "charm.land/bubbletea/v2"
@@ -43,26 +45,96 @@ func (m *Model) openFileModal() {
 	m.fileModal = &md
 }
 
+func (m *Model) openPromptModal() {
+	var items []modal.Item
+	for _, name := range m.promptNames() {
+		desc, _, _ := strings.Cut(m.prompts[name], "\n")
+		items = append(items, modal.Item{Name: name, Desc: desc})
+	}
+	searchFn := func(query string) []modal.Item {
+		q := strings.ToLower(query)
+		var filtered []modal.Item
+		for _, item := range items {
+			if strings.Contains(strings.ToLower(item.Name), q) {
+				filtered = append(filtered, item)
+			}
+		}
+		return filtered
+	}
+	md := modal.New(searchFn, "Prompt: ", modal.Colors{
+		Fg:     palette.Fg,
+		Bg:     palette.Bg,
+		Dim:    palette.Dim,
+		SelFg:  palette.Bg,
+		SelBg:  palette.Fg,
+		Border: palette.Border,
+	})
+	md.WidthPct = 60
+	m.promptModal = &md
+}
+
+func (m *Model) updatePromptModal(msg tea.Msg) (Model, tea.Cmd, bool) {
+	if m.promptModal == nil {
+		return *m, nil, false
+	}
+	action, cmd := m.promptModal.HandleMsg(msg)
+	switch a := action.(type) {
+	case modal.ActionClose:
+		m.promptModal = nil
+		return *m, nil, true
+	case modal.ActionSelect:
+		m.promptModal = nil
+		m.agentInput.InsertText(a.Item.Name + " ")
+		m.agentInput.Focus()
+		return *m, nil, true
+	}
+	if cmd != nil {
+		return *m, cmd, true
+	}
+	switch msg.(type) {
+	case tea.KeyPressMsg, tea.MouseMsg:
+		return *m, nil, true
+	}
+	return *m, nil, false
+}
+
 func (m *Model) openKeybindsModal() {
+	newlineKey := "enter"
+	if m.submitKey == "enter" {
+		newlineKey = "shift+enter"
+	}
 	items := []modal.Item{
 		{Name: "ctrl+h", Desc: "keybinds"},
 		{Name: "@", Desc: "file search"},
 		{Name: "ctrl+m", Desc: "switch model"},
+		{Name: "ctrl+t", Desc: "toggle agent plan (scratchpad)"},
+		{Name: "ctrl+g", Desc: "toggle mouse capture"},
+		{Name: "ctrl+l", Desc: "toggle input highlighting"},
+		{Name: "ctrl+o", Desc: "toggle sending the viewed file as context"},
+		{Name: "f8/shift+f8", Desc: "next/prev diagnostic in the file view"},
+		{Name: "/fork [N]", Desc: "fork session (first N turns, or all)"},
+		{Name: "/files", Desc: "list files read or changed this session"},
+		{Name: "/temp [value]", Desc: "show or set the temperature (0.0-2.0)"},
+		{Name: "/prompt [name args]", Desc: "send a prompt template, or list them"},
 		{Name: "ctrl+shift+c", Desc: "copy selection"},
 		{Name: "ctrl+shift+v", Desc: "paste"},
+		{Name: "ctrl+q", Desc: "ask about the selection (quote it into the input)"},
 		{Name: "ctrl+c", Desc: "quit"},
 		{Name: "esc", Desc: "cancel/blur"},
-		{Name: "enter", Desc: "send message"},
-		{Name: "shift+enter", Desc: "newline in input"},
+		{Name: m.submitKey, Desc: "send message"},
+		{Name: newlineKey, Desc: "newline in input"},
 		{Name: "tab", Desc: "indent"},
 		{Name: "backspace", Desc: "delete backward"},
 		{Name: "delete", Desc: "delete forward"},
 		{Name: "up/down/left/right", Desc: "move cursor"},
+		{Name: "up/down (empty input)", Desc: "recall sent messages"},
 		{Name: "shift+arrows", Desc: "extend selection"},
-		{Name: "home/end/ctrl+a/ctrl+e", Desc: "line start/end"},
-		{Name: "pgup/pgdown", Desc: "page scroll"},
+		{Name: "home/end/ctrl+a/ctrl+e", Desc: "line start (indent, then column 0)/end"},
+		{Name: "pgup/pgdown", Desc: "page scroll (conversation when input unfocused)"},
 		{Name: "shift+pgup/shift+pgdown", Desc: "extend selection by page"},
 		{Name: "ctrl+home/ctrl+end", Desc: "file start/end"},
+		{Name: "alt+up/alt+down", Desc: "move line or selected lines"},
+		{Name: "ctrl+shift+d", Desc: "duplicate line or selection"},
 	}
 	searchFn := func(query string) []modal.Item {
 		if query == "" {
@@ -233,33 +305,37 @@ func (m *Model) updateModelsModal(msg tea.Msg) (Model, tea.Cmd, bool) {
 // produced by openModelsModal, or a bare model name (falls back to the current
 // provider config name for backwards compatibility).
 func (m *Model) switchModelCmd(selection string) tea.Cmd {
-	registry := m.registry
-	currentProviderConfigName := m.providerConfigName
-	providerOpts := m.providerOpts
-	oldProv := m.provider
-
 	// Parse "providerName/modelName". Use SplitN so model names containing
 	// additional slashes (e.g. "org/repo/model") are preserved intact.
-	providerConfigName := currentProviderConfigName
+	providerConfigName := m.providerConfigName
 	modelName := selection
 	if parts := strings.SplitN(selection, "/", 2); len(parts) == 2 {
 		providerConfigName = parts[0]
 		modelName = parts[1]
 	}
+	return m.switchProviderCmd(providerConfigName, modelName, m.providerOpts, "")
+}
+
+// switchProviderCmd replaces the active provider with a new one for
+// providerConfigName/modelName built with opts. profile names the profile
+// being applied, if any.
+func (m *Model) switchProviderCmd(providerConfigName, modelName string, opts provider.Options, profile string) tea.Cmd {
+	registry := m.registry
+	oldProv := m.provider
 
 	return func() tea.Msg {
 		if registry == nil {
 			return modelSwitchedMsg{err: provider.ErrProviderNotFound}
 		}
 		log.Info().Str("provider", providerConfigName).Str("model", modelName).Msg("switchModelCmd")
-		newProv, err := registry.Create(providerConfigName, modelName, providerOpts)
+		newProv, err := registry.Create(providerConfigName, modelName, opts)
 		if err != nil {
 			return modelSwitchedMsg{err: err}
 		}
 		if oldProv != nil {
 			oldProv.Close()
 		}
-		return modelSwitchedMsg{modelName: modelName, providerName: providerConfigName, prov: newProv}
+		return modelSwitchedMsg{modelName: modelName, providerName: providerConfigName, prov: newProv, opts: opts, profile: profile}
 	}
 }
 
@@ -271,10 +347,32 @@ func (m *Model) openToolViewModal(title, content string) {
 		SelFg:  palette.Bg,
 		SelBg:  palette.Fg,
 		Border: palette.Border,
+		Error:  palette.Error,
+		Warn:   palette.Accent,
 	})
 	m.toolViewModal = &tv
 }
 
+// locationRow returns the index of the content line that shows filePath at
+// line: a hashline-tagged "line:hash|" row or a Grep "path:line:" hit. It
+// returns -1 when there is no location or no such row.
+func locationRow(content, filePath string, line int) int {
+	if filePath == "" || line <= 0 {
+		return -1
+	}
+	tag := strconv.Itoa(line) + ":"
+	hit := filePath + ":" + tag
+	for i, l := range strings.Split(content, "\n") {
+		if strings.HasPrefix(l, hit) {
+			return i
+		}
+		if strings.HasPrefix(l, tag) && strings.Contains(l, "|") {
+			return i
+		}
+	}
+	return -1
+}
+
 func (m *Model) updateToolViewModal(msg tea.Msg) (Model, tea.Cmd, bool) {
 	if m.toolViewModal == nil {
 		return *m, nil, false
@@ -282,6 +380,7 @@ func (m *Model) updateToolViewModal(msg tea.Msg) (Model, tea.Cmd, bool) {
 	action, cmd := m.toolViewModal.HandleMsg(msg)
 	switch action.(type) {
 	case modal.ActionClose:
+		m.viewedFile.first, m.viewedFile.last = m.toolViewModal.VisibleLines()
 		m.toolViewModal = nil
 		return *m, nil, true
 	}
@@ -295,6 +394,53 @@ func (m *Model) updateToolViewModal(msg tea.Msg) (Model, tea.Cmd, bool) {
 	return *m, nil, false
 }
 
+// scratchpadContent returns the agent's current plan, or a placeholder.
+func (m *Model) scratchpadContent() string {
+	if m.scratchpad != nil {
+		if content := m.scratchpad.Content(); content != "" {
+			return content
+		}
+	}
+	return "(no plan yet — the agent writes one with TodoWrite)"
+}
+
+func (m *Model) openScratchpadModal() {
+	tv := modal.NewToolView("Plan", m.scratchpadContent(), modal.Colors{
+		Fg:     palette.Fg,
+		Bg:     palette.Bg,
+		Dim:    palette.Dim,
+		SelFg:  palette.Bg,
+		SelBg:  palette.Fg,
+		Border: palette.Border,
+	})
+	m.scratchpadModal = &tv
+}
+
+func (m *Model) updateScratchpadModal(msg tea.Msg) (Model, tea.Cmd, bool) {
+	if m.scratchpadModal == nil {
+		return *m, nil, false
+	}
+	// ctrl+t toggles the panel closed again.
+	if kp, ok := msg.(tea.KeyPressMsg); ok && kp.Keystroke() == "ctrl+t" {
+		m.scratchpadModal = nil
+		return *m, nil, true
+	}
+	action, cmd := m.scratchpadModal.HandleMsg(msg)
+	switch action.(type) {
+	case modal.ActionClose:
+		m.scratchpadModal = nil
+		return *m, nil, true
+	}
+	if cmd != nil {
+		return *m, cmd, true
+	}
+	switch msg.(type) {
+	case tea.KeyPressMsg, tea.MouseMsg:
+		return *m, nil, true
+	}
+	return *m, nil, false
+}
+
 func (m *Model) handleModelsFetched(msg modelsFetchedMsg) tea.Model {
 	if msg.err != nil {
 		log.Error().Err(msg.err).Msg("handleModelsFetched error")
@@ -328,8 +474,15 @@ func (m *Model) handleModelSwitched(msg modelSwitchedMsg) tea.Model {
 		m.sharedProvider.Store(&prov)
 	}
 	m.currentModelName = msg.modelName
+	tempChanged := !sameTemp(msg.opts.Temperature, m.providerOpts.Temperature)
+	m.providerOpts = msg.opts
 	if msg.providerName != "" {
 		m.providerConfigName = msg.providerName
 	}
+	if msg.profile != "" {
+		m.appendText("", m.styles.Dim.Render(fmt.Sprintf("profile %s: %s/%s", msg.profile, msg.providerName, msg.modelName)), "")
+	} else if tempChanged {
+		m.appendText("", m.styles.Dim.Render("temperature: "+formatTemp(msg.opts.Temperature)), "")
+	}
 	return m
 }
@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/xonecas/symb/internal/config"
+	"github.com/xonecas/symb/internal/provider"
+)
+
+// parseProfileCommand recognises "/profile" and "/profile NAME". An empty
+// name lists the configured profiles.
+func parseProfileCommand(input string) (name string, ok bool, err error) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 || fields[0] != "/profile" {
+		return "", false, nil
+	}
+	switch len(fields) {
+	case 1:
+		return "", true, nil
+	case 2:
+		return fields[1], true, nil
+	}
+	return "", true, fmt.Errorf("usage: /profile [name]")
+}
+
+// handleProfile switches to the named profile, or lists profiles when name
+// is empty.
+func (m *Model) handleProfile(name string) (Model, tea.Cmd) {
+	if name == "" {
+		m.appendText("", m.styles.Dim.Render(m.profileList()), "")
+		return *m, nil
+	}
+	prof, ok := m.profiles[name]
+	if !ok {
+		m.appendText("", m.styles.Error.Render(fmt.Sprintf("profile %q not found; %s", name, m.profileList())), "")
+		return *m, nil
+	}
+	opts := m.providerOpts
+	opts.Temperature = provider.ConfiguredTemperature(prof.Temperature)
+	return *m, m.switchProviderCmd(prof.Provider, prof.Model, opts, name)
+}
+
+// profileList renders the configured profiles, marking the one matching
+// the active provider and model.
+func (m *Model) profileList() string {
+	if len(m.profiles) == 0 {
+		return "no profiles configured; add [profiles.<name>] to config.toml"
+	}
+	names := make([]string, 0, len(m.profiles))
+	for name := range m.profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = profileLabel(name, m.profiles[name])
+		if m.profiles[name].Provider == m.providerConfigName && m.profiles[name].Model == m.currentModelName {
+			parts[i] += " (active)"
+		}
+	}
+	return "profiles: " + strings.Join(parts, ", ")
+}
+
+func profileLabel(name string, p config.ProfileConfig) string {
+	return fmt.Sprintf("%s → %s/%s", name, p.Provider, p.Model)
+}
@@ -0,0 +1,24 @@
+package tui
+
+import "testing"
+
+func TestParseProfileCommand(t *testing.T) {
+	tests := []struct {
+		in      string
+		name    string
+		ok      bool
+		wantErr bool
+	}{
+		{"/profile", "", true, false},
+		{" /profile fast ", "fast", true, false},
+		{"/profile a b", "", true, true},
+		{"/profiles", "", false, false},
+		{"profile fast", "", false, false},
+	}
+	for _, tt := range tests {
+		name, ok, err := parseProfileCommand(tt.in)
+		if name != tt.name || ok != tt.ok || (err != nil) != tt.wantErr {
+			t.Errorf("parseProfileCommand(%q) = %q, %v, %v", tt.in, name, ok, err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// promptArgRe matches a template placeholder: $1 through $9, or $@ for all
+// arguments.
+var promptArgRe = regexp.MustCompile(`\$[1-9@]`)
+
+// parsePromptCommand recognises "/prompt" and "/prompt NAME ARGS...". An
+// empty name lists the configured templates.
+func parsePromptCommand(input string) (name string, args []string, ok bool) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 || fields[0] != "/prompt" {
+		return "", nil, false
+	}
+	if len(fields) == 1 {
+		return "", nil, true
+	}
+	return fields[1], fields[2:], true
+}
+
+// expandPrompt substitutes args into tmpl's placeholders. Templates that
+// reference more arguments than given are an error.
+func expandPrompt(tmpl string, args []string) (string, error) {
+	need := 0
+	text := promptArgRe.ReplaceAllStringFunc(tmpl, func(p string) string {
+		if p == "$@" {
+			return strings.Join(args, " ")
+		}
+		n := int(p[1] - '0')
+		need = max(need, n)
+		if n > len(args) {
+			return p
+		}
+		return args[n-1]
+	})
+	if need > len(args) {
+		return "", fmt.Errorf("needs %d argument(s), got %d", need, len(args))
+	}
+	return text, nil
+}
+
+// promptText expands the named template with args.
+func (m *Model) promptText(name string, args []string) (string, error) {
+	tmpl, ok := m.prompts[name]
+	if !ok {
+		return "", fmt.Errorf("prompt %q not found; %s", name, m.promptList())
+	}
+	text, err := expandPrompt(tmpl, args)
+	if err != nil {
+		return "", fmt.Errorf("/prompt %s: %w", name, err)
+	}
+	return text, nil
+}
+
+// handlePromptList lists the configured templates.
+func (m *Model) handlePromptList() (Model, tea.Cmd) {
+	m.appendText("", m.styles.Dim.Render(m.promptList()), "")
+	return *m, nil
+}
+
+// promptList renders the configured template names.
+func (m *Model) promptList() string {
+	if len(m.prompts) == 0 {
+		return "no prompts configured; add [prompts] to config.toml"
+	}
+	return "prompts: " + strings.Join(m.promptNames(), ", ")
+}
+
+func (m *Model) promptNames() []string {
+	names := make([]string, 0, len(m.prompts))
+	for name := range m.prompts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
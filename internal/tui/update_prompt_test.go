@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/xonecas/symb/internal/config"
+	"github.com/xonecas/symb/internal/provider"
+)
+
+func TestExpandPrompt(t *testing.T) {
+	tests := []struct {
+		tmpl    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{"Review @$1 for bugs", []string{"a.go"}, "Review @a.go for bugs", false},
+		{"Compare $2 with $1", []string{"x", "y"}, "Compare y with x", false},
+		{"Explain $@", []string{"foo", "bar"}, "Explain foo bar", false},
+		{"No args", []string{"ignored"}, "No args", false},
+		{"Review $1 and $2", []string{"a.go"}, "", true},
+	}
+	for _, tt := range tests {
+		got, err := expandPrompt(tt.tmpl, tt.args)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("expandPrompt(%q, %q) = %q, %v", tt.tmpl, tt.args, got, err)
+		}
+	}
+}
+
+// TestPromptPicker verifies typing a space after "/prompt" opens the
+// template picker and picking one completes the command.
+func TestPromptPicker(t *testing.T) {
+	initTheme("vulcan")
+	prompts := map[string]string{"review": "Review @$1", "explain": "Explain $@"}
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, prompts, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+
+	m.agentInput.SetValue("/prompt")
+	for _, k := range []tea.KeyPressMsg{{Code: tea.KeyEnd}, {Code: tea.KeySpace, Text: " "}} {
+		updated, _ = m.Update(k)
+		m = updated.(Model)
+	}
+	if m.promptModal == nil {
+		t.Fatal("prompt picker not opened")
+	}
+	for _, k := range []tea.KeyPressMsg{{Code: tea.KeyDown}, {Code: tea.KeyDown}, {Code: tea.KeyEnter}} {
+		updated, _ = m.Update(k)
+		m = updated.(Model)
+	}
+	if m.promptModal != nil {
+		t.Error("prompt picker still open")
+	}
+	if got := m.agentInput.Value(); got != "/prompt review " {
+		t.Errorf("input = %q, want %q", got, "/prompt review ")
+	}
+}
@@ -7,12 +7,29 @@ import (
 // handleResize applies a window size change and re-derives layout.
 func (m *Model) handleResize(msg tea.WindowSizeMsg) {
 	m.width, m.height = msg.Width, msg.Height
-	m.layout = generateLayout(m.width, m.height)
+	m.layout = generateLayout(m.width, m.height, max(m.layout.input.Dy(), inputRows))
 	m.updateComponentSizes()
+	m.fitInput()
 }
 
 // updateComponentSizes pushes layout dimensions to sub-models.
 func (m *Model) updateComponentSizes() {
 	m.agentInput.SetWidth(m.layout.input.Dx() - 2) // padding for border
-	m.agentInput.SetHeight(inputRows)
+	m.agentInput.SetHeight(m.layout.input.Dy())
+}
+
+// fitInput grows or shrinks the agent input to its content, between
+// inputRows and inputMaxRows but never past half the screen, and re-derives
+// the layout when its height changes.
+func (m *Model) fitInput() {
+	if m.width == 0 {
+		return
+	}
+	limit := max(min(m.inputMaxRows, (m.height-statusRows)/2), inputRows)
+	h := min(max(m.agentInput.VisualRowCount(), inputRows), limit)
+	if h == m.layout.input.Dy() {
+		return
+	}
+	m.layout = generateLayout(m.width, m.height, h)
+	m.updateComponentSizes()
 }
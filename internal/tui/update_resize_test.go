@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/xonecas/symb/internal/config"
+	"github.com/xonecas/symb/internal/provider"
+)
+
+// TestInputGrowth verifies the agent input grows with its content up to
+// input_max_rows and shrinks back when cleared, keeping the layout in step.
+func TestInputGrowth(t *testing.T) {
+	initTheme("vulcan")
+	ui := config.UIConfig{InputMaxRows: 6, Placeholder: "Say something"}
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, ui, config.LimitsConfig{}, config.SessionConfig{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+	m = updated.(Model)
+
+	if m.agentInput.Placeholder != "Say something" {
+		t.Errorf("placeholder = %q", m.agentInput.Placeholder)
+	}
+	if got := m.layout.input.Dy(); got != inputRows {
+		t.Fatalf("empty input height = %d, want %d", got, inputRows)
+	}
+
+	for _, tc := range []struct {
+		lines, want int
+	}{
+		{4, 4},
+		{20, 6}, // capped at input_max_rows
+		{1, inputRows},
+	} {
+		updated, _ = m.Update(tea.PasteMsg{Content: strings.Repeat("line\n", tc.lines-1) + "end"})
+		m = updated.(Model)
+		if got := m.layout.input.Dy(); got != tc.want {
+			t.Errorf("%d lines: input height = %d, want %d", tc.lines, got, tc.want)
+		}
+		if m.layout.sep.Max.Y != m.layout.input.Min.Y {
+			t.Errorf("%d lines: separator at %d not above input at %d", tc.lines, m.layout.sep.Min.Y, m.layout.input.Min.Y)
+		}
+		m.agentInput.Reset()
+	}
+}
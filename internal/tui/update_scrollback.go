@@ -0,0 +1,67 @@
+package tui
+
+import (
+	tea "charm.land/bubbletea/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/xonecas/symb/internal/store"
+)
+
+// olderTurnsMsg carries turns loaded from the DB for scrollback.
+type olderTurnsMsg struct {
+	sessionID string
+	msgs      []store.SessionMessage
+	firstID   int64 // DB id of the first loaded message
+	err       error
+}
+
+// olderTurnsBatch is how many turns one scrollback load prepends.
+const olderTurnsBatch = 5
+
+// loadOlderTurnsCmd fetches the turns trimmed from the top of the
+// conversation, if any and none are already loading.
+func (m *Model) loadOlderTurnsCmd() tea.Cmd {
+	if m.store == nil || m.olderBefore <= 0 || m.loadingOlder || m.turnPending {
+		return nil
+	}
+	m.loadingOlder = true
+	db, sessionID, before := m.store, m.sessionID, m.olderBefore
+	return func() tea.Msg {
+		msgs, firstID, err := db.LoadTurnsBefore(sessionID, before, olderTurnsBatch)
+		return olderTurnsMsg{sessionID: sessionID, msgs: msgs, firstID: firstID, err: err}
+	}
+}
+
+// handleOlderTurns prepends loaded turns to the conversation. The scroll
+// offset counts from the bottom, so the visible lines stay put. The next
+// trimOldTurns drops them again, keeping memory bounded.
+func (m *Model) handleOlderTurns(msg olderTurnsMsg) Model {
+	m.loadingOlder = false
+	// A turn that started meanwhile holds a convIdx for its boundary;
+	// shifting entries now would orphan it. The next scroll retries.
+	if msg.sessionID != m.sessionID || m.turnPending {
+		return *m
+	}
+	if msg.err != nil {
+		log.Warn().Err(msg.err).Msg("load older turns failed")
+		return *m
+	}
+	if len(msg.msgs) == 0 {
+		m.olderBefore = 0
+		return *m
+	}
+
+	entries, _ := historyConvEntries(store.ToProviderMessages(msg.msgs), m.styles)
+	m.linkFileRefs(entries)
+	n := len(entries)
+	m.convEntries = append(entries, m.convEntries...)
+	for i := range m.turnBoundaries {
+		m.turnBoundaries[i].convIdx += n
+	}
+	if m.streamEntryStart >= 0 {
+		m.streamEntryStart += n
+	}
+	m.convSel = nil
+	m.frameLines = nil
+	m.olderBefore = msg.firstID
+	return *m
+}
@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/xonecas/symb/internal/config"
+	"github.com/xonecas/symb/internal/provider"
+	"github.com/xonecas/symb/internal/store"
+)
+
+// TestHandleOlderTurns verifies that prepended scrollback shifts turn
+// boundaries and advances the load cursor.
+func TestHandleOlderTurns(t *testing.T) {
+	initTheme("vulcan")
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	m.convEntries = []convEntry{{display: "current"}}
+	m.turnBoundaries = []turnBoundary{{convIdx: 0, dbMsgID: 10}}
+	m.olderBefore = 10
+	m.loadingOlder = true
+
+	m = m.handleOlderTurns(olderTurnsMsg{
+		sessionID: "s",
+		msgs:      []store.SessionMessage{{Role: "user", Content: "older"}, {Role: "assistant", Content: "reply"}},
+		firstID:   7,
+	})
+	if m.loadingOlder || m.olderBefore != 7 {
+		t.Errorf("loadingOlder=%v olderBefore=%d, want false and 7", m.loadingOlder, m.olderBefore)
+	}
+	if got := m.convEntries[m.turnBoundaries[0].convIdx].display; got != "current" {
+		t.Errorf("boundary points at %q after prepend, want %q", got, "current")
+	}
+
+	m.loadingOlder = true
+	m = m.handleOlderTurns(olderTurnsMsg{sessionID: "s"})
+	if m.olderBefore != 0 {
+		t.Errorf("olderBefore = %d after reaching the session start, want 0", m.olderBefore)
+	}
+}
+
+// TestPageConv verifies conversation paging keeps the configured overlap
+// and clamps at both ends.
+func TestPageConv(t *testing.T) {
+	initTheme("vulcan")
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+	for i := range 100 {
+		m.convEntries = append(m.convEntries, convEntry{display: fmt.Sprintf("line %d", i)})
+	}
+	m.agentInput.Blur()
+	convH := m.layout.conv.Dy()
+	total := len(m.wrappedConvLines())
+
+	updated, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyPgUp})
+	m = updated.(Model)
+	if want := convH - 2; m.scrollOffset != want {
+		t.Errorf("after pgup scrollOffset = %d, want %d", m.scrollOffset, want)
+	}
+
+	for range 10 {
+		updated, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyPgUp})
+		m = updated.(Model)
+	}
+	if want := total - convH; m.scrollOffset != want {
+		t.Errorf("at top scrollOffset = %d, want %d", m.scrollOffset, want)
+	}
+
+	for range 10 {
+		updated, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyPgDown})
+		m = updated.(Model)
+	}
+	if m.scrollOffset != 0 {
+		t.Errorf("at bottom scrollOffset = %d, want 0", m.scrollOffset)
+	}
+}
@@ -2,10 +2,32 @@ package tui
 
 import (
 	tea "charm.land/bubbletea/v2"
+	"github.com/rs/zerolog/log"
 )
 
-// handleLSPDiag is a no-op now that the editor pane is removed.
-func (m *Model) handleLSPDiag(_ LSPDiagnosticsMsg) Model {
+// handleLSPDiag keeps the latest diagnostics per file and refreshes the
+// file view if it shows that file.
+func (m *Model) handleLSPDiag(msg LSPDiagnosticsMsg) Model {
+	if len(msg.Lines) == 0 {
+		delete(m.diagnostics, msg.FilePath)
+	} else {
+		if m.diagnostics == nil {
+			m.diagnostics = make(map[string]LSPDiagnosticsMsg)
+		}
+		m.diagnostics[msg.FilePath] = msg
+	}
+	if m.toolViewModal != nil && m.viewedFile.absPath == msg.FilePath {
+		m.toolViewModal.SetDiagnostics(m.viewedFile.diagnostics(msg))
+	}
+	return *m
+}
+
+// handleIndexDone clears the indexing indicator once the symbol index is built.
+func (m *Model) handleIndexDone(msg IndexDoneMsg) Model {
+	m.indexing = false
+	if msg.Err != nil {
+		log.Warn().Err(msg.Err).Msg("tree-sitter index build failed")
+	}
 	return *m
 }
 
@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// parseTempCommand recognises "/temp" and "/temp VALUE". A negative value
+// means "show the current temperature".
+func parseTempCommand(input string) (temp float64, ok bool, err error) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 || fields[0] != "/temp" {
+		return 0, false, nil
+	}
+	switch len(fields) {
+	case 1:
+		return -1, true, nil
+	case 2:
+		t, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || t < 0 || t > 2 {
+			return 0, true, fmt.Errorf("temperature must be a number between 0.0 and 2.0")
+		}
+		return t, true, nil
+	}
+	return 0, true, fmt.Errorf("usage: /temp [value]")
+}
+
+// handleTemp recreates the active provider with a new temperature, or shows
+// the current one when temp is negative.
+func (m *Model) handleTemp(temp float64) (Model, tea.Cmd) {
+	if temp < 0 {
+		m.appendText("", m.styles.Dim.Render("temperature: "+formatTemp(m.providerOpts.Temperature)), "")
+		return *m, nil
+	}
+	opts := m.providerOpts
+	opts.Temperature = &temp
+	return *m, m.switchProviderCmd(m.providerConfigName, m.currentModelName, opts, "")
+}
+
+// formatTemp renders a temperature; nil leaves it to the provider.
+func formatTemp(t *float64) string {
+	if t == nil {
+		return "provider default"
+	}
+	return strconv.FormatFloat(*t, 'f', -1, 64)
+}
+
+// sameTemp reports whether two temperatures are equal, nil included.
+func sameTemp(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
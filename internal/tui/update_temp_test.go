@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/xonecas/symb/internal/provider"
+)
+
+func TestParseTempCommand(t *testing.T) {
+	tests := []struct {
+		in      string
+		temp    float64
+		ok      bool
+		wantErr bool
+	}{
+		{"/temp", -1, true, false},
+		{" /temp 0.2 ", 0.2, true, false},
+		{"/temp 0", 0, true, false},
+		{"/temp 2", 2, true, false},
+		{"/temp 2.5", 0, true, true},
+		{"/temp -1", 0, true, true},
+		{"/temp hot", 0, true, true},
+		{"/temp 1 2", 0, true, true},
+		{"/temperature", 0, false, false},
+		{"temp 1", 0, false, false},
+	}
+	for _, tt := range tests {
+		temp, ok, err := parseTempCommand(tt.in)
+		if temp != tt.temp || ok != tt.ok || (err != nil) != tt.wantErr {
+			t.Errorf("parseTempCommand(%q) = %v, %v, %v", tt.in, temp, ok, err)
+		}
+	}
+}
+
+// TestTempZero verifies /temp 0 sends an explicit zero rather than leaving
+// the temperature to the provider.
+func TestTempZero(t *testing.T) {
+	reg := provider.NewRegistry()
+	reg.RegisterFactory("local", provider.NewOllamaFactory("local", "http://127.0.0.1:1"))
+	m := Model{registry: reg, providerConfigName: "local", currentModelName: "m", styles: DefaultStyles()}
+
+	_, cmd := m.handleTemp(0)
+	msg, ok := cmd().(modelSwitchedMsg)
+	if !ok || msg.err != nil {
+		t.Fatalf("switch = %#v", msg)
+	}
+	if msg.opts.Temperature == nil || *msg.opts.Temperature != 0 {
+		t.Errorf("temperature = %v, want an explicit 0", msg.opts.Temperature)
+	}
+	if got := formatTemp(msg.opts.Temperature); got != "0" {
+		t.Errorf("formatTemp = %q", got)
+	}
+	if got := formatTemp(nil); got != "provider default" {
+		t.Errorf("formatTemp(nil) = %q", got)
+	}
+}
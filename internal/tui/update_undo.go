@@ -3,6 +3,7 @@ package tui
 import (
 	"context"
 	"errors"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/rs/zerolog/log"
@@ -23,7 +24,7 @@ func (m *Model) demoteOldUndo() {
 // trimOldTurns drops the oldest display turns when we exceed maxDisplayTurns.
 // Messages live in the DB — this only trims display entries to bound rendering cost.
 func (m *Model) trimOldTurns() {
-	for len(m.turnBoundaries) > maxDisplayTurns {
+	for m.maxDisplayTurns >= 0 && len(m.turnBoundaries) > m.maxDisplayTurns {
 		cutConv := m.turnBoundaries[1].convIdx
 
 		m.convEntries = m.convEntries[cutConv:]
@@ -32,6 +33,9 @@ func (m *Model) trimOldTurns() {
 		for i := range m.turnBoundaries {
 			m.turnBoundaries[i].convIdx -= cutConv
 		}
+		if id := m.turnBoundaries[0].dbMsgID; id > 0 {
+			m.olderBefore = id
+		}
 	}
 
 	m.frameLines = nil
@@ -64,6 +68,7 @@ func (m *Model) handleUndo() (Model, tea.Cmd) {
 	m.totalOutputTokens = tb.outputTokens
 	m.turnInputTokens = 0
 	m.turnOutputTokens = 0
+	m.markCheckpoint(time.Now(), false)
 
 	// 1. Truncate display entries.
 	m.convEntries = m.convEntries[:tb.convIdx]
@@ -85,8 +90,7 @@ func (m *Model) handleUndo() (Model, tea.Cmd) {
 	// 7. Reset streaming state.
 	m.streaming = false
 	m.streamEntryStart = -1
-	m.streamingReasoning = ""
-	m.streamingContent = ""
+	m.streamSegments = nil
 
 	// 8. Scroll to bottom.
 	m.scrollOffset = 0
@@ -101,6 +105,7 @@ func (m *Model) undoSideEffectsCmd(dbMsgID int64) tea.Cmd {
 	store := m.store
 	fileTracker := m.fileTracker
 	tsIndex := m.tsIndex
+	proxy := m.mcpProxy
 	sessionID := m.sessionID
 	return func() tea.Msg {
 		var undoErr error
@@ -117,6 +122,9 @@ func (m *Model) undoSideEffectsCmd(dbMsgID int64) tea.Cmd {
 		if fileTracker != nil {
 			fileTracker.Reset()
 		}
+		if proxy != nil {
+			proxy.InvalidateFiles(restoredFiles...)
+		}
 		if tsIndex != nil {
 			for _, f := range restoredFiles {
 				tsIndex.UpdateFile(f)
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/xonecas/symb/internal/config"
+	"github.com/xonecas/symb/internal/provider"
+)
+
+// TestTrimOldTurns verifies the display cap and that turnBoundaries stay
+// aligned with convEntries after trimming.
+func TestTrimOldTurns(t *testing.T) {
+	initTheme("vulcan")
+	for _, tt := range []struct {
+		cap, want int
+	}{
+		{3, 3},
+		{8, 6},
+		{-1, 6},
+	} {
+		m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{MaxDisplayTurns: tt.cap}, config.LimitsConfig{}, config.SessionConfig{})
+		updated, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+		m = updated.(Model)
+		m.convEntries = nil
+		for i := range 6 {
+			m.turnBoundaries = append(m.turnBoundaries, turnBoundary{convIdx: len(m.convEntries), dbMsgID: int64(i + 1)})
+			m.convEntries = append(m.convEntries,
+				convEntry{display: fmt.Sprintf("user %d", i)},
+				convEntry{display: fmt.Sprintf("reply %d", i)},
+				convEntry{display: ""})
+		}
+
+		m.trimOldTurns()
+
+		if len(m.turnBoundaries) != tt.want {
+			t.Fatalf("cap %d: kept %d turns, want %d", tt.cap, len(m.turnBoundaries), tt.want)
+		}
+		for _, b := range m.turnBoundaries {
+			want := fmt.Sprintf("user %d", b.dbMsgID-1)
+			if got := m.convEntries[b.convIdx].display; got != want {
+				t.Errorf("cap %d: boundary %d points at %q, want %q", tt.cap, b.dbMsgID, got, want)
+			}
+		}
+	}
+}
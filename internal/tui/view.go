@@ -13,18 +13,26 @@ import (
 func (m Model) View() tea.View {
 	content := m.renderContent()
 	switch {
+	case m.confirmModal != nil:
+		content = m.confirmModal.view.View(m.width, m.height)
 	case m.keybindsModal != nil:
 		content = m.keybindsModal.View(m.width, m.height)
 	case m.fileModal != nil:
 		content = m.fileModal.View(m.width, m.height)
 	case m.modelsModal != nil:
 		content = m.modelsModal.View(m.width, m.height)
+	case m.promptModal != nil:
+		content = m.promptModal.View(m.width, m.height)
 	case m.toolViewModal != nil:
 		content = m.toolViewModal.View(m.width, m.height)
+	case m.scratchpadModal != nil:
+		content = m.scratchpadModal.View(m.width, m.height)
 	}
 	v := tea.NewView(content)
 	v.AltScreen = true
-	v.MouseMode = tea.MouseModeAllMotion
+	if m.mouseEnabled {
+		v.MouseMode = tea.MouseModeAllMotion
+	}
 	return v
 }
 
@@ -3,77 +3,249 @@ package tui
 import (
 	"strconv"
 	"strings"
+	"time"
 
 	"charm.land/lipgloss/v2"
 )
 
+// statusSegments renders each named status-bar segment (see ui.status_left
+// and ui.status_right), or "" when the segment has nothing to show.
+var statusSegments = map[string]func(*Model) string{
+	"git":       (*Model).statusGit,
+	"indexing":  (*Model).statusIndexing,
+	"plan":      (*Model).statusPlan,
+	"offline":   (*Model).statusOffline,
+	"unsaved":   (*Model).statusUnsaved,
+	"budget":    (*Model).statusBudget,
+	"tokens":    (*Model).statusTokens,
+	"turn":      (*Model).statusTurn,
+	"context":   (*Model).statusContext,
+	"timer":     (*Model).statusTimer,
+	"mouse":     (*Model).statusMouse,
+	"highlight": (*Model).statusHighlight,
+	"cursor":    (*Model).statusCursor,
+	"error":     (*Model).statusError,
+	"model":     (*Model).statusModel,
+	"spinner":   (*Model).statusSpinner,
+}
+
 // renderStatusBar writes the status separator and bar.
 func (m Model) renderStatusBar(b *strings.Builder, bgFill lipgloss.Style) {
 	b.WriteString(m.styles.Border.Render(strings.Repeat("─", m.width)))
 	b.WriteByte('\n')
 
-	// -- Left segments --
-	var leftParts []string
+	left := strings.Join(m.renderSegments(m.statusLeft), m.styles.StatusText.Render("  "))
+	if left != "" {
+		left = m.styles.StatusText.Render(" ") + left
+	}
+	right := strings.Join(m.renderSegments(m.statusRight), m.styles.StatusText.Render(" "))
 
-	// Git branch + dirty
-	if m.gitBranch != "" {
-		branch := m.gitBranch
-		if m.gitDirty {
-			branch += "*"
-		}
-		branchPart := m.styles.StatusText.Render(" " + branch)
-		if m.gitAdded+m.gitModified+m.gitRemoved > 0 {
-			counts := strings.Join([]string{
-				m.styles.StatusAdd.Render("+" + strconv.Itoa(m.gitAdded)),
-				m.styles.StatusMod.Render("~" + strconv.Itoa(m.gitModified)),
-				m.styles.StatusDel.Render("-" + strconv.Itoa(m.gitRemoved)),
-			}, m.styles.StatusText.Render(" "))
-			branchPart = strings.Join([]string{branchPart, counts}, m.styles.StatusText.Render(" "))
+	// -- Compose: left + gap + right + trailing space --
+	leftW := lipgloss.Width(left)
+	rightW := lipgloss.Width(right)
+	gap := m.width - leftW - rightW - 1
+	if gap < 0 {
+		gap = 0
+	}
+	b.WriteString(left)
+	b.WriteString(bgFill.Render(strings.Repeat(" ", gap)))
+	b.WriteString(right)
+	b.WriteString(bgFill.Render(" "))
+}
+
+// renderSegments renders the named segments in order, dropping empty ones.
+func (m *Model) renderSegments(names []string) []string {
+	var parts []string
+	for _, name := range names {
+		if render, ok := statusSegments[name]; ok {
+			if s := render(m); s != "" {
+				parts = append(parts, s)
+			}
 		}
-		leftParts = append(leftParts, branchPart)
 	}
+	return parts
+}
 
-	left := strings.Join(leftParts, m.styles.StatusText.Render("  "))
+// statusGit shows the git branch, a dirty marker and change counts.
+func (m *Model) statusGit() string {
+	if m.gitBranch == "" {
+		return ""
+	}
+	branch := m.gitBranch
+	if m.gitDirty {
+		branch += "*"
+	}
+	branchPart := m.styles.StatusText.Render(branch)
+	if m.gitAdded+m.gitModified+m.gitRemoved > 0 {
+		counts := strings.Join([]string{
+			m.styles.StatusAdd.Render("+" + strconv.Itoa(m.gitAdded)),
+			m.styles.StatusMod.Render("~" + strconv.Itoa(m.gitModified)),
+			m.styles.StatusDel.Render("-" + strconv.Itoa(m.gitRemoved)),
+		}, m.styles.StatusText.Render(" "))
+		branchPart = strings.Join([]string{branchPart, counts}, m.styles.StatusText.Render(" "))
+	}
+	return branchPart
+}
 
-	// -- Right segments --
-	var rightParts []string
+// statusIndexing shows background symbol indexing progress.
+func (m *Model) statusIndexing() string {
+	if !m.indexing {
+		return ""
+	}
+	label := "indexing…"
+	if m.indexedFiles > 0 {
+		label = "indexing… " + strconv.Itoa(m.indexedFiles)
+	}
+	return m.styles.Dim.Render(label)
+}
 
-	// Network error (truncated)
-	if m.lastNetError != "" {
-		errText := m.lastNetError
-		if len(errText) > 30 {
-			errText = errText[:30] + "…"
-		}
-		rightParts = append(rightParts, m.styles.Error.Render("✗ "+errText))
+// statusPlan marks that the agent has a plan (ctrl+t to view).
+func (m *Model) statusPlan() string {
+	if m.scratchpad == nil || m.scratchpad.Content() == "" {
+		return ""
+	}
+	return m.styles.StatusText.Render("☰ plan")
+}
+
+// statusOffline marks offline mode: network tools and remote providers blocked.
+func (m *Model) statusOffline() string {
+	if m.mcpProxy == nil || !m.mcpProxy.Offline() {
+		return ""
+	}
+	return m.styles.StatusText.Render("offline")
+}
+
+// statusUnsaved warns that message writes failed or the queue is backing up.
+func (m *Model) statusUnsaved() string {
+	if m.storeFailures.Load() == 0 && !m.storeBacklogged() {
+		return ""
+	}
+	return m.styles.Warning.Render("unsaved")
+}
+
+// statusBudget shows session tokens against the session token budget.
+func (m *Model) statusBudget() string {
+	if m.limits.SessionTokens <= 0 {
+		return ""
 	}
+	label := "tok " + formatTokens(m.totalInputTokens+m.totalOutputTokens) + "/" + formatTokens(m.limits.SessionTokens)
+	style := m.styles.StatusText
+	if m.sessionBudgetSpent() {
+		style = m.styles.Error
+	}
+	return style.Render(label)
+}
+
+// statusTokens shows the session's input and output tokens.
+func (m *Model) statusTokens() string {
+	if m.totalInputTokens+m.totalOutputTokens == 0 {
+		return ""
+	}
+	return m.styles.StatusText.Render("↑" + formatTokens(m.totalInputTokens) + " ↓" + formatTokens(m.totalOutputTokens))
+}
 
-	// Provider config name + model
+// statusTurn shows the current turn's elapsed time and output token rate,
+// frozen once the turn ends. Usage arrives per API call, so the rate
+// updates in steps.
+func (m *Model) statusTurn() string {
+	if m.turnStart.IsZero() {
+		return ""
+	}
+	end := m.turnEnd
+	if end.IsZero() {
+		end = time.Now()
+	}
+	elapsed := end.Sub(m.turnStart)
+	label := strconv.FormatFloat(elapsed.Seconds(), 'f', 1, 64) + "s"
+	if m.turnOutputTokens > 0 && elapsed >= time.Second {
+		label += " " + strconv.Itoa(int(float64(m.turnOutputTokens)/elapsed.Seconds()+0.5)) + " tok/s"
+	}
+	return m.styles.StatusText.Render(label)
+}
+
+// statusContext shows the context window utilization of the last API call.
+func (m *Model) statusContext() string {
+	used := m.contextUtilization()
+	if used <= 0 {
+		return ""
+	}
+	style := m.styles.StatusText
+	switch {
+	case used >= 0.9:
+		style = m.styles.Error
+	case used >= 0.75:
+		style = m.styles.Warning
+	}
+	return style.Render("ctx " + strconv.Itoa(int(used*100+0.5)) + "%")
+}
+
+// statusTimer shows the turn's elapsed time against its wall-clock limit.
+func (m *Model) statusTimer() string {
+	if m.limits.TurnSeconds <= 0 || !m.llmInFlight {
+		return ""
+	}
+	elapsed := int(time.Since(m.turnStart).Seconds())
+	return m.styles.StatusText.Render("⏱ " + strconv.Itoa(elapsed) + "/" + strconv.Itoa(m.limits.TurnSeconds) + "s")
+}
+
+// statusMouse marks mouse capture as off (ctrl+g to re-enable).
+func (m *Model) statusMouse() string {
+	if m.mouseEnabled {
+		return ""
+	}
+	return m.styles.StatusText.Render("mouse off")
+}
+
+// statusHighlight marks input highlighting as off (toggled or over the line cap).
+func (m *Model) statusHighlight() string {
+	if m.agentInput.Highlighting() {
+		return ""
+	}
+	return m.styles.StatusText.Render("hl off")
+}
+
+// statusCursor shows the input cursor position, size and selection,
+// hidden while the input is empty.
+func (m *Model) statusCursor() string {
+	if !m.agentInput.Focused() || m.agentInput.Value() == "" {
+		return ""
+	}
+	row, col := m.agentInput.CursorPos()
+	label := strconv.Itoa(row+1) + ":" + strconv.Itoa(col+1) +
+		" " + strconv.Itoa(m.agentInput.LineCount()) + "L " +
+		strconv.Itoa(m.agentInput.WordCount()) + "w"
+	if n := m.agentInput.SelectionLen(); n > 0 {
+		label += " sel " + strconv.Itoa(n)
+	}
+	return m.styles.StatusText.Render(label)
+}
+
+// statusError shows the last network error, truncated.
+func (m *Model) statusError() string {
+	if m.lastNetError == "" {
+		return ""
+	}
+	errText := m.lastNetError
+	if len(errText) > 30 {
+		errText = errText[:30] + "…"
+	}
+	return m.styles.Error.Render("✗ " + errText)
+}
+
+// statusModel shows the provider config name and model.
+func (m *Model) statusModel() string {
 	providerLabel := m.providerConfigName
 	if m.currentModelName != "" {
 		providerLabel += "/" + m.currentModelName
 	}
-	rightParts = append(rightParts, m.styles.StatusText.Render(providerLabel))
+	return m.styles.StatusText.Render(providerLabel)
+}
 
-	// Animated braille spinner — red on error, accent otherwise
+// statusSpinner is the animated braille spinner: red on error, accent otherwise.
+func (m *Model) statusSpinner() string {
 	frame := brailleFrames[m.spinFrame%len(brailleFrames)]
 	if m.lastNetError != "" {
-		frame = m.styles.Error.Render(frame)
-	} else {
-		frame = lipgloss.NewStyle().Background(ColorBg).Foreground(ColorHighlight).Render(frame)
-	}
-	rightParts = append(rightParts, frame)
-
-	right := strings.Join(rightParts, m.styles.StatusText.Render(" "))
-
-	// -- Compose: left + gap + right + trailing space --
-	leftW := lipgloss.Width(left)
-	rightW := lipgloss.Width(right)
-	gap := m.width - leftW - rightW - 1
-	if gap < 0 {
-		gap = 0
+		return m.styles.Error.Render(frame)
 	}
-	b.WriteString(left)
-	b.WriteString(bgFill.Render(strings.Repeat(" ", gap)))
-	b.WriteString(right)
-	b.WriteString(bgFill.Render(" "))
+	return lipgloss.NewStyle().Background(ColorBg).Foreground(ColorHighlight).Render(frame)
 }
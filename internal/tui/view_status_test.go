@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/xonecas/symb/internal/config"
+	"github.com/xonecas/symb/internal/provider"
+)
+
+// TestStatusSegments verifies the default bar and that configured segments
+// appear in their configured order, with unlisted ones left out.
+func TestStatusSegments(t *testing.T) {
+	initTheme("vulcan")
+	status := func(ui config.UIConfig) string {
+		m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, ui, config.LimitsConfig{}, config.SessionConfig{})
+		updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+		m = updated.(Model)
+		m.gitBranch = "main"
+		m.mouseEnabled = false
+		m.totalInputTokens, m.totalOutputTokens = 1200, 300
+		var b strings.Builder
+		m.renderStatusBar(&b, m.styles.BgFill)
+		return strings.TrimSpace(strings.SplitN(ansi.Strip(b.String()), "\n", 2)[1])
+	}
+
+	got := status(config.UIConfig{})
+	if !strings.HasPrefix(got, "main  mouse off") || !strings.Contains(got, "p/") || strings.Contains(got, "↑") {
+		t.Errorf("default bar = %q", got)
+	}
+
+	got = status(config.UIConfig{StatusLeft: []string{"tokens", "git"}, StatusRight: []string{}})
+	if got != "↑1.2k ↓300  main" {
+		t.Errorf("configured bar = %q, want %q", got, "↑1.2k ↓300  main")
+	}
+}
+
+// TestTurnReadout verifies the turn segment shows elapsed time and output
+// token rate while a turn runs and keeps its final numbers once it ends.
+func TestTurnReadout(t *testing.T) {
+	initTheme("vulcan")
+	m := New(nil, nil, nil, nil, "test", nil, "s", nil, nil, nil, "p", nil, nil, nil, provider.Options{}, nil, nil, config.UIConfig{}, config.LimitsConfig{}, config.SessionConfig{})
+	if got := m.statusTurn(); got != "" {
+		t.Fatalf("readout before any turn: %q", got)
+	}
+
+	m.llmInFlight = true
+	m.turnStart = time.Now().Add(-10 * time.Second)
+	updated, _ := m.Update(llmBatchMsg{llmUsageMsg{inputTokens: 1000, outputTokens: 500}})
+	m = updated.(Model)
+	if got := ansi.Strip(m.statusTurn()); !strings.HasPrefix(got, "10.") || !strings.HasSuffix(got, "s 50 tok/s") {
+		t.Errorf("running readout = %q, want ~10s at 50 tok/s", got)
+	}
+
+	m.finishTurn()
+	frozen := ansi.Strip(m.statusTurn())
+	m.turnStart = m.turnStart.Add(-time.Minute) // a frozen readout ignores the clock
+	m.turnEnd = m.turnEnd.Add(-time.Minute)
+	if got := ansi.Strip(m.statusTurn()); got != frozen {
+		t.Errorf("readout after the turn = %q, want frozen %q", got, frozen)
+	}
+}